@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/api"
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"gorm.io/gorm"
+)
+
+// 应用依赖，在启动时按顺序构建仓储、事件存储和服务
+type container struct {
+	repos *repository.Repositories
+	store service.EventStore
+
+	authService      *service.AuthService
+	projectService   *service.ProjectService
+	eventService     *service.EventService
+	retentionService *service.RetentionService
+	budgetService    *service.BudgetService
+	rollupService    *service.RollupService
+
+	handler *api.Handler
+}
+
+// 构建应用依赖
+func newContainer(db *gorm.DB) (*container, error) {
+	repos := repository.New(db)
+	store, err := service.NewEventStore(repos, model.EventStoreSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &container{
+		repos:            repos,
+		store:            store,
+		authService:      service.NewAuthService(repos.Users),
+		projectService:   service.NewProjectService(repos.Projects),
+		eventService:     service.NewEventService(repos, store),
+		retentionService: service.NewRetentionService(repos, store),
+		budgetService:    service.NewBudgetService(repos.Projects, repos.Performance),
+		rollupService:    service.NewRollupService(repos),
+	}
+	c.handler = api.NewHandler(c.authService, c.projectService, c.eventService, c.retentionService, c.budgetService)
+	return c, nil
+}
+
+// 启动后台定时任务
+func (c *container) startBackgroundJobs() {
+	service.StartBackgroundJobs(c.repos.Events, c.eventService, c.retentionService, c.rollupService, c.store)
+}
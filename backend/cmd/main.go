@@ -1,17 +1,23 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
-	"github.com/akinoccc/web-tracing-admin/internal/api"
 	"github.com/akinoccc/web-tracing-admin/internal/middleware"
 	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/akinoccc/web-tracing-admin/migrations"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "github.com/akinoccc/web-tracing-admin/docs"
+	// 内置时区数据，运行镜像中没有 tzdata 时统计接口仍可按时区分组
+	_ "time/tzdata"
 )
 
 // @title Web Tracing Admin API
@@ -37,6 +43,24 @@ func main() {
 	// 初始化配置
 	model.Setup()
 
+	// 执行子命令
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	// 执行数据库迁移
+	migrateOnStart()
+
+	// 构建服务依赖
+	app, err := newContainer(model.GetDB())
+	if err != nil {
+		log.Fatalf("Failed to setup event store: %v", err)
+	}
+
+	// 启动后台任务
+	app.startBackgroundJobs()
+
 	// 创建 Gin 实例
 	r := gin.Default()
 
@@ -44,7 +68,7 @@ func main() {
 	r.Use(middleware.CORS())
 
 	// 注册路由
-	registerRoutes(r)
+	registerRoutes(r, app)
 
 	// 启动服务器
 	port := fmt.Sprintf(":%d", model.ServerSetting.HttpPort)
@@ -52,42 +76,194 @@ func main() {
 	r.Run(port)
 }
 
+// 执行子命令
+func runCommand(name string, args []string) {
+	switch name {
+	case "backfill":
+		runBackfill(args)
+	case "migrate":
+		runMigrate(args)
+	default:
+		log.Fatalf("Unknown command: %s", name)
+	}
+}
+
+// 为已有数据构建预聚合，-rebuild 时清空后从头构建
+func runBackfill(args []string) {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	rebuild := flags.Bool("rebuild", false, "清空预聚合数据后从头构建")
+	flags.Parse(args)
+
+	rollupService := service.NewRollupService(repository.New(model.GetDB()))
+	var n int
+	var err error
+	if *rebuild {
+		n, err = rollupService.RebuildRollups()
+	} else {
+		n, err = rollupService.BuildRollups()
+	}
+	if err != nil {
+		log.Fatalf("Failed to backfill rollups: %v", err)
+	}
+	log.Printf("Backfilled rollups from %d records", n)
+}
+
+// 启动时执行未执行的迁移，关闭自动迁移时只提示
+func migrateOnStart() {
+	db := model.GetDB()
+	if !model.DatabaseSetting.MigrateOnStart {
+		pending, err := migrations.Pending(db)
+		if err != nil {
+			log.Fatalf("Failed to check migrations: %v", err)
+		}
+		if pending > 0 {
+			log.Printf("%d pending migrations, run `migrate up` to apply", pending)
+		}
+		return
+	}
+
+	applied, err := migrations.Up(db)
+	if err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	for _, migration := range applied {
+		log.Printf("Applied migration %s_%s", migration.Version, migration.Name)
+	}
+}
+
+// 数据库迁移：up 执行全部未执行的迁移，down 回滚最近的迁移，status 查看执行状态
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatalf("Usage: migrate up|down [-steps n]|status")
+	}
+
+	db := model.GetDB()
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(db)
+		for _, migration := range applied {
+			log.Printf("Applied migration %s_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Printf("Database is up to date")
+		}
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "回滚的迁移数量")
+		flags.Parse(args[1:])
+		if *steps < 1 {
+			log.Fatalf("Invalid -steps %d: must be at least 1", *steps)
+		}
+
+		reverted, err := migrations.Down(db, *steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted migration %s_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to revert migration: %v", err)
+		}
+	case "status":
+		statuses, err := migrations.Status(db)
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Unknown {
+				state += " (unknown)"
+			}
+			fmt.Printf("%s_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		log.Fatalf("Unknown migrate command: %s", args[0])
+	}
+}
+
 // 注册路由
-func registerRoutes(r *gin.Engine) {
+func registerRoutes(r *gin.Engine, app *container) {
+	h := app.handler
+
 	// Swagger 文档
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// 数据上报接口 - 不需要认证
-	r.POST("/api/trackweb", api.TrackWeb)
+	r.POST("/api/trackweb", h.TrackWeb)
+	r.POST("/api/otlp/v1/traces", h.OTLPTraces)
+	r.POST("/api/otlp/v1/logs", h.OTLPLogs)
+	r.POST("/sentry/api/:projectId/store/", h.SentryStore)
+	r.POST("/sentry/api/:projectId/envelope/", h.SentryEnvelope)
 
 	// 认证路由
-	r.POST("/api/auth/login", api.Login)
-	r.POST("/api/auth/register", api.Register)
+	r.POST("/api/auth/login", h.Login)
+	r.POST("/api/auth/register", h.Register)
 
 	// 需要认证的路由
 	apiGroup := r.Group("/api")
-	apiGroup.Use(middleware.JWT())
+	apiGroup.Use(middleware.JWT(app.authService))
 	{
 		// 项目路由
-		apiGroup.POST("/projects", api.CreateProject)
-		apiGroup.GET("/projects", api.GetProjects)
-		apiGroup.GET("/projects/:id", api.GetProject)
-		apiGroup.PUT("/projects/:id", api.UpdateProject)
-		apiGroup.DELETE("/projects/:id", api.DeleteProject)
+		apiGroup.POST("/projects", h.CreateProject)
+		apiGroup.GET("/projects", h.GetProjects)
+		apiGroup.GET("/projects/:id", h.GetProject)
+		apiGroup.PUT("/projects/:id", h.UpdateProject)
+		apiGroup.DELETE("/projects/:id", h.DeleteProject)
+
+		// 性能预算路由
+		apiGroup.POST("/projects/:id/budgets", h.CreateBudget)
+		apiGroup.GET("/projects/:id/budgets", h.GetBudgets)
+		apiGroup.GET("/projects/:id/budgets/status", h.GetBudgetStatus)
+		apiGroup.PUT("/projects/:id/budgets/:budgetId", h.UpdateBudget)
+		apiGroup.DELETE("/projects/:id/budgets/:budgetId", h.DeleteBudget)
+
+		// 数据保留路由
+		apiGroup.GET("/projects/:id/retention", h.GetRetention)
+		apiGroup.PUT("/projects/:id/retention", h.UpdateRetention)
+		apiGroup.POST("/projects/:id/purge", h.PurgeProjectData)
+
+		// 事件浏览路由
+		apiGroup.GET("/events", h.GetEvents)
+		apiGroup.GET("/events/stats", h.GetEventStats)
+		apiGroup.GET("/events/stats/distribution", h.GetEventDistribution)
+		apiGroup.GET("/events/stats/browser", h.GetBrowserDistribution)
+		apiGroup.GET("/events/stats/os", h.GetOSDistribution)
+		apiGroup.GET("/events/stats/device", h.GetDeviceDistribution)
+		apiGroup.GET("/events/stats/error-type", h.GetErrorTypeDistribution)
+		apiGroup.GET("/events/:id", h.GetEventDetail)
 
 		// 错误监控路由
-		apiGroup.GET("/errors", api.GetErrors)
-		apiGroup.GET("/errors/:id", api.GetErrorDetail)
-		apiGroup.GET("/errors/stats", api.GetErrorStats)
+		apiGroup.GET("/errors", h.GetErrors)
+		apiGroup.GET("/errors/:id", h.GetErrorDetail)
+		apiGroup.GET("/errors/stats", h.GetErrorStats)
+
+		// 日志路由
+		apiGroup.GET("/logs", h.GetLogs)
+
+		// 链路追踪路由
+		apiGroup.GET("/traces/:traceId", h.GetTraceEvents)
 
 		// 性能监控路由
-		apiGroup.GET("/performance", api.GetPerformance)
-		apiGroup.GET("/performance/stats", api.GetPerformanceStats)
-		apiGroup.GET("/performance/resources", api.GetResourcePerformance)
+		apiGroup.GET("/performance", h.GetPerformance)
+		apiGroup.GET("/performance/stats", h.GetPerformanceStats)
+		apiGroup.GET("/performance/resources", h.GetResourcePerformance)
+		apiGroup.GET("/performance/resources/aggregate", h.GetResourceAggregate)
+		apiGroup.GET("/performance/vitals", h.GetWebVitals)
+		apiGroup.GET("/performance/pages", h.GetPagePerformance)
+		apiGroup.GET("/performance/jank", h.GetJankRanking)
+		apiGroup.GET("/performance/routes", h.GetRouteTiming)
+		apiGroup.GET("/performance/apis", h.GetAPIPerformance)
 
 		// 用户行为路由
-		apiGroup.GET("/behavior/pv", api.GetPageViews)
-		apiGroup.GET("/behavior/clicks", api.GetClicks)
-		apiGroup.GET("/behavior/stats", api.GetBehaviorStats)
+		apiGroup.GET("/behavior/pv", h.GetPageViews)
+		apiGroup.GET("/behavior/clicks", h.GetClicks)
+		apiGroup.GET("/behavior/stats", h.GetBehaviorStats)
+		apiGroup.GET("/behavior/paths", h.GetPagePaths)
+		apiGroup.GET("/behavior/heatmap", h.GetClickHeatmap)
+		apiGroup.GET("/behavior/frustrations", h.GetFrustrations)
 	}
 }
@@ -194,21 +194,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/behavior/pv": {
+        "/api/behavior/frustrations": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的页面访问数据",
+                "description": "按页面和元素统计狂点、无响应点击，并返回受影响的会话，未指定开始时间时统计结束时间前7天",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "用户行为"
                 ],
-                "summary": "获取页面访问数据",
+                "summary": "获取挫败点击排行",
                 "parameters": [
                     {
                         "type": "integer",
@@ -218,17 +218,19 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
+                        "enum": [
+                            "rage",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "挫败类型",
+                        "name": "type",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "pageSize",
+                        "type": "string",
+                        "description": "页面地址",
+                        "name": "pageUrl",
                         "in": "query"
                     },
                     {
@@ -242,13 +244,20 @@ const docTemplate = `{
                         "description": "结束时间戳",
                         "name": "endTime",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "返回数量",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "页面访问数据列表",
+                        "description": "挫败点击排行",
                         "schema": {
-                            "$ref": "#/definitions/service.PVListResponse"
+                            "$ref": "#/definitions/service.FrustrationListResponse"
                         }
                     },
                     "400": {
@@ -272,21 +281,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/behavior/stats": {
+        "/api/behavior/heatmap": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的用户行为统计信息",
+                "description": "按视口宽度归一化的网格统计页面点击分布，并返回点击最多的元素",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "用户行为"
                 ],
-                "summary": "获取用户行为统计信息",
+                "summary": "获取页面点击热力图",
                 "parameters": [
                     {
                         "type": "integer",
@@ -295,6 +304,12 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "页面地址，默认点击最多的页面",
+                        "name": "pageUrl",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "开始时间戳",
@@ -306,13 +321,20 @@ const docTemplate = `{
                         "description": "结束时间戳",
                         "name": "endTime",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "网格列数",
+                        "name": "columns",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户行为统计信息",
+                        "description": "点击热力图",
                         "schema": {
-                            "$ref": "#/definitions/service.BehaviorStatsResponse"
+                            "$ref": "#/definitions/service.HeatmapResponse"
                         }
                     },
                     "400": {
@@ -336,21 +358,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/errors": {
+        "/api/behavior/paths": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的错误列表",
+                "description": "按会话统计页面之间的跳转路径，返回桑基图所需的节点和连线",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "错误监控"
+                    "用户行为"
                 ],
-                "summary": "获取错误列表",
+                "summary": "获取用户页面路径",
                 "parameters": [
                     {
                         "type": "integer",
@@ -359,20 +381,6 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
                         "description": "开始时间戳",
@@ -387,22 +395,29 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "错误类型",
-                        "name": "errorType",
+                        "description": "起始页面",
+                        "name": "startPage",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "严重程度",
-                        "name": "severity",
+                        "description": "结束页面",
+                        "name": "endPage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "路径深度",
+                        "name": "depth",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "错误列表",
+                        "description": "用户路径",
                         "schema": {
-                            "$ref": "#/definitions/service.ErrorListResponse"
+                            "$ref": "#/definitions/service.PathAnalysisResponse"
                         }
                     },
                     "400": {
@@ -426,21 +441,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/errors/stats": {
+        "/api/behavior/pv": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的错误统计信息",
+                "description": "获取项目的页面访问数据",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "错误监控"
+                    "用户行为"
                 ],
-                "summary": "获取错误统计信息",
+                "summary": "获取页面访问数据",
                 "parameters": [
                     {
                         "type": "integer",
@@ -449,6 +464,20 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "开始时间戳",
@@ -464,9 +493,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "错误统计信息",
+                        "description": "页面访问数据列表",
                         "schema": {
-                            "$ref": "#/definitions/service.ErrorStatsResponse"
+                            "$ref": "#/definitions/service.PVListResponse"
                         }
                     },
                     "400": {
@@ -490,35 +519,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/errors/{id}": {
+        "/api/behavior/stats": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取错误详细信息",
+                "description": "获取时间范围内的页面访问和点击统计及趋势，默认最近7天按天统计，同时返回上一周期的对比数据",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "错误监控"
+                    "用户行为"
                 ],
-                "summary": "获取错误详情",
+                "summary": "获取用户行为统计信息",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "错误ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "统计粒度：minute/hour/day/week，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC",
+                        "name": "timezone",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "错误详情",
+                        "description": "用户行为统计信息",
                         "schema": {
-                            "$ref": "#/definitions/service.ErrorDetailResponse"
+                            "$ref": "#/definitions/service.BehaviorStatsResponse"
                         }
                     },
                     "400": {
@@ -533,12 +586,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "错误不存在",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "内部错误",
                         "schema": {
@@ -548,21 +595,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/performance": {
+        "/api/errors": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的性能数据",
+                "description": "获取项目的错误列表",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "性能监控"
+                    "错误监控"
                 ],
-                "summary": "获取性能数据",
+                "summary": "获取错误列表",
                 "parameters": [
                     {
                         "type": "integer",
@@ -598,21 +645,23 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "enum": [
-                            "page",
-                            "resource"
-                        ],
                         "type": "string",
-                        "description": "性能类型",
-                        "name": "type",
+                        "description": "错误类型",
+                        "name": "errorType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "严重程度",
+                        "name": "severity",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "性能数据列表",
+                        "description": "错误列表",
                         "schema": {
-                            "$ref": "#/definitions/service.PerformanceListResponse"
+                            "$ref": "#/definitions/service.ErrorListResponse"
                         }
                     },
                     "400": {
@@ -636,21 +685,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/performance/resources": {
+        "/api/errors/stats": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的资源性能数据",
+                "description": "获取时间范围内的错误统计和趋势，默认最近7天按天统计，同时返回上一周期的对比数据",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "性能监控"
+                    "错误监控"
                 ],
-                "summary": "获取资源性能数据",
+                "summary": "获取错误统计信息",
                 "parameters": [
                     {
                         "type": "integer",
@@ -659,20 +708,6 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
                         "description": "开始时间戳",
@@ -687,16 +722,22 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "资源类型",
-                        "name": "resourceType",
+                        "description": "统计粒度：minute/hour/day/week，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC",
+                        "name": "timezone",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "资源性能数据列表",
+                        "description": "错误统计信息",
                         "schema": {
-                            "$ref": "#/definitions/service.ResourcePerformanceListResponse"
+                            "$ref": "#/definitions/service.ErrorStatsResponse"
                         }
                     },
                     "400": {
@@ -720,47 +761,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/performance/stats": {
+        "/api/errors/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目的性能统计信息",
+                "description": "获取错误详细信息",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "性能监控"
+                    "错误监控"
                 ],
-                "summary": "获取性能统计信息",
+                "summary": "获取错误详情",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "项目ID",
-                        "name": "projectId",
-                        "in": "query",
+                        "description": "错误ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "开始时间戳",
-                        "name": "startTime",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "结束时间戳",
-                        "name": "endTime",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "性能统计信息",
+                        "description": "错误详情",
                         "schema": {
-                            "$ref": "#/definitions/service.PerformanceStatsResponse"
+                            "$ref": "#/definitions/service.ErrorDetailResponse"
                         }
                     },
                     "400": {
@@ -775,6 +804,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "错误不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "内部错误",
                         "schema": {
@@ -784,78 +819,108 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects": {
+        "/api/events": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取当前用户的所有项目",
+                "description": "浏览项目的原始事件，按触发时间倒序，使用游标分页，下一页传入上一页返回的 nextCursor",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "事件"
                 ],
-                "summary": "获取项目列表",
-                "responses": {
-                    "200": {
-                        "description": "项目列表",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Project"
-                            }
-                        }
+                "summary": "获取事件列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
                     },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "事件类型，多个以逗号分隔",
+                        "name": "eventType",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "内部错误",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
                     {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "创建新项目",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "项目"
-                ],
-                "summary": "创建项目",
-                "parameters": [
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
                     {
-                        "description": "项目信息",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.CreateProjectRequest"
-                        }
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "页面URL",
+                        "name": "pageUrl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "sessionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "浏览器",
+                        "name": "browser",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作系统",
+                        "name": "os",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "设备类型",
+                        "name": "deviceType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发布版本",
+                        "name": "release",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页游标",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "事件列表",
                         "schema": {
-                            "$ref": "#/definitions/model.Project"
+                            "$ref": "#/definitions/service.EventListResponse"
                         }
                     },
                     "400": {
@@ -879,35 +944,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects/{id}": {
+        "/api/events/stats": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "获取项目详细信息",
+                "description": "按时间桶统计事件数量，支持与事件列表相同的筛选条件，默认最近7天按天统计，同时返回上一周期对应时间桶的数量",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "事件"
                 ],
-                "summary": "获取项目详情",
+                "summary": "获取事件数量趋势",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
+                        "name": "projectId",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型，多个以逗号分隔",
+                        "name": "eventType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "页面URL",
+                        "name": "pageUrl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "sessionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "浏览器",
+                        "name": "browser",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作系统",
+                        "name": "os",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "设备类型",
+                        "name": "deviceType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "发布版本",
+                        "name": "release",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "统计粒度：minute/hour/day/week，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC",
+                        "name": "timezone",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "项目详情",
+                        "description": "统计数据",
                         "schema": {
-                            "$ref": "#/definitions/model.Project"
+                            "$ref": "#/definitions/service.EventStatsResponse"
                         }
                     },
                     "400": {
@@ -922,12 +1059,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "项目不存在",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "内部错误",
                         "schema": {
@@ -935,47 +1066,62 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/events/stats/browser": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "更新项目信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "按浏览器统计事件数量和占比",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "事件"
                 ],
-                "summary": "更新项目",
+                "summary": "获取浏览器分布",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
+                        "name": "projectId",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "项目信息",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.UpdateProjectRequest"
-                        }
+                        "type": "string",
+                        "description": "事件类型，多个以逗号分隔",
+                        "name": "eventType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "分布项数量",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "统计数据",
                         "schema": {
-                            "$ref": "#/definitions/model.Project"
+                            "$ref": "#/definitions/service.DistributionStatsResponse"
                         }
                     },
                     "400": {
@@ -990,12 +1136,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "项目不存在",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "内部错误",
                         "schema": {
@@ -1003,35 +1143,62 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/events/stats/device": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "删除项目",
+                "description": "按设备类型统计事件数量和占比",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "项目"
+                    "事件"
                 ],
-                "summary": "删除项目",
+                "summary": "获取设备类型分布",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
+                        "name": "projectId",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型，多个以逗号分隔",
+                        "name": "eventType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "分布项数量",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "统计数据",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "$ref": "#/definitions/service.DistributionStatsResponse"
                         }
                     },
                     "400": {
@@ -1046,12 +1213,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "项目不存在",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "内部错误",
                         "schema": {
@@ -1061,35 +1222,279 @@ const docTemplate = `{
                 }
             }
         },
-        "/trackweb": {
-            "post": {
-                "description": "接收SDK上报的错误和性能数据",
-                "consumes": [
-                    "application/json"
+        "/api/events/stats/distribution": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "按维度统计事件数量和占比，超出数量限制的项合并为 other，支持与事件列表相同的筛选条件",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "数据上报"
+                    "事件"
                 ],
-                "summary": "接收SDK上报数据",
+                "summary": "获取事件分布统计",
                 "parameters": [
                     {
-                        "description": "上报数据",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.TrackRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "上报成功",
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "browser",
+                            "browser_version",
+                            "os",
+                            "os_version",
+                            "device_type",
+                            "screen",
+                            "sdk_version",
+                            "release",
+                            "region",
+                            "error_type"
+                        ],
+                        "type": "string",
+                        "description": "统计维度",
+                        "name": "dimension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型，多个以逗号分隔",
+                        "name": "eventType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "分布项数量",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "统计数据",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "$ref": "#/definitions/service.DistributionStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/events/stats/error-type": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按错误类型统计错误事件数量和占比",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "事件"
+                ],
+                "summary": "获取错误类型分布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "分布项数量",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "统计数据",
+                        "schema": {
+                            "$ref": "#/definitions/service.DistributionStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/events/stats/os": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按操作系统统计事件数量和占比",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "事件"
+                ],
+                "summary": "获取操作系统分布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型，多个以逗号分隔",
+                        "name": "eventType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "分布项数量",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "统计数据",
+                        "schema": {
+                            "$ref": "#/definitions/service.DistributionStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/events/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取事件的基础信息和事件类型对应的全部详情数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "事件"
+                ],
+                "summary": "获取事件详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "事件ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "事件详情",
+                        "schema": {
+                            "$ref": "#/definitions/service.EventDetailResponse"
                         }
                     },
                     "400": {
@@ -1098,6 +1503,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "事件不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "内部错误",
                         "schema": {
@@ -1106,210 +1523,3500 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
+        },
+        "/api/logs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按关键词、级别、页面和时间范围搜索日志，关键词按空格拆分且需全部命中，同时返回各级别数量",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志"
+                ],
+                "summary": "搜索控制台日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "error",
+                            "warn",
+                            "info",
+                            "debug"
+                        ],
+                        "type": "string",
+                        "description": "日志级别",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "页面URL",
+                        "name": "pageUrl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "日志列表",
+                        "schema": {
+                            "$ref": "#/definitions/service.LogListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/otlp/v1/logs": {
+            "post": {
+                "description": "接收 OpenTelemetry 浏览器 SDK 通过 OTLP/HTTP 上报的日志（支持 protobuf 和 JSON），异常日志及 ERROR 级别以上的日志映射为错误",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据上报"
+                ],
+                "summary": "接收 OTLP 日志数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "应用标识",
+                        "name": "X-App-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "应用标识",
+                        "name": "appKey",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上报成功",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/otlp/v1/traces": {
+            "post": {
+                "description": "接收 OpenTelemetry 浏览器 SDK 通过 OTLP/HTTP 上报的 Span（支持 protobuf 和 JSON），异常映射为错误，documentLoad 映射为页面性能，资源 Span 映射为资源性能，请求 Span 映射为接口请求或 HTTP 错误",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据上报"
+                ],
+                "summary": "接收 OTLP 链路数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "应用标识",
+                        "name": "X-App-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "应用标识",
+                        "name": "appKey",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上报成功",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取项目的性能数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取性能数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "resource"
+                        ],
+                        "type": "string",
+                        "description": "性能类型",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "性能数据列表",
+                        "schema": {
+                            "$ref": "#/definitions/service.PerformanceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/apis": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按请求方法和接口模板（路径参数替换为 :id）聚合 XHR/fetch 请求，返回按采样率估算的调用量、p50/p95 耗时、成功率和状态码分布",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取接口性能",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "请求方法",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "接口地址关键词",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "calls",
+                            "p50",
+                            "p95",
+                            "successRate"
+                        ],
+                        "type": "string",
+                        "description": "排序字段",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "desc",
+                            "asc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "接口性能",
+                        "schema": {
+                            "$ref": "#/definitions/service.APIPerformanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/jank": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按页面汇总长任务和交互延迟，排行导致长任务的脚本以及交互延迟高的元素",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取卡顿归因排行",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "页面URL，为空表示所有页面",
+                        "name": "pageUrl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "排行数量",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "卡顿归因排行",
+                        "schema": {
+                            "$ref": "#/definitions/service.JankResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/pages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按归一化页面聚合性能数据，返回 p75 指标和导航阶段耗时，可按环境筛选",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取页面性能排行",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "浏览器",
+                        "name": "browser",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作系统",
+                        "name": "os",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "设备类型",
+                        "name": "deviceType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "地区",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "samples",
+                            "lcp",
+                            "fcp",
+                            "ttfb",
+                            "cls",
+                            "inp"
+                        ],
+                        "type": "string",
+                        "description": "排序字段",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "desc",
+                            "asc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "页面性能排行",
+                        "schema": {
+                            "$ref": "#/definitions/service.PagePerformanceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/resources": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取项目的资源性能数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取资源性能数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源类型",
+                        "name": "resourceType",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "资源性能数据列表",
+                        "schema": {
+                            "$ref": "#/definitions/service.ResourcePerformanceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/resources/aggregate": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按归一化资源URL或域名聚合资源性能，返回请求次数、p75/p95 耗时、平均大小、缓存命中率以及第一方/第三方归属",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取资源性能聚合",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源类型",
+                        "name": "resourceType",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "url",
+                            "domain"
+                        ],
+                        "type": "string",
+                        "description": "聚合维度",
+                        "name": "groupBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "first",
+                            "third"
+                        ],
+                        "type": "string",
+                        "description": "资源归属",
+                        "name": "party",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "count",
+                            "p75",
+                            "p95",
+                            "size",
+                            "decodedSize",
+                            "cacheHitRatio"
+                        ],
+                        "type": "string",
+                        "description": "排序字段",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "desc",
+                            "asc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "资源性能聚合",
+                        "schema": {
+                            "$ref": "#/definitions/service.ResourceAggregateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/routes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按来源和目标路由汇总单页应用路由切换耗时，并返回超过阈值的最慢切换样本",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取路由切换耗时",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "页面URL，匹配来源或目标路由，为空表示所有页面",
+                        "name": "pageUrl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "慢切换阈值（毫秒）",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "路由数量",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "路由切换耗时",
+                        "schema": {
+                            "$ref": "#/definitions/service.RouteTimingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取时间范围内的性能统计、趋势和 Web Vitals 分位数，默认最近7天按天统计，同时返回上一周期的对比数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取性能统计信息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "统计粒度：minute/hour/day/week，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC",
+                        "name": "timezone",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "性能统计信息",
+                        "schema": {
+                            "$ref": "#/definitions/service.PerformanceStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/performance/vitals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取各项性能指标的 p50/p75/p90/p95/p99、良好/待改进/差的分布以及每个时间桶的 p75 趋势，默认最近7天，同时返回上一周期的分位数",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能监控"
+                ],
+                "summary": "获取 Web Vitals 分位数统计",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "统计粒度：minute/hour/day/week，默认 day",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC",
+                        "name": "timezone",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Web Vitals 分位数统计",
+                        "schema": {
+                            "$ref": "#/definitions/service.WebVitalsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前用户的所有项目",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "项目"
+                ],
+                "summary": "获取项目列表",
+                "responses": {
+                    "200": {
+                        "description": "项目列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Project"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "创建新项目",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "项目"
+                ],
+                "summary": "创建项目",
+                "parameters": [
+                    {
+                        "description": "项目信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateProjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.Project"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取项目详细信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "项目"
+                ],
+                "summary": "获取项目详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "项目详情",
+                        "schema": {
+                            "$ref": "#/definitions/model.Project"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "更新项目信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "项目"
+                ],
+                "summary": "更新项目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "项目信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateProjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.Project"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "删除项目",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "项目"
+                ],
+                "summary": "删除项目",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/budgets": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取项目的所有性能预算",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能预算"
+                ],
+                "summary": "获取性能预算列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "性能预算列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PerformanceBudget"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "为项目创建性能预算，指标的 p75 超过阈值即视为不达标",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能预算"
+                ],
+                "summary": "创建性能预算",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "预算信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.BudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.PerformanceBudget"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/budgets/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按发布版本评估项目所有启用的预算，未指定版本时评估最近上报的版本，passed 为 false 表示存在不达标的预算，可用于发布流水线卡点",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能预算"
+                ],
+                "summary": "获取性能预算评估结果",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "发布版本",
+                        "name": "release",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "开始时间戳",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束时间戳",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "预算评估结果",
+                        "schema": {
+                            "$ref": "#/definitions/service.BudgetStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/budgets/{budgetId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "更新项目的性能预算",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能预算"
+                ],
+                "summary": "更新性能预算",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "预算ID",
+                        "name": "budgetId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "预算信息",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.BudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.PerformanceBudget"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "删除项目的性能预算",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "性能预算"
+                ],
+                "summary": "删除性能预算",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "预算ID",
+                        "name": "budgetId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "预算不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/purge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按数据类别或事件类型删除项目在时间范围内的数据（时间戳为毫秒，不含结束时间），类别和事件类型都为空时清理所有类型，分批删除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据保留"
+                ],
+                "summary": "清理项目数据",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "清理范围",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.PurgeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除的事件数量",
+                        "schema": {
+                            "$ref": "#/definitions/service.PurgeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projects/{id}/retention": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取项目各数据类别的保留天数，未单独设置的类别使用配置中的默认值，0 表示永久保留",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据保留"
+                ],
+                "summary": "获取数据保留策略",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "数据保留策略",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.RetentionItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "项目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "设置项目各数据类别的保留天数，未包含的类别保持不变，0 表示永久保留",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据保留"
+                ],
+                "summary": "更新数据保留策略",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "保留策略",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RetentionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新后的数据保留策略",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.RetentionItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/traces/{traceId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "查找携带指定 W3C traceId 的所有前端事件，并返回追踪系统链接",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "链路追踪"
+                ],
+                "summary": "按 traceId 查找前端事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "traceId（32位十六进制）",
+                        "name": "traceId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "项目ID",
+                        "name": "projectId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "链路关联的前端事件",
+                        "schema": {
+                            "$ref": "#/definitions/service.TraceEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sentry/api/{projectId}/envelope/": {
+            "post": {
+                "description": "兼容 Sentry envelope 协议，只处理 event 条目，sentry_key 也可从信封头的 dsn 中获取",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据上报"
+                ],
+                "summary": "接收 Sentry envelope 上报",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DSN 中的项目编号，仅用于兼容",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sentry 认证信息",
+                        "name": "X-Sentry-Auth",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "项目 AppKey",
+                        "name": "sentry_key",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上报成功",
+                        "schema": {
+                            "$ref": "#/definitions/service.SentryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sentry/api/{projectId}/store/": {
+            "post": {
+                "description": "兼容 Sentry store 协议，sentry_key 对应项目的 AppKey，DSN 形如 http://\u003cAppKey\u003e@\u003chost\u003e/sentry/1",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据上报"
+                ],
+                "summary": "接收 Sentry store 上报",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DSN 中的项目编号，仅用于兼容",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sentry 认证信息",
+                        "name": "X-Sentry-Auth",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "项目 AppKey",
+                        "name": "sentry_key",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上报成功",
+                        "schema": {
+                            "$ref": "#/definitions/service.SentryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trackweb": {
+            "post": {
+                "description": "接收SDK上报的错误和性能数据",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "数据上报"
+                ],
+                "summary": "接收SDK上报数据",
+                "parameters": [
+                    {
+                        "description": "上报数据",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.TrackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上报成功",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BaseInfo": {
+            "type": "object",
+            "properties": {
+                "appCode": {
+                    "type": "string"
+                },
+                "appKey": {
+                    "type": "string"
+                },
+                "appName": {
+                    "type": "string"
+                },
+                "browser": {
+                    "type": "string"
+                },
+                "browserVersion": {
+                    "type": "string"
+                },
+                "clientHeight": {
+                    "type": "integer"
+                },
+                "clientWidth": {
+                    "type": "integer"
+                },
+                "colorDepth": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "deviceId": {
+                    "type": "string"
+                },
+                "deviceType": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "ext": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "osVersion": {
+                    "type": "string"
+                },
+                "pageId": {
+                    "type": "string"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "pixelDepth": {
+                    "type": "integer"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "projectId": {
+                    "type": "integer"
+                },
+                "referrer": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "release": {
+                    "description": "release 是 MySQL 保留字",
+                    "type": "string"
+                },
+                "screenHeight": {
+                    "type": "integer"
+                },
+                "screenWidth": {
+                    "type": "integer"
+                },
+                "sdkUserUuid": {
+                    "type": "string"
+                },
+                "sdkVersion": {
+                    "description": "扩展字段",
+                    "type": "string"
+                },
+                "sendTime": {
+                    "type": "integer"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "userUuid": {
+                    "type": "string"
+                },
+                "vendor": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.EventMain": {
+            "type": "object",
+            "properties": {
+                "baseInfo": {
+                    "$ref": "#/definitions/model.BaseInfo"
+                },
+                "baseInfoId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "projectId": {
+                    "type": "integer"
+                },
+                "referer": {
+                    "type": "string"
+                },
+                "sendTime": {
+                    "type": "integer"
+                },
+                "spanId": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "traceId": {
+                    "description": "W3C Trace Context，用于关联后端链路",
+                    "type": "string"
+                },
+                "triggerPageUrl": {
+                    "type": "string"
+                },
+                "triggerTime": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PerformanceBudget": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pageUrl": {
+                    "description": "归一化后的页面，为空表示所有页面",
+                    "type": "string"
+                },
+                "projectId": {
+                    "type": "integer"
+                },
+                "resourceType": {
+                    "description": "资源类指标的资源类型，为空表示所有类型",
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Project": {
+            "type": "object",
+            "properties": {
+                "appKey": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.User": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.APIPerformanceItem": {
+            "type": "object",
+            "properties": {
+                "avgDuration": {
+                    "type": "number"
+                },
+                "calls": {
+                    "description": "按采样率估算的调用量",
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "p50Duration": {
+                    "type": "number"
+                },
+                "p95Duration": {
+                    "type": "number"
+                },
+                "samples": {
+                    "description": "实际入库的请求数",
+                    "type": "integer"
+                },
+                "statusDistribution": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "successRate": {
+                    "type": "number"
+                },
+                "urlTemplate": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.APIPerformanceResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.APIPerformanceItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BehaviorStatsResponse": {
+            "type": "object",
+            "properties": {
+                "clickStats": {
+                    "$ref": "#/definitions/service.ClickStatsData"
+                },
+                "previousClickStats": {
+                    "$ref": "#/definitions/service.ClickStatsData"
+                },
+                "previousPvStats": {
+                    "$ref": "#/definitions/service.PVStatsData"
+                },
+                "previousPvTrend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PVTrendItem"
+                    }
+                },
+                "pvStats": {
+                    "$ref": "#/definitions/service.PVStatsData"
+                },
+                "pvTrend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PVTrendItem"
+                    }
+                },
+                "range": {
+                    "$ref": "#/definitions/service.StatsRangeInfo"
+                }
+            }
+        },
+        "service.Breadcrumb": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "level": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.BudgetRequest": {
+            "type": "object",
+            "required": [
+                "metric",
+                "name"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "resourceType": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.BudgetStatusItem": {
+            "type": "object",
+            "properties": {
+                "budgetId": {
+                    "type": "integer"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "resourceType": {
+                    "type": "string"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.BudgetStatusResponse": {
+            "type": "object",
+            "properties": {
+                "budgets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BudgetStatusItem"
+                    }
+                },
+                "passed": {
+                    "type": "boolean"
+                },
+                "release": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ClickItem": {
+            "type": "object",
+            "properties": {
+                "elementPath": {
+                    "type": "string"
+                },
+                "elementType": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "innerText": {
+                    "type": "string"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "triggerTime": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ClickListResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ClickItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ClickStatsData": {
+            "type": "object",
+            "properties": {
+                "clicksToday": {
+                    "type": "integer"
+                },
+                "topElements": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "totalClicks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.CreateProjectRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.DistributionItem": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "percentage": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.DistributionStatsResponse": {
+            "type": "object",
+            "properties": {
+                "dimension": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.DistributionItem"
+                    }
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "service.ErrorDetailResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ErrorEventItem"
+                    }
+                },
+                "group": {
+                    "$ref": "#/definitions/service.ErrorGroupItem"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ErrorEventItem": {
+            "type": "object",
+            "properties": {
+                "breadcrumbs": {
+                    "description": "错误发生前的用户操作，按时间正序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Breadcrumb"
+                    }
+                },
+                "browser": {
+                    "type": "string"
+                },
+                "columnNumber": {
+                    "type": "integer"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "errorStack": {
+                    "type": "string"
+                },
+                "errorType": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "filePath": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lineNumber": {
+                    "type": "integer"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "spanId": {
+                    "type": "string"
+                },
+                "traceId": {
+                    "type": "string"
+                },
+                "traceUrl": {
+                    "type": "string"
+                },
+                "triggerTime": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ErrorGroupItem": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "errorType": {
+                    "type": "string"
+                },
+                "fingerprint": {
+                    "type": "string"
+                },
+                "firstSeen": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastSeen": {
+                    "type": "integer"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subType": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ErrorListResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ErrorGroupItem"
+                    }
+                },
+                "stats": {
+                    "$ref": "#/definitions/service.ErrorStatsData"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ErrorStatsData": {
+            "type": "object",
+            "properties": {
+                "affectedUsers": {
+                    "type": "integer"
+                },
+                "browserDistribution": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "errorsToday": {
+                    "type": "integer"
+                },
+                "errorsYesterday": {
+                    "type": "integer"
+                },
+                "osDistribution": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "totalErrors": {
+                    "type": "integer"
+                },
+                "typeDistribution": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "service.ErrorStatsResponse": {
+            "type": "object",
+            "properties": {
+                "previous": {
+                    "$ref": "#/definitions/service.ErrorStatsData"
+                },
+                "previousTrend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ErrorTrendItem"
+                    }
+                },
+                "range": {
+                    "$ref": "#/definitions/service.StatsRangeInfo"
+                },
+                "stats": {
+                    "$ref": "#/definitions/service.ErrorStatsData"
+                },
+                "trend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ErrorTrendItem"
+                    }
+                }
+            }
+        },
+        "service.ErrorTrendItem": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.EventDetailResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "event": {
+                    "$ref": "#/definitions/model.EventMain"
+                }
+            }
+        },
+        "service.EventItem": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string"
+                },
+                "deviceType": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "release": {
+                    "type": "string"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "traceId": {
+                    "type": "string"
+                },
+                "triggerPageUrl": {
+                    "type": "string"
+                },
+                "triggerTime": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "userUuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.EventListResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.EventItem"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.EventStatsResponse": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "dates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "previousCounts": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "range": {
+                    "$ref": "#/definitions/service.StatsRangeInfo"
+                }
+            }
+        },
+        "service.FrustrationItem": {
+            "type": "object",
+            "properties": {
+                "affectedSessions": {
+                    "type": "integer"
+                },
+                "deadClicks": {
+                    "type": "integer"
+                },
+                "elementPath": {
+                    "type": "string"
+                },
+                "elementType": {
+                    "type": "string"
+                },
+                "innerText": {
+                    "type": "string"
+                },
+                "lastSeen": {
+                    "type": "integer"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "rageClicks": {
+                    "type": "integer"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "totalClicks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.FrustrationListResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.FrustrationItem"
+                    }
+                }
+            }
+        },
+        "service.HeatmapCell": {
+            "type": "object",
+            "properties": {
+                "col": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.HeatmapElement": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "elementPath": {
+                    "type": "string"
+                },
+                "elementType": {
+                    "type": "string"
+                },
+                "innerText": {
+                    "type": "string"
+                },
+                "ratio": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.HeatmapPage": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "pageUrl": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.HeatmapResponse": {
+            "type": "object",
+            "properties": {
+                "cells": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.HeatmapCell"
+                    }
+                },
+                "columns": {
+                    "type": "integer"
+                },
+                "maxCount": {
+                    "type": "integer"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.HeatmapPage"
+                    }
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "topElements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.HeatmapElement"
+                    }
+                },
+                "totalClicks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.JankElement": {
             "type": "object",
             "properties": {
-                "message": {
+                "avgInputDelay": {
+                    "type": "number"
+                },
+                "avgPresentationDelay": {
+                    "type": "number"
+                },
+                "avgProcessingTime": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "elementPath": {
+                    "type": "string"
+                },
+                "elementType": {
+                    "type": "string"
+                },
+                "interactionType": {
+                    "type": "string"
+                },
+                "p75Duration": {
+                    "type": "number"
+                },
+                "pageUrl": {
                     "type": "string"
+                },
+                "slowCount": {
+                    "type": "integer"
                 }
             }
         },
-        "api.SuccessResponse": {
+        "service.JankPage": {
             "type": "object",
             "properties": {
-                "message": {
+                "interactionCount": {
+                    "type": "integer"
+                },
+                "longTaskCount": {
+                    "type": "integer"
+                },
+                "p75InteractionDuration": {
+                    "type": "number"
+                },
+                "pageUrl": {
                     "type": "string"
+                },
+                "slowInteractionCount": {
+                    "type": "integer"
+                },
+                "totalBlockingTime": {
+                    "type": "integer"
                 }
             }
         },
-        "model.Project": {
+        "service.JankResponse": {
             "type": "object",
             "properties": {
-                "appKey": {
+                "elements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.JankElement"
+                    }
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.JankPage"
+                    }
+                },
+                "scripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.JankScript"
+                    }
+                }
+            }
+        },
+        "service.JankScript": {
+            "type": "object",
+            "properties": {
+                "avgDuration": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "functionName": {
                     "type": "string"
                 },
-                "createdAt": {
+                "maxDuration": {
+                    "type": "integer"
+                },
+                "pageUrl": {
                     "type": "string"
                 },
-                "description": {
+                "scriptUrl": {
+                    "type": "string"
+                },
+                "totalBlocking": {
+                    "type": "integer"
+                },
+                "totalDuration": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.LogItem": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "type": "string"
+                },
+                "eventId": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "level": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "message": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/model.User"
+                "pageUrl": {
+                    "type": "string"
                 },
-                "userId": {
+                "stack": {
+                    "type": "string"
+                },
+                "triggerTime": {
                     "type": "integer"
                 }
             }
         },
-        "model.User": {
+        "service.LogListResponse": {
             "type": "object",
             "properties": {
-                "createdAt": {
+                "levelCounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.LogItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
                     "type": "string"
                 },
-                "email": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expireAt": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                }
+            }
+        },
+        "service.PVItem": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "eventId": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "updatedAt": {
+                "isNewVisit": {
+                    "type": "boolean"
+                },
+                "os": {
                     "type": "string"
                 },
-                "username": {
+                "pageUrl": {
+                    "type": "string"
+                },
+                "referrer": {
+                    "type": "string"
+                },
+                "stayTime": {
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
+                },
+                "triggerTime": {
+                    "type": "integer"
                 }
             }
         },
-        "service.BehaviorStatsResponse": {
+        "service.PVListResponse": {
             "type": "object",
             "properties": {
-                "clickStats": {
-                    "$ref": "#/definitions/service.ClickStatsData"
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PVItem"
+                    }
                 },
-                "pvStats": {
-                    "$ref": "#/definitions/service.PVStatsData"
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.PVStatsData": {
+            "type": "object",
+            "properties": {
+                "avgStayTime": {
+                    "type": "integer"
                 },
-                "pvTrend": {
+                "bounceRate": {
+                    "type": "number"
+                },
+                "pvToday": {
+                    "type": "integer"
+                },
+                "topPages": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "totalPV": {
+                    "type": "integer"
+                },
+                "totalUV": {
+                    "type": "integer"
+                },
+                "uvToday": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.PVTrendItem": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "pv": {
+                    "type": "integer"
+                },
+                "uv": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.PagePerformanceItem": {
+            "type": "object",
+            "properties": {
+                "p75CLS": {
+                    "type": "number"
+                },
+                "p75FCP": {
+                    "type": "number"
+                },
+                "p75INP": {
+                    "type": "number"
+                },
+                "p75LCP": {
+                    "type": "number"
+                },
+                "p75TTFB": {
+                    "type": "number"
+                },
+                "pageUrl": {
+                    "type": "string"
+                },
+                "ratings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.VitalScore"
+                    }
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "waterfall": {
+                    "$ref": "#/definitions/service.PageTimingWaterfall"
+                }
+            }
+        },
+        "service.PagePerformanceListResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.PVTrendItem"
+                        "$ref": "#/definitions/service.PagePerformanceItem"
                     }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "service.ClickItem": {
+        "service.PageTimingWaterfall": {
             "type": "object",
             "properties": {
-                "elementPath": {
-                    "type": "string"
-                },
-                "elementType": {
-                    "type": "string"
+                "dns": {
+                    "type": "number"
                 },
-                "eventId": {
-                    "type": "string"
+                "domParse": {
+                    "type": "number"
                 },
-                "id": {
-                    "type": "integer"
+                "ssl": {
+                    "type": "number"
                 },
-                "innerText": {
-                    "type": "string"
+                "tcp": {
+                    "type": "number"
                 },
-                "pageUrl": {
-                    "type": "string"
+                "trans": {
+                    "type": "number"
                 },
-                "triggerTime": {
-                    "type": "integer"
+                "ttfb": {
+                    "type": "number"
                 }
             }
         },
-        "service.ClickListResponse": {
+        "service.PathAnalysisResponse": {
             "type": "object",
             "properties": {
-                "list": {
+                "links": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.ClickItem"
+                        "$ref": "#/definitions/service.PathLink"
                     }
                 },
-                "total": {
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PathNode"
+                    }
+                },
+                "sessions": {
                     "type": "integer"
                 }
             }
         },
-        "service.ClickStatsData": {
+        "service.PathLink": {
             "type": "object",
             "properties": {
-                "clicksToday": {
-                    "type": "integer"
+                "source": {
+                    "type": "string"
                 },
-                "topElements": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
-                    }
+                "target": {
+                    "type": "string"
                 },
-                "totalClicks": {
+                "value": {
                     "type": "integer"
                 }
             }
         },
-        "service.CreateProjectRequest": {
+        "service.PathNode": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "description": {
+                "count": {
+                    "type": "integer"
+                },
+                "id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
+                },
+                "step": {
+                    "type": "integer"
                 }
             }
         },
-        "service.ErrorDetailResponse": {
+        "service.PerformanceListResponse": {
             "type": "object",
             "properties": {
-                "events": {
+                "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.ErrorEventItem"
+                        "$ref": "#/definitions/service.PerformancePageItem"
                     }
                 },
-                "group": {
-                    "$ref": "#/definitions/service.ErrorGroupItem"
+                "stats": {
+                    "$ref": "#/definitions/service.PerformanceStatsData"
                 },
                 "total": {
                     "type": "integer"
                 }
             }
         },
-        "service.ErrorEventItem": {
+        "service.PerformancePageItem": {
             "type": "object",
             "properties": {
                 "browser": {
                     "type": "string"
                 },
-                "columnNumber": {
-                    "type": "integer"
+                "cls": {
+                    "type": "number"
                 },
                 "device": {
                     "type": "string"
                 },
-                "errorMessage": {
-                    "type": "string"
+                "domReady": {
+                    "type": "integer"
                 },
-                "errorStack": {
+                "eventId": {
                     "type": "string"
                 },
-                "errorType": {
-                    "type": "string"
+                "fcp": {
+                    "type": "integer"
                 },
-                "eventId": {
-                    "type": "string"
+                "fid": {
+                    "type": "integer"
                 },
-                "filePath": {
-                    "type": "string"
+                "fp": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "lineNumber": {
+                "inp": {
+                    "type": "integer"
+                },
+                "lcp": {
+                    "type": "integer"
+                },
+                "load": {
                     "type": "integer"
                 },
                 "os": {
@@ -1320,128 +5027,144 @@ const docTemplate = `{
                 },
                 "triggerTime": {
                     "type": "integer"
+                },
+                "ttfb": {
+                    "type": "integer"
                 }
             }
         },
-        "service.ErrorGroupItem": {
+        "service.PerformanceStatsData": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "errorMessage": {
-                    "type": "string"
+                "avgCLS": {
+                    "type": "number"
                 },
-                "errorType": {
-                    "type": "string"
+                "avgDomReady": {
+                    "type": "integer"
                 },
-                "fingerprint": {
-                    "type": "string"
+                "avgFCP": {
+                    "type": "integer"
                 },
-                "firstSeen": {
+                "avgFID": {
                     "type": "integer"
                 },
-                "id": {
+                "avgFP": {
                     "type": "integer"
                 },
-                "lastSeen": {
+                "avgINP": {
                     "type": "integer"
                 },
-                "severity": {
-                    "type": "string"
+                "avgLCP": {
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "avgLoad": {
+                    "type": "integer"
                 },
-                "subType": {
-                    "type": "string"
+                "avgTTFB": {
+                    "type": "integer"
                 }
             }
         },
-        "service.ErrorListResponse": {
+        "service.PerformanceStatsResponse": {
             "type": "object",
             "properties": {
-                "list": {
+                "previous": {
+                    "$ref": "#/definitions/service.PerformanceStatsData"
+                },
+                "previousTrend": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.ErrorGroupItem"
+                        "$ref": "#/definitions/service.PerformanceTrendItem"
+                    }
+                },
+                "previousVitals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.WebVitalMetric"
                     }
                 },
+                "range": {
+                    "$ref": "#/definitions/service.StatsRangeInfo"
+                },
                 "stats": {
-                    "$ref": "#/definitions/service.ErrorStatsData"
+                    "$ref": "#/definitions/service.PerformanceStatsData"
                 },
-                "total": {
-                    "type": "integer"
+                "trend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PerformanceTrendItem"
+                    }
+                },
+                "vitals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.WebVitalMetric"
+                    }
                 }
             }
         },
-        "service.ErrorStatsData": {
+        "service.PerformanceTrendItem": {
             "type": "object",
             "properties": {
-                "affectedUsers": {
-                    "type": "integer"
-                },
-                "browserDistribution": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
-                    }
+                "date": {
+                    "type": "string"
                 },
-                "errorsToday": {
+                "fcp": {
                     "type": "integer"
                 },
-                "errorsYesterday": {
+                "fp": {
                     "type": "integer"
                 },
-                "osDistribution": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
-                    }
-                },
-                "totalErrors": {
+                "lcp": {
                     "type": "integer"
                 },
-                "typeDistribution": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
-                    }
+                "ttfb": {
+                    "type": "integer"
                 }
             }
         },
-        "service.ErrorStatsResponse": {
+        "service.PurgeRequest": {
             "type": "object",
             "properties": {
-                "stats": {
-                    "$ref": "#/definitions/service.ErrorStatsData"
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "trend": {
+                "endTime": {
+                    "type": "integer"
+                },
+                "eventTypes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.ErrorTrendItem"
+                        "type": "string"
                     }
+                },
+                "startTime": {
+                    "type": "integer"
                 }
             }
         },
-        "service.ErrorTrendItem": {
+        "service.PurgeResponse": {
             "type": "object",
             "properties": {
-                "count": {
+                "deleted": {
                     "type": "integer"
-                },
-                "date": {
-                    "type": "string"
                 }
             }
         },
-        "service.LoginRequest": {
+        "service.RegisterRequest": {
             "type": "object",
             "required": [
+                "email",
                 "password",
                 "username"
             ],
             "properties": {
+                "email": {
+                    "type": "string"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -1450,28 +5173,60 @@ const docTemplate = `{
                 }
             }
         },
-        "service.LoginResponse": {
+        "service.ResourceAggregateItem": {
             "type": "object",
             "properties": {
-                "expireAt": {
+                "avgDecodedBodySize": {
+                    "type": "number"
+                },
+                "avgTransferSize": {
+                    "type": "number"
+                },
+                "cacheHitRatio": {
+                    "type": "number"
+                },
+                "count": {
                     "type": "integer"
                 },
-                "token": {
+                "domain": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/model.User"
+                "key": {
+                    "type": "string"
+                },
+                "p75Duration": {
+                    "type": "number"
+                },
+                "p95Duration": {
+                    "type": "number"
+                },
+                "party": {
+                    "type": "string"
+                },
+                "resourceType": {
+                    "type": "string"
                 }
             }
         },
-        "service.PVItem": {
+        "service.ResourceAggregateResponse": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ResourceAggregateItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ResourcePerformanceItem": {
             "type": "object",
             "properties": {
-                "browser": {
-                    "type": "string"
-                },
-                "device": {
-                    "type": "string"
+                "duration": {
+                    "type": "integer"
                 },
                 "eventId": {
                     "type": "string"
@@ -1479,36 +5234,33 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "isNewVisit": {
-                    "type": "boolean"
-                },
-                "os": {
+                "initiatorType": {
                     "type": "string"
                 },
                 "pageUrl": {
                     "type": "string"
                 },
-                "referrer": {
+                "resourceType": {
                     "type": "string"
                 },
-                "stayTime": {
-                    "type": "integer"
-                },
-                "title": {
+                "resourceUrl": {
                     "type": "string"
                 },
-                "triggerTime": {
+                "startTime": {
+                    "type": "integer"
+                },
+                "transferSize": {
                     "type": "integer"
                 }
             }
         },
-        "service.PVListResponse": {
+        "service.ResourcePerformanceListResponse": {
             "type": "object",
             "properties": {
                 "list": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.PVItem"
+                        "$ref": "#/definitions/service.ResourcePerformanceItem"
                     }
                 },
                 "total": {
@@ -1516,241 +5268,208 @@ const docTemplate = `{
                 }
             }
         },
-        "service.PVStatsData": {
+        "service.RetentionItem": {
             "type": "object",
             "properties": {
-                "avgStayTime": {
-                    "type": "integer"
-                },
-                "bounceRate": {
-                    "type": "number"
+                "category": {
+                    "type": "string"
                 },
-                "pvToday": {
+                "days": {
+                    "description": "0 表示永久保留",
                     "type": "integer"
                 },
-                "topPages": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
-                "totalPV": {
-                    "type": "integer"
-                },
-                "totalUV": {
-                    "type": "integer"
-                },
-                "uvToday": {
-                    "type": "integer"
+                "isDefault": {
+                    "description": "是否使用配置中的默认值",
+                    "type": "boolean"
                 }
             }
         },
-        "service.PVTrendItem": {
+        "service.RetentionPolicyRequest": {
             "type": "object",
+            "required": [
+                "category"
+            ],
             "properties": {
-                "date": {
+                "category": {
                     "type": "string"
                 },
-                "pv": {
-                    "type": "integer"
-                },
-                "uv": {
+                "days": {
                     "type": "integer"
                 }
             }
         },
-        "service.PerformanceListResponse": {
+        "service.RetentionRequest": {
             "type": "object",
+            "required": [
+                "policies"
+            ],
             "properties": {
-                "list": {
+                "policies": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.PerformancePageItem"
+                        "$ref": "#/definitions/service.RetentionPolicyRequest"
                     }
-                },
-                "stats": {
-                    "$ref": "#/definitions/service.PerformanceStatsData"
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "service.PerformancePageItem": {
+        "service.RouteSample": {
             "type": "object",
             "properties": {
-                "browser": {
+                "action": {
                     "type": "string"
                 },
-                "cls": {
-                    "type": "number"
-                },
-                "device": {
-                    "type": "string"
-                },
-                "domReady": {
+                "duration": {
                     "type": "integer"
                 },
                 "eventId": {
                     "type": "string"
                 },
-                "fcp": {
-                    "type": "integer"
-                },
-                "fid": {
-                    "type": "integer"
-                },
-                "fp": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "lcp": {
-                    "type": "integer"
+                "fromUrl": {
+                    "type": "string"
                 },
-                "load": {
-                    "type": "integer"
+                "mode": {
+                    "type": "string"
                 },
-                "os": {
+                "sessionId": {
                     "type": "string"
                 },
-                "pageUrl": {
+                "toUrl": {
                     "type": "string"
                 },
                 "triggerTime": {
                     "type": "integer"
-                },
-                "ttfb": {
-                    "type": "integer"
                 }
             }
         },
-        "service.PerformanceStatsData": {
+        "service.RouteTiming": {
             "type": "object",
             "properties": {
-                "avgCLS": {
+                "avgDuration": {
                     "type": "number"
                 },
-                "avgDomReady": {
-                    "type": "integer"
+                "avgStayTime": {
+                    "type": "number"
                 },
-                "avgFCP": {
+                "count": {
                     "type": "integer"
                 },
-                "avgFID": {
-                    "type": "integer"
+                "fromRoute": {
+                    "type": "string"
                 },
-                "avgFP": {
+                "maxDuration": {
                     "type": "integer"
                 },
-                "avgLCP": {
-                    "type": "integer"
+                "p75Duration": {
+                    "type": "number"
                 },
-                "avgLoad": {
-                    "type": "integer"
+                "p95Duration": {
+                    "type": "number"
                 },
-                "avgTTFB": {
+                "slowCount": {
                     "type": "integer"
+                },
+                "toRoute": {
+                    "type": "string"
                 }
             }
         },
-        "service.PerformanceStatsResponse": {
+        "service.RouteTimingResponse": {
             "type": "object",
             "properties": {
-                "stats": {
-                    "$ref": "#/definitions/service.PerformanceStatsData"
+                "routes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RouteTiming"
+                    }
                 },
-                "trend": {
+                "slowest": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.PerformanceTrendItem"
+                        "$ref": "#/definitions/service.RouteSample"
                     }
+                },
+                "threshold": {
+                    "type": "integer"
                 }
             }
         },
-        "service.PerformanceTrendItem": {
+        "service.SentryResponse": {
             "type": "object",
             "properties": {
-                "date": {
+                "id": {
                     "type": "string"
-                },
-                "fcp": {
-                    "type": "integer"
-                },
-                "fp": {
-                    "type": "integer"
-                },
-                "lcp": {
-                    "type": "integer"
-                },
-                "ttfb": {
-                    "type": "integer"
                 }
             }
         },
-        "service.RegisterRequest": {
+        "service.StatsRangeInfo": {
             "type": "object",
-            "required": [
-                "email",
-                "password",
-                "username"
-            ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "endTime": {
+                    "type": "integer"
                 },
-                "password": {
+                "granularity": {
                     "type": "string"
                 },
-                "username": {
+                "previousEndTime": {
+                    "type": "integer"
+                },
+                "previousStartTime": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "integer"
+                },
+                "timezone": {
                     "type": "string"
                 }
             }
         },
-        "service.ResourcePerformanceItem": {
+        "service.TraceEventItem": {
             "type": "object",
             "properties": {
-                "duration": {
-                    "type": "integer"
-                },
                 "eventId": {
                     "type": "string"
                 },
+                "eventType": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "initiatorType": {
-                    "type": "string"
-                },
                 "pageUrl": {
                     "type": "string"
                 },
-                "resourceType": {
+                "spanId": {
                     "type": "string"
                 },
-                "resourceUrl": {
+                "summary": {
                     "type": "string"
                 },
-                "startTime": {
-                    "type": "integer"
-                },
-                "transferSize": {
+                "triggerTime": {
                     "type": "integer"
                 }
             }
         },
-        "service.ResourcePerformanceListResponse": {
+        "service.TraceEventsResponse": {
             "type": "object",
             "properties": {
-                "list": {
+                "events": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/service.ResourcePerformanceItem"
+                        "$ref": "#/definitions/service.TraceEventItem"
                     }
                 },
-                "total": {
-                    "type": "integer"
+                "traceId": {
+                    "type": "string"
+                },
+                "traceUrl": {
+                    "type": "string"
                 }
             }
         },
@@ -1770,6 +5489,96 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "service.VitalScore": {
+            "type": "object",
+            "properties": {
+                "rating": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "service.WebVitalMetric": {
+            "type": "object",
+            "properties": {
+                "good": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "needsImprovement": {
+                    "type": "integer"
+                },
+                "p50": {
+                    "type": "number"
+                },
+                "p75": {
+                    "type": "number"
+                },
+                "p90": {
+                    "type": "number"
+                },
+                "p95": {
+                    "type": "number"
+                },
+                "p99": {
+                    "type": "number"
+                },
+                "poor": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "string"
+                },
+                "samples": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.WebVitalsResponse": {
+            "type": "object",
+            "properties": {
+                "metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.WebVitalMetric"
+                    }
+                },
+                "previous": {
+                    "description": "上一周期的分位数和评级分布",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.WebVitalMetric"
+                    }
+                },
+                "range": {
+                    "$ref": "#/definitions/service.StatsRangeInfo"
+                },
+                "trend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.WebVitalsTrendItem"
+                    }
+                }
+            }
+        },
+        "service.WebVitalsTrendItem": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "p75": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
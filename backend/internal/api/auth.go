@@ -17,15 +17,14 @@ import (
 // @Failure 400 {object} ErrorResponse "请求错误"
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Router /api/auth/login [post]
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var req service.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
 		return
 	}
 
-	authService := service.AuthService{}
-	resp, err := authService.Login(&req)
+	resp, err := h.auth.Login(&req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
 		return
@@ -44,15 +43,14 @@ func Login(c *gin.Context) {
 // @Failure 400 {object} ErrorResponse "请求错误"
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Router /api/auth/register [post]
-func Register(c *gin.Context) {
+func (h *Handler) Register(c *gin.Context) {
 	var req service.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
 		return
 	}
 
-	authService := service.AuthService{}
-	_, err := authService.Register(&req)
+	_, err := h.auth.Register(&req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
 		return
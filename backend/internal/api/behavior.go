@@ -3,7 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/akinoccc/web-tracing-admin/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,15 +21,14 @@ import (
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/behavior/pv [get]
-func GetPageViews(c *gin.Context) {
+func (h *Handler) GetPageViews(c *gin.Context) {
 	projectID := c.Query("projectId")
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
 	startTime := c.Query("startTime")
 	endTime := c.Query("endTime")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetPageViewList(projectID, page, pageSize, startTime, endTime)
+	resp, err := h.events.GetPageViewList(projectID, page, pageSize, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -54,15 +52,14 @@ func GetPageViews(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/behavior/clicks [get]
-func GetClicks(c *gin.Context) {
+func (h *Handler) GetClicks(c *gin.Context) {
 	projectID := c.Query("projectId")
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
 	startTime := c.Query("startTime")
 	endTime := c.Query("endTime")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetClickList(projectID, page, pageSize, startTime, endTime)
+	resp, err := h.events.GetClickList(projectID, page, pageSize, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -72,25 +69,121 @@ func GetClicks(c *gin.Context) {
 }
 
 // @Summary 获取用户行为统计信息
-// @Description 获取项目的用户行为统计信息
+// @Description 获取时间范围内的页面访问和点击统计及趋势，默认最近7天按天统计，同时返回上一周期的对比数据
 // @Tags 用户行为
 // @Produce json
 // @Param projectId query int true "项目ID"
 // @Param startTime query int false "开始时间戳"
 // @Param endTime query int false "结束时间戳"
+// @Param granularity query string false "统计粒度：minute/hour/day/week，默认 day"
+// @Param timezone query string false "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC"
 // @Success 200 {object} service.BehaviorStatsResponse "用户行为统计信息"
 // @Failure 400 {object} ErrorResponse "请求错误"
 // @Failure 401 {object} ErrorResponse "未授权"
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/behavior/stats [get]
-func GetBehaviorStats(c *gin.Context) {
+func (h *Handler) GetBehaviorStats(c *gin.Context) {
 	projectID := c.Query("projectId")
+
+	resp, err := h.events.GetBehaviorStats(projectID, statsQueryFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取用户页面路径
+// @Description 按会话统计页面之间的跳转路径，返回桑基图所需的节点和连线
+// @Tags 用户行为
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param startPage query string false "起始页面"
+// @Param endPage query string false "结束页面"
+// @Param depth query int false "路径深度" default(5)
+// @Success 200 {object} service.PathAnalysisResponse "用户路径"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/behavior/paths [get]
+func (h *Handler) GetPagePaths(c *gin.Context) {
+	projectID := c.Query("projectId")
+	startTime := c.Query("startTime")
+	endTime := c.Query("endTime")
+	startPage := c.Query("startPage")
+	endPage := c.Query("endPage")
+	depth := c.DefaultQuery("depth", "5")
+
+	resp, err := h.events.GetPagePaths(projectID, startTime, endTime, startPage, endPage, depth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取页面点击热力图
+// @Description 按视口宽度归一化的网格统计页面点击分布，并返回点击最多的元素
+// @Tags 用户行为
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param pageUrl query string false "页面地址，默认点击最多的页面"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param columns query int false "网格列数" default(50)
+// @Success 200 {object} service.HeatmapResponse "点击热力图"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/behavior/heatmap [get]
+func (h *Handler) GetClickHeatmap(c *gin.Context) {
+	projectID := c.Query("projectId")
+	pageURL := c.Query("pageUrl")
+	startTime := c.Query("startTime")
+	endTime := c.Query("endTime")
+	columns := c.DefaultQuery("columns", "50")
+
+	resp, err := h.events.GetClickHeatmap(projectID, pageURL, startTime, endTime, columns)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取挫败点击排行
+// @Description 按页面和元素统计狂点、无响应点击，并返回受影响的会话，未指定开始时间时统计结束时间前7天
+// @Tags 用户行为
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param type query string false "挫败类型" Enums(rage, dead)
+// @Param pageUrl query string false "页面地址"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "返回数量" default(20)
+// @Success 200 {object} service.FrustrationListResponse "挫败点击排行"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/behavior/frustrations [get]
+func (h *Handler) GetFrustrations(c *gin.Context) {
+	projectID := c.Query("projectId")
+	frustrationType := c.Query("type")
+	pageURL := c.Query("pageUrl")
 	startTime := c.Query("startTime")
 	endTime := c.Query("endTime")
+	limit := c.DefaultQuery("limit", "20")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetBehaviorStats(projectID, startTime, endTime)
+	resp, err := h.events.GetFrustrations(projectID, frustrationType, pageURL, startTime, endTime, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
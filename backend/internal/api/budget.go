@@ -0,0 +1,190 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 创建性能预算
+// @Description 为项目创建性能预算，指标的 p75 超过阈值即视为不达标
+// @Tags 性能预算
+// @Accept json
+// @Produce json
+// @Param id path int true "项目ID"
+// @Param data body service.BudgetRequest true "预算信息"
+// @Success 200 {object} model.PerformanceBudget "创建成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/budgets [post]
+func (h *Handler) CreateBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	var req service.BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	budget, err := h.budgets.CreateBudget(uint(id), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// @Summary 获取性能预算列表
+// @Description 获取项目的所有性能预算
+// @Tags 性能预算
+// @Produce json
+// @Param id path int true "项目ID"
+// @Success 200 {array} model.PerformanceBudget "性能预算列表"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/budgets [get]
+func (h *Handler) GetBudgets(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	budgets, err := h.budgets.GetBudgets(uint(id), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+// @Summary 更新性能预算
+// @Description 更新项目的性能预算
+// @Tags 性能预算
+// @Accept json
+// @Produce json
+// @Param id path int true "项目ID"
+// @Param budgetId path int true "预算ID"
+// @Param data body service.BudgetRequest true "预算信息"
+// @Success 200 {object} model.PerformanceBudget "更新成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/budgets/{budgetId} [put]
+func (h *Handler) UpdateBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	budgetID, err := strconv.ParseUint(c.Param("budgetId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的预算ID"})
+		return
+	}
+
+	var req service.BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	budget, err := h.budgets.UpdateBudget(uint(id), uint(budgetID), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// @Summary 删除性能预算
+// @Description 删除项目的性能预算
+// @Tags 性能预算
+// @Produce json
+// @Param id path int true "项目ID"
+// @Param budgetId path int true "预算ID"
+// @Success 200 {object} SuccessResponse "删除成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "预算不存在"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/budgets/{budgetId} [delete]
+func (h *Handler) DeleteBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	budgetID, err := strconv.ParseUint(c.Param("budgetId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的预算ID"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	if err := h.budgets.DeleteBudget(uint(id), uint(budgetID), userID); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "删除成功"})
+}
+
+// @Summary 获取性能预算评估结果
+// @Description 按发布版本评估项目所有启用的预算，未指定版本时评估最近上报的版本，passed 为 false 表示存在不达标的预算，可用于发布流水线卡点
+// @Tags 性能预算
+// @Produce json
+// @Param id path int true "项目ID"
+// @Param release query string false "发布版本"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Success 200 {object} service.BudgetStatusResponse "预算评估结果"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/budgets/status [get]
+func (h *Handler) GetBudgetStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	resp, err := h.budgets.GetBudgetStatus(uint(id), c.Query("release"), c.Query("startTime"), c.Query("endTime"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
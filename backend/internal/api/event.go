@@ -0,0 +1,239 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// 从查询参数读取事件筛选条件
+func eventFilterFromQuery(c *gin.Context) service.EventFilter {
+	return service.EventFilter{
+		EventType:  c.Query("eventType"),
+		StartTime:  c.Query("startTime"),
+		EndTime:    c.Query("endTime"),
+		PageURL:    c.Query("pageUrl"),
+		UserID:     c.Query("userId"),
+		SessionID:  c.Query("sessionId"),
+		Browser:    c.Query("browser"),
+		OS:         c.Query("os"),
+		DeviceType: c.Query("deviceType"),
+		Release:    c.Query("release"),
+	}
+}
+
+// 从查询参数读取统计时间范围、粒度和时区
+func statsQueryFromQuery(c *gin.Context) service.StatsQuery {
+	return service.StatsQuery{
+		StartTime:   c.Query("startTime"),
+		EndTime:     c.Query("endTime"),
+		Granularity: c.Query("granularity"),
+		Timezone:    c.Query("timezone"),
+	}
+}
+
+// @Summary 获取事件列表
+// @Description 浏览项目的原始事件，按触发时间倒序，使用游标分页，下一页传入上一页返回的 nextCursor
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param eventType query string false "事件类型，多个以逗号分隔"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param pageUrl query string false "页面URL"
+// @Param userId query string false "用户ID"
+// @Param sessionId query string false "会话ID"
+// @Param browser query string false "浏览器"
+// @Param os query string false "操作系统"
+// @Param deviceType query string false "设备类型"
+// @Param release query string false "发布版本"
+// @Param cursor query string false "分页游标"
+// @Param pageSize query int false "每页数量" default(20)
+// @Success 200 {object} service.EventListResponse "事件列表"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events [get]
+func (h *Handler) GetEvents(c *gin.Context) {
+	projectID := c.Query("projectId")
+	cursor := c.Query("cursor")
+	pageSize := c.DefaultQuery("pageSize", "20")
+
+	resp, err := h.events.GetEventList(projectID, cursor, pageSize, eventFilterFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取事件详情
+// @Description 获取事件的基础信息和事件类型对应的全部详情数据
+// @Tags 事件
+// @Produce json
+// @Param id path int true "事件ID"
+// @Success 200 {object} service.EventDetailResponse "事件详情"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "事件不存在"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/{id} [get]
+func (h *Handler) GetEventDetail(c *gin.Context) {
+	id := c.Param("id")
+
+	resp, err := h.events.GetEventDetail(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取事件数量趋势
+// @Description 按时间桶统计事件数量，支持与事件列表相同的筛选条件，默认最近7天按天统计，同时返回上一周期对应时间桶的数量
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param eventType query string false "事件类型，多个以逗号分隔"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param pageUrl query string false "页面URL"
+// @Param userId query string false "用户ID"
+// @Param sessionId query string false "会话ID"
+// @Param browser query string false "浏览器"
+// @Param os query string false "操作系统"
+// @Param deviceType query string false "设备类型"
+// @Param release query string false "发布版本"
+// @Param granularity query string false "统计粒度：minute/hour/day/week，默认 day"
+// @Param timezone query string false "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC"
+// @Success 200 {object} service.EventStatsResponse "统计数据"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/stats [get]
+func (h *Handler) GetEventStats(c *gin.Context) {
+	projectID := c.Query("projectId")
+
+	resp, err := h.events.GetEventStats(projectID, eventFilterFromQuery(c), statsQueryFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// 按维度返回分布统计
+func (h *Handler) distributionStats(c *gin.Context, dimension string) {
+	projectID := c.Query("projectId")
+	limit := c.DefaultQuery("limit", "20")
+
+	resp, err := h.events.GetDistribution(projectID, dimension, limit, eventFilterFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取事件分布统计
+// @Description 按维度统计事件数量和占比，超出数量限制的项合并为 other，支持与事件列表相同的筛选条件
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param dimension query string true "统计维度" Enums(browser, browser_version, os, os_version, device_type, screen, sdk_version, release, region, error_type)
+// @Param eventType query string false "事件类型，多个以逗号分隔"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "分布项数量" default(20)
+// @Success 200 {object} service.DistributionStatsResponse "统计数据"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/stats/distribution [get]
+func (h *Handler) GetEventDistribution(c *gin.Context) {
+	h.distributionStats(c, c.Query("dimension"))
+}
+
+// @Summary 获取浏览器分布
+// @Description 按浏览器统计事件数量和占比
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param eventType query string false "事件类型，多个以逗号分隔"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "分布项数量" default(20)
+// @Success 200 {object} service.DistributionStatsResponse "统计数据"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/stats/browser [get]
+func (h *Handler) GetBrowserDistribution(c *gin.Context) {
+	h.distributionStats(c, service.DimensionBrowser)
+}
+
+// @Summary 获取操作系统分布
+// @Description 按操作系统统计事件数量和占比
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param eventType query string false "事件类型，多个以逗号分隔"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "分布项数量" default(20)
+// @Success 200 {object} service.DistributionStatsResponse "统计数据"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/stats/os [get]
+func (h *Handler) GetOSDistribution(c *gin.Context) {
+	h.distributionStats(c, service.DimensionOS)
+}
+
+// @Summary 获取设备类型分布
+// @Description 按设备类型统计事件数量和占比
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param eventType query string false "事件类型，多个以逗号分隔"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "分布项数量" default(20)
+// @Success 200 {object} service.DistributionStatsResponse "统计数据"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/stats/device [get]
+func (h *Handler) GetDeviceDistribution(c *gin.Context) {
+	h.distributionStats(c, service.DimensionDeviceType)
+}
+
+// @Summary 获取错误类型分布
+// @Description 按错误类型统计错误事件数量和占比
+// @Tags 事件
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "分布项数量" default(20)
+// @Success 200 {object} service.DistributionStatsResponse "统计数据"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/events/stats/error-type [get]
+func (h *Handler) GetErrorTypeDistribution(c *gin.Context) {
+	h.distributionStats(c, service.DimensionErrorType)
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+)
+
+// Handler 接口处理器，持有各接口依赖的服务
+type Handler struct {
+	auth      *service.AuthService
+	projects  *service.ProjectService
+	events    *service.EventService
+	retention *service.RetentionService
+	budgets   *service.BudgetService
+}
+
+// NewHandler 创建接口处理器
+func NewHandler(
+	auth *service.AuthService,
+	projects *service.ProjectService,
+	events *service.EventService,
+	retention *service.RetentionService,
+	budgets *service.BudgetService,
+) *Handler {
+	return &Handler{
+		auth:      auth,
+		projects:  projects,
+		events:    events,
+		retention: retention,
+		budgets:   budgets,
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 搜索控制台日志
+// @Description 按关键词、级别、页面和时间范围搜索日志，关键词按空格拆分且需全部命中，同时返回各级别数量
+// @Tags 日志
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param level query string false "日志级别" Enums(error, warn, info, debug)
+// @Param keyword query string false "关键词"
+// @Param pageUrl query string false "页面URL"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Success 200 {object} service.LogListResponse "日志列表"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/logs [get]
+func (h *Handler) GetLogs(c *gin.Context) {
+	projectID := c.Query("projectId")
+	page := c.DefaultQuery("page", "1")
+	pageSize := c.DefaultQuery("pageSize", "10")
+	filter := service.LogFilter{
+		Level:     c.Query("level"),
+		Keyword:   c.Query("keyword"),
+		PageURL:   c.Query("pageUrl"),
+		StartTime: c.Query("startTime"),
+		EndTime:   c.Query("endTime"),
+	}
+
+	resp, err := h.events.GetLogs(projectID, page, pageSize, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// OTLP 请求体大小上限
+const maxOTLPBodySize = 10 << 20
+
+// 读取 OTLP 请求体，支持 gzip 压缩
+func readOTLPBody(c *gin.Context) ([]byte, error) {
+	var reader io.Reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxOTLPBodySize)
+	if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = io.LimitReader(gzipReader, maxOTLPBodySize)
+	}
+	return io.ReadAll(reader)
+}
+
+// 是否为 protobuf 编码
+func isOTLPProtobuf(c *gin.Context) bool {
+	return strings.HasPrefix(c.ContentType(), "application/x-protobuf")
+}
+
+// OTLP 上报的公共信息，应用标识从请求头或查询参数获取，也可放在资源属性 app.key 中
+func otlpBaseRequest(c *gin.Context) service.TrackRequest {
+	appKey := c.GetHeader("X-App-Key")
+	if appKey == "" {
+		appKey = c.Query("appKey")
+	}
+	return service.TrackRequest{
+		AppKey:    appKey,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Region:    clientRegion(c),
+	}
+}
+
+// 按请求的编码返回空的导出响应
+func otlpSuccess(c *gin.Context) {
+	if isOTLPProtobuf(c) {
+		c.Data(http.StatusOK, "application/x-protobuf", []byte{})
+		return
+	}
+	c.JSON(http.StatusOK, struct{}{})
+}
+
+// @Summary 接收 OTLP 链路数据
+// @Description 接收 OpenTelemetry 浏览器 SDK 通过 OTLP/HTTP 上报的 Span（支持 protobuf 和 JSON），异常映射为错误，documentLoad 映射为页面性能，资源 Span 映射为资源性能，请求 Span 映射为接口请求或 HTTP 错误
+// @Tags 数据上报
+// @Accept json
+// @Accept application/x-protobuf
+// @Produce json
+// @Param X-App-Key header string false "应用标识"
+// @Param appKey query string false "应用标识"
+// @Success 200 {object} object "上报成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Router /api/otlp/v1/traces [post]
+func (h *Handler) OTLPTraces(c *gin.Context) {
+	body, err := readOTLPBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	var req *service.OTLPTraceRequest
+	if isOTLPProtobuf(c) {
+		req, err = service.DecodeOTLPTraceProto(body)
+	} else {
+		req = &service.OTLPTraceRequest{}
+		err = json.Unmarshal(body, req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	if _, err := h.events.ProcessOTLPTraces(req, otlpBaseRequest(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	otlpSuccess(c)
+}
+
+// @Summary 接收 OTLP 日志数据
+// @Description 接收 OpenTelemetry 浏览器 SDK 通过 OTLP/HTTP 上报的日志（支持 protobuf 和 JSON），异常日志及 ERROR 级别以上的日志映射为错误
+// @Tags 数据上报
+// @Accept json
+// @Accept application/x-protobuf
+// @Produce json
+// @Param X-App-Key header string false "应用标识"
+// @Param appKey query string false "应用标识"
+// @Success 200 {object} object "上报成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Router /api/otlp/v1/logs [post]
+func (h *Handler) OTLPLogs(c *gin.Context) {
+	body, err := readOTLPBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	var req *service.OTLPLogsRequest
+	if isOTLPProtobuf(c) {
+		req, err = service.DecodeOTLPLogsProto(body)
+	} else {
+		req = &service.OTLPLogsRequest{}
+		err = json.Unmarshal(body, req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	if _, err := h.events.ProcessOTLPLogs(req, otlpBaseRequest(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	otlpSuccess(c)
+}
@@ -23,7 +23,7 @@ import (
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/performance [get]
-func GetPerformance(c *gin.Context) {
+func (h *Handler) GetPerformance(c *gin.Context) {
 	projectID := c.Query("projectId")
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
@@ -31,8 +31,7 @@ func GetPerformance(c *gin.Context) {
 	endTime := c.Query("endTime")
 	perfType := c.Query("type")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetPerformanceList(projectID, page, pageSize, startTime, endTime, perfType)
+	resp, err := h.events.GetPerformanceList(projectID, page, pageSize, startTime, endTime, perfType)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -42,25 +41,24 @@ func GetPerformance(c *gin.Context) {
 }
 
 // @Summary 获取性能统计信息
-// @Description 获取项目的性能统计信息
+// @Description 获取时间范围内的性能统计、趋势和 Web Vitals 分位数，默认最近7天按天统计，同时返回上一周期的对比数据
 // @Tags 性能监控
 // @Produce json
 // @Param projectId query int true "项目ID"
 // @Param startTime query int false "开始时间戳"
 // @Param endTime query int false "结束时间戳"
+// @Param granularity query string false "统计粒度：minute/hour/day/week，默认 day"
+// @Param timezone query string false "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC"
 // @Success 200 {object} service.PerformanceStatsResponse "性能统计信息"
 // @Failure 400 {object} ErrorResponse "请求错误"
 // @Failure 401 {object} ErrorResponse "未授权"
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/performance/stats [get]
-func GetPerformanceStats(c *gin.Context) {
+func (h *Handler) GetPerformanceStats(c *gin.Context) {
 	projectID := c.Query("projectId")
-	startTime := c.Query("startTime")
-	endTime := c.Query("endTime")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetPerformanceStats(projectID, startTime, endTime)
+	resp, err := h.events.GetPerformanceStats(projectID, statsQueryFromQuery(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -85,7 +83,7 @@ func GetPerformanceStats(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/performance/resources [get]
-func GetResourcePerformance(c *gin.Context) {
+func (h *Handler) GetResourcePerformance(c *gin.Context) {
 	projectID := c.Query("projectId")
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
@@ -93,8 +91,227 @@ func GetResourcePerformance(c *gin.Context) {
 	endTime := c.Query("endTime")
 	resourceType := c.Query("resourceType")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetResourcePerformanceList(projectID, page, pageSize, startTime, endTime, resourceType)
+	resp, err := h.events.GetResourcePerformanceList(projectID, page, pageSize, startTime, endTime, resourceType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取资源性能聚合
+// @Description 按归一化资源URL或域名聚合资源性能，返回请求次数、p75/p95 耗时、平均大小、缓存命中率以及第一方/第三方归属
+// @Tags 性能监控
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param resourceType query string false "资源类型"
+// @Param groupBy query string false "聚合维度" Enums(url, domain)
+// @Param party query string false "资源归属" Enums(first, third)
+// @Param sortBy query string false "排序字段" Enums(count, p75, p95, size, decodedSize, cacheHitRatio)
+// @Param order query string false "排序方向" Enums(desc, asc)
+// @Success 200 {object} service.ResourceAggregateResponse "资源性能聚合"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/performance/resources/aggregate [get]
+func (h *Handler) GetResourceAggregate(c *gin.Context) {
+	projectID := c.Query("projectId")
+	page := c.DefaultQuery("page", "1")
+	pageSize := c.DefaultQuery("pageSize", "10")
+	filter := service.ResourceAggregateFilter{
+		StartTime:    c.Query("startTime"),
+		EndTime:      c.Query("endTime"),
+		ResourceType: c.Query("resourceType"),
+		GroupBy:      c.DefaultQuery("groupBy", "url"),
+		Party:        c.Query("party"),
+		SortBy:       c.DefaultQuery("sortBy", "count"),
+		Order:        c.DefaultQuery("order", "desc"),
+	}
+
+	resp, err := h.events.GetResourceAggregate(projectID, page, pageSize, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取 Web Vitals 分位数统计
+// @Description 获取各项性能指标的 p50/p75/p90/p95/p99、良好/待改进/差的分布以及每个时间桶的 p75 趋势，默认最近7天，同时返回上一周期的分位数
+// @Tags 性能监控
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param granularity query string false "统计粒度：minute/hour/day/week，默认 day"
+// @Param timezone query string false "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC"
+// @Success 200 {object} service.WebVitalsResponse "Web Vitals 分位数统计"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/performance/vitals [get]
+func (h *Handler) GetWebVitals(c *gin.Context) {
+	projectID := c.Query("projectId")
+
+	resp, err := h.events.GetWebVitals(projectID, statsQueryFromQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取页面性能排行
+// @Description 按归一化页面聚合性能数据，返回 p75 指标和导航阶段耗时，可按环境筛选
+// @Tags 性能监控
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param browser query string false "浏览器"
+// @Param os query string false "操作系统"
+// @Param deviceType query string false "设备类型"
+// @Param region query string false "地区"
+// @Param sortBy query string false "排序字段" Enums(samples, lcp, fcp, ttfb, cls, inp)
+// @Param order query string false "排序方向" Enums(desc, asc)
+// @Success 200 {object} service.PagePerformanceListResponse "页面性能排行"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/performance/pages [get]
+func (h *Handler) GetPagePerformance(c *gin.Context) {
+	projectID := c.Query("projectId")
+	page := c.DefaultQuery("page", "1")
+	pageSize := c.DefaultQuery("pageSize", "10")
+	filter := service.PagePerformanceFilter{
+		StartTime:  c.Query("startTime"),
+		EndTime:    c.Query("endTime"),
+		Browser:    c.Query("browser"),
+		OS:         c.Query("os"),
+		DeviceType: c.Query("deviceType"),
+		Region:     c.Query("region"),
+		SortBy:     c.DefaultQuery("sortBy", "samples"),
+		Order:      c.DefaultQuery("order", "desc"),
+	}
+
+	resp, err := h.events.GetPagePerformanceList(projectID, page, pageSize, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取卡顿归因排行
+// @Description 按页面汇总长任务和交互延迟，排行导致长任务的脚本以及交互延迟高的元素
+// @Tags 性能监控
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param pageUrl query string false "页面URL，为空表示所有页面"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param limit query int false "排行数量" default(20)
+// @Success 200 {object} service.JankResponse "卡顿归因排行"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/performance/jank [get]
+func (h *Handler) GetJankRanking(c *gin.Context) {
+	projectID := c.Query("projectId")
+	pageURL := c.Query("pageUrl")
+	startTime := c.Query("startTime")
+	endTime := c.Query("endTime")
+	limit := c.DefaultQuery("limit", "20")
+
+	resp, err := h.events.GetJankRanking(projectID, pageURL, startTime, endTime, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取路由切换耗时
+// @Description 按来源和目标路由汇总单页应用路由切换耗时，并返回超过阈值的最慢切换样本
+// @Tags 性能监控
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param pageUrl query string false "页面URL，匹配来源或目标路由，为空表示所有页面"
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param threshold query int false "慢切换阈值（毫秒）" default(1000)
+// @Param limit query int false "路由数量" default(20)
+// @Success 200 {object} service.RouteTimingResponse "路由切换耗时"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/performance/routes [get]
+func (h *Handler) GetRouteTiming(c *gin.Context) {
+	projectID := c.Query("projectId")
+	pageURL := c.Query("pageUrl")
+	startTime := c.Query("startTime")
+	endTime := c.Query("endTime")
+	threshold := c.DefaultQuery("threshold", "1000")
+	limit := c.DefaultQuery("limit", "20")
+
+	resp, err := h.events.GetRouteTiming(projectID, pageURL, startTime, endTime, threshold, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 获取接口性能
+// @Description 按请求方法和接口模板（路径参数替换为 :id）聚合 XHR/fetch 请求，返回按采样率估算的调用量、p50/p95 耗时、成功率和状态码分布
+// @Tags 性能监控
+// @Produce json
+// @Param projectId query int true "项目ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param startTime query int false "开始时间戳"
+// @Param endTime query int false "结束时间戳"
+// @Param method query string false "请求方法"
+// @Param keyword query string false "接口地址关键词"
+// @Param sortBy query string false "排序字段" Enums(calls, p50, p95, successRate)
+// @Param order query string false "排序方向" Enums(desc, asc)
+// @Success 200 {object} service.APIPerformanceResponse "接口性能"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 500 {object} ErrorResponse "内部错误"
+// @Security ApiKeyAuth
+// @Router /api/performance/apis [get]
+func (h *Handler) GetAPIPerformance(c *gin.Context) {
+	projectID := c.Query("projectId")
+	page := c.DefaultQuery("page", "1")
+	pageSize := c.DefaultQuery("pageSize", "10")
+	filter := service.APIPerformanceFilter{
+		StartTime: c.Query("startTime"),
+		EndTime:   c.Query("endTime"),
+		Method:    c.Query("method"),
+		Keyword:   c.Query("keyword"),
+		SortBy:    c.DefaultQuery("sortBy", "calls"),
+		Order:     c.DefaultQuery("order", "desc"),
+	}
+
+	resp, err := h.events.GetAPIPerformance(projectID, page, pageSize, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
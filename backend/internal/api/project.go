@@ -20,7 +20,7 @@ import (
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/projects [post]
-func CreateProject(c *gin.Context) {
+func (h *Handler) CreateProject(c *gin.Context) {
 	var req service.CreateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
@@ -30,8 +30,7 @@ func CreateProject(c *gin.Context) {
 	// 获取当前用户 ID
 	userID := c.GetUint("userID")
 
-	projectService := service.ProjectService{}
-	project, err := projectService.CreateProject(&req, userID)
+	project, err := h.projects.CreateProject(&req, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -49,12 +48,11 @@ func CreateProject(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/projects [get]
-func GetProjects(c *gin.Context) {
+func (h *Handler) GetProjects(c *gin.Context) {
 	// 获取当前用户 ID
 	userID := c.GetUint("userID")
 
-	projectService := service.ProjectService{}
-	projects, err := projectService.GetUserProjects(userID)
+	projects, err := h.projects.GetUserProjects(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -75,7 +73,7 @@ func GetProjects(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/projects/{id} [get]
-func GetProject(c *gin.Context) {
+func (h *Handler) GetProject(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -86,8 +84,7 @@ func GetProject(c *gin.Context) {
 	// 获取当前用户 ID
 	userID := c.GetUint("userID")
 
-	projectService := service.ProjectService{}
-	project, err := projectService.GetProject(uint(id), userID)
+	project, err := h.projects.GetProject(uint(id), userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
 		return
@@ -110,7 +107,7 @@ func GetProject(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/projects/{id} [put]
-func UpdateProject(c *gin.Context) {
+func (h *Handler) UpdateProject(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -127,8 +124,7 @@ func UpdateProject(c *gin.Context) {
 	// 获取当前用户 ID
 	userID := c.GetUint("userID")
 
-	projectService := service.ProjectService{}
-	project, err := projectService.UpdateProject(uint(id), &req, userID)
+	project, err := h.projects.UpdateProject(uint(id), &req, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
 		return
@@ -149,7 +145,7 @@ func UpdateProject(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/projects/{id} [delete]
-func DeleteProject(c *gin.Context) {
+func (h *Handler) DeleteProject(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -160,8 +156,7 @@ func DeleteProject(c *gin.Context) {
 	// 获取当前用户 ID
 	userID := c.GetUint("userID")
 
-	projectService := service.ProjectService{}
-	err = projectService.DeleteProject(uint(id), userID)
+	err = h.projects.DeleteProject(uint(id), userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
 		return
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 获取数据保留策略
+// @Description 获取项目各数据类别的保留天数，未单独设置的类别使用配置中的默认值，0 表示永久保留
+// @Tags 数据保留
+// @Produce json
+// @Param id path int true "项目ID"
+// @Success 200 {array} service.RetentionItem "数据保留策略"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 404 {object} ErrorResponse "项目不存在"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/retention [get]
+func (h *Handler) GetRetention(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	items, err := h.retention.GetRetention(uint(id), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary 更新数据保留策略
+// @Description 设置项目各数据类别的保留天数，未包含的类别保持不变，0 表示永久保留
+// @Tags 数据保留
+// @Accept json
+// @Produce json
+// @Param id path int true "项目ID"
+// @Param data body service.RetentionRequest true "保留策略"
+// @Success 200 {array} service.RetentionItem "更新后的数据保留策略"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/retention [put]
+func (h *Handler) UpdateRetention(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	var req service.RetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	items, err := h.retention.UpdateRetention(uint(id), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary 清理项目数据
+// @Description 按数据类别或事件类型删除项目在时间范围内的数据（时间戳为毫秒，不含结束时间），类别和事件类型都为空时清理所有类型，分批删除
+// @Tags 数据保留
+// @Accept json
+// @Produce json
+// @Param id path int true "项目ID"
+// @Param data body service.PurgeRequest true "清理范围"
+// @Success 200 {object} service.PurgeResponse "删除的事件数量"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Security ApiKeyAuth
+// @Router /api/projects/{id}/purge [post]
+func (h *Handler) PurgeProjectData(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的项目ID"})
+		return
+	}
+
+	var req service.PurgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	// 获取当前用户 ID
+	userID := c.GetUint("userID")
+
+	resp, err := h.retention.PurgeProjectData(uint(id), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Sentry 请求体大小上限
+const maxSentryBodySize = 10 << 20
+
+// 读取 Sentry 请求体，支持 gzip/deflate 压缩以及旧版 SDK 的 base64+zlib 编码
+func readSentryBody(c *gin.Context) ([]byte, error) {
+	var reader io.Reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxSentryBodySize)
+	switch strings.ToLower(c.GetHeader("Content-Encoding")) {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = io.LimitReader(gzipReader, maxSentryBodySize)
+	case "deflate":
+		zlibReader, err := zlib.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer zlibReader.Close()
+		reader = io.LimitReader(zlibReader, maxSentryBodySize)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// 非 JSON 内容尝试按 base64+zlib 解码
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		if decoded, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil {
+			if zlibReader, err := zlib.NewReader(bytes.NewReader(decoded)); err == nil {
+				defer zlibReader.Close()
+				if inflated, err := io.ReadAll(io.LimitReader(zlibReader, maxSentryBodySize)); err == nil {
+					return inflated, nil
+				}
+			}
+		}
+	}
+	return body, nil
+}
+
+// Sentry 上报的公共信息，sentry_key 可能在请求头或查询参数中
+func sentryBaseRequest(c *gin.Context) service.TrackRequest {
+	appKey := service.SentryKeyFromAuth(c.GetHeader("X-Sentry-Auth"))
+	if appKey == "" {
+		appKey = c.Query("sentry_key")
+	}
+	return service.TrackRequest{
+		AppKey:    appKey,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Region:    clientRegion(c),
+	}
+}
+
+// @Summary 接收 Sentry store 上报
+// @Description 兼容 Sentry store 协议，sentry_key 对应项目的 AppKey，DSN 形如 http://<AppKey>@<host>/sentry/1
+// @Tags 数据上报
+// @Accept json
+// @Produce json
+// @Param projectId path string true "DSN 中的项目编号，仅用于兼容"
+// @Param X-Sentry-Auth header string false "Sentry 认证信息"
+// @Param sentry_key query string false "项目 AppKey"
+// @Success 200 {object} service.SentryResponse "上报成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Router /sentry/api/{projectId}/store/ [post]
+func (h *Handler) SentryStore(c *gin.Context) {
+	body, err := readSentryBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	base := sentryBaseRequest(c)
+	if base.AppKey == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Message: "缺少sentry_key"})
+		return
+	}
+
+	resp, err := h.events.ProcessSentryStore(body, base)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary 接收 Sentry envelope 上报
+// @Description 兼容 Sentry envelope 协议，只处理 event 条目，sentry_key 也可从信封头的 dsn 中获取
+// @Tags 数据上报
+// @Accept plain
+// @Produce json
+// @Param projectId path string true "DSN 中的项目编号，仅用于兼容"
+// @Param X-Sentry-Auth header string false "Sentry 认证信息"
+// @Param sentry_key query string false "项目 AppKey"
+// @Success 200 {object} service.SentryResponse "上报成功"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Router /sentry/api/{projectId}/envelope/ [post]
+func (h *Handler) SentryEnvelope(c *gin.Context) {
+	body, err := readSentryBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
+		return
+	}
+
+	resp, err := h.events.ProcessSentryEnvelope(body, sentryBaseRequest(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
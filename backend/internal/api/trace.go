@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 按 traceId 查找前端事件
+// @Description 查找携带指定 W3C traceId 的所有前端事件，并返回追踪系统链接
+// @Tags 链路追踪
+// @Produce json
+// @Param traceId path string true "traceId（32位十六进制）"
+// @Param projectId query int true "项目ID"
+// @Success 200 {object} service.TraceEventsResponse "链路关联的前端事件"
+// @Failure 400 {object} ErrorResponse "请求错误"
+// @Failure 401 {object} ErrorResponse "未授权"
+// @Security ApiKeyAuth
+// @Router /api/traces/{traceId} [get]
+func (h *Handler) GetTraceEvents(c *gin.Context) {
+	projectID := c.Query("projectId")
+	traceID := c.Param("traceId")
+
+	resp, err := h.events.GetTraceEvents(projectID, traceID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
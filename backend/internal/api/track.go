@@ -17,15 +17,17 @@ import (
 // @Failure 400 {object} ErrorResponse "请求错误"
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Router /trackweb [post]
-func TrackWeb(c *gin.Context) {
+func (h *Handler) TrackWeb(c *gin.Context) {
 	var req service.TrackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Message: "无效的请求参数"})
 		return
 	}
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+	req.Region = clientRegion(c)
 
-	eventService := service.EventService{}
-	err := eventService.ProcessTrackData(&req)
+	err := h.events.ProcessTrackData(&req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -34,6 +36,19 @@ func TrackWeb(c *gin.Context) {
 	c.JSON(http.StatusOK, SuccessResponse{Message: "上报成功"})
 }
 
+// CDN 或网关注入的地理位置请求头
+var regionHeaders = []string{"CF-IPCountry", "X-Country-Code", "X-Client-Region"}
+
+// 从请求头中获取客户端地区
+func clientRegion(c *gin.Context) string {
+	for _, header := range regionHeaders {
+		if region := c.GetHeader(header); region != "" {
+			return region
+		}
+	}
+	return ""
+}
+
 // @Summary 获取错误列表
 // @Description 获取项目的错误列表
 // @Tags 错误监控
@@ -51,7 +66,7 @@ func TrackWeb(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/errors [get]
-func GetErrors(c *gin.Context) {
+func (h *Handler) GetErrors(c *gin.Context) {
 	projectID := c.Query("projectId")
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
@@ -60,8 +75,7 @@ func GetErrors(c *gin.Context) {
 	errorType := c.Query("errorType")
 	severity := c.Query("severity")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetErrorList(projectID, page, pageSize, startTime, endTime, errorType, severity)
+	resp, err := h.events.GetErrorList(projectID, page, pageSize, startTime, endTime, errorType, severity)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
@@ -82,11 +96,10 @@ func GetErrors(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/errors/{id} [get]
-func GetErrorDetail(c *gin.Context) {
+func (h *Handler) GetErrorDetail(c *gin.Context) {
 	id := c.Param("id")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetErrorDetail(id)
+	resp, err := h.events.GetErrorDetail(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Message: err.Error()})
 		return
@@ -96,25 +109,24 @@ func GetErrorDetail(c *gin.Context) {
 }
 
 // @Summary 获取错误统计信息
-// @Description 获取项目的错误统计信息
+// @Description 获取时间范围内的错误统计和趋势，默认最近7天按天统计，同时返回上一周期的对比数据
 // @Tags 错误监控
 // @Produce json
 // @Param projectId query int true "项目ID"
 // @Param startTime query int false "开始时间戳"
 // @Param endTime query int false "结束时间戳"
+// @Param granularity query string false "统计粒度：minute/hour/day/week，默认 day"
+// @Param timezone query string false "时区，IANA 名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC"
 // @Success 200 {object} service.ErrorStatsResponse "错误统计信息"
 // @Failure 400 {object} ErrorResponse "请求错误"
 // @Failure 401 {object} ErrorResponse "未授权"
 // @Failure 500 {object} ErrorResponse "内部错误"
 // @Security ApiKeyAuth
 // @Router /api/errors/stats [get]
-func GetErrorStats(c *gin.Context) {
+func (h *Handler) GetErrorStats(c *gin.Context) {
 	projectID := c.Query("projectId")
-	startTime := c.Query("startTime")
-	endTime := c.Query("endTime")
 
-	eventService := service.EventService{}
-	resp, err := eventService.GetErrorStats(projectID, startTime, endTime)
+	resp, err := h.events.GetErrorStats(projectID, statsQueryFromQuery(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
 		return
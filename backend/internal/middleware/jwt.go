@@ -9,7 +9,7 @@ import (
 )
 
 // JWT 认证中间件
-func JWT() gin.HandlerFunc {
+func JWT(authService *service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var token string
 		// 从 Authorization 头获取 token
@@ -35,7 +35,6 @@ func JWT() gin.HandlerFunc {
 		}
 
 		// 解析 token
-		authService := service.AuthService{}
 		claims, err := authService.ParseToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
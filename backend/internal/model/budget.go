@@ -0,0 +1,26 @@
+package model
+
+// 预算指标
+const (
+	BudgetMetricLCP              = "lcp"
+	BudgetMetricFCP              = "fcp"
+	BudgetMetricFID              = "fid"
+	BudgetMetricINP              = "inp"
+	BudgetMetricCLS              = "cls"
+	BudgetMetricTTFB             = "ttfb"
+	BudgetMetricResourceSize     = "resource_size"     // 单次页面加载的资源传输总大小（字节）
+	BudgetMetricResourceDuration = "resource_duration" // 单个资源加载耗时（毫秒）
+)
+
+// PerformanceBudget 性能预算，指标的 p75 超过阈值即视为不达标
+type PerformanceBudget struct {
+	Model
+	ProjectID    uint     `json:"projectId" gorm:"not null;index"`
+	Project      *Project `json:"-" gorm:"foreignKey:ProjectID"`
+	Name         string   `json:"name" gorm:"size:100;not null"`
+	Metric       string   `json:"metric" gorm:"size:50;not null"`
+	PageURL      string   `json:"pageUrl" gorm:"size:500"`     // 归一化后的页面，为空表示所有页面
+	ResourceType string   `json:"resourceType" gorm:"size:50"` // 资源类指标的资源类型，为空表示所有类型
+	Threshold    float64  `json:"threshold" gorm:"not null"`
+	Enabled      bool     `json:"enabled"`
+}
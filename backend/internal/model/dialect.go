@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 支持的数据库类型
+const (
+	DatabaseMySQL    = "mysql"
+	DatabasePostgres = "pgsql"
+	DatabaseSQLite   = "sqlite"
+)
+
+// SQL 片段中的表名占位符，如 {event_main}
+var tablePlaceholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Dialect 各数据库之间有差异的 SQL 片段
+type Dialect interface {
+	// TimeSlot 将毫秒时间戳列按固定长度划分为时间片，返回时间片序号的整数表达式
+	TimeSlot(column string, size int64) string
+	// Like 返回 column LIKE ? 条件，模式中的通配符使用反斜杠转义
+	Like(column string) string
+}
+
+// MySQL 和 PostgreSQL 的 LIKE 默认以反斜杠为转义符
+type mysqlDialect struct{}
+
+func (mysqlDialect) TimeSlot(column string, size int64) string {
+	return fmt.Sprintf("(%s DIV %d)", column, size)
+}
+
+func (mysqlDialect) Like(column string) string {
+	return column + " LIKE ?"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) TimeSlot(column string, size int64) string {
+	// 整数相除即向下取整
+	return fmt.Sprintf("(%s / %d)", column, size)
+}
+
+func (postgresDialect) Like(column string) string {
+	return column + " LIKE ?"
+}
+
+// SQLite 的 LIKE 没有默认转义符，需要显式指定
+type sqliteDialect struct{}
+
+func (sqliteDialect) TimeSlot(column string, size int64) string {
+	return fmt.Sprintf("(%s / %d)", column, size)
+}
+
+func (sqliteDialect) Like(column string) string {
+	return column + ` LIKE ? ESCAPE '\'`
+}
+
+// GetDialect 获取当前数据库的方言
+func GetDialect() Dialect {
+	switch DatabaseSetting.Type {
+	case DatabasePostgres:
+		return postgresDialect{}
+	case DatabaseSQLite:
+		return sqliteDialect{}
+	default:
+		return mysqlDialect{}
+	}
+}
+
+// TableName 带配置前缀的表名
+func TableName(name string) string {
+	return DatabaseSetting.TablePrefix + name
+}
+
+// SQL 将 SQL 片段中的 {表名} 占位符替换为带配置前缀的表名，
+// 如 "JOIN {event_main} ON {event_main}.id = {pv_detail}.event_id"
+func SQL(fragment string) string {
+	return tablePlaceholderPattern.ReplaceAllStringFunc(fragment, func(placeholder string) string {
+		return TableName(placeholder[1 : len(placeholder)-1])
+	})
+}
+
+// EscapeLike 转义 LIKE 模式中的通配符，配合 Dialect.Like 使用
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
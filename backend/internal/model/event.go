@@ -10,6 +10,13 @@ const (
 	EventTypeDwell               = "dwell"
 	EventTypeIntersection        = "intersection"
 	EventTypeCustom              = "custom"
+	EventTypeRageClick           = "rage_click" // 由点击序列推导
+	EventTypeDeadClick           = "dead_click" // 由点击序列推导
+	EventTypeLongTask            = "long_task"  // 长任务及长动画帧（LoAF）
+	EventTypeInteraction         = "interaction"
+	EventTypeLog                 = "log"
+	EventTypeRoute               = "route" // 单页应用路由切换
+	EventTypeRequest             = "request"
 )
 
 // 错误类型枚举
@@ -21,6 +28,7 @@ const (
 	ErrorTypeVue      = "vue_error"
 	ErrorTypeReact    = "react_error"
 	ErrorTypeCustom   = "custom_error"
+	ErrorTypeConsole  = "console_error" // 由 console.error 日志提升
 )
 
 // 错误子类型枚举
@@ -84,6 +92,9 @@ type BaseInfo struct {
 	PixelDepth   int    `json:"pixelDepth"`
 	DeviceID     string `json:"deviceId" gorm:"size:100"`
 	PageID       string `json:"pageId" gorm:"size:100"`
+	Region       string `json:"region" gorm:"size:100"`
+	Release      string `json:"release" gorm:"column:release_version;size:100;index"` // release 是 MySQL 保留字
+	Environment  string `json:"environment" gorm:"size:50"`
 	SendTime     int64  `json:"sendTime"`
 	Ext          string `json:"ext" gorm:"type:text"`
 }
@@ -101,31 +112,36 @@ type EventMain struct {
 	TriggerPageURL string    `json:"triggerPageUrl" gorm:"type:text"`
 	Title          string    `json:"title" gorm:"size:255"`
 	Referer        string    `json:"referer" gorm:"type:text"`
+	// W3C Trace Context，用于关联后端链路
+	TraceID string `json:"traceId" gorm:"size:32;index"`
+	SpanID  string `json:"spanId" gorm:"size:16"`
 }
 
 // 性能页面详情
 type PerformancePageDetail struct {
 	Model
-	EventID         uint       `json:"eventId" gorm:"not null"`
-	Event           *EventMain `json:"event" gorm:"foreignKey:EventID"`
-	FP              int64      `json:"fp"`
-	FCP             int64      `json:"fcp"`
-	LCP             int64      `json:"lcp"`
-	FID             int64      `json:"fid"`
-	CLS             float64    `json:"cls"`
-	TTFB            int64      `json:"ttfb"`
-	DomReady        int64      `json:"domReady"`
-	Load            int64      `json:"load"`
-	FirstByte       int64      `json:"firstByte"`
-	DNS             int64      `json:"dns"`
-	TCP             int64      `json:"tcp"`
-	SSL             int64      `json:"ssl"`
-	TTFB2           int64      `json:"ttfb2"`
-	Trans           int64      `json:"trans"`
-	DomParse        int64      `json:"domParse"`
-	ResourceLoad    int64      `json:"resourceLoad"`
-	DomContentLoad  int64      `json:"domContentLoad"`
-	FirstScreenTime int64      `json:"firstScreenTime"`
+	EventID uint       `json:"eventId" gorm:"not null"`
+	Event   *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	// 性能指标在同一次页面加载中分别上报，未上报的为 NULL，以区分值为 0 的指标（如 CLS）
+	FP              *int64   `json:"fp"`
+	FCP             *int64   `json:"fcp"`
+	LCP             *int64   `json:"lcp"`
+	FID             *int64   `json:"fid"`
+	INP             *int64   `json:"inp"`
+	CLS             *float64 `json:"cls"`
+	TTFB            *int64   `json:"ttfb"`
+	DomReady        *int64   `json:"domReady"`
+	Load            *int64   `json:"load"`
+	FirstByte       int64    `json:"firstByte"`
+	DNS             int64    `json:"dns"`
+	TCP             int64    `json:"tcp"`
+	SSL             int64    `json:"ssl"`
+	TTFB2           int64    `json:"ttfb2"`
+	Trans           int64    `json:"trans"`
+	DomParse        int64    `json:"domParse"`
+	ResourceLoad    int64    `json:"resourceLoad"`
+	DomContentLoad  int64    `json:"domContentLoad"`
+	FirstScreenTime int64    `json:"firstScreenTime"`
 }
 
 // 性能资源详情
@@ -158,7 +174,7 @@ type PVDetail struct {
 	PageURL      string     `json:"pageUrl" gorm:"type:text;not null"`
 	Title        string     `json:"title" gorm:"size:255"`
 	Referrer     string     `json:"referrer" gorm:"type:text"`
-	StayTime     int64      `json:"stayTime"`
+	StayTime     int64      `json:"stayTime"` // 停留时间（毫秒），0 表示未上报
 	IsNewVisit   bool       `json:"isNewVisit"`
 	IsNewSession bool       `json:"isNewSession"`
 }
@@ -171,6 +187,11 @@ type ClickDetail struct {
 	ElementPath string     `json:"elementPath" gorm:"type:text"`
 	ElementType string     `json:"elementType" gorm:"size:50"`
 	InnerText   string     `json:"innerText" gorm:"type:text"`
+	// 点击坐标（相对页面左上角）及视口尺寸
+	X              int `json:"x"`
+	Y              int `json:"y"`
+	ViewportWidth  int `json:"viewportWidth"`
+	ViewportHeight int `json:"viewportHeight"`
 }
 
 // 停留详情
@@ -202,3 +223,166 @@ type CustomDetail struct {
 	EventParams string     `json:"eventParams" gorm:"type:text"`
 	Data        string     `json:"data" gorm:"type:text"`
 }
+
+// 日志级别
+const (
+	LogLevelError = "error"
+	LogLevelWarn  = "warn"
+	LogLevelInfo  = "info"
+	LogLevelDebug = "debug"
+)
+
+// 控制台日志详情
+type LogDetail struct {
+	Model
+	EventID   uint       `json:"eventId" gorm:"not null"`
+	Event     *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	Level     string     `json:"level" gorm:"size:20;index"`
+	Message   string     `json:"message" gorm:"type:text"`
+	Arguments string     `json:"arguments" gorm:"type:text"` // 参数列表的 JSON
+	Stack     string     `json:"stack" gorm:"type:text"`
+}
+
+// 长任务类型
+const (
+	LongTaskKindTask           = "longtask"
+	LongTaskKindAnimationFrame = "long-animation-frame"
+)
+
+// 长任务详情，长动画帧取耗时最长的脚本作为归因
+type LongTaskDetail struct {
+	Model
+	EventID          uint       `json:"eventId" gorm:"not null"`
+	Event            *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	Kind             string     `json:"kind" gorm:"size:50"`
+	StartTime        int64      `json:"startTime"`
+	Duration         int64      `json:"duration"`
+	BlockingDuration int64      `json:"blockingDuration"`
+	ScriptURL        string     `json:"scriptUrl" gorm:"type:text"`
+	ScriptFunction   string     `json:"scriptFunction" gorm:"size:200"`
+	ScriptInvoker    string     `json:"scriptInvoker" gorm:"size:200"`
+	ScriptDuration   int64      `json:"scriptDuration"`
+}
+
+// 交互延迟详情，Duration = InputDelay + ProcessingTime + PresentationDelay
+type InteractionDetail struct {
+	Model
+	EventID           uint       `json:"eventId" gorm:"not null"`
+	Event             *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	InteractionType   string     `json:"interactionType" gorm:"size:50"`
+	ElementPath       string     `json:"elementPath" gorm:"type:text"`
+	ElementType       string     `json:"elementType" gorm:"size:50"`
+	StartTime         int64      `json:"startTime"`
+	Duration          int64      `json:"duration"`
+	InputDelay        int64      `json:"inputDelay"`
+	ProcessingTime    int64      `json:"processingTime"`
+	PresentationDelay int64      `json:"presentationDelay"`
+}
+
+// 路由切换详情，单页应用每次切换同时生成一条页面访问记录
+type RouteDetail struct {
+	Model
+	EventID  uint       `json:"eventId" gorm:"not null"`
+	Event    *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	FromURL  string     `json:"fromUrl" gorm:"type:text"`
+	ToURL    string     `json:"toUrl" gorm:"type:text"`
+	Mode     string     `json:"mode" gorm:"size:20"`   // history、hash
+	Action   string     `json:"action" gorm:"size:20"` // push、replace、pop
+	Duration int64      `json:"duration"`              // 路由切换耗时（毫秒）
+	StayTime int64      `json:"stayTime"`              // 在来源路由的停留时间（毫秒）
+}
+
+// 接口请求详情，包含成功和失败的 XHR/fetch 请求，成功请求按采样率入库
+type RequestDetail struct {
+	Model
+	EventID     uint       `json:"eventId" gorm:"not null"`
+	Event       *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	URL         string     `json:"url" gorm:"type:text"`
+	URLTemplate string     `json:"urlTemplate" gorm:"size:255;index"` // 路径参数替换为 :id 后的接口地址
+	Method      string     `json:"method" gorm:"size:20"`
+	Params      string     `json:"params" gorm:"type:text"`
+	Status      int        `json:"status"`
+	Duration    int64      `json:"duration"`
+	Success     bool       `json:"success"`
+	SampleRate  float64    `json:"sampleRate"` // 入库时的采样率，用于估算实际调用量
+}
+
+// 点击挫败详情（狂点、无响应点击），由点击事件推导生成
+type FrustrationDetail struct {
+	Model
+	EventID      uint       `json:"eventId" gorm:"not null"`
+	Event        *EventMain `json:"event" gorm:"foreignKey:EventID"`
+	SessionID    string     `json:"sessionId" gorm:"size:100"`
+	PageURL      string     `json:"pageUrl" gorm:"type:text"`
+	ElementPath  string     `json:"elementPath" gorm:"type:text"`
+	ElementType  string     `json:"elementType" gorm:"size:50"`
+	InnerText    string     `json:"innerText" gorm:"type:text"`
+	ClickCount   int        `json:"clickCount"`
+	FirstClickID uint       `json:"firstClickId"`
+	StartTime    int64      `json:"startTime"`
+	EndTime      int64      `json:"endTime"`
+}
+
+// EventDetailModel 事件详情模型，Name 作为详情在接口中的键
+type EventDetailModel struct {
+	Name string
+	New  func() interface{}
+}
+
+// 事件类型对应的详情模型，新增事件类型时在此注册
+var eventDetailModels = map[string][]EventDetailModel{
+	EventTypeError: {
+		{Name: "error", New: func() interface{} { return &ErrorDetail{} }},
+		{Name: "httpError", New: func() interface{} { return &HttpErrorDetail{} }},
+		{Name: "resourceError", New: func() interface{} { return &ResourceErrorDetail{} }},
+	},
+	EventTypePerformancePage: {
+		{Name: "performance", New: func() interface{} { return &PerformancePageDetail{} }},
+	},
+	EventTypePerformanceResource: {
+		{Name: "resource", New: func() interface{} { return &PerformanceResourceDetail{} }},
+	},
+	EventTypePV: {
+		{Name: "pv", New: func() interface{} { return &PVDetail{} }},
+	},
+	EventTypeClick: {
+		{Name: "click", New: func() interface{} { return &ClickDetail{} }},
+	},
+	EventTypeDwell: {
+		{Name: "dwell", New: func() interface{} { return &DwellDetail{} }},
+	},
+	EventTypeIntersection: {
+		{Name: "intersection", New: func() interface{} { return &IntersectionDetail{} }},
+	},
+	EventTypeCustom: {
+		{Name: "custom", New: func() interface{} { return &CustomDetail{} }},
+	},
+	EventTypeRageClick: {
+		{Name: "frustration", New: func() interface{} { return &FrustrationDetail{} }},
+	},
+	EventTypeDeadClick: {
+		{Name: "frustration", New: func() interface{} { return &FrustrationDetail{} }},
+	},
+	EventTypeLongTask: {
+		{Name: "longTask", New: func() interface{} { return &LongTaskDetail{} }},
+	},
+	EventTypeInteraction: {
+		{Name: "interaction", New: func() interface{} { return &InteractionDetail{} }},
+	},
+	EventTypeLog: {
+		{Name: "log", New: func() interface{} { return &LogDetail{} }},
+		{Name: "error", New: func() interface{} { return &ErrorDetail{} }}, // 提升为错误的 console.error
+	},
+	EventTypeRoute: {
+		{Name: "route", New: func() interface{} { return &RouteDetail{} }},
+		{Name: "pv", New: func() interface{} { return &PVDetail{} }},
+	},
+	EventTypeRequest: {
+		{Name: "request", New: func() interface{} { return &RequestDetail{} }},
+	},
+}
+
+// GetEventDetailModels 获取事件类型对应的详情模型，未注册的类型返回 nil
+func GetEventDetailModels(eventType string) []EventDetailModel {
+	return eventDetailModels[eventType]
+}
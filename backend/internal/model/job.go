@@ -0,0 +1,73 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 后台任务游标，记录任务已处理到的位置
+type JobCursor struct {
+	Model
+	Name   string `json:"name" gorm:"size:100;not null;unique"`
+	LastID uint   `json:"lastId" gorm:"not null;default:0"`
+}
+
+// 获取任务游标，不存在时返回 0
+func GetJobCursor(db *gorm.DB, name string) (uint, error) {
+	var cursor JobCursor
+	result := db.Where("name = ?", name).Limit(1).Find(&cursor)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return cursor.LastID, nil
+}
+
+// 保存任务游标
+func SaveJobCursor(db *gorm.DB, name string, lastID uint) error {
+	var cursor JobCursor
+	result := db.Where("name = ?", name).Limit(1).Find(&cursor)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		cursor = JobCursor{Name: name}
+	}
+	cursor.LastID = lastID
+	return db.Save(&cursor).Error
+}
+
+// ErrJobCursorMoved 游标已被其他任务更新
+var ErrJobCursorMoved = errors.New("任务游标已被其他任务更新")
+
+// 在事务中将游标从 from 推进到 to，游标已被其他任务更新时返回 ErrJobCursorMoved，
+// 用于保证同一批数据只被处理一次
+func AdvanceJobCursor(tx *gorm.DB, name string, from, to uint) error {
+	result := tx.Model(&JobCursor{}).
+		Where("name = ? AND last_id = ?", name, from).
+		Updates(map[string]interface{}{"last_id": to, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// 游标尚未创建
+	if from == 0 {
+		var count int64
+		if err := tx.Model(&JobCursor{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return tx.Create(&JobCursor{Name: name, LastID: to}).Error
+		}
+	}
+	return ErrJobCursorMoved
+}
+
+// 在事务中删除任务游标，任务将从头开始处理
+func DeleteJobCursors(tx *gorm.DB, names []string) error {
+	return tx.Where("name IN ?", names).Delete(&JobCursor{}).Error
+}
@@ -3,8 +3,11 @@ package model
 import (
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"github.com/go-ini/ini"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -24,12 +27,14 @@ type Model struct {
 
 // 数据库配置
 type Database struct {
-	Type        string
+	Type        string // mysql、pgsql 或 sqlite
 	User        string
 	Password    string
 	Host        string
-	Name        string
+	Name        string // 数据库名称，sqlite 为数据库文件路径
 	TablePrefix string
+	// 启动服务时是否自动执行未执行的迁移，关闭后需通过 migrate up 命令执行
+	MigrateOnStart bool
 }
 
 // 服务器配置
@@ -41,8 +46,119 @@ type Server struct {
 	JwtSecret    string
 }
 
-var DatabaseSetting = &Database{}
+// URL 归一化配置
+type Normalize struct {
+	StripQuery        bool
+	StripHash         bool
+	IDPatterns        []string `delim:","`
+	FirstPartyDomains []string `delim:","`
+}
+
+// 点击挫败检测配置
+type Frustration struct {
+	RageClickCount  int   // 判定为狂点的最少点击次数
+	RageClickWindow int64 // 狂点相邻两次点击的最大间隔（毫秒）
+	DeadClickWindow int64 // 点击后等待页面响应的时长（毫秒）
+	SettleDelay     int   // 点击入库多久后再检测（秒），等待后续事件上报
+	Interval        int   // 检测任务执行间隔（秒）
+}
+
+// 日志配置
+type Log struct {
+	PromoteConsoleError bool // 是否将 console.error 日志提升为错误分组
+}
+
+// 接口请求监控配置
+type Request struct {
+	SampleRate float64 // 成功请求的采样率（0-1），失败请求始终入库
+}
+
+// 数据保留配置，各类别的保留天数为项目未单独设置时的默认值，0 表示永久保留
+type Retention struct {
+	Interval        int // 清理任务执行间隔（秒）
+	BatchSize       int // 每批删除的事件数量
+	ErrorDays       int
+	PerformanceDays int
+	BehaviorDays    int
+	LogDays         int
+	RequestDays     int
+	CustomDays      int
+}
+
+// 预聚合配置
+type Rollup struct {
+	Interval        int // 预聚合任务执行间隔（秒）
+	BatchSize       int // 每批处理的记录数量
+	SettleDelay     int // 记录入库多久后再聚合（秒）
+	LateSettleDelay int // 性能和页面访问记录入库后仍会合并指标或回填停留时间，等待更久再聚合（秒），需长于 30 分钟的性能合并窗口
+	MinuteDays      int // 分钟粒度保留天数
+	HourDays        int // 小时粒度保留天数，0 表示永久保留
+}
+
+// 事件分析存储配置
+type EventStore struct {
+	Type         string // gorm（默认，直接查询关系库）、clickhouse 或 memory
+	Url          string // ClickHouse HTTP 接口地址，如 http://127.0.0.1:8123
+	Database     string
+	User         string
+	Password     string
+	TTLDays      int // ClickHouse 中事件的保留天数，0 表示永久保留
+	SyncInterval int // 从关系库同步事件的间隔（秒）
+	BatchSize    int // 每批同步的事件数量
+	SettleDelay  int // 事件入库多久后再同步（秒），等待详情写入
+}
+
+// 链路追踪配置
+type Tracing struct {
+	UrlTemplate string // 追踪系统链接模板，支持 {traceId}、{spanId} 占位符
+}
+
+var DatabaseSetting = &Database{
+	MigrateOnStart: true,
+}
 var ServerSetting = &Server{}
+var NormalizeSetting = &Normalize{
+	StripQuery: true,
+	StripHash:  true,
+	IDPatterns: []string{`^\d+$`},
+}
+var FrustrationSetting = &Frustration{
+	RageClickCount:  3,
+	RageClickWindow: 1000,
+	DeadClickWindow: 1000,
+	SettleDelay:     60,
+	Interval:        60,
+}
+var TracingSetting = &Tracing{}
+var EventStoreSetting = &EventStore{
+	Type:         "gorm",
+	Database:     "default",
+	SyncInterval: 10,
+	BatchSize:    5000,
+	SettleDelay:  10,
+}
+var LogSetting = &Log{}
+var RequestSetting = &Request{
+	SampleRate: 1,
+}
+var RetentionSetting = &Retention{
+	Interval:        3600,
+	BatchSize:       1000,
+	ErrorDays:       90,
+	PerformanceDays: 30,
+	BehaviorDays:    14,
+	LogDays:         14,
+	RequestDays:     14,
+	CustomDays:      30,
+}
+var RollupSetting = &Rollup{
+	Interval:        60,
+	BatchSize:       5000,
+	SettleDelay:     60,
+	LateSettleDelay: 2400,
+	MinuteDays:      2,
+	HourDays:        31,
+}
 
 // 初始化配置
 func Setup() {
@@ -62,27 +178,91 @@ func Setup() {
 		log.Fatalf("Failed to map server section: %v", err)
 	}
 
+	err = cfg.Section("normalize").MapTo(NormalizeSetting)
+	if err != nil {
+		log.Fatalf("Failed to map normalize section: %v", err)
+	}
+
+	err = cfg.Section("frustration").MapTo(FrustrationSetting)
+	if err != nil {
+		log.Fatalf("Failed to map frustration section: %v", err)
+	}
+
+	err = cfg.Section("tracing").MapTo(TracingSetting)
+	if err != nil {
+		log.Fatalf("Failed to map tracing section: %v", err)
+	}
+
+	err = cfg.Section("log").MapTo(LogSetting)
+	if err != nil {
+		log.Fatalf("Failed to map log section: %v", err)
+	}
+
+	err = cfg.Section("request").MapTo(RequestSetting)
+	if err != nil {
+		log.Fatalf("Failed to map request section: %v", err)
+	}
+
+	err = cfg.Section("retention").MapTo(RetentionSetting)
+	if err != nil {
+		log.Fatalf("Failed to map retention section: %v", err)
+	}
+
+	err = cfg.Section("rollup").MapTo(RollupSetting)
+	if err != nil {
+		log.Fatalf("Failed to map rollup section: %v", err)
+	}
+
+	err = cfg.Section("eventstore").MapTo(EventStoreSetting)
+	if err != nil {
+		log.Fatalf("Failed to map eventstore section: %v", err)
+	}
+
+	// 连接到指定的数据库
+	var dialector gorm.Dialector
+	switch DatabaseSetting.Type {
+	case DatabaseMySQL, DatabasePostgres:
+		dialector = serverDialector()
+	case DatabaseSQLite:
+		dialector = sqliteDialector()
+	default:
+		log.Fatalf("Unsupported database type: %s", DatabaseSetting.Type)
+	}
+
+	db, err = gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix:   DatabaseSetting.TablePrefix,
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+}
+
+// 连接 MySQL 或 PostgreSQL 服务器，数据库不存在时先创建
+func serverDialector() gorm.Dialector {
 	var tempDB *gorm.DB
 	var dsn string
+	var err error
 
 	// 根据数据库类型选择正确的数据库驱动
 	switch DatabaseSetting.Type {
-	case "mysql":
+	case DatabaseMySQL:
 		// 连接到 MySQL 服务器，不指定数据库
 		dsn = fmt.Sprintf("%s:%s@tcp(%s)/?charset=utf8mb4&parseTime=True&loc=Local",
 			DatabaseSetting.User,
 			DatabaseSetting.Password,
 			DatabaseSetting.Host)
 		tempDB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	case "pgsql":
+	case DatabasePostgres:
 		// 连接到 PostgreSQL 服务器，不指定数据库
 		dsn = fmt.Sprintf("host=%s user=%s password=%s sslmode=disable",
 			DatabaseSetting.Host,
 			DatabaseSetting.User,
 			DatabaseSetting.Password)
 		tempDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	default:
-		log.Fatalf("Unsupported database type: %s", DatabaseSetting.Type)
 	}
 	if err != nil {
 		log.Fatalf("Failed to connect to database server: %v", err)
@@ -91,9 +271,9 @@ func Setup() {
 	// 创建数据库（如果不存在）
 	var sql string
 	switch DatabaseSetting.Type {
-	case "mysql":
+	case DatabaseMySQL:
 		sql = fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s;", DatabaseSetting.Name)
-	case "pgsql":
+	case DatabasePostgres:
 		// 检查数据库是否存在
 		var exists bool
 		err = tempDB.Raw("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = ?)", DatabaseSetting.Name).Scan(&exists).Error
@@ -121,78 +301,35 @@ func Setup() {
 	// 连接到指定的数据库
 	var dialector gorm.Dialector
 	switch DatabaseSetting.Type {
-	case "mysql":
+	case DatabaseMySQL:
 		dsn = fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 			DatabaseSetting.User,
 			DatabaseSetting.Password,
 			DatabaseSetting.Host,
 			DatabaseSetting.Name)
 		dialector = mysql.Open(dsn)
-	case "pgsql":
+	case DatabasePostgres:
 		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=disable",
 			DatabaseSetting.Host,
 			DatabaseSetting.User,
 			DatabaseSetting.Password,
 			DatabaseSetting.Name)
 		dialector = postgres.Open(dsn)
-	default:
-		log.Fatalf("Unsupported database type: %s", DatabaseSetting.Type)
-	}
-
-	db, err = gorm.Open(dialector, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-		NamingStrategy: schema.NamingStrategy{
-			TablePrefix:   DatabaseSetting.TablePrefix,
-			SingularTable: true,
-		},
-	})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	return dialector
 
-	// 自动迁移数据库
-	// 先创建基础表
-	err = db.AutoMigrate(
-		&User{},
-		&Project{},
-		&BaseInfo{},
-	)
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
-	}
+}
 
-	// 创建 Event 表
-	err = db.AutoMigrate(&EventMain{})
-	if err != nil {
-		log.Fatalf("Failed to migrate Event table: %v", err)
-	}
-
-	// 创建各种事件表
-	err = db.AutoMigrate(
-		&PerformancePageDetail{},
-		&PerformanceResourceDetail{},
-		&PVDetail{},
-		&ClickDetail{},
-		&DwellDetail{},
-		&IntersectionDetail{},
-		&CustomDetail{},
-	)
-	if err != nil {
-		log.Fatalf("Failed to migrate event tables: %v", err)
-	}
-
-	// 创建错误相关表
-	err = db.AutoMigrate(
-		&ErrorDetail{},
-		&HttpErrorDetail{},
-		&ResourceErrorDetail{},
-		&VueErrorDetail{},
-		&ReactErrorDetail{},
-		&ErrorGroup{},
-	)
-	if err != nil {
-		log.Fatalf("Failed to migrate error tables: %v", err)
+// 打开 SQLite 数据库文件，不存在时自动创建，用于单机嵌入式部署
+func sqliteDialector() gorm.Dialector {
+	if dir := filepath.Dir(DatabaseSetting.Name); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Fatalf("Failed to create database directory: %v", err)
+		}
 	}
+	// 使用 WAL 提升读写并发，写事务立即加锁并等待，避免后台任务与请求并发写入时报错
+	dsn := DatabaseSetting.Name + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate"
+	return sqlite.Open(dsn)
 }
 
 // 获取数据库连接
@@ -0,0 +1,62 @@
+package model
+
+// 数据保留类别
+const (
+	RetentionCategoryError       = "error"
+	RetentionCategoryPerformance = "performance"
+	RetentionCategoryBehavior    = "behavior"
+	RetentionCategoryLog         = "log"
+	RetentionCategoryRequest     = "request"
+	RetentionCategoryCustom      = "custom"
+)
+
+// RetentionCategoryEventTypes 各保留类别包含的事件类型
+var RetentionCategoryEventTypes = map[string][]string{
+	RetentionCategoryError: {EventTypeError},
+	RetentionCategoryPerformance: {
+		EventTypePerformancePage,
+		EventTypePerformanceResource,
+		EventTypeLongTask,
+		EventTypeInteraction,
+	},
+	RetentionCategoryBehavior: {
+		EventTypePV,
+		EventTypeClick,
+		EventTypeDwell,
+		EventTypeIntersection,
+		EventTypeRoute,
+		EventTypeRageClick,
+		EventTypeDeadClick,
+	},
+	RetentionCategoryLog:     {EventTypeLog},
+	RetentionCategoryRequest: {EventTypeRequest},
+	RetentionCategoryCustom:  {EventTypeCustom},
+}
+
+// RetentionPolicy 项目的数据保留策略，覆盖配置中的默认保留天数，Days 为 0 表示永久保留
+type RetentionPolicy struct {
+	Model
+	ProjectID uint     `json:"projectId" gorm:"not null;uniqueIndex:idx_retention_project_category"`
+	Project   *Project `json:"-" gorm:"foreignKey:ProjectID"`
+	Category  string   `json:"category" gorm:"size:50;not null;uniqueIndex:idx_retention_project_category"`
+	Days      int      `json:"days" gorm:"not null"`
+}
+
+// DefaultRetentionDays 获取配置中类别的默认保留天数
+func DefaultRetentionDays(category string) int {
+	switch category {
+	case RetentionCategoryError:
+		return RetentionSetting.ErrorDays
+	case RetentionCategoryPerformance:
+		return RetentionSetting.PerformanceDays
+	case RetentionCategoryBehavior:
+		return RetentionSetting.BehaviorDays
+	case RetentionCategoryLog:
+		return RetentionSetting.LogDays
+	case RetentionCategoryRequest:
+		return RetentionSetting.RequestDays
+	case RetentionCategoryCustom:
+		return RetentionSetting.CustomDays
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package model
+
+import "gorm.io/gorm"
+
+// 预聚合粒度
+const (
+	RollupMinute = "minute"
+	RollupHour   = "hour"
+	RollupDay    = "day"
+)
+
+// 各粒度的桶长度（毫秒）
+var rollupBucketSizes = map[string]int64{
+	RollupMinute: 60 * 1000,
+	RollupHour:   3600 * 1000,
+	RollupDay:    86400 * 1000,
+}
+
+// RollupBucketSize 获取粒度对应的桶长度（毫秒）
+func RollupBucketSize(granularity string) int64 {
+	return rollupBucketSizes[granularity]
+}
+
+// RollupBucketStart 获取时间戳（毫秒）所在桶的起始时间，按 UTC 对齐
+func RollupBucketStart(granularity string, ts int64) int64 {
+	size := RollupBucketSize(granularity)
+	if ts < 0 {
+		return ts - (size+ts%size)%size
+	}
+	return ts - ts%size
+}
+
+// 计数预聚合，Dimension 为空表示该指标的总量，分钟粒度只保存总量
+type RollupCount struct {
+	Model
+	ProjectID   uint    `json:"projectId" gorm:"not null;uniqueIndex:idx_rollup_count"`
+	Granularity string  `json:"granularity" gorm:"size:10;not null;uniqueIndex:idx_rollup_count"`
+	BucketStart int64   `json:"bucketStart" gorm:"not null;uniqueIndex:idx_rollup_count"`
+	Metric      string  `json:"metric" gorm:"size:50;not null;uniqueIndex:idx_rollup_count"`
+	Dimension   string  `json:"dimension" gorm:"size:20;not null;uniqueIndex:idx_rollup_count"`
+	Value       string  `json:"value" gorm:"size:255;not null;uniqueIndex:idx_rollup_count"`
+	Count       int64   `json:"count" gorm:"not null"`
+	Sum         float64 `json:"sum" gorm:"not null"`
+}
+
+// 去重计数预聚合，保存 HyperLogLog 寄存器，只有小时和天粒度
+type RollupSketch struct {
+	Model
+	ProjectID   uint   `json:"projectId" gorm:"not null;uniqueIndex:idx_rollup_sketch"`
+	Granularity string `json:"granularity" gorm:"size:10;not null;uniqueIndex:idx_rollup_sketch"`
+	BucketStart int64  `json:"bucketStart" gorm:"not null;uniqueIndex:idx_rollup_sketch"`
+	Metric      string `json:"metric" gorm:"size:50;not null;uniqueIndex:idx_rollup_sketch"`
+	Sketch      []byte `json:"-" gorm:"not null"`
+}
+
+// 指标直方图预聚合，Bucket 为对数桶序号，只有小时和天粒度
+type RollupHistogram struct {
+	Model
+	ProjectID   uint   `json:"projectId" gorm:"not null;uniqueIndex:idx_rollup_histogram"`
+	Granularity string `json:"granularity" gorm:"size:10;not null;uniqueIndex:idx_rollup_histogram"`
+	BucketStart int64  `json:"bucketStart" gorm:"not null;uniqueIndex:idx_rollup_histogram"`
+	Metric      string `json:"metric" gorm:"size:50;not null;uniqueIndex:idx_rollup_histogram"`
+	Bucket      int    `json:"bucket" gorm:"not null;uniqueIndex:idx_rollup_histogram"`
+	Count       int64  `json:"count" gorm:"not null"`
+}
+
+// 删除某一粒度中早于指定时间的预聚合数据
+func DeleteRollupsBefore(db *gorm.DB, granularity string, before int64) error {
+	for _, rollup := range []interface{}{&RollupCount{}, &RollupSketch{}, &RollupHistogram{}} {
+		if err := db.Where("granularity = ? AND bucket_start < ?", granularity, before).Delete(rollup).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 删除项目中指定指标在 [from, to) 内各粒度的预聚合数据
+func DeleteProjectRollups(tx *gorm.DB, projectID uint, metrics []string, from, to int64) error {
+	for _, rollup := range []interface{}{&RollupCount{}, &RollupSketch{}, &RollupHistogram{}} {
+		if err := tx.Where("project_id = ? AND metric IN ? AND bucket_start >= ? AND bucket_start < ?", projectID, metrics, from, to).
+			Delete(rollup).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 清空全部预聚合数据
+func DeleteAllRollups(tx *gorm.DB) error {
+	tx = tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+	for _, rollup := range []interface{}{&RollupCount{}, &RollupSketch{}, &RollupHistogram{}} {
+		if err := tx.Delete(rollup).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
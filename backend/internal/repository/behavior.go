@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// PageViewRow 页面访问记录
+type PageViewRow struct {
+	ID          uint
+	EventID     string
+	PageURL     string
+	Title       string
+	Referrer    string
+	TriggerTime int64
+	StayTime    int64
+	IsNewVisit  bool
+	Browser     string
+	OS          string
+	Device      string
+}
+
+// ClickRow 点击记录
+type ClickRow struct {
+	ID          uint
+	EventID     string
+	ElementPath string
+	ElementType string
+	InnerText   string
+	TriggerTime int64
+	PageURL     string
+}
+
+// PageCount 按原始页面URL汇总的数量
+type PageCount struct {
+	PageURL string
+	Count   int64
+}
+
+// HeatmapClick 热力图使用的点击位置
+type HeatmapClick struct {
+	PageURL       string
+	ElementPath   string
+	ElementType   string
+	InnerText     string
+	X             int
+	Y             int
+	ViewportWidth int
+}
+
+// SessionPageView 会话内的一次页面访问
+type SessionPageView struct {
+	SessionID string
+	UserUUID  string
+	PageURL   string
+}
+
+// RouteChange 路由切换记录
+type RouteChange struct {
+	EventID     string
+	SessionID   string
+	FromURL     string
+	ToURL       string
+	Mode        string
+	Action      string
+	Duration    int64
+	StayTime    int64
+	TriggerTime int64
+}
+
+// PendingClick 待检测挫败的点击
+type PendingClick struct {
+	ID          uint
+	EventMainID uint
+	ProjectID   uint
+	BaseInfoID  uint
+	TriggerTime int64
+	PageURL     string
+	ElementPath string
+	ElementType string
+	InnerText   string
+	SessionID   string
+	UserUUID    string
+}
+
+// FrustrationRow 挫败事件记录
+type FrustrationRow struct {
+	EventType   string
+	TriggerTime int64
+	SessionID   string
+	PageURL     string
+	ElementPath string
+	ElementType string
+	InnerText   string
+	ClickCount  int64
+}
+
+// BehaviorRepository 用户行为仓储，读取页面访问、点击、路由切换和挫败事件
+type BehaviorRepository interface {
+	// ListPageViews 按触发时间倒序分页获取页面访问，返回记录和总数
+	ListPageViews(projectID uint, timeRange TimeRange, limit, offset int) ([]PageViewRow, int64, error)
+	// ListClicks 按触发时间倒序分页获取点击，返回记录和总数
+	ListClicks(projectID uint, timeRange TimeRange, limit, offset int) ([]ClickRow, int64, error)
+	// CountClicksByPage 按原始页面URL统计点击数，按数量降序
+	CountClicksByPage(projectID uint, timeRange TimeRange, limit int) ([]PageCount, error)
+	// ListHeatmapClicks 按触发时间倒序获取页面URL匹配任一 LIKE 模式的点击
+	ListHeatmapClicks(projectID uint, timeRange TimeRange, pagePatterns []string, limit int) ([]HeatmapClick, error)
+	// ListSessionPageViews 获取最近活跃的 maxSessions 个会话的页面访问，按会话和触发时间排序。
+	// 会话ID为空的记录按用户标识分组，两者都为空的记录不返回
+	ListSessionPageViews(projectID uint, timeRange TimeRange, maxSessions, limit int) ([]SessionPageView, error)
+	// ListRouteChanges 按触发时间倒序获取路由切换
+	ListRouteChanges(projectID uint, timeRange TimeRange, limit int) ([]RouteChange, error)
+
+	// ListPendingClicks 按ID顺序获取 afterID 之后、在 settleBefore 之前入库的点击
+	ListPendingClicks(afterID uint, settleBefore time.Time, limit int) ([]PendingClick, error)
+	// ReactionTimes 获取会话内触发时间在 [from, to] 内指定类型事件的触发时间，会话ID为空时按用户标识匹配
+	ReactionTimes(projectID uint, sessionID, userUUID string, eventTypes []string, from, to int64) ([]int64, error)
+	// ListFrustrations 按触发时间倒序获取指定类型的挫败事件，页面URL匹配任一 LIKE 模式，模式为空时不限制页面
+	ListFrustrations(projectID uint, eventTypes []string, timeRange TimeRange, pagePatterns []string, limit int) ([]FrustrationRow, error)
+}
+
+type behaviorRepository struct {
+	db *gorm.DB
+}
+
+// NewBehaviorRepository 创建用户行为仓储
+func NewBehaviorRepository(db *gorm.DB) BehaviorRepository {
+	return &behaviorRepository{db: db}
+}
+
+func (r *behaviorRepository) ListPageViews(projectID uint, timeRange TimeRange, limit, offset int) ([]PageViewRow, int64, error) {
+	query := timeRange.apply(r.db.Model(&model.PVDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {pv_detail}.event_id")).
+		Where(model.SQL("{event_main}.project_id = ?"), projectID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []PageViewRow
+	if err := query.
+		Select(model.SQL("{pv_detail}.id, {event_main}.event_id, {pv_detail}.page_url, {pv_detail}.title, " +
+			"{pv_detail}.referrer, {event_main}.trigger_time, {pv_detail}.stay_time, {pv_detail}.is_new_visit, " +
+			"{base_info}.browser, {base_info}.os, {base_info}.device")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r *behaviorRepository) ListClicks(projectID uint, timeRange TimeRange, limit, offset int) ([]ClickRow, int64, error) {
+	query := timeRange.apply(r.clickQuery(projectID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []ClickRow
+	if err := query.
+		Select(model.SQL("{click_detail}.id, {event_main}.event_id, {click_detail}.element_path, " +
+			"{click_detail}.element_type, {click_detail}.inner_text, {event_main}.trigger_time, " +
+			"{event_main}.trigger_page_url as page_url")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// 项目的点击查询
+func (r *behaviorRepository) clickQuery(projectID uint) *gorm.DB {
+	return r.db.Model(&model.ClickDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {click_detail}.event_id")).
+		Where(model.SQL("{event_main}.project_id = ?"), projectID)
+}
+
+func (r *behaviorRepository) CountClicksByPage(projectID uint, timeRange TimeRange, limit int) ([]PageCount, error) {
+	var rows []PageCount
+	if err := timeRange.apply(r.clickQuery(projectID)).
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, COUNT(*) as count")).
+		Group(model.SQL("{event_main}.trigger_page_url")).
+		Order("count DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *behaviorRepository) ListHeatmapClicks(projectID uint, timeRange TimeRange, pagePatterns []string, limit int) ([]HeatmapClick, error) {
+	query := timeRange.apply(r.clickQuery(projectID))
+
+	var rows []HeatmapClick
+	if err := likeAny(query, model.SQL("{event_main}.trigger_page_url"), pagePatterns).
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, {click_detail}.element_path, {click_detail}.element_type, " +
+			"{click_detail}.inner_text, {click_detail}.x, {click_detail}.y, {click_detail}.viewport_width")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *behaviorRepository) ListSessionPageViews(projectID uint, timeRange TimeRange, maxSessions, limit int) ([]SessionPageView, error) {
+	pvQuery := func() *gorm.DB {
+		return timeRange.apply(r.db.Model(&model.PVDetail{}).
+			Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {pv_detail}.event_id")).
+			Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+			Where(model.SQL("{event_main}.project_id = ?"), projectID))
+	}
+
+	// 先选出最近活跃的会话，再读取这些会话的完整访问记录
+	recentSessions := pvQuery().
+		Where(model.SQL("({base_info}.session_id <> '' OR {base_info}.user_uuid <> '')")).
+		Select(model.SQL("{base_info}.session_id, {base_info}.user_uuid")).
+		Group(model.SQL("{base_info}.session_id, {base_info}.user_uuid")).
+		Order(model.SQL("MAX({event_main}.trigger_time) DESC")).
+		Limit(maxSessions)
+
+	var rows []SessionPageView
+	if err := pvQuery().
+		Joins(model.SQL("JOIN (?) recent_session ON recent_session.session_id = {base_info}.session_id "+
+			"AND recent_session.user_uuid = {base_info}.user_uuid"), recentSessions).
+		Select(model.SQL("{base_info}.session_id, {base_info}.user_uuid, {pv_detail}.page_url")).
+		Order(model.SQL("{base_info}.session_id, {base_info}.user_uuid, {event_main}.trigger_time, {event_main}.id")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *behaviorRepository) ListRouteChanges(projectID uint, timeRange TimeRange, limit int) ([]RouteChange, error) {
+	var rows []RouteChange
+	if err := timeRange.apply(r.db.Model(&model.RouteDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {route_detail}.event_id")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Where(model.SQL("{event_main}.project_id = ?"), projectID)).
+		Select(model.SQL("{event_main}.event_id, {base_info}.session_id, {route_detail}.from_url, {route_detail}.to_url, " +
+			"{route_detail}.mode, {route_detail}.action, {route_detail}.duration, {route_detail}.stay_time, {event_main}.trigger_time")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *behaviorRepository) ListPendingClicks(afterID uint, settleBefore time.Time, limit int) ([]PendingClick, error) {
+	var rows []PendingClick
+	if err := r.db.Model(&model.ClickDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {click_detail}.event_id")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Where(model.SQL("{click_detail}.id > ? AND {click_detail}.created_at <= ?"), afterID, settleBefore).
+		Select(model.SQL("{click_detail}.id, {event_main}.id as event_main_id, {event_main}.project_id, {event_main}.base_info_id, " +
+			"{event_main}.trigger_time, {event_main}.trigger_page_url as page_url, {click_detail}.element_path, " +
+			"{click_detail}.element_type, {click_detail}.inner_text, {base_info}.session_id, {base_info}.user_uuid")).
+		Order(model.SQL("{click_detail}.id")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *behaviorRepository) ReactionTimes(projectID uint, sessionID, userUUID string, eventTypes []string, from, to int64) ([]int64, error) {
+	query := r.db.Model(&model.EventMain{}).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Where(model.SQL("{event_main}.project_id = ? AND {event_main}.event_type IN ?"), projectID, eventTypes).
+		Where(model.SQL("{event_main}.trigger_time >= ? AND {event_main}.trigger_time <= ?"), from, to)
+	if sessionID != "" {
+		query = query.Where(model.SQL("{base_info}.session_id = ?"), sessionID)
+	} else {
+		query = query.Where(model.SQL("{base_info}.user_uuid = ?"), userUUID)
+	}
+
+	var times []int64
+	if err := query.Pluck(model.SQL("{event_main}.trigger_time"), &times).Error; err != nil {
+		return nil, err
+	}
+	return times, nil
+}
+
+func (r *behaviorRepository) ListFrustrations(projectID uint, eventTypes []string, timeRange TimeRange, pagePatterns []string, limit int) ([]FrustrationRow, error) {
+	query := timeRange.apply(r.db.Model(&model.FrustrationDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {frustration_detail}.event_id")).
+		Where(model.SQL("{event_main}.project_id = ? AND {event_main}.event_type IN ?"), projectID, eventTypes))
+
+	var rows []FrustrationRow
+	if err := likeAny(query, model.SQL("{frustration_detail}.page_url"), pagePatterns).
+		Select(model.SQL("{event_main}.event_type, {event_main}.trigger_time, {frustration_detail}.session_id, " +
+			"{frustration_detail}.page_url, {frustration_detail}.element_path, {frustration_detail}.element_type, " +
+			"{frustration_detail}.inner_text, {frustration_detail}.click_count")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
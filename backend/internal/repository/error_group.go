@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrorGroupFilter 错误分组筛选条件，时间为 0 或字符串为空时不筛选
+type ErrorGroupFilter struct {
+	StartTime int64 // 最后出现时间的下限
+	EndTime   int64 // 最后出现时间的上限
+	ErrorType string
+	Severity  string
+}
+
+// ErrorGroupRepository 错误分组仓储
+type ErrorGroupRepository interface {
+	// Record 按错误详情的指纹创建或更新错误分组
+	Record(projectID, eventID uint, detail *model.ErrorDetail) (*model.ErrorGroup, error)
+	// FindByID 通过 ID 获取错误分组
+	FindByID(id uint) (*model.ErrorGroup, error)
+	// List 按最后出现时间倒序获取项目的错误分组，返回分组和总数
+	List(projectID uint, filter ErrorGroupFilter, limit, offset int) ([]model.ErrorGroup, int64, error)
+	// RecentDetails 获取指纹最近的错误详情
+	RecentDetails(fingerprint string, limit int) ([]model.ErrorDetail, error)
+}
+
+type errorGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewErrorGroupRepository 创建错误分组仓储
+func NewErrorGroupRepository(db *gorm.DB) ErrorGroupRepository {
+	return &errorGroupRepository{db: db}
+}
+
+func (r *errorGroupRepository) Record(projectID, eventID uint, detail *model.ErrorDetail) (*model.ErrorGroup, error) {
+	var group model.ErrorGroup
+	now := time.Now().UnixMilli()
+
+	err := r.db.Where("fingerprint = ? AND project_id = ?", detail.Fingerprint, projectID).First(&group).Error
+	if err != nil {
+		group = model.ErrorGroup{
+			Fingerprint:   detail.Fingerprint,
+			ErrorType:     detail.ErrorType,
+			ErrorMessage:  detail.ErrorMessage,
+			Count:         1,
+			FirstSeen:     now,
+			LastSeen:      now,
+			ProjectID:     projectID,
+			SampleEventID: eventID,
+			Status:        "active",
+			Severity:      detail.Severity,
+			SubType:       detail.SubType,
+		}
+		return &group, r.db.Create(&group).Error
+	}
+
+	group.Count++
+	group.LastSeen = now
+	// 每隔一定次数更新示例事件，以获取最新的上下文
+	if group.Count%10 == 0 {
+		group.SampleEventID = eventID
+	}
+
+	return &group, r.db.Save(&group).Error
+}
+
+func (r *errorGroupRepository) FindByID(id uint) (*model.ErrorGroup, error) {
+	var group model.ErrorGroup
+	if err := r.db.First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *errorGroupRepository) List(projectID uint, filter ErrorGroupFilter, limit, offset int) ([]model.ErrorGroup, int64, error) {
+	query := r.db.Model(&model.ErrorGroup{}).Where("project_id = ?", projectID)
+	if filter.StartTime != 0 {
+		query = query.Where("last_seen >= ?", filter.StartTime)
+	}
+	if filter.EndTime != 0 {
+		query = query.Where("last_seen <= ?", filter.EndTime)
+	}
+	if filter.ErrorType != "" {
+		query = query.Where("error_type = ?", filter.ErrorType)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var groups []model.ErrorGroup
+	if err := query.Order("last_seen DESC").Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+func (r *errorGroupRepository) RecentDetails(fingerprint string, limit int) ([]model.ErrorDetail, error) {
+	var details []model.ErrorDetail
+	if err := r.db.Where("fingerprint = ?", fingerprint).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&details).Error; err != nil {
+		return nil, err
+	}
+	return details, nil
+}
@@ -0,0 +1,587 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// PerformancePageMatch 查找同一次页面加载的性能记录的条件
+type PerformancePageMatch struct {
+	ProjectID uint
+	From      int64 // 触发时间下限
+	To        int64 // 触发时间上限
+	PageID    string
+	// 没有页面加载ID时按会话和页面匹配，只匹配 MetricColumn 列尚未上报（为 NULL）的记录
+	SessionID    string
+	PageURL      string
+	MetricColumn string
+}
+
+// PageViewRef 页面访问记录的停留时间和触发时间
+type PageViewRef struct {
+	ID          uint
+	StayTime    int64
+	TriggerTime int64
+}
+
+// EventQuery 原始事件筛选条件，时间为 0 或字符串为空时不筛选
+type EventQuery struct {
+	TimeRange
+	ProjectID  uint
+	EventTypes []string
+	PageURL    string // 页面URL包含的关键词
+	UserID     string // 同时匹配业务用户ID和SDK生成的用户标识
+	SessionID  string
+	Browser    string
+	OS         string
+	DeviceType string
+	Release    string
+}
+
+// EventCursor 事件分页游标，从该位置之后继续按触发时间和ID倒序读取
+type EventCursor struct {
+	TriggerTime int64
+	ID          uint
+}
+
+// EventRow 事件主记录和基础信息
+type EventRow struct {
+	ID             uint
+	EventID        string
+	EventType      string
+	TriggerTime    int64
+	TriggerPageURL string
+	Title          string
+	TraceID        string
+	UserID         string
+	UserUUID       string
+	SessionID      string
+	Browser        string
+	OS             string
+	DeviceType     string
+	Release        string `gorm:"column:release_version"`
+	Environment    string
+	Region         string
+}
+
+// FieldCount 按字段分组的事件数量，Values 与查询的字段一一对应
+type FieldCount struct {
+	Values []string
+	Count  int64
+}
+
+// LogQuery 日志筛选条件，时间为 0 或字符串为空时不筛选
+type LogQuery struct {
+	TimeRange
+	ProjectID uint
+	Level     string
+	Keywords  []string // 每个词都需出现在消息或参数中，不区分大小写
+	PageURL   string   // 页面URL包含的关键词
+}
+
+// LogRow 日志记录
+type LogRow struct {
+	ID          uint
+	EventID     string
+	Level       string
+	Message     string
+	Arguments   string
+	Stack       string
+	PageURL     string
+	TriggerTime int64
+}
+
+// StoredEvent 宽表中的一条事件，合并事件主表、基础信息和统计用到的详情字段
+type StoredEvent struct {
+	ID             uint    `json:"id"`
+	EventID        string  `json:"event_id"`
+	EventType      string  `json:"event_type"`
+	ProjectID      uint    `json:"project_id"`
+	TriggerTime    int64   `json:"trigger_time"`
+	PageURL        string  `json:"page_url"`
+	Title          string  `json:"title"`
+	TraceID        string  `json:"trace_id"`
+	UserID         string  `json:"user_id"`
+	UserUUID       string  `json:"user_uuid"`
+	SessionID      string  `json:"session_id"`
+	Browser        string  `json:"browser"`
+	BrowserVersion string  `json:"browser_version"`
+	OS             string  `json:"os"`
+	OSVersion      string  `json:"os_version"`
+	DeviceType     string  `json:"device_type"`
+	ScreenWidth    int     `json:"screen_width"`
+	SDKVersion     string  `json:"sdk_version"`
+	Release        string  `json:"release" gorm:"column:release_version"`
+	Environment    string  `json:"environment"`
+	Region         string  `json:"region"`
+	ErrorType      string  `json:"error_type"`
+	Method         string  `json:"method"`
+	URLTemplate    string  `json:"url_template"`
+	Status         int     `json:"status"`
+	Duration       float64 `json:"duration"`
+	Success        bool    `json:"success"`
+	SampleRate     float64 `json:"sample_rate"`
+}
+
+// EventRef 待删除事件的主记录ID和基础信息ID
+type EventRef struct {
+	ID         uint
+	BaseInfoID uint
+}
+
+// 可分组统计的字段在关系库中对应的列
+var eventFieldColumns = map[string]string{
+	"browser":         "{base_info}.browser",
+	"browser_version": "{base_info}.browser_version",
+	"os":              "{base_info}.os",
+	"os_version":      "{base_info}.os_version",
+	"device_type":     "{base_info}.device_type",
+	"screen_width":    "{base_info}.screen_width",
+	"sdk_version":     "{base_info}.sdk_version",
+	"release":         "{base_info}.release_version",
+	"region":          "{base_info}.region",
+	"error_type":      "{error_detail}.error_type",
+}
+
+// EventRepository 事件仓储，负责事件入库、按ID读取、原始事件查询和清理
+type EventRepository interface {
+	// CreateBaseInfo 保存事件的基础信息
+	CreateBaseInfo(baseInfo *model.BaseInfo) error
+	// CreateEvent 保存事件主记录
+	CreateEvent(event *model.EventMain) error
+	// ExistsEventID 事件ID是否已存在
+	ExistsEventID(eventID string) (bool, error)
+	// CreateDetail 保存事件详情，detail 为详情模型的指针
+	CreateDetail(detail interface{}) error
+	// SaveDetail 更新事件详情
+	SaveDetail(detail interface{}) error
+
+	// FindEvent 通过 ID 获取事件，同时加载基础信息
+	FindEvent(id uint) (*model.EventMain, error)
+	// FindDetail 读取事件的一条详情到 detail，返回是否存在
+	FindDetail(eventID uint, detail interface{}) (bool, error)
+	// FindDetails 读取一批事件的详情到 details 切片
+	FindDetails(eventIDs []uint, details interface{}) error
+	// FindByTraceID 按触发时间顺序获取项目内同一链路的事件
+	FindByTraceID(projectID uint, traceID string, limit int) ([]model.EventMain, error)
+
+	// FindPerformancePage 获取最近一条满足条件的页面性能详情，不存在时返回 nil
+	FindPerformancePage(match PerformancePageMatch) (*model.PerformancePageDetail, error)
+	// FindPreviousPageView 获取会话在 before 之前最近的一次页面访问，不存在时返回 nil
+	FindPreviousPageView(projectID uint, sessionID string, before int64, excludeEventID uint) (*PageViewRef, error)
+	// UpdatePageViewStayTime 更新页面访问的停留时间
+	UpdatePageViewStayTime(id uint, stayTime int64) error
+
+	// SearchEvents 按触发时间和ID倒序获取事件，cursor 不为 nil 时从游标之后继续读取
+	SearchEvents(filter EventQuery, cursor *EventCursor, limit int) ([]EventRow, error)
+	// CountSlots 按固定长度的时间片统计事件数量，返回时间片序号（触发时间整除时间片长度）到数量
+	CountSlots(filter EventQuery, slotSize int64) (map[int64]int64, error)
+	// CountByFields 按字段分组统计事件数量，字段为 browser、os、error_type 等，包含 error_type 时只统计错误事件
+	CountByFields(filter EventQuery, fields []string) ([]FieldCount, error)
+	// ListLogs 按触发时间倒序分页获取日志，返回记录和总数
+	ListLogs(filter LogQuery, limit, offset int) ([]LogRow, int64, error)
+	// CountLogLevels 按级别统计日志数量，不受级别筛选影响
+	CountLogLevels(filter LogQuery) (map[string]int64, error)
+	// ListStoredEvents 按ID顺序获取 afterID 之后、在 settleBefore 之前入库的事件宽表记录
+	ListStoredEvents(afterID uint, settleBefore time.Time, limit int) ([]StoredEvent, error)
+
+	// FindEventRefs 按ID顺序获取项目触发时间在 [startTime, endTime) 内指定类型的事件，startTime 为 0 时不限制下限
+	FindEventRefs(projectID uint, eventTypes []string, startTime, endTime int64, limit int) ([]EventRef, error)
+	// DeleteEvents 在一个事务中删除事件及其详情，details 为详情模型的指针；只删除不再被引用的基础信息
+	DeleteEvents(refs []EventRef, details []interface{}) error
+}
+
+type eventRepository struct {
+	db *gorm.DB
+}
+
+// NewEventRepository 创建事件仓储
+func NewEventRepository(db *gorm.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+func (r *eventRepository) CreateBaseInfo(baseInfo *model.BaseInfo) error {
+	return r.db.Create(baseInfo).Error
+}
+
+func (r *eventRepository) CreateEvent(event *model.EventMain) error {
+	return r.db.Create(event).Error
+}
+
+func (r *eventRepository) ExistsEventID(eventID string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.EventMain{}).Where("event_id = ?", eventID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *eventRepository) CreateDetail(detail interface{}) error {
+	return r.db.Create(detail).Error
+}
+
+func (r *eventRepository) SaveDetail(detail interface{}) error {
+	return r.db.Save(detail).Error
+}
+
+func (r *eventRepository) FindEvent(id uint) (*model.EventMain, error) {
+	var event model.EventMain
+	if err := r.db.Preload("BaseInfo").First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *eventRepository) FindDetail(eventID uint, detail interface{}) (bool, error) {
+	result := r.db.Where("event_id = ?", eventID).Limit(1).Find(detail)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *eventRepository) FindDetails(eventIDs []uint, details interface{}) error {
+	return r.db.Where("event_id IN ?", eventIDs).Find(details).Error
+}
+
+func (r *eventRepository) FindByTraceID(projectID uint, traceID string, limit int) ([]model.EventMain, error) {
+	var events []model.EventMain
+	if err := r.db.Where("project_id = ? AND trace_id = ?", projectID, traceID).
+		Order("trigger_time ASC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *eventRepository) FindPerformancePage(match PerformancePageMatch) (*model.PerformancePageDetail, error) {
+	query := r.db.Model(&model.PerformancePageDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {performance_page_detail}.event_id")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Where(model.SQL("{event_main}.project_id = ? AND {event_main}.event_type = ?"), match.ProjectID, model.EventTypePerformancePage).
+		Where(model.SQL("{event_main}.trigger_time >= ? AND {event_main}.trigger_time <= ?"), match.From, match.To)
+
+	if match.PageID != "" {
+		query = query.Where(model.SQL("{base_info}.page_id = ?"), match.PageID)
+	} else {
+		query = query.Where(model.SQL("{base_info}.session_id = ? AND {event_main}.trigger_page_url = ?"), match.SessionID, match.PageURL).
+			Where(fmt.Sprintf(model.SQL("{performance_page_detail}.%s IS NULL"), match.MetricColumn))
+	}
+
+	var perfDetail model.PerformancePageDetail
+	result := query.Select(model.SQL("{performance_page_detail}.*")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(1).
+		Find(&perfDetail)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &perfDetail, nil
+}
+
+func (r *eventRepository) FindPreviousPageView(projectID uint, sessionID string, before int64, excludeEventID uint) (*PageViewRef, error) {
+	var previous PageViewRef
+	if err := r.db.Model(&model.PVDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {pv_detail}.event_id")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Where(model.SQL("{event_main}.project_id = ? AND {base_info}.session_id = ?"), projectID, sessionID).
+		Where(model.SQL("{event_main}.trigger_time <= ? AND {event_main}.id <> ?"), before, excludeEventID).
+		Select(model.SQL("{pv_detail}.id, {pv_detail}.stay_time, {event_main}.trigger_time")).
+		Order(model.SQL("{event_main}.trigger_time DESC, {event_main}.id DESC")).
+		Limit(1).
+		Scan(&previous).Error; err != nil {
+		return nil, err
+	}
+	if previous.ID == 0 {
+		return nil, nil
+	}
+	return &previous, nil
+}
+
+func (r *eventRepository) UpdatePageViewStayTime(id uint, stayTime int64) error {
+	return r.db.Model(&model.PVDetail{}).
+		Where("id = ?", id).
+		Update("stay_time", stayTime).Error
+}
+
+// 为关联了基础信息的事件查询添加筛选条件
+func eventQuery(query *gorm.DB, filter EventQuery) *gorm.DB {
+	query = filter.TimeRange.apply(query.Where(model.SQL("{event_main}.project_id = ?"), filter.ProjectID))
+
+	// 添加事件类型过滤
+	if len(filter.EventTypes) > 0 {
+		query = query.Where(model.SQL("{event_main}.event_type IN ?"), filter.EventTypes)
+	}
+
+	// 添加页面过滤
+	if filter.PageURL != "" {
+		query = query.Where(model.GetDialect().Like(model.SQL("{event_main}.trigger_page_url")), containsPattern(filter.PageURL))
+	}
+
+	// 添加用户和会话过滤
+	if filter.UserID != "" {
+		query = query.Where(model.SQL("({base_info}.user_id = ? OR {base_info}.user_uuid = ?)"), filter.UserID, filter.UserID)
+	}
+	if filter.SessionID != "" {
+		query = query.Where(model.SQL("{base_info}.session_id = ?"), filter.SessionID)
+	}
+
+	// 添加环境过滤
+	if filter.Browser != "" {
+		query = query.Where(model.SQL("{base_info}.browser = ?"), filter.Browser)
+	}
+	if filter.OS != "" {
+		query = query.Where(model.SQL("{base_info}.os = ?"), filter.OS)
+	}
+	if filter.DeviceType != "" {
+		query = query.Where(model.SQL("{base_info}.device_type = ?"), filter.DeviceType)
+	}
+	if filter.Release != "" {
+		query = query.Where(model.SQL("{base_info}.release_version = ?"), filter.Release)
+	}
+
+	return query
+}
+
+// 关联基础信息的事件主表查询
+func (r *eventRepository) eventMainQuery() *gorm.DB {
+	return r.db.Model(&model.EventMain{}).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id"))
+}
+
+func (r *eventRepository) SearchEvents(filter EventQuery, cursor *EventCursor, limit int) ([]EventRow, error) {
+	query := eventQuery(r.eventMainQuery(), filter)
+	if cursor != nil {
+		query = query.Where(model.SQL("({event_main}.trigger_time < ? OR ({event_main}.trigger_time = ? AND {event_main}.id < ?))"),
+			cursor.TriggerTime, cursor.TriggerTime, cursor.ID)
+	}
+
+	var rows []EventRow
+	if err := query.
+		Select(model.SQL("{event_main}.id, {event_main}.event_id, {event_main}.event_type, {event_main}.trigger_time, " +
+			"{event_main}.trigger_page_url, {event_main}.title, {event_main}.trace_id, {base_info}.user_id, " +
+			"{base_info}.user_uuid, {base_info}.session_id, {base_info}.browser, {base_info}.os, " +
+			"{base_info}.device_type, {base_info}.release_version, {base_info}.environment, {base_info}.region")).
+		Order(model.SQL("{event_main}.trigger_time DESC, {event_main}.id DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *eventRepository) CountSlots(filter EventQuery, slotSize int64) (map[int64]int64, error) {
+	var rows []struct {
+		Slot  int64
+		Count int64
+	}
+	if err := eventQuery(r.eventMainQuery(), filter).
+		Select(model.GetDialect().TimeSlot(model.SQL("{event_main}.trigger_time"), slotSize) + " as slot, COUNT(*) as count").
+		Group("slot").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	slots := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		slots[row.Slot] += row.Count
+	}
+	return slots, nil
+}
+
+func (r *eventRepository) CountByFields(filter EventQuery, fields []string) ([]FieldCount, error) {
+	query := r.eventMainQuery()
+
+	// 按分组列统计数量，列值统一转为字符串
+	columns := make([]string, 0, len(fields))
+	selects := make([]string, 0, len(fields)+1)
+	for i, field := range fields {
+		column, ok := eventFieldColumns[field]
+		if !ok {
+			return nil, fmt.Errorf("不支持的统计字段: %s", field)
+		}
+		if field == "error_type" {
+			query = query.Joins(model.SQL("JOIN {error_detail} ON {error_detail}.event_id = {event_main}.id"))
+		}
+		column = model.SQL(column)
+		columns = append(columns, column)
+		selects = append(selects, column+" as v"+strconv.Itoa(i))
+	}
+	selects = append(selects, "COUNT(*) as count")
+
+	var rows []map[string]interface{}
+	if err := eventQuery(query, filter).
+		Select(strings.Join(selects, ", ")).
+		Group(strings.Join(columns, ", ")).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]FieldCount, 0, len(rows))
+	for _, row := range rows {
+		values := make([]string, len(fields))
+		for i := range fields {
+			values[i] = columnString(row["v"+strconv.Itoa(i)])
+		}
+		count, _ := strconv.ParseInt(columnString(row["count"]), 10, 64)
+		result = append(result, FieldCount{Values: values, Count: count})
+	}
+	return result, nil
+}
+
+// 将数据库返回的值转为字符串，不同驱动返回的类型不同
+func columnString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int:
+		return strconv.Itoa(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// 日志查询，不包含级别条件
+func (r *eventRepository) logQuery(filter LogQuery) *gorm.DB {
+	query := filter.TimeRange.apply(r.db.Model(&model.LogDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {log_detail}.event_id")).
+		Where(model.SQL("{event_main}.project_id = ?"), filter.ProjectID))
+
+	// 添加页面过滤
+	if filter.PageURL != "" {
+		query = query.Where(model.GetDialect().Like(model.SQL("{event_main}.trigger_page_url")), containsPattern(filter.PageURL))
+	}
+
+	// 添加关键词过滤，统一转小写以兼容区分大小写的数据库
+	dialect := model.GetDialect()
+	condition := "(" + dialect.Like(model.SQL("LOWER({log_detail}.message)")) + " OR " +
+		dialect.Like(model.SQL("LOWER({log_detail}.arguments)")) + ")"
+	for _, keyword := range filter.Keywords {
+		pattern := containsPattern(strings.ToLower(keyword))
+		query = query.Where(condition, pattern, pattern)
+	}
+	return query
+}
+
+func (r *eventRepository) ListLogs(filter LogQuery, limit, offset int) ([]LogRow, int64, error) {
+	query := r.logQuery(filter)
+	if filter.Level != "" {
+		query = query.Where(model.SQL("{log_detail}.level = ?"), filter.Level)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []LogRow
+	if err := query.
+		Select(model.SQL("{log_detail}.id, {event_main}.event_id, {log_detail}.level, {log_detail}.message, " +
+			"{log_detail}.arguments, {log_detail}.stack, {event_main}.trigger_page_url as page_url, {event_main}.trigger_time")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r *eventRepository) CountLogLevels(filter LogQuery) (map[string]int64, error) {
+	var rows []struct {
+		Level string
+		Count int64
+	}
+	if err := r.logQuery(filter).
+		Select(model.SQL("{log_detail}.level, COUNT(*) as count")).
+		Group(model.SQL("{log_detail}.level")).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Level] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *eventRepository) ListStoredEvents(afterID uint, settleBefore time.Time, limit int) ([]StoredEvent, error) {
+	var events []StoredEvent
+	if err := r.eventMainQuery().
+		Joins(model.SQL("LEFT JOIN {error_detail} ON {error_detail}.event_id = {event_main}.id")).
+		Joins(model.SQL("LEFT JOIN {request_detail} ON {request_detail}.event_id = {event_main}.id")).
+		Where(model.SQL("{event_main}.id > ? AND {event_main}.created_at <= ?"), afterID, settleBefore).
+		Select(model.SQL("{event_main}.id, {event_main}.event_id, {event_main}.event_type, {event_main}.project_id, " +
+			"{event_main}.trigger_time, {event_main}.trigger_page_url as page_url, {event_main}.title, {event_main}.trace_id, " +
+			"{base_info}.user_id, {base_info}.user_uuid, {base_info}.session_id, {base_info}.browser, " +
+			"{base_info}.browser_version, {base_info}.os, {base_info}.os_version, {base_info}.device_type, " +
+			"{base_info}.screen_width, {base_info}.sdk_version, {base_info}.release_version, {base_info}.environment, " +
+			"{base_info}.region, COALESCE({error_detail}.error_type, '') as error_type, " +
+			"COALESCE({request_detail}.method, '') as method, COALESCE({request_detail}.url_template, '') as url_template, " +
+			"COALESCE({request_detail}.status, 0) as status, COALESCE({request_detail}.duration, 0) as duration, " +
+			"COALESCE({request_detail}.success, false) as success, COALESCE({request_detail}.sample_rate, 0) as sample_rate")).
+		Order(model.SQL("{event_main}.id")).
+		Limit(limit).
+		Scan(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *eventRepository) FindEventRefs(projectID uint, eventTypes []string, startTime, endTime int64, limit int) ([]EventRef, error) {
+	query := r.db.Model(&model.EventMain{}).
+		Where("project_id = ? AND event_type IN ? AND trigger_time < ?", projectID, eventTypes, endTime)
+	if startTime > 0 {
+		query = query.Where("trigger_time >= ?", startTime)
+	}
+
+	var refs []EventRef
+	if err := query.Select("id, base_info_id").Order("id").Limit(limit).Scan(&refs).Error; err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func (r *eventRepository) DeleteEvents(refs []EventRef, details []interface{}) error {
+	eventIDs := make([]uint, 0, len(refs))
+	baseInfoIDs := make([]uint, 0, len(refs))
+	for _, ref := range refs {
+		eventIDs = append(eventIDs, ref.ID)
+		baseInfoIDs = append(baseInfoIDs, ref.BaseInfoID)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, detail := range details {
+			if err := tx.Where("event_id IN ?", eventIDs).Delete(detail).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id IN ?", eventIDs).Delete(&model.EventMain{}).Error; err != nil {
+			return err
+		}
+		// 基础信息可能被合并的性能事件共用，只删除不再被引用的
+		return tx.Where(model.SQL("id IN ? AND NOT EXISTS (SELECT 1 FROM {event_main} WHERE {event_main}.base_info_id = {base_info}.id)"), baseInfoIDs).
+			Delete(&model.BaseInfo{}).Error
+	})
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// JobCursorRepository 后台任务游标仓储，记录任务已处理到的位置
+type JobCursorRepository interface {
+	// Get 获取任务游标，不存在时返回 0
+	Get(name string) (uint, error)
+	// Save 保存任务游标
+	Save(name string, lastID uint) error
+	// Advance 将游标从 from 推进到 to，游标已被其他任务更新时返回 model.ErrJobCursorMoved
+	Advance(name string, from, to uint) error
+	// Delete 删除任务游标，任务将从头开始处理
+	Delete(names []string) error
+}
+
+type jobCursorRepository struct {
+	db *gorm.DB
+}
+
+// NewJobCursorRepository 创建任务游标仓储
+func NewJobCursorRepository(db *gorm.DB) JobCursorRepository {
+	return &jobCursorRepository{db: db}
+}
+
+func (r *jobCursorRepository) Get(name string) (uint, error) {
+	return model.GetJobCursor(r.db, name)
+}
+
+func (r *jobCursorRepository) Save(name string, lastID uint) error {
+	return model.SaveJobCursor(r.db, name, lastID)
+}
+
+func (r *jobCursorRepository) Advance(name string, from, to uint) error {
+	return model.AdvanceJobCursor(r.db, name, from, to)
+}
+
+func (r *jobCursorRepository) Delete(names []string) error {
+	return model.DeleteJobCursors(r.db, names)
+}
@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// PerformancePageRow 页面性能记录，使用可空类型兼容 MySQL 返回的浮点数字符串和未上报的指标
+type PerformancePageRow struct {
+	ID          uint
+	EventID     string
+	PageURL     string
+	TriggerTime int64
+	FP          sql.NullFloat64 `gorm:"column:fp"`
+	FCP         sql.NullFloat64 `gorm:"column:fcp"`
+	LCP         sql.NullFloat64 `gorm:"column:lcp"`
+	FID         sql.NullFloat64 `gorm:"column:fid"`
+	INP         sql.NullFloat64 `gorm:"column:inp"`
+	CLS         sql.NullFloat64 `gorm:"column:cls"`
+	TTFB        sql.NullFloat64 `gorm:"column:ttfb"`
+	DomReady    sql.NullFloat64 `gorm:"column:dom_ready"`
+	Load        sql.NullFloat64 `gorm:"column:load"`
+	Browser     string
+	OS          string
+	Device      string
+}
+
+// ResourceRow 资源性能记录
+type ResourceRow struct {
+	ID            uint
+	EventID       string
+	ResourceURL   string
+	ResourceType  string
+	InitiatorType string
+	StartTime     int64
+	Duration      int64
+	TransferSize  int64
+	PageURL       string
+}
+
+// PageSampleFilter 页面性能样本筛选条件，字符串为空时不筛选
+type PageSampleFilter struct {
+	TimeRange
+	Browser    string
+	OS         string
+	DeviceType string
+	Region     string
+}
+
+// PagePerformanceSample 页面性能样本
+type PagePerformanceSample struct {
+	PageURL  string
+	LCP      *float64 // 性能指标未上报时为 NULL
+	FCP      *float64
+	TTFB     *float64
+	CLS      *float64
+	INP      *float64
+	DNS      float64
+	TCP      float64
+	SSL      float64
+	Trans    float64
+	DomParse float64
+}
+
+// ResourceSample 资源性能样本
+type ResourceSample struct {
+	PageURL         string
+	ResourceURL     string
+	ResourceType    string
+	Duration        float64
+	TransferSize    float64
+	DecodedBodySize float64
+	FromCache       bool
+}
+
+// BudgetSampleFilter 性能预算样本筛选条件，字符串为空时不筛选
+type BudgetSampleFilter struct {
+	TimeRange
+	ProjectID    uint
+	Metric       string // 预算指标，model.BudgetMetric*
+	Release      string
+	PagePatterns []string // 页面URL匹配任一 LIKE 模式
+	ResourceType string   // 只用于资源指标
+}
+
+// BudgetSample 性能预算样本，同一页面加载的资源按 PageID 或会话和页面汇总
+type BudgetSample struct {
+	PageURL   string
+	PageID    string
+	SessionID string
+	Value     float64
+}
+
+// LongTaskSample 长任务样本
+type LongTaskSample struct {
+	PageURL          string
+	ScriptURL        string
+	ScriptFunction   string
+	Duration         int64
+	BlockingDuration int64
+}
+
+// InteractionSample 交互样本
+type InteractionSample struct {
+	PageURL           string
+	ElementPath       string
+	ElementType       string
+	InteractionType   string
+	Duration          int64
+	InputDelay        int64
+	ProcessingTime    int64
+	PresentationDelay int64
+}
+
+// APIRequestFilter 接口请求筛选条件，字符串为空时不筛选
+type APIRequestFilter struct {
+	TimeRange
+	Method  string
+	Keyword string // 接口模板包含的关键词
+}
+
+// APIRequestSample 接口请求样本
+type APIRequestSample struct {
+	Method      string
+	URLTemplate string
+	Status      int
+	Duration    float64
+	Success     bool
+	SampleRate  float64
+}
+
+// 页面性能预算指标对应的列
+var budgetPageColumns = map[string]string{
+	model.BudgetMetricLCP:  "lcp",
+	model.BudgetMetricFCP:  "fcp",
+	model.BudgetMetricFID:  "f_id",
+	model.BudgetMetricINP:  "inp",
+	model.BudgetMetricCLS:  "cls",
+	model.BudgetMetricTTFB: "ttfb",
+}
+
+// PerformanceRepository 性能仓储，读取页面性能、资源、卡顿和接口请求的原始记录
+type PerformanceRepository interface {
+	// ListPages 按触发时间倒序分页获取页面性能，返回记录和总数
+	ListPages(projectID uint, timeRange TimeRange, limit, offset int) ([]PerformancePageRow, int64, error)
+	// ListResources 按触发时间倒序分页获取资源性能，资源类型为空时不筛选，返回记录和总数
+	ListResources(projectID uint, timeRange TimeRange, resourceType string, limit, offset int) ([]ResourceRow, int64, error)
+	// PageSamples 按触发时间倒序获取页面性能样本
+	PageSamples(projectID uint, filter PageSampleFilter, limit int) ([]PagePerformanceSample, error)
+	// ResourceSamples 按触发时间倒序获取资源性能样本，资源类型为空时不筛选
+	ResourceSamples(projectID uint, timeRange TimeRange, resourceType string, limit int) ([]ResourceSample, error)
+	// BudgetSamples 按触发时间倒序获取预算指标的样本值，页面指标只返回已上报的记录
+	BudgetSamples(filter BudgetSampleFilter, limit int) ([]BudgetSample, error)
+	// LatestRelease 项目最近上报的版本，没有时返回空字符串
+	LatestRelease(projectID uint) (string, error)
+	// LongTasks 按触发时间倒序获取长任务样本
+	LongTasks(projectID uint, timeRange TimeRange, limit int) ([]LongTaskSample, error)
+	// Interactions 按触发时间倒序获取交互样本
+	Interactions(projectID uint, timeRange TimeRange, limit int) ([]InteractionSample, error)
+	// APIRequests 按触发时间倒序获取接口请求样本
+	APIRequests(projectID uint, filter APIRequestFilter, limit int) ([]APIRequestSample, error)
+}
+
+type performanceRepository struct {
+	db *gorm.DB
+}
+
+// NewPerformanceRepository 创建性能仓储
+func NewPerformanceRepository(db *gorm.DB) PerformanceRepository {
+	return &performanceRepository{db: db}
+}
+
+// 关联事件主表的项目详情查询，table 为详情表
+func (r *performanceRepository) detailQuery(detail interface{}, table string, projectID uint, timeRange TimeRange) *gorm.DB {
+	return timeRange.apply(r.db.Model(detail).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {"+table+"}.event_id")).
+		Where(model.SQL("{event_main}.project_id = ?"), projectID))
+}
+
+func (r *performanceRepository) ListPages(projectID uint, timeRange TimeRange, limit, offset int) ([]PerformancePageRow, int64, error) {
+	query := r.detailQuery(&model.PerformancePageDetail{}, "performance_page_detail", projectID, timeRange)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []PerformancePageRow
+	if err := query.
+		Select(model.SQL("{performance_page_detail}.id, {event_main}.event_id, {event_main}.trigger_page_url as page_url, {event_main}.trigger_time, " +
+			"{performance_page_detail}.fp, {performance_page_detail}.fcp, {performance_page_detail}.lcp, " +
+			"{performance_page_detail}.f_id as fid, " +
+			"{performance_page_detail}.inp, " +
+			"{performance_page_detail}.cls, {performance_page_detail}.ttfb, {performance_page_detail}.dom_ready, {performance_page_detail}.load, " +
+			"{base_info}.browser, {base_info}.os, {base_info}.device")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r *performanceRepository) ListResources(projectID uint, timeRange TimeRange, resourceType string, limit, offset int) ([]ResourceRow, int64, error) {
+	query := r.detailQuery(&model.PerformanceResourceDetail{}, "performance_resource_detail", projectID, timeRange)
+	if resourceType != "" {
+		query = query.Where(model.SQL("{performance_resource_detail}.resource_type = ?"), resourceType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []ResourceRow
+	if err := query.
+		Select(model.SQL("{performance_resource_detail}.id, {event_main}.event_id, {performance_resource_detail}.resource_url, " +
+			"{performance_resource_detail}.resource_type, {performance_resource_detail}.initiator_type, " +
+			"{performance_resource_detail}.start_time, {performance_resource_detail}.duration, " +
+			"{performance_resource_detail}.transfer_size, {event_main}.trigger_page_url as page_url")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+func (r *performanceRepository) PageSamples(projectID uint, filter PageSampleFilter, limit int) ([]PagePerformanceSample, error) {
+	query := r.detailQuery(&model.PerformancePageDetail{}, "performance_page_detail", projectID, filter.TimeRange).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id"))
+
+	// 添加环境过滤
+	if filter.Browser != "" {
+		query = query.Where(model.SQL("{base_info}.browser = ?"), filter.Browser)
+	}
+	if filter.OS != "" {
+		query = query.Where(model.SQL("{base_info}.os = ?"), filter.OS)
+	}
+	if filter.DeviceType != "" {
+		query = query.Where(model.SQL("{base_info}.device_type = ?"), filter.DeviceType)
+	}
+	if filter.Region != "" {
+		query = query.Where(model.SQL("{base_info}.region = ?"), filter.Region)
+	}
+
+	var rows []PagePerformanceSample
+	if err := query.
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, {performance_page_detail}.lcp, {performance_page_detail}.fcp, " +
+			"{performance_page_detail}.ttfb, {performance_page_detail}.cls, {performance_page_detail}.inp, " +
+			"{performance_page_detail}.dns, {performance_page_detail}.tcp, {performance_page_detail}.ssl, " +
+			"{performance_page_detail}.trans, {performance_page_detail}.dom_parse")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *performanceRepository) ResourceSamples(projectID uint, timeRange TimeRange, resourceType string, limit int) ([]ResourceSample, error) {
+	query := r.detailQuery(&model.PerformanceResourceDetail{}, "performance_resource_detail", projectID, timeRange)
+	if resourceType != "" {
+		query = query.Where(model.SQL("{performance_resource_detail}.resource_type = ?"), resourceType)
+	}
+
+	var rows []ResourceSample
+	if err := query.
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, {performance_resource_detail}.resource_url, " +
+			"{performance_resource_detail}.resource_type, {performance_resource_detail}.duration, " +
+			"{performance_resource_detail}.transfer_size, {performance_resource_detail}.decoded_body_size, " +
+			"{performance_resource_detail}.from_cache")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *performanceRepository) BudgetSamples(filter BudgetSampleFilter, limit int) ([]BudgetSample, error) {
+	var query *gorm.DB
+	var selectExpr string
+	if column, ok := budgetPageColumns[filter.Metric]; ok {
+		query = r.detailQuery(&model.PerformancePageDetail{}, "performance_page_detail", filter.ProjectID, filter.TimeRange).
+			Where(model.SQL("{performance_page_detail}." + column + " IS NOT NULL"))
+		selectExpr = model.SQL("{performance_page_detail}." + column + " as value")
+	} else {
+		query = r.detailQuery(&model.PerformanceResourceDetail{}, "performance_resource_detail", filter.ProjectID, filter.TimeRange)
+		if filter.ResourceType != "" {
+			query = query.Where(model.SQL("{performance_resource_detail}.resource_type = ?"), filter.ResourceType)
+		}
+		if filter.Metric == model.BudgetMetricResourceSize {
+			selectExpr = model.SQL("{performance_resource_detail}.transfer_size as value")
+		} else {
+			selectExpr = model.SQL("{performance_resource_detail}.duration as value")
+		}
+	}
+
+	query = query.Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id"))
+	if filter.Release != "" {
+		query = query.Where(model.SQL("{base_info}.release_version = ?"), filter.Release)
+	}
+
+	var rows []BudgetSample
+	if err := likeAny(query, model.SQL("{event_main}.trigger_page_url"), filter.PagePatterns).
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, {base_info}.page_id, {base_info}.session_id, " + selectExpr)).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *performanceRepository) LatestRelease(projectID uint) (string, error) {
+	var latest model.BaseInfo
+	err := r.db.Where("project_id = ? AND release_version <> ''", projectID).Order("id DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	return latest.Release, nil
+}
+
+func (r *performanceRepository) LongTasks(projectID uint, timeRange TimeRange, limit int) ([]LongTaskSample, error) {
+	var rows []LongTaskSample
+	if err := r.detailQuery(&model.LongTaskDetail{}, "long_task_detail", projectID, timeRange).
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, {long_task_detail}.script_url, " +
+			"{long_task_detail}.script_function, {long_task_detail}.duration, {long_task_detail}.blocking_duration")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *performanceRepository) Interactions(projectID uint, timeRange TimeRange, limit int) ([]InteractionSample, error) {
+	var rows []InteractionSample
+	if err := r.detailQuery(&model.InteractionDetail{}, "interaction_detail", projectID, timeRange).
+		Select(model.SQL("{event_main}.trigger_page_url as page_url, {interaction_detail}.element_path, " +
+			"{interaction_detail}.element_type, {interaction_detail}.interaction_type, {interaction_detail}.duration, " +
+			"{interaction_detail}.input_delay, {interaction_detail}.processing_time, {interaction_detail}.presentation_delay")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *performanceRepository) APIRequests(projectID uint, filter APIRequestFilter, limit int) ([]APIRequestSample, error) {
+	query := r.detailQuery(&model.RequestDetail{}, "request_detail", projectID, filter.TimeRange)
+	if filter.Method != "" {
+		query = query.Where(model.SQL("{request_detail}.method = ?"), strings.ToUpper(filter.Method))
+	}
+	if filter.Keyword != "" {
+		query = query.Where(model.GetDialect().Like(model.SQL("{request_detail}.url_template")), containsPattern(filter.Keyword))
+	}
+
+	var rows []APIRequestSample
+	if err := query.
+		Select(model.SQL("{request_detail}.method, {request_detail}.url_template, {request_detail}.status, " +
+			"{request_detail}.duration, {request_detail}.success, {request_detail}.sample_rate")).
+		Order(model.SQL("{event_main}.trigger_time DESC")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// ProjectRepository 项目仓储，包括项目的性能预算和数据保留策略
+type ProjectRepository interface {
+	// Create 创建项目并生成 AppKey
+	Create(name, description string, userID uint) (*model.Project, error)
+	// FindByID 通过 ID 获取项目
+	FindByID(id uint) (*model.Project, error)
+	// FindByAppKey 通过 AppKey 获取项目
+	FindByAppKey(appKey string) (*model.Project, error)
+	// ListByUser 获取用户的所有项目
+	ListByUser(userID uint) ([]model.Project, error)
+	// ListIDs 获取全部项目ID
+	ListIDs() ([]uint, error)
+	// Save 保存项目
+	Save(project *model.Project) error
+	// Delete 删除项目
+	Delete(id uint) error
+
+	// ListBudgets 获取项目的所有性能预算
+	ListBudgets(projectID uint) ([]model.PerformanceBudget, error)
+	// FindBudget 通过 ID 获取项目下的性能预算
+	FindBudget(projectID, id uint) (*model.PerformanceBudget, error)
+	// SaveBudget 创建或更新性能预算
+	SaveBudget(budget *model.PerformanceBudget) error
+	// DeleteBudget 删除性能预算
+	DeleteBudget(projectID, id uint) error
+
+	// ListRetentionPolicies 获取项目的数据保留策略
+	ListRetentionPolicies(projectID uint) ([]model.RetentionPolicy, error)
+	// SaveRetentionPolicy 保存项目某一类别的数据保留策略，已存在时更新天数
+	SaveRetentionPolicy(projectID uint, category string, days int) error
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository 创建项目仓储
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+func (r *projectRepository) Create(name, description string, userID uint) (*model.Project, error) {
+	// 生成 AppKey
+	h := md5.New()
+	h.Write([]byte(name + time.Now().String()))
+	appKey := hex.EncodeToString(h.Sum(nil))
+
+	project := model.Project{
+		Name:        name,
+		AppKey:      appKey,
+		Description: description,
+		UserID:      userID,
+	}
+
+	if err := r.db.Create(&project).Error; err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (r *projectRepository) FindByID(id uint) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) FindByAppKey(appKey string) (*model.Project, error) {
+	var project model.Project
+	if err := r.db.Where("app_key = ?", appKey).First(&project).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) ListByUser(userID uint) ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.db.Where("user_id = ?", userID).Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) ListIDs() ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Project{}).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *projectRepository) Save(project *model.Project) error {
+	return r.db.Save(project).Error
+}
+
+func (r *projectRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Project{}, id).Error
+}
+
+func (r *projectRepository) ListBudgets(projectID uint) ([]model.PerformanceBudget, error) {
+	var budgets []model.PerformanceBudget
+	if err := r.db.Where("project_id = ?", projectID).Order("id").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+func (r *projectRepository) FindBudget(projectID, id uint) (*model.PerformanceBudget, error) {
+	var budget model.PerformanceBudget
+	if err := r.db.Where("project_id = ?", projectID).First(&budget, id).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *projectRepository) SaveBudget(budget *model.PerformanceBudget) error {
+	return r.db.Save(budget).Error
+}
+
+func (r *projectRepository) DeleteBudget(projectID, id uint) error {
+	return r.db.Where("project_id = ?", projectID).Delete(&model.PerformanceBudget{}, id).Error
+}
+
+func (r *projectRepository) ListRetentionPolicies(projectID uint) ([]model.RetentionPolicy, error) {
+	var policies []model.RetentionPolicy
+	if err := r.db.Where("project_id = ?", projectID).Order("category").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *projectRepository) SaveRetentionPolicy(projectID uint, category string, days int) error {
+	var policy model.RetentionPolicy
+	result := r.db.Where("project_id = ? AND category = ?", projectID, category).Limit(1).Find(&policy)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		policy = model.RetentionPolicy{ProjectID: projectID, Category: category}
+	}
+	policy.Days = days
+	return r.db.Save(&policy).Error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// TimeRange 事件触发时间范围（毫秒），为 0 的一端不限制
+type TimeRange struct {
+	StartTime int64
+	EndTime   int64
+}
+
+// 为关联了事件主表的查询添加触发时间条件
+func (r TimeRange) apply(query *gorm.DB) *gorm.DB {
+	if r.StartTime != 0 {
+		query = query.Where(model.SQL("{event_main}.trigger_time >= ?"), r.StartTime)
+	}
+	if r.EndTime != 0 {
+		query = query.Where(model.SQL("{event_main}.trigger_time <= ?"), r.EndTime)
+	}
+	return query
+}
+
+// 列值包含关键词的 LIKE 条件参数
+func containsPattern(keyword string) string {
+	return "%" + model.EscapeLike(keyword) + "%"
+}
+
+// 列值匹配任一 LIKE 模式，模式为空时不添加条件
+func likeAny(query *gorm.DB, column string, patterns []string) *gorm.DB {
+	if len(patterns) == 0 {
+		return query
+	}
+	like := model.GetDialect().Like(column)
+	conditions := make([]string, 0, len(patterns))
+	args := make([]interface{}, 0, len(patterns))
+	for _, pattern := range patterns {
+		conditions = append(conditions, like)
+		args = append(args, pattern)
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
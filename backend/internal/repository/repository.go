@@ -0,0 +1,47 @@
+// Package repository 数据访问层，每个聚合定义一个仓储接口，默认实现基于 GORM。
+// 服务通过构造函数注入仓储，测试时可以替换为其他实现
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories 全部仓储
+type Repositories struct {
+	Users       UserRepository
+	Projects    ProjectRepository
+	Events      EventRepository
+	ErrorGroups ErrorGroupRepository
+	Stats       StatsRepository
+	Behaviors   BehaviorRepository
+	Performance PerformanceRepository
+	Rollups     RollupRepository
+	JobCursors  JobCursorRepository
+
+	db *gorm.DB
+}
+
+// New 创建基于数据库连接的全部仓储
+func New(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:       NewUserRepository(db),
+		Projects:    NewProjectRepository(db),
+		Events:      NewEventRepository(db),
+		ErrorGroups: NewErrorGroupRepository(db),
+		Stats:       NewStatsRepository(db),
+		Behaviors:   NewBehaviorRepository(db),
+		Performance: NewPerformanceRepository(db),
+		Rollups:     NewRollupRepository(db),
+		JobCursors:  NewJobCursorRepository(db),
+		db:          db,
+	}
+}
+
+// Transaction 在同一事务中使用全部仓储，fn 返回错误时回滚。
+// 未关联数据库连接的仓储（如测试中替换的实现）直接执行 fn
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	if r.db == nil {
+		return fn(r)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RollupScope 预聚合读取详情记录的范围
+type RollupScope struct {
+	AfterID      uint      // 只读取ID大于该值的记录
+	ThroughID    uint      // 大于 0 时只读取ID不大于该值的记录
+	SettleBefore time.Time // 非零时只读取在该时间之前入库的记录
+	ProjectID    uint      // 大于 0 时只读取该项目的记录
+	From, To     int64     // To 大于 0 时只读取触发时间在 [From, To) 内的记录
+}
+
+// 为详情表查询添加读取范围条件，table 为详情表
+func (scope RollupScope) apply(query *gorm.DB, table string) *gorm.DB {
+	query = query.Where(model.SQL("{"+table+"}.id > ?"), scope.AfterID)
+	if scope.ThroughID > 0 {
+		query = query.Where(model.SQL("{"+table+"}.id <= ?"), scope.ThroughID)
+	}
+	if !scope.SettleBefore.IsZero() {
+		query = query.Where(model.SQL("{"+table+"}.created_at <= ?"), scope.SettleBefore)
+	}
+	if scope.ProjectID > 0 {
+		query = query.Where(model.SQL("{event_main}.project_id = ?"), scope.ProjectID)
+	}
+	if scope.To > 0 {
+		query = query.Where(model.SQL("{event_main}.trigger_time >= ? AND {event_main}.trigger_time < ?"), scope.From, scope.To)
+	}
+	return query
+}
+
+// ErrorRollupRow 待聚合的错误
+type ErrorRollupRow struct {
+	ID          uint
+	ProjectID   uint
+	TriggerTime int64
+	ErrorType   string
+	Browser     string
+	OS          string
+	UserUUID    string
+	UserID      string
+	SessionID   string
+}
+
+// PageViewRollupRow 待聚合的页面访问
+type PageViewRollupRow struct {
+	ID          uint
+	ProjectID   uint
+	TriggerTime int64
+	PageURL     string
+	StayTime    int64
+	UserUUID    string
+	UserID      string
+	SessionID   string
+}
+
+// ClickRollupRow 待聚合的点击
+type ClickRollupRow struct {
+	ID          uint
+	ProjectID   uint
+	TriggerTime int64
+	ElementPath string
+}
+
+// PerformanceRollupRow 待聚合的页面性能，未上报的指标为 nil
+type PerformanceRollupRow struct {
+	ID          uint
+	ProjectID   uint
+	TriggerTime int64
+	FP          *float64
+	FCP         *float64
+	LCP         *float64
+	FID         *float64
+	INP         *float64
+	CLS         *float64
+	TTFB        *float64
+	DomReady    *float64
+	Load        *float64
+}
+
+// RollupRepository 预聚合仓储，按ID顺序读取待聚合的详情记录，并累加到预聚合表
+type RollupRepository interface {
+	// ErrorRows 读取范围内的错误
+	ErrorRows(scope RollupScope, limit int) ([]ErrorRollupRow, error)
+	// PageViewRows 读取范围内的页面访问
+	PageViewRows(scope RollupScope, limit int) ([]PageViewRollupRow, error)
+	// ClickRows 读取范围内的点击
+	ClickRows(scope RollupScope, limit int) ([]ClickRollupRow, error)
+	// PerformanceRows 读取范围内的页面性能
+	PerformanceRows(scope RollupScope, limit int) ([]PerformanceRollupRow, error)
+
+	// AddCount 累加计数，已有记录时累加 Count 和 Sum
+	AddCount(row *model.RollupCount) error
+	// AddHistogram 累加直方图分桶，已有记录时累加 Count
+	AddHistogram(row *model.RollupHistogram) error
+	// FindSketch 获取去重草图，不存在时返回 nil
+	FindSketch(projectID uint, granularity string, bucketStart int64, metric string) (*model.RollupSketch, error)
+	// SaveSketch 保存去重草图
+	SaveSketch(sketch *model.RollupSketch) error
+
+	// DeleteAll 清空全部预聚合数据
+	DeleteAll() error
+	// DeleteBefore 删除某一粒度中早于指定时间的预聚合数据
+	DeleteBefore(granularity string, before int64) error
+	// DeleteProject 删除项目中指定指标在 [from, to) 内各粒度的预聚合数据
+	DeleteProject(projectID uint, metrics []string, from, to int64) error
+}
+
+type rollupRepository struct {
+	db *gorm.DB
+}
+
+// NewRollupRepository 创建预聚合仓储
+func NewRollupRepository(db *gorm.DB) RollupRepository {
+	return &rollupRepository{db: db}
+}
+
+func (r *rollupRepository) ErrorRows(scope RollupScope, limit int) ([]ErrorRollupRow, error) {
+	var rows []ErrorRollupRow
+	query := r.db.Model(&model.ErrorDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {error_detail}.event_id")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id"))
+	if err := scope.apply(query, "error_detail").
+		Select(model.SQL("{error_detail}.id, {event_main}.project_id, {event_main}.trigger_time, {error_detail}.error_type, " +
+			"{base_info}.browser, {base_info}.os, {base_info}.user_uuid, {base_info}.user_id, {base_info}.session_id")).
+		Order(model.SQL("{error_detail}.id")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *rollupRepository) PageViewRows(scope RollupScope, limit int) ([]PageViewRollupRow, error) {
+	var rows []PageViewRollupRow
+	query := r.db.Model(&model.PVDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {pv_detail}.event_id")).
+		Joins(model.SQL("JOIN {base_info} ON {base_info}.id = {event_main}.base_info_id"))
+	if err := scope.apply(query, "pv_detail").
+		Select(model.SQL("{pv_detail}.id, {event_main}.project_id, {event_main}.trigger_time, {pv_detail}.page_url, " +
+			"{pv_detail}.stay_time, {base_info}.user_uuid, {base_info}.user_id, {base_info}.session_id")).
+		Order(model.SQL("{pv_detail}.id")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *rollupRepository) ClickRows(scope RollupScope, limit int) ([]ClickRollupRow, error) {
+	var rows []ClickRollupRow
+	query := r.db.Model(&model.ClickDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {click_detail}.event_id"))
+	if err := scope.apply(query, "click_detail").
+		Select(model.SQL("{click_detail}.id, {event_main}.project_id, {event_main}.trigger_time, {click_detail}.element_path")).
+		Order(model.SQL("{click_detail}.id")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *rollupRepository) PerformanceRows(scope RollupScope, limit int) ([]PerformanceRollupRow, error) {
+	var rows []PerformanceRollupRow
+	query := r.db.Model(&model.PerformancePageDetail{}).
+		Joins(model.SQL("JOIN {event_main} ON {event_main}.id = {performance_page_detail}.event_id"))
+	if err := scope.apply(query, "performance_page_detail").
+		Select(model.SQL("{performance_page_detail}.id, {event_main}.project_id, {event_main}.trigger_time, " +
+			"{performance_page_detail}.fp, {performance_page_detail}.fcp, {performance_page_detail}.lcp, " +
+			"{performance_page_detail}.f_id, {performance_page_detail}.inp, {performance_page_detail}.cls, " +
+			"{performance_page_detail}.ttfb, {performance_page_detail}.dom_ready, {performance_page_detail}.load")).
+		Order(model.SQL("{performance_page_detail}.id")).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *rollupRepository) AddCount(row *model.RollupCount) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "granularity"}, {Name: "bucket_start"},
+			{Name: "metric"}, {Name: "dimension"}, {Name: "value"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":      gorm.Expr(model.SQL("{rollup_count}.count + ?"), row.Count),
+			"sum":        gorm.Expr(model.SQL("{rollup_count}.sum + ?"), row.Sum),
+			"updated_at": time.Now(),
+		}),
+	}).Create(row).Error
+}
+
+func (r *rollupRepository) AddHistogram(row *model.RollupHistogram) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "granularity"}, {Name: "bucket_start"},
+			{Name: "metric"}, {Name: "bucket"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":      gorm.Expr(model.SQL("{rollup_histogram}.count + ?"), row.Count),
+			"updated_at": time.Now(),
+		}),
+	}).Create(row).Error
+}
+
+func (r *rollupRepository) FindSketch(projectID uint, granularity string, bucketStart int64, metric string) (*model.RollupSketch, error) {
+	var sketch model.RollupSketch
+	result := r.db.Where("project_id = ? AND granularity = ? AND bucket_start = ? AND metric = ?",
+		projectID, granularity, bucketStart, metric).Limit(1).Find(&sketch)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &sketch, nil
+}
+
+func (r *rollupRepository) SaveSketch(sketch *model.RollupSketch) error {
+	return r.db.Save(sketch).Error
+}
+
+func (r *rollupRepository) DeleteAll() error {
+	return model.DeleteAllRollups(r.db)
+}
+
+func (r *rollupRepository) DeleteBefore(granularity string, before int64) error {
+	return model.DeleteRollupsBefore(r.db, granularity, before)
+}
+
+func (r *rollupRepository) DeleteProject(projectID uint, metrics []string, from, to int64) error {
+	return model.DeleteProjectRollups(r.db, projectID, metrics, from, to)
+}
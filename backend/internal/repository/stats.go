@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// RollupSegment 查询预聚合时使用的一段时间范围 [Start, End)
+type RollupSegment struct {
+	Granularity string
+	Start       int64
+	End         int64
+}
+
+// RollupCountSum 按维度值汇总的计数
+type RollupCountSum struct {
+	Value string
+	Count float64
+	Sum   float64
+}
+
+// RollupBucketSum 按直方图分桶汇总的数量
+type RollupBucketSum struct {
+	Bucket int
+	Count  float64
+}
+
+// StatsRepository 统计仓储，读取预聚合的计数、去重草图和直方图
+type StatsRepository interface {
+	// SumCounts 汇总多段时间范围内的计数，按维度值分组
+	SumCounts(projectID uint, metric, dimension string, segments []RollupSegment) ([]RollupCountSum, error)
+	// Sketches 获取多段时间范围内的去重草图
+	Sketches(projectID uint, metric string, segments []RollupSegment) ([][]byte, error)
+	// SumHistogram 汇总多段时间范围内的直方图，按分桶分组
+	SumHistogram(projectID uint, metric string, segments []RollupSegment) ([]RollupBucketSum, error)
+
+	// Counts 获取某一粒度下时间桶在 [start, end) 内的计数
+	Counts(projectID uint, metric, dimension, granularity string, start, end int64) ([]model.RollupCount, error)
+	// SketchBuckets 获取某一粒度下时间桶在 [start, end) 内的去重草图
+	SketchBuckets(projectID uint, metric, granularity string, start, end int64) ([]model.RollupSketch, error)
+	// HistogramBuckets 获取某一粒度下时间桶在 [start, end) 内的直方图
+	HistogramBuckets(projectID uint, metric, granularity string, start, end int64) ([]model.RollupHistogram, error)
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository 创建统计仓储
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// 为预聚合查询添加时间范围条件
+func rollupSegmentQuery(query *gorm.DB, segments []RollupSegment) *gorm.DB {
+	if len(segments) == 0 {
+		return query.Where("1 = 0")
+	}
+	conditions := make([]string, 0, len(segments))
+	args := make([]interface{}, 0, len(segments)*3)
+	for _, segment := range segments {
+		conditions = append(conditions, "(granularity = ? AND bucket_start >= ? AND bucket_start < ?)")
+		args = append(args, segment.Granularity, segment.Start, segment.End)
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+func (r *statsRepository) SumCounts(projectID uint, metric, dimension string, segments []RollupSegment) ([]RollupCountSum, error) {
+	// MySQL 的 SUM 返回 DECIMAL，统一按浮点数读取
+	var rows []RollupCountSum
+	query := r.db.Model(&model.RollupCount{}).
+		Where("project_id = ? AND metric = ? AND dimension = ?", projectID, metric, dimension)
+	if err := rollupSegmentQuery(query, segments).
+		Select("value, SUM(count) as count, SUM(sum) as sum").
+		Group("value").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *statsRepository) Sketches(projectID uint, metric string, segments []RollupSegment) ([][]byte, error) {
+	var sketches [][]byte
+	query := r.db.Model(&model.RollupSketch{}).
+		Where("project_id = ? AND metric = ?", projectID, metric)
+	if err := rollupSegmentQuery(query, segments).
+		Pluck("sketch", &sketches).Error; err != nil {
+		return nil, err
+	}
+	return sketches, nil
+}
+
+func (r *statsRepository) SumHistogram(projectID uint, metric string, segments []RollupSegment) ([]RollupBucketSum, error) {
+	var rows []RollupBucketSum
+	query := r.db.Model(&model.RollupHistogram{}).
+		Where("project_id = ? AND metric = ?", projectID, metric)
+	if err := rollupSegmentQuery(query, segments).
+		Select("bucket, SUM(count) as count").
+		Group("bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *statsRepository) Counts(projectID uint, metric, dimension, granularity string, start, end int64) ([]model.RollupCount, error) {
+	var rows []model.RollupCount
+	if err := r.db.
+		Where("project_id = ? AND metric = ? AND dimension = ? AND granularity = ? AND bucket_start >= ? AND bucket_start < ?",
+			projectID, metric, dimension, granularity, start, end).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *statsRepository) SketchBuckets(projectID uint, metric, granularity string, start, end int64) ([]model.RollupSketch, error) {
+	var rows []model.RollupSketch
+	if err := r.db.
+		Where("project_id = ? AND metric = ? AND granularity = ? AND bucket_start >= ? AND bucket_start < ?",
+			projectID, metric, granularity, start, end).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *statsRepository) HistogramBuckets(projectID uint, metric, granularity string, start, end int64) ([]model.RollupHistogram, error) {
+	var rows []model.RollupHistogram
+	if err := r.db.
+		Where("project_id = ? AND metric = ? AND granularity = ? AND bucket_start >= ? AND bucket_start < ?",
+			projectID, metric, granularity, start, end).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// UserRepository 用户仓储
+type UserRepository interface {
+	// Create 创建用户，密码以哈希保存
+	Create(username, password, email string) (*model.User, error)
+	// FindByUsername 通过用户名获取用户
+	FindByUsername(username string) (*model.User, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建用户仓储
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(username, password, email string) (*model.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{
+		Username: username,
+		Password: string(hashedPassword),
+		Email:    email,
+	}
+
+	if err := r.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(username string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+const (
+	// 单次聚合最多读取的请求记录数量
+	maxAPIPerformanceRows = 200000
+	// 请求参数序列化后的最大字节数
+	maxRequestParamsSize = 2048
+)
+
+// APIPerformanceResponse 接口性能响应
+type APIPerformanceResponse struct {
+	Total int64                `json:"total"`
+	List  []APIPerformanceItem `json:"list"`
+}
+
+// APIPerformanceItem 按接口模板聚合的性能数据
+type APIPerformanceItem struct {
+	Method             string           `json:"method"`
+	URLTemplate        string           `json:"urlTemplate"`
+	Calls              int64            `json:"calls"`   // 按采样率估算的调用量
+	Samples            int64            `json:"samples"` // 实际入库的请求数
+	SuccessRate        float64          `json:"successRate"`
+	AvgDuration        float64          `json:"avgDuration"`
+	P50Duration        float64          `json:"p50Duration"`
+	P95Duration        float64          `json:"p95Duration"`
+	StatusDistribution map[string]int64 `json:"statusDistribution"`
+}
+
+// APIPerformanceFilter 接口性能筛选条件
+type APIPerformanceFilter struct {
+	StartTime string
+	EndTime   string
+	Method    string
+	Keyword   string
+	SortBy    string
+	Order     string
+}
+
+// 接口聚合中间结果
+type apiRequestGroup struct {
+	item      APIPerformanceItem
+	durations []float64
+	calls     float64
+	successes float64
+}
+
+// NormalizeAPIURL 归一化接口地址，保留域名，去掉查询参数并将ID类路径片段替换为占位符
+func NormalizeAPIURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	segments := replaceIDSegments(strings.Split(u.Path, "/"))
+	return truncateRunes(u.Host+strings.Join(segments, "/"), 255)
+}
+
+// 请求是否成功，状态码为 0 表示网络错误或请求被取消
+func isRequestSuccess(status int) bool {
+	return status >= 200 && status < 400
+}
+
+// 成功请求的采样率，限制在 (0, 1] 之间
+func requestSampleRate() float64 {
+	rate := model.RequestSetting.SampleRate
+	if rate <= 0 || rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// 判断接口请求是否入库，失败请求始终保留，成功请求按采样率保留
+func keepRequestSample(req *TrackRequest) bool {
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(req.Data, &dataMap); err != nil {
+		return true
+	}
+	status, _ := firstNumber(dataMap, "status", "responseStatus")
+	if !isRequestSuccess(int(status)) {
+		return true
+	}
+	return rand.Float64() < requestSampleRate()
+}
+
+// 从SDK接口请求事件处理
+func (s *EventService) processRequestEventFromSDK(req *TrackRequest, eventID uint) error {
+	// 创建接口请求详情
+	requestDetail := model.RequestDetail{
+		EventID: eventID,
+	}
+
+	// 从事件数据中提取请求信息
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
+		requestDetail.URL = stringField(dataMap, "requestUrl")
+		if requestDetail.URL == "" {
+			requestDetail.URL = stringField(dataMap, "url")
+		}
+		requestDetail.Method = strings.ToUpper(stringField(dataMap, "method"))
+		if requestDetail.Method == "" {
+			requestDetail.Method = "GET"
+		}
+
+		if status, ok := firstNumber(dataMap, "status", "responseStatus"); ok {
+			requestDetail.Status = int(status)
+		}
+		if duration, ok := firstNumber(dataMap, "duration"); ok {
+			requestDetail.Duration = int64(duration)
+		}
+
+		// 提取请求参数，超出大小时丢弃
+		for _, key := range []string{"params", "requestData"} {
+			params, ok := dataMap[key]
+			if !ok || params == nil {
+				continue
+			}
+			if value, ok := params.(string); ok {
+				requestDetail.Params = value
+			} else if encoded, err := json.Marshal(params); err == nil {
+				requestDetail.Params = string(encoded)
+			}
+			break
+		}
+		if len(requestDetail.Params) > maxRequestParamsSize {
+			requestDetail.Params = ""
+		}
+	}
+
+	requestDetail.URLTemplate = NormalizeAPIURL(requestDetail.URL)
+	requestDetail.Success = isRequestSuccess(requestDetail.Status)
+	requestDetail.SampleRate = 1
+	if requestDetail.Success {
+		requestDetail.SampleRate = requestSampleRate()
+	}
+
+	// 保存接口请求详情
+	return s.events.CreateDetail(&requestDetail)
+}
+
+// GetAPIPerformance 按请求方法和接口模板聚合调用量、耗时分位数、成功率和状态码分布
+func (s *EventService) GetAPIPerformance(projectIDStr, pageStr, pageSizeStr string, filter APIPerformanceFilter) (*APIPerformanceResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	list, err := s.store.APIStats(projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+	sortAPIPerformance(list, filter.SortBy, filter.Order != "asc")
+
+	total := int64(len(list))
+	offset := (page - 1) * pageSize
+	if offset > len(list) {
+		offset = len(list)
+	}
+	end := offset + pageSize
+	if end > len(list) {
+		end = len(list)
+	}
+
+	return &APIPerformanceResponse{
+		Total: total,
+		List:  list[offset:end],
+	}, nil
+}
+
+// 接口排序，默认按调用量排序
+func sortAPIPerformance(list []APIPerformanceItem, sortBy string, desc bool) {
+	value := func(item APIPerformanceItem) float64 {
+		switch sortBy {
+		case "p50":
+			return item.P50Duration
+		case "p95":
+			return item.P95Duration
+		case "successRate":
+			return item.SuccessRate
+		default:
+			return float64(item.Calls)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		vi, vj := value(list[i]), value(list[j])
+		if vi != vj {
+			if desc {
+				return vi > vj
+			}
+			return vi < vj
+		}
+		if list[i].URLTemplate != list[j].URLTemplate {
+			return list[i].URLTemplate < list[j].URLTemplate
+		}
+		return list[i].Method < list[j].Method
+	})
+}
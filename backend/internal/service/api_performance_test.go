@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetAPIPerformanceWeightsSamples(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.performance.requests = []repository.APIRequestSample{
+		// 成功请求按采样率入库，调用量按采样率还原
+		{Method: "GET", URLTemplate: "api.example.com/users/:id", Status: 200, Duration: 100, Success: true, SampleRate: 0.5},
+		{Method: "GET", URLTemplate: "api.example.com/users/:id", Status: 500, Duration: 300, SampleRate: 1},
+		{Method: "POST", URLTemplate: "api.example.com/orders", Status: 201, Duration: 50, Success: true, SampleRate: 1},
+	}
+	service := fakes.eventService()
+
+	resp, err := service.GetAPIPerformance("1", "1", "10", APIPerformanceFilter{
+		StartTime: "1700000001000",
+		EndTime:   "1700000002000",
+		Method:    "GET",
+		Keyword:   "users",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := repository.APIRequestFilter{TimeRange: repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}, Method: "GET", Keyword: "users"}
+	if fakes.performance.requestFilter != want {
+		t.Fatalf("筛选条件 %+v，期望 %+v", fakes.performance.requestFilter, want)
+	}
+
+	if resp.Total != 2 {
+		t.Fatalf("接口 %+v", resp.List)
+	}
+	item := resp.List[0]
+	if item.URLTemplate != "api.example.com/users/:id" || item.Calls != 3 || item.Samples != 2 || item.SuccessRate != 0.667 ||
+		item.AvgDuration != 200 || item.P50Duration != 200 || item.StatusDistribution["200"] != 1 || item.StatusDistribution["500"] != 1 {
+		t.Fatalf("接口汇总 %+v", item)
+	}
+
+	// 按成功率升序
+	resp, err = service.GetAPIPerformance("1", "1", "1", APIPerformanceFilter{SortBy: "successRate", Order: "asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || len(resp.List) != 1 || resp.List[0].Method != "GET" {
+		t.Fatalf("排序 %+v", resp.List)
+	}
+}
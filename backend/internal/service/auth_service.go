@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
 	"github.com/dgrijalva/jwt-go"
 )
 
@@ -36,7 +37,14 @@ type LoginResponse struct {
 }
 
 // 认证服务
-type AuthService struct{}
+type AuthService struct {
+	users repository.UserRepository
+}
+
+// NewAuthService 创建认证服务
+func NewAuthService(users repository.UserRepository) *AuthService {
+	return &AuthService{users: users}
+}
 
 // 生成 JWT token
 func (s *AuthService) GenerateToken(user *model.User) (string, int64, error) {
@@ -79,7 +87,7 @@ func (s *AuthService) ParseToken(token string) (*Claims, error) {
 
 // 用户登录
 func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
-	user, err := model.GetUserByUsername(req.Username)
+	user, err := s.users.FindByUsername(req.Username)
 	if err != nil {
 		return nil, errors.New("用户不存在")
 	}
@@ -103,13 +111,13 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 // 用户注册
 func (s *AuthService) Register(req *RegisterRequest) (*model.User, error) {
 	// 检查用户名是否已存在
-	existingUser, _ := model.GetUserByUsername(req.Username)
+	existingUser, _ := s.users.FindByUsername(req.Username)
 	if existingUser != nil {
 		return nil, errors.New("用户名已存在")
 	}
 
 	// 创建新用户
-	user, err := model.CreateUser(req.Username, req.Password, req.Email)
+	user, err := s.users.Create(req.Username, req.Password, req.Email)
 	if err != nil {
 		return nil, err
 	}
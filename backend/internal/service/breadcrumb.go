@@ -0,0 +1,161 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 面包屑类型
+const (
+	BreadcrumbTypeNavigation = "navigation"
+	BreadcrumbTypeClick      = "click"
+	BreadcrumbTypeConsole    = "console"
+	BreadcrumbTypeHTTP       = "http"
+	BreadcrumbTypeCustom     = "custom"
+)
+
+const (
+	// 每个错误最多保留的面包屑数量，超出时保留最近的
+	maxBreadcrumbs = 50
+	// 单条面包屑消息的最大长度（字符）
+	maxBreadcrumbMessageLength = 500
+	// 单条面包屑附加数据序列化后的最大字节数，超出时丢弃附加数据
+	maxBreadcrumbDataSize = 2048
+)
+
+// Breadcrumb 错误发生前的用户操作记录
+type Breadcrumb struct {
+	Type      string                 `json:"type"`
+	Category  string                 `json:"category,omitempty"`
+	Level     string                 `json:"level,omitempty"`
+	Message   string                 `json:"message"`
+	Timestamp int64                  `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// 错误上下文中面包屑的存储结构
+type errorContext struct {
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs"`
+}
+
+// 将 SDK 上报的面包屑规范化为统一结构，兼容 web-tracing 和 Sentry 两种格式
+func normalizeBreadcrumbs(raw []interface{}) []Breadcrumb {
+	breadcrumbs := make([]Breadcrumb, 0, len(raw))
+	for _, item := range raw {
+		crumb, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		breadcrumb := Breadcrumb{
+			Type:      breadcrumbType(crumb),
+			Category:  stringField(crumb, "category"),
+			Level:     stringField(crumb, "level"),
+			Message:   truncateRunes(breadcrumbMessage(crumb), maxBreadcrumbMessageLength),
+			Timestamp: breadcrumbTimestamp(crumb),
+		}
+		if data, ok := crumb["data"].(map[string]interface{}); ok && len(data) > 0 {
+			if encoded, err := json.Marshal(data); err == nil && len(encoded) <= maxBreadcrumbDataSize {
+				breadcrumb.Data = data
+			}
+		}
+		breadcrumbs = append(breadcrumbs, breadcrumb)
+	}
+
+	sort.SliceStable(breadcrumbs, func(i, j int) bool {
+		return breadcrumbs[i].Timestamp < breadcrumbs[j].Timestamp
+	})
+	if len(breadcrumbs) > maxBreadcrumbs {
+		breadcrumbs = breadcrumbs[len(breadcrumbs)-maxBreadcrumbs:]
+	}
+	return breadcrumbs
+}
+
+// 解析错误上下文中的面包屑
+func parseBreadcrumbs(context string) []Breadcrumb {
+	breadcrumbs := []Breadcrumb{}
+	if context == "" {
+		return breadcrumbs
+	}
+	var parsed errorContext
+	if err := json.Unmarshal([]byte(context), &parsed); err == nil && parsed.Breadcrumbs != nil {
+		breadcrumbs = parsed.Breadcrumbs
+	}
+	return breadcrumbs
+}
+
+// 推断面包屑类型，Sentry 的类型信息在 category 中
+func breadcrumbType(crumb map[string]interface{}) string {
+	for _, value := range []string{stringField(crumb, "type"), stringField(crumb, "category")} {
+		switch strings.ToLower(value) {
+		case "navigation", "route", "router", "history", "hashchange":
+			return BreadcrumbTypeNavigation
+		case "click", "ui.click", "user", "ui":
+			return BreadcrumbTypeClick
+		case "console", "log", "debug":
+			return BreadcrumbTypeConsole
+		case "http", "fetch", "xhr", "xmlhttprequest", "request":
+			return BreadcrumbTypeHTTP
+		}
+	}
+	return BreadcrumbTypeCustom
+}
+
+// 面包屑描述，没有消息时根据附加数据生成
+func breadcrumbMessage(crumb map[string]interface{}) string {
+	if message := stringField(crumb, "message"); message != "" {
+		return message
+	}
+	data, _ := crumb["data"].(map[string]interface{})
+	if data == nil {
+		return ""
+	}
+	if to := stringField(data, "to"); to != "" {
+		return stringField(data, "from") + " -> " + to
+	}
+	if url := stringField(data, "url"); url != "" {
+		message := strings.TrimSpace(stringField(data, "method") + " " + url)
+		if status, ok := data["status_code"]; ok {
+			message += fmt.Sprintf(" %v", status)
+		} else if status, ok := data["status"]; ok {
+			message += fmt.Sprintf(" %v", status)
+		}
+		return message
+	}
+	return ""
+}
+
+// 面包屑时间戳统一为毫秒，兼容秒、毫秒和 RFC3339 字符串
+func breadcrumbTimestamp(crumb map[string]interface{}) int64 {
+	switch value := crumb["timestamp"].(type) {
+	case float64:
+		if value < 1e12 {
+			return int64(value * 1000)
+		}
+		return int64(value)
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t.UnixMilli()
+		}
+	}
+	return 0
+}
+
+// 读取字符串字段
+func stringField(dataMap map[string]interface{}, key string) string {
+	value, _ := dataMap[key].(string)
+	return value
+}
+
+// 按字符截断字符串
+func truncateRunes(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:limit])
+}
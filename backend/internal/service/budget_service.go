@@ -0,0 +1,263 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 单个预算评估最多读取的记录数量
+const maxBudgetRows = 100000
+
+// 预算评估结果
+const (
+	BudgetStatusPass   = "pass"
+	BudgetStatusFail   = "fail"
+	BudgetStatusNoData = "no_data"
+)
+
+// 页面性能类预算指标
+var budgetPageMetrics = map[string]bool{
+	model.BudgetMetricLCP:  true,
+	model.BudgetMetricFCP:  true,
+	model.BudgetMetricFID:  true,
+	model.BudgetMetricINP:  true,
+	model.BudgetMetricCLS:  true,
+	model.BudgetMetricTTFB: true,
+}
+
+// 性能预算创建/更新请求
+type BudgetRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	Metric       string  `json:"metric" binding:"required"`
+	PageURL      string  `json:"pageUrl"`
+	ResourceType string  `json:"resourceType"`
+	Threshold    float64 `json:"threshold"`
+	Enabled      *bool   `json:"enabled"`
+}
+
+// BudgetStatusResponse 性能预算评估响应
+type BudgetStatusResponse struct {
+	Release string             `json:"release"`
+	Passed  bool               `json:"passed"`
+	Budgets []BudgetStatusItem `json:"budgets"`
+}
+
+// BudgetStatusItem 单个预算的评估结果
+type BudgetStatusItem struct {
+	BudgetID     uint    `json:"budgetId"`
+	Name         string  `json:"name"`
+	Metric       string  `json:"metric"`
+	PageURL      string  `json:"pageUrl"`
+	ResourceType string  `json:"resourceType"`
+	Threshold    float64 `json:"threshold"`
+	Value        float64 `json:"value"`
+	Samples      int64   `json:"samples"`
+	Status       string  `json:"status"`
+}
+
+// 预算服务
+type BudgetService struct {
+	projects    repository.ProjectRepository
+	performance repository.PerformanceRepository
+}
+
+// NewBudgetService 创建性能预算服务
+func NewBudgetService(projects repository.ProjectRepository, performance repository.PerformanceRepository) *BudgetService {
+	return &BudgetService{projects: projects, performance: performance}
+}
+
+// 检查项目是否属于该用户
+func (s *BudgetService) checkProject(projectID, userID uint) error {
+	project, err := s.projects.FindByID(projectID)
+	if err != nil {
+		return errors.New("项目不存在")
+	}
+	if project.UserID != userID {
+		return errors.New("无权访问该项目")
+	}
+	return nil
+}
+
+// 校验预算请求并写入模型
+func applyBudgetRequest(budget *model.PerformanceBudget, req *BudgetRequest) error {
+	if !budgetPageMetrics[req.Metric] &&
+		req.Metric != model.BudgetMetricResourceSize && req.Metric != model.BudgetMetricResourceDuration {
+		return errors.New("不支持的预算指标")
+	}
+	if req.Threshold <= 0 {
+		return errors.New("预算阈值必须大于0")
+	}
+
+	budget.Name = req.Name
+	budget.Metric = req.Metric
+	budget.PageURL = NormalizeURL(req.PageURL)
+	budget.ResourceType = req.ResourceType
+	budget.Threshold = req.Threshold
+	if req.Enabled != nil {
+		budget.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+// 创建性能预算
+func (s *BudgetService) CreateBudget(projectID uint, req *BudgetRequest, userID uint) (*model.PerformanceBudget, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	budget := &model.PerformanceBudget{ProjectID: projectID, Enabled: true}
+	if err := applyBudgetRequest(budget, req); err != nil {
+		return nil, err
+	}
+	if err := s.projects.SaveBudget(budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// 获取项目的性能预算列表
+func (s *BudgetService) GetBudgets(projectID uint, userID uint) ([]model.PerformanceBudget, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+	return s.projects.ListBudgets(projectID)
+}
+
+// 更新性能预算
+func (s *BudgetService) UpdateBudget(projectID, budgetID uint, req *BudgetRequest, userID uint) (*model.PerformanceBudget, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	budget, err := s.projects.FindBudget(projectID, budgetID)
+	if err != nil {
+		return nil, errors.New("性能预算不存在")
+	}
+	if err := applyBudgetRequest(budget, req); err != nil {
+		return nil, err
+	}
+	if err := s.projects.SaveBudget(budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// 删除性能预算
+func (s *BudgetService) DeleteBudget(projectID, budgetID uint, userID uint) error {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return err
+	}
+	if _, err := s.projects.FindBudget(projectID, budgetID); err != nil {
+		return errors.New("性能预算不存在")
+	}
+	return s.projects.DeleteBudget(projectID, budgetID)
+}
+
+// GetBudgetStatus 评估项目所有启用的预算，未指定版本时评估最近上报的版本
+func (s *BudgetService) GetBudgetStatus(projectID uint, release, startTimeStr, endTimeStr string, userID uint) (*BudgetStatusResponse, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	if release == "" {
+		latest, err := s.performance.LatestRelease(projectID)
+		if err != nil {
+			return nil, err
+		}
+		release = latest
+	}
+
+	budgets, err := s.projects.ListBudgets(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &BudgetStatusResponse{
+		Release: release,
+		Passed:  true,
+		Budgets: []BudgetStatusItem{},
+	}
+	for _, budget := range budgets {
+		if !budget.Enabled {
+			continue
+		}
+
+		values, err := s.budgetSamples(budget, release, startTimeStr, endTimeStr)
+		if err != nil {
+			return nil, err
+		}
+
+		item := BudgetStatusItem{
+			BudgetID:     budget.ID,
+			Name:         budget.Name,
+			Metric:       budget.Metric,
+			PageURL:      budget.PageURL,
+			ResourceType: budget.ResourceType,
+			Threshold:    budget.Threshold,
+			Samples:      int64(len(values)),
+			Status:       BudgetStatusNoData,
+		}
+		if len(values) > 0 {
+			item.Value = percentile(values, 0.75)
+			item.Status = BudgetStatusPass
+			if item.Value > budget.Threshold {
+				item.Status = BudgetStatusFail
+				resp.Passed = false
+			}
+		}
+		resp.Budgets = append(resp.Budgets, item)
+	}
+
+	return resp, nil
+}
+
+// 收集预算指标的样本值
+func (s *BudgetService) budgetSamples(budget model.PerformanceBudget, release, startTimeStr, endTimeStr string) ([]float64, error) {
+	filter := repository.BudgetSampleFilter{
+		TimeRange:    parseTimeRange(startTimeStr, endTimeStr),
+		ProjectID:    budget.ProjectID,
+		Metric:       budget.Metric,
+		Release:      release,
+		ResourceType: budget.ResourceType,
+	}
+	// 先在 SQL 中按页面筛选，避免其他页面的记录占满行数上限
+	if budget.PageURL != "" {
+		filter.PagePatterns = pageURLPatterns(budget.PageURL)
+	}
+
+	rows, err := s.performance.BudgetSamples(filter, maxBudgetRows)
+	if err != nil {
+		return nil, err
+	}
+
+	// 资源大小按单次页面加载汇总，同一页面实例优先用 pageId 识别
+	if budget.Metric == model.BudgetMetricResourceSize {
+		totals := make(map[string]float64)
+		for _, row := range rows {
+			if budget.PageURL != "" && NormalizeURL(row.PageURL) != budget.PageURL {
+				continue
+			}
+			key := row.PageID
+			if key == "" {
+				key = row.SessionID + "|" + row.PageURL
+			}
+			totals[key] += row.Value
+		}
+		values := make([]float64, 0, len(totals))
+		for _, total := range totals {
+			values = append(values, total)
+		}
+		return values, nil
+	}
+
+	values := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		if budget.PageURL != "" && NormalizeURL(row.PageURL) != budget.PageURL {
+			continue
+		}
+		values = append(values, row.Value)
+	}
+	return values, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetBudgetStatusEvaluatesLatestRelease(t *testing.T) {
+	fakes := newFakeRepositories()
+	itemsPage := NormalizeURL("https://example.com/items/1")
+	fakes.projects.budgets = []model.PerformanceBudget{
+		{ProjectID: testProjectID, Name: "商品页 LCP", Metric: model.BudgetMetricLCP, PageURL: itemsPage, Threshold: 2500, Enabled: true},
+		{ProjectID: testProjectID, Name: "资源体积", Metric: model.BudgetMetricResourceSize, ResourceType: "script", Threshold: 1000, Enabled: true},
+		{ProjectID: testProjectID, Name: "CLS", Metric: model.BudgetMetricCLS, Threshold: 0.1, Enabled: true},
+		{ProjectID: testProjectID, Name: "已停用", Metric: model.BudgetMetricFCP, Threshold: 1, Enabled: false},
+	}
+	fakes.performance.release = "2.0.0"
+	fakes.performance.budget = map[string][]repository.BudgetSample{
+		model.BudgetMetricLCP: {
+			{PageURL: "https://example.com/items/1", Value: 2000},
+			{PageURL: "https://example.com/items/2", Value: 3000},
+			// SQL 条件误匹配的其他页面
+			{PageURL: "https://example.com/items/1/reviews", Value: 9000},
+		},
+		// 同一次页面加载的资源合并计算
+		model.BudgetMetricResourceSize: {
+			{PageURL: "/home", PageID: "p1", Value: 300},
+			{PageURL: "/home", PageID: "p1", Value: 400},
+			{PageURL: "/home", SessionID: "s1", Value: 500},
+		},
+	}
+	service := NewBudgetService(fakes.projects, fakes.performance)
+
+	resp, err := service.GetBudgetStatus(testProjectID, "", "1700000001000", "1700000002000", testUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Release != "2.0.0" || resp.Passed || len(resp.Budgets) != 3 {
+		t.Fatalf("预算评估 %+v", resp)
+	}
+
+	lcp := resp.Budgets[0]
+	if lcp.Samples != 2 || lcp.Value != 2750 || lcp.Status != BudgetStatusFail {
+		t.Fatalf("LCP 预算 %+v", lcp)
+	}
+	size := resp.Budgets[1]
+	if size.Samples != 2 || size.Value != 650 || size.Status != BudgetStatusPass {
+		t.Fatalf("资源体积预算 %+v", size)
+	}
+	if resp.Budgets[2].Status != BudgetStatusNoData {
+		t.Fatalf("CLS 预算 %+v", resp.Budgets[2])
+	}
+
+	filters := fakes.performance.budgetFilters
+	if len(filters) != 3 {
+		t.Fatalf("查询了 %d 次样本", len(filters))
+	}
+	want := repository.BudgetSampleFilter{
+		TimeRange:    repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000},
+		ProjectID:    testProjectID,
+		Metric:       model.BudgetMetricLCP,
+		Release:      "2.0.0",
+		PagePatterns: pageURLPatterns(itemsPage),
+	}
+	if !reflect.DeepEqual(filters[0], want) {
+		t.Fatalf("样本筛选条件 %+v，期望 %+v", filters[0], want)
+	}
+	if filters[1].ResourceType != "script" || filters[1].PagePatterns != nil {
+		t.Fatalf("资源样本筛选条件 %+v", filters[1])
+	}
+}
+
+func TestBudgetServiceChecksProjectAndRequest(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := NewBudgetService(fakes.projects, fakes.performance)
+
+	if _, err := service.GetBudgetStatus(testProjectID, "1.0.0", "", "", testUserID+1); err == nil {
+		t.Fatal("其他用户的项目应返回错误")
+	}
+	if _, err := service.CreateBudget(testProjectID, &BudgetRequest{Name: "x", Metric: "unknown", Threshold: 1}, testUserID); err == nil {
+		t.Fatal("不支持的指标应返回错误")
+	}
+	if _, err := service.CreateBudget(testProjectID, &BudgetRequest{Name: "x", Metric: model.BudgetMetricLCP}, testUserID); err == nil {
+		t.Fatal("阈值为 0 应返回错误")
+	}
+}
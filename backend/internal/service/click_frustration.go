@@ -0,0 +1,373 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+const (
+	// 点击挫败检测任务游标名称
+	frustrationCursorName = "click_frustration"
+	// 每批检测的点击数量
+	frustrationBatchSize = 5000
+	// 每个元素返回的会话数量
+	frustrationSessionLimit = 10
+	// 默认返回的元素数量
+	defaultFrustrationLimit = 20
+	// 单次聚合最多读取的挫败事件数量
+	maxFrustrationRows = 100000
+)
+
+// 点击后出现这些事件视为页面有响应：页面访问、路由切换、错误（包括接口错误）、接口请求和资源加载。
+// SDK 不上报 DOM 变化，只改变 DOM 的响应（如展开菜单）无法识别，这类点击可能被判定为无响应点击
+var clickReactionEventTypes = []string{
+	model.EventTypePV,
+	model.EventTypeRoute,
+	model.EventTypeError,
+	model.EventTypeRequest,
+	model.EventTypePerformanceResource,
+}
+
+// 这些元素点击后本身没有可见反馈，不参与无响应点击判定
+var deadClickIgnoredElements = map[string]bool{
+	"input":    true,
+	"textarea": true,
+	"select":   true,
+	"option":   true,
+	"html":     true,
+	"body":     true,
+}
+
+// FrustrationListResponse 挫败元素列表响应
+type FrustrationListResponse struct {
+	List []FrustrationItem `json:"list"`
+}
+
+// FrustrationItem 页面中引起挫败的元素
+type FrustrationItem struct {
+	PageURL          string   `json:"pageUrl"`
+	ElementPath      string   `json:"elementPath"`
+	ElementType      string   `json:"elementType"`
+	InnerText        string   `json:"innerText"`
+	RageClicks       int64    `json:"rageClicks"`
+	DeadClicks       int64    `json:"deadClicks"`
+	TotalClicks      int64    `json:"totalClicks"`
+	AffectedSessions int64    `json:"affectedSessions"`
+	Sessions         []string `json:"sessions"`
+	LastSeen         int64    `json:"lastSeen"`
+}
+
+// 时间戳统一转换为毫秒，兼容秒级和毫秒级上报
+func timestampMillis(ts int64) int64 {
+	if ts > 0 && ts < 1e12 {
+		return ts * 1000
+	}
+	return ts
+}
+
+// DetectClickFrustrations 检测新入库点击中的狂点和无响应点击，返回生成的事件数
+func (s *EventService) DetectClickFrustrations() (int, error) {
+	created := 0
+	for {
+		n, processed, err := s.detectClickFrustrationBatch()
+		created += n
+		if err != nil || processed < frustrationBatchSize {
+			return created, err
+		}
+	}
+}
+
+// 检测一批点击，返回生成的事件数和处理的点击数
+func (s *EventService) detectClickFrustrationBatch() (int, int, error) {
+	cursor, err := s.jobCursors.Get(frustrationCursorName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// 只检测入库一段时间后的点击，等待后续事件上报
+	settleBefore := time.Now().Add(-time.Duration(model.FrustrationSetting.SettleDelay) * time.Second)
+
+	clicks, err := s.behaviors.ListPendingClicks(cursor, settleBefore, frustrationBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(clicks) == 0 {
+		return 0, 0, nil
+	}
+
+	// 按会话分组
+	sessions := make(map[string][]repository.PendingClick)
+	for _, click := range clicks {
+		if click.SessionID == "" && click.UserUUID == "" {
+			continue
+		}
+		key := fmt.Sprintf("%d|%s|%s", click.ProjectID, click.SessionID, click.UserUUID)
+		sessions[key] = append(sessions[key], click)
+	}
+
+	created := 0
+	for _, sessionClicks := range sessions {
+		n, err := s.detectSessionFrustrations(sessionClicks)
+		created += n
+		if err != nil {
+			return created, len(clicks), err
+		}
+	}
+
+	if err := s.jobCursors.Save(frustrationCursorName, clicks[len(clicks)-1].ID); err != nil {
+		return created, len(clicks), err
+	}
+
+	return created, len(clicks), nil
+}
+
+// 检测同一会话内的点击序列
+func (s *EventService) detectSessionFrustrations(clicks []repository.PendingClick) (int, error) {
+	sort.Slice(clicks, func(i, j int) bool {
+		if clicks[i].TriggerTime != clicks[j].TriggerTime {
+			return clicks[i].TriggerTime < clicks[j].TriggerTime
+		}
+		return clicks[i].ID < clicks[j].ID
+	})
+
+	setting := model.FrustrationSetting
+	created := 0
+	inRage := make(map[uint]bool)
+
+	// 狂点：同一元素上连续点击，相邻间隔不超过窗口
+	byElement := make(map[string][]repository.PendingClick)
+	for _, click := range clicks {
+		if click.ElementPath != "" {
+			byElement[click.ElementPath] = append(byElement[click.ElementPath], click)
+		}
+	}
+	for _, elementClicks := range byElement {
+		start := 0
+		for i := 1; i <= len(elementClicks); i++ {
+			if i < len(elementClicks) &&
+				timestampMillis(elementClicks[i].TriggerTime)-timestampMillis(elementClicks[i-1].TriggerTime) <= setting.RageClickWindow {
+				continue
+			}
+			burst := elementClicks[start:i]
+			start = i
+			if len(burst) < setting.RageClickCount {
+				continue
+			}
+			if err := s.createFrustrationEvent(model.EventTypeRageClick, burst); err != nil {
+				return created, err
+			}
+			created++
+			for _, click := range burst {
+				inRage[click.ID] = true
+			}
+		}
+	}
+
+	// 无响应点击：点击后窗口内没有 clickReactionEventTypes 中的事件
+	var candidates []repository.PendingClick
+	for _, click := range clicks {
+		if inRage[click.ID] || deadClickIgnoredElements[strings.ToLower(click.ElementType)] {
+			continue
+		}
+		candidates = append(candidates, click)
+	}
+	if len(candidates) == 0 {
+		return created, nil
+	}
+
+	reactions, err := s.getClickReactionTimes(candidates)
+	if err != nil {
+		return created, err
+	}
+	for _, click := range candidates {
+		clickTime := timestampMillis(click.TriggerTime)
+		idx := sort.Search(len(reactions), func(i int) bool { return reactions[i] >= clickTime })
+		if idx < len(reactions) && reactions[idx]-clickTime <= setting.DeadClickWindow {
+			continue
+		}
+		if err := s.createFrustrationEvent(model.EventTypeDeadClick, []repository.PendingClick{click}); err != nil {
+			return created, err
+		}
+		created++
+	}
+
+	return created, nil
+}
+
+// 获取会话内可视为点击响应的事件时间（毫秒，升序）
+func (s *EventService) getClickReactionTimes(clicks []repository.PendingClick) ([]int64, error) {
+	first := clicks[0]
+	last := clicks[len(clicks)-1]
+
+	// 秒级时间戳下窗口向上取整
+	window := model.FrustrationSetting.DeadClickWindow
+	if last.TriggerTime < 1e12 {
+		window = (window + 999) / 1000
+	}
+
+	times, err := s.behaviors.ReactionTimes(first.ProjectID, first.SessionID, first.UserUUID, clickReactionEventTypes,
+		first.TriggerTime, last.TriggerTime+window)
+	if err != nil {
+		return nil, err
+	}
+	for i := range times {
+		times[i] = timestampMillis(times[i])
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	return times, nil
+}
+
+// 保存推导出的挫败事件
+func (s *EventService) createFrustrationEvent(eventType string, clicks []repository.PendingClick) error {
+	first := clicks[0]
+	last := clicks[len(clicks)-1]
+
+	// 以首次点击生成确定的事件ID，重复检测时不会产生重复事件
+	eventMain := model.EventMain{
+		EventID:        fmt.Sprintf("%s-%d", eventType, first.ID),
+		EventType:      eventType,
+		ProjectID:      first.ProjectID,
+		BaseInfoID:     first.BaseInfoID,
+		TriggerTime:    first.TriggerTime,
+		SendTime:       time.Now().UnixMilli(),
+		TriggerPageURL: first.PageURL,
+	}
+	exists, err := s.events.ExistsEventID(eventMain.EventID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if err := s.events.CreateEvent(&eventMain); err != nil {
+		return err
+	}
+
+	sessionID := first.SessionID
+	if sessionID == "" {
+		sessionID = first.UserUUID
+	}
+
+	return s.events.CreateDetail(&model.FrustrationDetail{
+		EventID:      eventMain.ID,
+		SessionID:    sessionID,
+		PageURL:      first.PageURL,
+		ElementPath:  first.ElementPath,
+		ElementType:  first.ElementType,
+		InnerText:    first.InnerText,
+		ClickCount:   len(clicks),
+		FirstClickID: first.ID,
+		StartTime:    first.TriggerTime,
+		EndTime:      last.TriggerTime,
+	})
+}
+
+// GetFrustrations 获取引起挫败的元素排行
+func (s *EventService) GetFrustrations(projectIDStr, frustrationType, pageURL, startTimeStr, endTimeStr, limitStr string) (*FrustrationListResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = defaultFrustrationLimit
+	}
+
+	eventTypes := []string{model.EventTypeRageClick, model.EventTypeDeadClick}
+	switch frustrationType {
+	case "":
+	case "rage":
+		eventTypes = []string{model.EventTypeRageClick}
+	case "dead":
+		eventTypes = []string{model.EventTypeDeadClick}
+	default:
+		return nil, errors.New("无效的类型")
+	}
+
+	// 未指定开始时间时只统计最近一段时间，避免读取项目的全部挫败事件
+	timeRange := parseTimeRange(startTimeStr, endTimeStr)
+	if timeRange.StartTime == 0 {
+		end := timeRange.EndTime
+		if end == 0 {
+			end = time.Now().UnixMilli()
+		}
+		timeRange.StartTime = end - defaultStatsRange
+	}
+
+	// 页面先按 LIKE 模式在 SQL 中筛选，再按归一化页面和元素分组
+	pageURL = NormalizeURL(pageURL)
+	var pagePatterns []string
+	if pageURL != "" {
+		pagePatterns = pageURLPatterns(pageURL)
+	}
+	rows, err := s.behaviors.ListFrustrations(uint(projectID), eventTypes, timeRange, pagePatterns, maxFrustrationRows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*FrustrationItem)
+	sessionSets := make(map[string]map[string]bool)
+	var keys []string
+	for _, row := range rows {
+		page := NormalizeURL(row.PageURL)
+		if pageURL != "" && page != pageURL {
+			continue
+		}
+
+		key := page + "\x00" + row.ElementPath
+		item, ok := items[key]
+		if !ok {
+			item = &FrustrationItem{
+				PageURL:     page,
+				ElementPath: row.ElementPath,
+				ElementType: row.ElementType,
+				InnerText:   row.InnerText,
+				Sessions:    []string{},
+				LastSeen:    row.TriggerTime,
+			}
+			items[key] = item
+			sessionSets[key] = make(map[string]bool)
+			keys = append(keys, key)
+		}
+
+		if row.EventType == model.EventTypeRageClick {
+			item.RageClicks++
+		} else {
+			item.DeadClicks++
+		}
+		item.TotalClicks += row.ClickCount
+
+		if row.SessionID != "" && !sessionSets[key][row.SessionID] {
+			sessionSets[key][row.SessionID] = true
+			item.AffectedSessions++
+			if len(item.Sessions) < frustrationSessionLimit {
+				item.Sessions = append(item.Sessions, row.SessionID)
+			}
+		}
+	}
+
+	list := make([]FrustrationItem, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, *items[key])
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].AffectedSessions != list[j].AffectedSessions {
+			return list[i].AffectedSessions > list[j].AffectedSessions
+		}
+		return list[i].RageClicks+list[i].DeadClicks > list[j].RageClicks+list[j].DeadClicks
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	return &FrustrationListResponse{List: list}, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestDetectClickFrustrations(t *testing.T) {
+	fakes := newFakeRepositories()
+	base := int64(1700000000000)
+	click := func(id uint, offset int64, elementPath, elementType string) repository.PendingClick {
+		return repository.PendingClick{
+			ID:          id,
+			EventMainID: id,
+			ProjectID:   testProjectID,
+			TriggerTime: base + offset,
+			PageURL:     "https://example.com/cart",
+			ElementPath: elementPath,
+			ElementType: elementType,
+			SessionID:   "s1",
+		}
+	}
+	fakes.behaviors.pending = []repository.PendingClick{
+		// 同一元素 1 秒内连续点击 3 次为狂点
+		click(1, 0, "button#buy", "button"),
+		click(2, 300, "button#buy", "button"),
+		click(3, 600, "button#buy", "button"),
+		// 点击后有页面跳转，不是无响应点击
+		click(4, 5000, "a#next", "a"),
+		// 点击后没有响应
+		click(5, 8000, "div#card", "div"),
+		// 输入框点击本身没有可见反馈，不参与判定
+		click(6, 9000, "input#name", "input"),
+		// 没有会话信息的点击无法判定
+		{ID: 7, ProjectID: testProjectID, TriggerTime: base + 9500, ElementPath: "div#card"},
+	}
+	fakes.behaviors.reactions = map[string][]int64{model.EventTypeRoute: {base + 5400}}
+	service := fakes.eventService()
+
+	created, err := service.DetectClickFrustrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 2 || len(fakes.events.events) != 2 {
+		t.Fatalf("生成 %d 个挫败事件，期望 2", created)
+	}
+
+	details := make(map[string]*model.FrustrationDetail)
+	for i, event := range fakes.events.events {
+		details[event.EventType] = fakes.events.details[i].(*model.FrustrationDetail)
+	}
+	rage := details[model.EventTypeRageClick]
+	if rage == nil || rage.ClickCount != 3 || rage.FirstClickID != 1 || rage.StartTime != base || rage.EndTime != base+600 || rage.SessionID != "s1" {
+		t.Fatalf("狂点详情 %+v", rage)
+	}
+	dead := details[model.EventTypeDeadClick]
+	if dead == nil || dead.ElementPath != "div#card" || dead.FirstClickID != 5 {
+		t.Fatalf("无响应点击详情 %+v", dead)
+	}
+	if cursor := fakes.jobCursors.cursors[frustrationCursorName]; cursor != 7 {
+		t.Fatalf("检测游标 %d，期望 7", cursor)
+	}
+
+	// 已检测的点击不会重复检测
+	if created, err := service.DetectClickFrustrations(); err != nil || created != 0 {
+		t.Fatalf("重复检测生成 %d 个事件，错误 %v", created, err)
+	}
+}
+
+func TestDetectClickFrustrationsCountsRequestAsReaction(t *testing.T) {
+	fakes := newFakeRepositories()
+	base := int64(1700000000000)
+	fakes.behaviors.pending = []repository.PendingClick{{
+		ID:          1,
+		EventMainID: 1,
+		ProjectID:   testProjectID,
+		TriggerTime: base,
+		PageURL:     "https://example.com/cart",
+		ElementPath: "button#save",
+		ElementType: "button",
+		SessionID:   "s1",
+	}}
+	// 点击后只发出了接口请求，页面有响应
+	fakes.behaviors.reactions = map[string][]int64{
+		model.EventTypeRequest: {base + model.FrustrationSetting.DeadClickWindow - 1},
+	}
+
+	created, err := fakes.eventService().DetectClickFrustrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 0 || len(fakes.events.events) != 0 {
+		t.Fatalf("生成 %d 个挫败事件，期望 0", created)
+	}
+}
+
+func TestGetFrustrationsGroupsByPageAndElement(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.behaviors.frustrations = []repository.FrustrationRow{
+		{EventType: model.EventTypeRageClick, TriggerTime: 3000, SessionID: "s1", PageURL: "https://example.com/items/1", ElementPath: "button#buy", ClickCount: 4},
+		{EventType: model.EventTypeDeadClick, TriggerTime: 2000, SessionID: "s2", PageURL: "https://example.com/items/2", ElementPath: "button#buy", ClickCount: 1},
+		{EventType: model.EventTypeRageClick, TriggerTime: 1000, SessionID: "s1", PageURL: "https://example.com/items/1", ElementPath: "button#buy", ClickCount: 3},
+		{EventType: model.EventTypeDeadClick, TriggerTime: 1500, SessionID: "s3", PageURL: "https://example.com/about", ElementPath: "div#logo", ClickCount: 1},
+	}
+	service := fakes.eventService()
+
+	resp, err := service.GetFrustrations("1", "", "https://example.com/items/9", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fakes.behaviors.eventTypes) != 2 {
+		t.Fatalf("事件类型 %v", fakes.behaviors.eventTypes)
+	}
+	// 页面在 SQL 中按模式筛选，未指定时间时只读取最近一段时间
+	if len(fakes.behaviors.pagePatterns) == 0 || fakes.behaviors.timeRange.StartTime == 0 || fakes.behaviors.timeRange.EndTime != 0 {
+		t.Fatalf("页面模式 %v 时间范围 %+v", fakes.behaviors.pagePatterns, fakes.behaviors.timeRange)
+	}
+	if len(resp.List) != 1 {
+		t.Fatalf("返回 %d 个元素，期望 1", len(resp.List))
+	}
+	item := resp.List[0]
+	if item.PageURL != NormalizeURL("https://example.com/items/1") || item.RageClicks != 2 || item.DeadClicks != 1 ||
+		item.TotalClicks != 8 || item.AffectedSessions != 2 || item.LastSeen != 3000 {
+		t.Fatalf("挫败元素 %+v", item)
+	}
+
+	if _, err := service.GetFrustrations("1", "rage", "", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(fakes.behaviors.eventTypes) != 1 || fakes.behaviors.eventTypes[0] != model.EventTypeRageClick {
+		t.Fatalf("事件类型 %v", fakes.behaviors.eventTypes)
+	}
+	if fakes.behaviors.pagePatterns != nil {
+		t.Fatalf("未指定页面时不应筛选页面 %v", fakes.behaviors.pagePatterns)
+	}
+
+	if _, err := service.GetFrustrations("1", "", "", "", "1700000000000", ""); err != nil {
+		t.Fatal(err)
+	}
+	if want := (repository.TimeRange{StartTime: 1700000000000 - defaultStatsRange, EndTime: 1700000000000}); fakes.behaviors.timeRange != want {
+		t.Fatalf("时间范围 %+v，期望 %+v", fakes.behaviors.timeRange, want)
+	}
+
+	if _, err := service.GetFrustrations("1", "unknown", "", "", "", ""); err == nil {
+		t.Fatal("无效类型应返回错误")
+	}
+}
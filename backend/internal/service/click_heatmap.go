@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+const (
+	// 默认热力图列数
+	defaultHeatmapColumns = 50
+	// 最大热力图列数
+	maxHeatmapColumns = 200
+	// 单次聚合最多读取的点击数量和页面数量
+	maxHeatmapRows = 200000
+	// 页面列表数量
+	heatmapPageLimit = 20
+	// 热门元素数量
+	heatmapElementLimit = 20
+)
+
+// HeatmapResponse 点击热力图响应
+type HeatmapResponse struct {
+	PageURL     string           `json:"pageUrl"`
+	Columns     int              `json:"columns"`
+	Rows        int              `json:"rows"`
+	TotalClicks int64            `json:"totalClicks"`
+	MaxCount    int64            `json:"maxCount"`
+	Cells       []HeatmapCell    `json:"cells"`
+	TopElements []HeatmapElement `json:"topElements"`
+	Pages       []HeatmapPage    `json:"pages"`
+}
+
+// HeatmapCell 热力图网格单元，宽高均为视口宽度的 1/columns
+type HeatmapCell struct {
+	Col   int   `json:"col"`
+	Row   int   `json:"row"`
+	Count int64 `json:"count"`
+}
+
+// HeatmapElement 页面内点击最多的元素
+type HeatmapElement struct {
+	ElementPath string  `json:"elementPath"`
+	ElementType string  `json:"elementType"`
+	InnerText   string  `json:"innerText"`
+	Count       int64   `json:"count"`
+	Ratio       float64 `json:"ratio"`
+}
+
+// HeatmapPage 有点击数据的页面
+type HeatmapPage struct {
+	PageURL string `json:"pageUrl"`
+	Count   int64  `json:"count"`
+}
+
+// GetClickHeatmap 获取页面点击热力图
+func (s *EventService) GetClickHeatmap(projectIDStr, pageURL, startTimeStr, endTimeStr, columnsStr string) (*HeatmapResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	columns, err := strconv.Atoi(columnsStr)
+	if err != nil || columns < 1 {
+		columns = defaultHeatmapColumns
+	}
+	if columns > maxHeatmapColumns {
+		columns = maxHeatmapColumns
+	}
+
+	// 页面点击数先按原始URL在 SQL 中汇总，再按归一化后的页面合并
+	timeRange := parseTimeRange(startTimeStr, endTimeStr)
+	pageRows, err := s.behaviors.CountClicksByPage(uint(projectID), timeRange, maxHeatmapRows)
+	if err != nil {
+		return nil, err
+	}
+
+	pageCounts := make(map[string]int64)
+	for _, row := range pageRows {
+		pageCounts[NormalizeURL(row.PageURL)] += row.Count
+	}
+
+	pages := make([]HeatmapPage, 0, len(pageCounts))
+	for page, count := range pageCounts {
+		pages = append(pages, HeatmapPage{PageURL: page, Count: count})
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		if pages[i].Count != pages[j].Count {
+			return pages[i].Count > pages[j].Count
+		}
+		return pages[i].PageURL < pages[j].PageURL
+	})
+
+	// 未指定页面时默认展示点击最多的页面
+	pageURL = NormalizeURL(pageURL)
+	if pageURL == "" && len(pages) > 0 {
+		pageURL = pages[0].PageURL
+	}
+	if len(pages) > heatmapPageLimit {
+		pages = pages[:heatmapPageLimit]
+	}
+
+	// 只读取所选页面的点击，SQL 条件可能有误匹配，再按归一化后的页面精确过滤
+	var clicks []repository.HeatmapClick
+	if pageURL != "" {
+		rows, err := s.behaviors.ListHeatmapClicks(uint(projectID), timeRange, pageURLPatterns(pageURL), maxHeatmapRows)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if NormalizeURL(row.PageURL) == pageURL {
+				clicks = append(clicks, row)
+			}
+		}
+	}
+
+	resp := buildHeatmap(clicks, columns)
+	resp.PageURL = pageURL
+	resp.Pages = pages
+
+	return resp, nil
+}
+
+// 将点击汇总为网格和元素排行
+func buildHeatmap(clicks []repository.HeatmapClick, columns int) *HeatmapResponse {
+	resp := &HeatmapResponse{
+		Columns:     columns,
+		TotalClicks: int64(len(clicks)),
+		Cells:       []HeatmapCell{},
+		TopElements: []HeatmapElement{},
+	}
+
+	type cellKey struct{ col, row int }
+	cells := make(map[cellKey]int64)
+	elements := make(map[string]*HeatmapElement)
+
+	for _, click := range clicks {
+		if click.ElementPath != "" {
+			element, ok := elements[click.ElementPath]
+			if !ok {
+				element = &HeatmapElement{
+					ElementPath: click.ElementPath,
+					ElementType: click.ElementType,
+					InnerText:   click.InnerText,
+				}
+				elements[click.ElementPath] = element
+			}
+			element.Count++
+		}
+
+		// 没有坐标或视口信息的点击只参与元素排行
+		if click.ViewportWidth <= 0 || click.X < 0 || click.Y < 0 {
+			continue
+		}
+		col := click.X * columns / click.ViewportWidth
+		if col >= columns {
+			col = columns - 1
+		}
+		row := click.Y * columns / click.ViewportWidth
+		cells[cellKey{col: col, row: row}]++
+		if row+1 > resp.Rows {
+			resp.Rows = row + 1
+		}
+	}
+
+	for key, count := range cells {
+		resp.Cells = append(resp.Cells, HeatmapCell{Col: key.col, Row: key.row, Count: count})
+		if count > resp.MaxCount {
+			resp.MaxCount = count
+		}
+	}
+	sort.Slice(resp.Cells, func(i, j int) bool {
+		if resp.Cells[i].Row != resp.Cells[j].Row {
+			return resp.Cells[i].Row < resp.Cells[j].Row
+		}
+		return resp.Cells[i].Col < resp.Cells[j].Col
+	})
+
+	for _, element := range elements {
+		element.Ratio = float64(element.Count) / float64(resp.TotalClicks) * 100
+		resp.TopElements = append(resp.TopElements, *element)
+	}
+	sort.Slice(resp.TopElements, func(i, j int) bool {
+		if resp.TopElements[i].Count != resp.TopElements[j].Count {
+			return resp.TopElements[i].Count > resp.TopElements[j].Count
+		}
+		return resp.TopElements[i].ElementPath < resp.TopElements[j].ElementPath
+	})
+	if len(resp.TopElements) > heatmapElementLimit {
+		resp.TopElements = resp.TopElements[:heatmapElementLimit]
+	}
+
+	return resp
+}
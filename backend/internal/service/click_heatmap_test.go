@@ -0,0 +1,66 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetClickHeatmapDefaultsToTopPage(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.behaviors.pageCounts = []repository.PageCount{
+		{PageURL: "https://example.com/users/1", Count: 3},
+		{PageURL: "https://example.com/users/2?tab=a", Count: 2},
+		{PageURL: "https://example.com/about", Count: 4},
+	}
+	fakes.behaviors.heatmap = []repository.HeatmapClick{
+		{PageURL: "https://example.com/users/1", ElementPath: "button#buy", X: 50, Y: 10, ViewportWidth: 100},
+		{PageURL: "https://example.com/users/2", ElementPath: "button#buy", X: 99, Y: 10, ViewportWidth: 100},
+		{PageURL: "https://example.com/users/3", ElementPath: "a#more"},
+		// SQL 条件误匹配的其他页面
+		{PageURL: "https://example.com/users/1/edit", ElementPath: "input", X: 10, Y: 10, ViewportWidth: 100},
+	}
+
+	resp, err := fakes.eventService().GetClickHeatmap("1", "", "1700000001000", "1700000002000", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 页面按归一化后的路径合并，默认展示点击最多的页面
+	usersPage := NormalizeURL("https://example.com/users/1")
+	wantPages := []HeatmapPage{{PageURL: usersPage, Count: 5}, {PageURL: "/about", Count: 4}}
+	if resp.PageURL != usersPage || !reflect.DeepEqual(resp.Pages, wantPages) {
+		t.Fatalf("页面 %s，页面列表 %+v", resp.PageURL, resp.Pages)
+	}
+	if !reflect.DeepEqual(fakes.behaviors.pagePatterns, pageURLPatterns(usersPage)) {
+		t.Fatalf("页面匹配条件 %v", fakes.behaviors.pagePatterns)
+	}
+	if fakes.behaviors.timeRange != (repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}) {
+		t.Fatalf("时间范围 %+v", fakes.behaviors.timeRange)
+	}
+
+	// 没有坐标的点击只参与元素排行
+	if resp.TotalClicks != 3 || resp.Columns != 2 || resp.Rows != 1 || resp.MaxCount != 2 {
+		t.Fatalf("热力图 %+v", resp)
+	}
+	if !reflect.DeepEqual(resp.Cells, []HeatmapCell{{Col: 1, Row: 0, Count: 2}}) {
+		t.Fatalf("网格 %+v", resp.Cells)
+	}
+	if len(resp.TopElements) != 2 || resp.TopElements[0].ElementPath != "button#buy" || resp.TopElements[0].Count != 2 {
+		t.Fatalf("热门元素 %+v", resp.TopElements)
+	}
+}
+
+func TestGetClickHeatmapWithoutClicks(t *testing.T) {
+	fakes := newFakeRepositories()
+
+	resp, err := fakes.eventService().GetClickHeatmap("1", "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 没有点击时不查询点击明细
+	if resp.PageURL != "" || resp.TotalClicks != 0 || resp.Columns != defaultHeatmapColumns || fakes.behaviors.pagePatterns != nil {
+		t.Fatalf("热力图 %+v", resp)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+const (
+	// 日志消息的最大长度（字符）
+	maxLogMessageLength = 2000
+	// 日志参数序列化后的最大字节数，超出时截断参数列表
+	maxLogArgumentsSize = 4096
+)
+
+// 日志指纹中需要忽略的可变部分（数字、十六进制串）
+var logVariablePattern = regexp.MustCompile(`0x[0-9a-fA-F]+|\d+`)
+
+// LogListResponse 日志列表响应
+type LogListResponse struct {
+	Total       int64            `json:"total"`
+	List        []LogItem        `json:"list"`
+	LevelCounts map[string]int64 `json:"levelCounts"`
+}
+
+// LogItem 日志项
+type LogItem struct {
+	ID          uint   `json:"id"`
+	EventID     string `json:"eventId"`
+	Level       string `json:"level"`
+	Message     string `json:"message"`
+	Arguments   string `json:"arguments"`
+	Stack       string `json:"stack"`
+	PageURL     string `json:"pageUrl"`
+	TriggerTime int64  `json:"triggerTime"`
+}
+
+// LogFilter 日志筛选条件
+type LogFilter struct {
+	Level     string
+	Keyword   string
+	PageURL   string
+	StartTime string
+	EndTime   string
+}
+
+// 规范化日志级别，兼容 console.error、warning 等写法
+func normalizeLogLevel(level string) string {
+	level = strings.ToLower(strings.TrimPrefix(strings.ToLower(level), "console."))
+	level = strings.TrimPrefix(level, "console_")
+	switch level {
+	case "error", "fatal", "assert":
+		return model.LogLevelError
+	case "warn", "warning":
+		return model.LogLevelWarn
+	case "debug", "trace":
+		return model.LogLevelDebug
+	default:
+		return model.LogLevelInfo
+	}
+}
+
+// 从SDK日志事件处理
+func (s *EventService) processLogEventFromSDK(req *TrackRequest, eventID uint, projectID uint) error {
+	// 创建日志详情，未上报级别时使用事件类型
+	logDetail := model.LogDetail{
+		EventID: eventID,
+		Level:   normalizeLogLevel(req.Type),
+	}
+
+	// 从事件数据中提取日志信息
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
+		if level, ok := dataMap["level"].(string); ok && level != "" {
+			logDetail.Level = normalizeLogLevel(level)
+		}
+
+		// 提取参数，超出大小时从末尾丢弃
+		args, _ := dataMap["args"].([]interface{})
+		if args == nil {
+			args, _ = dataMap["arguments"].([]interface{})
+		}
+		for len(args) > 0 {
+			encoded, err := json.Marshal(args)
+			if err == nil && len(encoded) <= maxLogArgumentsSize {
+				logDetail.Arguments = string(encoded)
+				break
+			}
+			args = args[:len(args)-1]
+		}
+
+		// 提取消息，未上报时用参数拼接
+		if message, ok := dataMap["message"].(string); ok {
+			logDetail.Message = message
+		} else if len(args) > 0 {
+			parts := make([]string, 0, len(args))
+			for _, arg := range args {
+				parts = append(parts, fmt.Sprintf("%v", arg))
+			}
+			logDetail.Message = strings.Join(parts, " ")
+		}
+		logDetail.Message = truncateRunes(logDetail.Message, maxLogMessageLength)
+
+		if stack, ok := dataMap["stack"].(string); ok {
+			logDetail.Stack = stack
+		}
+	}
+
+	// 保存日志详情
+	if err := s.events.CreateDetail(&logDetail); err != nil {
+		return err
+	}
+
+	// 按配置将 console.error 提升为错误分组
+	if model.LogSetting.PromoteConsoleError && logDetail.Level == model.LogLevelError {
+		return s.promoteConsoleError(&logDetail, projectID)
+	}
+	return nil
+}
+
+// 将错误日志记录为错误，指纹忽略消息中的数字以便相似日志归为一组
+func (s *EventService) promoteConsoleError(logDetail *model.LogDetail, projectID uint) error {
+	sum := md5.Sum([]byte(model.ErrorTypeConsole + "|" + logVariablePattern.ReplaceAllString(logDetail.Message, "?")))
+	errorDetail := model.ErrorDetail{
+		EventID:      logDetail.EventID,
+		ErrorType:    model.ErrorTypeConsole,
+		ErrorMessage: logDetail.Message,
+		ErrorStack:   logDetail.Stack,
+		Severity:     model.ErrorSeverityError,
+		Fingerprint:  hex.EncodeToString(sum[:]),
+	}
+	if err := s.events.CreateDetail(&errorDetail); err != nil {
+		return err
+	}
+
+	_, err := s.errorGroups.Record(projectID, logDetail.EventID, &errorDetail)
+	return err
+}
+
+// GetLogs 搜索日志，关键词按空白拆分，每个词都需出现在消息或参数中
+func (s *EventService) GetLogs(projectIDStr, pageStr, pageSizeStr string, filter LogFilter) (*LogListResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	query := repository.LogQuery{
+		TimeRange: parseTimeRange(filter.StartTime, filter.EndTime),
+		ProjectID: uint(projectID),
+		Keywords:  strings.Fields(filter.Keyword),
+		PageURL:   filter.PageURL,
+	}
+
+	// 统计各级别数量，不受级别过滤影响
+	counts, err := s.events.CountLogLevels(query)
+	if err != nil {
+		return nil, err
+	}
+	levelCounts := map[string]int64{
+		model.LogLevelError: 0,
+		model.LogLevelWarn:  0,
+		model.LogLevelInfo:  0,
+		model.LogLevelDebug: 0,
+	}
+	for level, count := range counts {
+		levelCounts[level] = count
+	}
+
+	// 添加级别过滤
+	if filter.Level != "" {
+		query.Level = normalizeLogLevel(filter.Level)
+	}
+
+	// 获取分页数据
+	rows, total, err := s.events.ListLogs(query, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]LogItem, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, LogItem{
+			ID:          row.ID,
+			EventID:     row.EventID,
+			Level:       row.Level,
+			Message:     row.Message,
+			Arguments:   row.Arguments,
+			Stack:       row.Stack,
+			PageURL:     row.PageURL,
+			TriggerTime: row.TriggerTime,
+		})
+	}
+
+	return &LogListResponse{
+		Total:       total,
+		List:        list,
+		LevelCounts: levelCounts,
+	}, nil
+}
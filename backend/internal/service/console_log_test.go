@@ -0,0 +1,68 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestProcessLogEventMapsLevelAndArguments(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	req := trackRequest("log", "console", map[string]interface{}{
+		"level": "warning",
+		"args":  []interface{}{"slow", 42},
+		"stack": "at app.js:1",
+	})
+	if err := service.ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	detail := findDetail[model.LogDetail](fakes.events.details)
+	if detail == nil {
+		t.Fatal("未保存日志详情")
+	}
+	if detail.Level != model.LogLevelWarn || detail.Message != "slow 42" || detail.Arguments != `["slow",42]` || detail.Stack != "at app.js:1" {
+		t.Fatalf("日志详情 %+v", detail)
+	}
+}
+
+func TestGetLogsBuildsQueryAndFillsLevels(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.events.levels = map[string]int64{model.LogLevelWarn: 3}
+	fakes.events.logs = []repository.LogRow{{ID: 1, EventID: "e1", Level: model.LogLevelWarn, Message: "slow response", TriggerTime: 1500}}
+	fakes.events.logTotal = 3
+	service := fakes.eventService()
+
+	resp, err := service.GetLogs("1", "2", "1", LogFilter{
+		Level:     "warning",
+		Keyword:   " slow  response ",
+		PageURL:   "/home",
+		StartTime: "1700000001000",
+		EndTime:   "1700000002000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := repository.LogQuery{
+		TimeRange: repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000},
+		ProjectID: 1,
+		Level:     model.LogLevelWarn,
+		Keywords:  []string{"slow", "response"},
+		PageURL:   "/home",
+	}
+	if !reflect.DeepEqual(fakes.events.logQuery, want) {
+		t.Fatalf("查询条件 %+v，期望 %+v", fakes.events.logQuery, want)
+	}
+	if resp.Total != 3 || len(resp.List) != 1 || resp.List[0].Message != "slow response" {
+		t.Fatalf("日志列表 %+v", resp)
+	}
+	// 没有记录的级别补零
+	if len(resp.LevelCounts) != 4 || resp.LevelCounts[model.LogLevelWarn] != 3 || resp.LevelCounts[model.LogLevelError] != 0 {
+		t.Fatalf("级别统计 %v", resp.LevelCounts)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 分布统计维度
+const (
+	DimensionBrowser        = "browser"
+	DimensionBrowserVersion = "browser_version"
+	DimensionOS             = "os"
+	DimensionOSVersion      = "os_version"
+	DimensionDeviceType     = "device_type"
+	DimensionScreen         = "screen"
+	DimensionSDKVersion     = "sdk_version"
+	DimensionRelease        = "release"
+	DimensionRegion         = "region"
+	DimensionErrorType      = "error_type"
+)
+
+const (
+	// 默认返回的分布项数量，其余合并为 other
+	defaultDistributionLimit = 20
+	// 无法识别的值
+	unknownDistributionLabel = "unknown"
+	// 超出数量限制的合并项
+	otherDistributionLabel = "other"
+)
+
+// 各维度分组的字段，多字段时在 Go 中组合为标签
+var distributionFields = map[string][]string{
+	DimensionBrowser:        {"browser"},
+	DimensionBrowserVersion: {"browser", "browser_version"},
+	DimensionOS:             {"os"},
+	DimensionOSVersion:      {"os", "os_version"},
+	DimensionDeviceType:     {"device_type"},
+	DimensionScreen:         {"screen_width"},
+	DimensionSDKVersion:     {"sdk_version"},
+	DimensionRelease:        {"release"},
+	DimensionRegion:         {"region"},
+	DimensionErrorType:      {"error_type"},
+}
+
+// 是否为 distributionFields 中的字段
+func isDistributionField(field string) bool {
+	for _, fields := range distributionFields {
+		for _, name := range fields {
+			if name == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 屏幕宽度分段（像素），与常见的响应式断点一致
+var screenWidthBuckets = []struct {
+	max   int
+	label string
+}{
+	{768, "<768"},
+	{1024, "768-1023"},
+	{1440, "1024-1439"},
+	{1920, "1440-1919"},
+}
+
+// DistributionStatsResponse 分布统计响应，Labels 与 Values 一一对应
+type DistributionStatsResponse struct {
+	Dimension string             `json:"dimension"`
+	Total     int64              `json:"total"`
+	Labels    []string           `json:"labels"`
+	Values    []int64            `json:"values"`
+	Items     []DistributionItem `json:"items"`
+}
+
+// DistributionItem 分布项
+type DistributionItem struct {
+	Label      string  `json:"label"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// 组合分组列的值为分布标签
+func distributionLabel(dimension string, values []string) string {
+	switch dimension {
+	case DimensionBrowserVersion, DimensionOSVersion:
+		// 版本只保留主版本号
+		if values[0] == "" {
+			return unknownDistributionLabel
+		}
+		major := strings.SplitN(values[1], ".", 2)[0]
+		if major == "" {
+			return values[0]
+		}
+		return values[0] + " " + major
+	case DimensionScreen:
+		width, err := strconv.Atoi(values[0])
+		if err != nil || width <= 0 {
+			return unknownDistributionLabel
+		}
+		for _, bucket := range screenWidthBuckets {
+			if width < bucket.max {
+				return bucket.label
+			}
+		}
+		return ">=1920"
+	}
+
+	if values[0] == "" {
+		return unknownDistributionLabel
+	}
+	return values[0]
+}
+
+// GetDistribution 按维度统计事件分布，错误类型维度只统计错误事件
+func (s *EventService) GetDistribution(projectIDStr, dimension, limitStr string, filter EventFilter) (*DistributionStatsResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	fields, ok := distributionFields[dimension]
+	if !ok {
+		return nil, errors.New("不支持的统计维度")
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = defaultDistributionLimit
+	}
+
+	rows, err := s.store.Distribution(projectID, fields, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 合并相同标签，如同一主版本的不同小版本
+	counts := make(map[string]int64)
+	var total int64
+	for _, row := range rows {
+		counts[distributionLabel(dimension, row.Values)] += row.Count
+		total += row.Count
+	}
+
+	items := make([]DistributionItem, 0, len(counts))
+	for label, count := range counts {
+		items = append(items, DistributionItem{Label: label, Count: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Label < items[j].Label
+	})
+
+	// 超出数量限制的项合并为 other
+	if len(items) > limit {
+		other := DistributionItem{Label: otherDistributionLabel}
+		for _, item := range items[limit:] {
+			other.Count += item.Count
+		}
+		items = append(items[:limit], other)
+	}
+
+	resp := &DistributionStatsResponse{
+		Dimension: dimension,
+		Total:     total,
+		Labels:    make([]string, 0, len(items)),
+		Values:    make([]int64, 0, len(items)),
+		Items:     items,
+	}
+	for i := range items {
+		if total > 0 {
+			items[i].Percentage = math.Round(float64(items[i].Count)*10000/float64(total)) / 100
+		}
+		resp.Labels = append(resp.Labels, items[i].Label)
+		resp.Values = append(resp.Values, items[i].Count)
+	}
+	return resp, nil
+}
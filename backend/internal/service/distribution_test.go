@@ -0,0 +1,59 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetDistributionMergesLabels(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.events.fieldCounts = []repository.FieldCount{
+		{Values: []string{"Chrome", "120.0.1"}, Count: 5},
+		{Values: []string{"Chrome", "120.2"}, Count: 3},
+		{Values: []string{"Safari", "17.1"}, Count: 1},
+		{Values: []string{"", ""}, Count: 1},
+	}
+	service := fakes.eventService()
+
+	// 秒级时间戳按毫秒查询
+	resp, err := service.GetDistribution("1", DimensionBrowserVersion, "1", EventFilter{EventType: model.EventTypePV, StartTime: "1700000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fakes.events.fields, []string{"browser", "browser_version"}) {
+		t.Fatalf("分组字段 %v", fakes.events.fields)
+	}
+	if fakes.events.query.StartTime != 1700000001000 || !reflect.DeepEqual(fakes.events.query.EventTypes, []string{model.EventTypePV}) {
+		t.Fatalf("查询条件 %+v", fakes.events.query)
+	}
+
+	// 同一主版本合并，超出数量限制的项合并为 other
+	want := []DistributionItem{
+		{Label: "Chrome 120", Count: 8, Percentage: 80},
+		{Label: otherDistributionLabel, Count: 2, Percentage: 20},
+	}
+	if resp.Total != 10 || !reflect.DeepEqual(resp.Items, want) || !reflect.DeepEqual(resp.Labels, []string{"Chrome 120", otherDistributionLabel}) {
+		t.Fatalf("分布 %+v", resp)
+	}
+
+	if _, err := service.GetDistribution("1", "unknown", "", EventFilter{}); err == nil {
+		t.Fatal("不支持的维度应返回错误")
+	}
+}
+
+func TestDistributionLabelScreenBuckets(t *testing.T) {
+	tests := map[string]string{
+		"375":  "<768",
+		"1280": "1024-1439",
+		"2560": ">=1920",
+		"0":    unknownDistributionLabel,
+	}
+	for width, want := range tests {
+		if got := distributionLabel(DimensionScreen, []string{width}); got != want {
+			t.Errorf("宽度 %s 的分段为 %s，期望 %s", width, got, want)
+		}
+	}
+}
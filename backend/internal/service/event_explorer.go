@@ -0,0 +1,262 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+const (
+	// 默认每页事件数量
+	defaultEventPageSize = 20
+	// 每页事件数量上限
+	maxEventPageSize = 100
+)
+
+// EventListResponse 事件列表响应，使用游标分页
+type EventListResponse struct {
+	List       []EventItem `json:"list"`
+	NextCursor string      `json:"nextCursor"`
+	HasMore    bool        `json:"hasMore"`
+}
+
+// EventItem 事件项
+type EventItem struct {
+	ID             uint   `json:"id"`
+	EventID        string `json:"eventId"`
+	EventType      string `json:"eventType"`
+	TriggerTime    int64  `json:"triggerTime"`
+	TriggerPageURL string `json:"triggerPageUrl"`
+	Title          string `json:"title"`
+	TraceID        string `json:"traceId"`
+	UserID         string `json:"userId"`
+	UserUUID       string `json:"userUuid"`
+	SessionID      string `json:"sessionId"`
+	Browser        string `json:"browser"`
+	OS             string `json:"os"`
+	DeviceType     string `json:"deviceType"`
+	Release        string `json:"release"`
+	Environment    string `json:"environment"`
+	Region         string `json:"region"`
+}
+
+// EventFilter 事件筛选条件
+type EventFilter struct {
+	EventType  string // 多个类型以逗号分隔
+	StartTime  string
+	EndTime    string
+	PageURL    string
+	UserID     string
+	SessionID  string
+	Browser    string
+	OS         string
+	DeviceType string
+	Release    string
+}
+
+// EventDetailResponse 事件详情响应，Details 按详情模型名称返回完整的类型化数据
+type EventDetailResponse struct {
+	Event   model.EventMain        `json:"event"`
+	Details map[string]interface{} `json:"details"`
+}
+
+// EventStatsResponse 事件数量趋势响应，PreviousCounts 为上一周期对应时间桶的数量
+type EventStatsResponse struct {
+	Range          StatsRangeInfo `json:"range"`
+	Dates          []string       `json:"dates"`
+	Counts         []int64        `json:"counts"`
+	PreviousCounts []int64        `json:"previousCounts"`
+}
+
+// 编码分页游标，游标为最后一条事件的触发时间和ID
+func encodeEventCursor(triggerTime int64, id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", triggerTime, id)))
+}
+
+// 解码分页游标
+func decodeEventCursor(cursor string) (int64, uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, errors.New("无效的游标")
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.New("无效的游标")
+	}
+	triggerTime, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("无效的游标")
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("无效的游标")
+	}
+	return triggerTime, id, nil
+}
+
+// GetEventList 浏览原始事件，按触发时间倒序，使用游标分页避免深分页
+func (s *EventService) GetEventList(projectIDStr, cursor, pageSizeStr string, filter EventFilter) (*EventListResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = defaultEventPageSize
+	}
+	if pageSize > maxEventPageSize {
+		pageSize = maxEventPageSize
+	}
+
+	// 从游标位置继续查询
+	var after *repository.EventCursor
+	if cursor != "" {
+		triggerTime, id, err := decodeEventCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &repository.EventCursor{TriggerTime: triggerTime, ID: uint(id)}
+	}
+
+	// 多取一条用于判断是否还有下一页
+	rows, err := s.events.SearchEvents(filter.query(projectID), after, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]EventItem, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, EventItem{
+			ID:             row.ID,
+			EventID:        row.EventID,
+			EventType:      row.EventType,
+			TriggerTime:    row.TriggerTime,
+			TriggerPageURL: row.TriggerPageURL,
+			Title:          row.Title,
+			TraceID:        row.TraceID,
+			UserID:         row.UserID,
+			UserUUID:       row.UserUUID,
+			SessionID:      row.SessionID,
+			Browser:        row.Browser,
+			OS:             row.OS,
+			DeviceType:     row.DeviceType,
+			Release:        row.Release,
+			Environment:    row.Environment,
+			Region:         row.Region,
+		})
+	}
+
+	resp := &EventListResponse{List: list}
+	if len(list) > pageSize {
+		resp.List = list[:pageSize]
+		resp.HasMore = true
+		last := resp.List[pageSize-1]
+		resp.NextCursor = encodeEventCursor(last.TriggerTime, last.ID)
+	}
+	return resp, nil
+}
+
+// GetEventDetail 获取事件详情，包括基础信息和事件类型对应的全部详情数据
+func (s *EventService) GetEventDetail(idStr string) (*EventDetailResponse, error) {
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的事件ID")
+	}
+
+	eventMain, err := s.events.FindEvent(uint(id))
+	if err != nil {
+		return nil, errors.New("事件不存在")
+	}
+
+	// 按注册的详情模型查询详情表
+	details := make(map[string]interface{})
+	for _, detailModel := range model.GetEventDetailModels(eventMain.EventType) {
+		detail := detailModel.New()
+		found, err := s.events.FindDetail(eventMain.ID, detail)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			details[detailModel.Name] = detail
+		}
+	}
+
+	return &EventDetailResponse{
+		Event:   *eventMain,
+		Details: details,
+	}, nil
+}
+
+// GetEventStats 按时间桶统计事件数量，并返回上一周期对应时间桶的数量
+func (s *EventService) GetEventStats(projectIDStr string, filter EventFilter, query StatsQuery) (*EventStatsResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	r, err := parseStatsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	previous := r.previous()
+
+	counts, err := s.eventCountSeries(projectID, filter, r)
+	if err != nil {
+		return nil, err
+	}
+	previousCounts, err := s.eventCountSeries(projectID, filter, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	// 转换为响应格式，没有数据的时间桶补零，上一周期按顺序对应
+	buckets := r.buckets()
+	previousBuckets := previous.buckets()
+	resp := &EventStatsResponse{
+		Range:          r.info(),
+		Dates:          make([]string, 0, len(buckets)),
+		Counts:         make([]int64, 0, len(buckets)),
+		PreviousCounts: make([]int64, 0, len(buckets)),
+	}
+	for i, bucket := range buckets {
+		resp.Dates = append(resp.Dates, r.label(bucket))
+		resp.Counts = append(resp.Counts, counts[bucket])
+		var previousCount int64
+		if i < len(previousBuckets) {
+			previousCount = previousCounts[previousBuckets[i]]
+		}
+		resp.PreviousCounts = append(resp.PreviousCounts, previousCount)
+	}
+	return resp, nil
+}
+
+// 按时间桶统计原始事件数量，先按能被时间桶整除的时间片分组，再在 Go 中按时区合并
+func (s *EventService) eventCountSeries(projectID uint64, filter EventFilter, r *statsRange) (map[int64]int64, error) {
+	filter.StartTime = strconv.FormatInt(r.Start, 10)
+	filter.EndTime = strconv.FormatInt(r.End-1, 10)
+
+	slotSize := int64(time.Hour / time.Millisecond)
+	if r.Granularity == GranularityMinute {
+		slotSize = int64(time.Minute / time.Millisecond)
+	} else if !r.aligned(model.RollupHour) {
+		// 非整点时区的偏移都是 15 分钟的整数倍
+		slotSize = int64(15 * time.Minute / time.Millisecond)
+	}
+
+	slots, err := s.store.CountSlots(projectID, filter, slotSize)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[int64]int64)
+	for slot, count := range slots {
+		series[r.bucketStart(slot*slotSize)] += count
+	}
+	return series, nil
+}
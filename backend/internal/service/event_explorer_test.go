@@ -0,0 +1,119 @@
+package service
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetEventListPaginatesWithCursor(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.events.rows = []repository.EventRow{
+		{ID: 3, EventID: "e3", EventType: model.EventTypeClick, TriggerTime: 3000, Browser: "Chrome", Release: "1.0.0"},
+		{ID: 2, EventID: "e2", EventType: model.EventTypePV, TriggerTime: 2000},
+		{ID: 1, EventID: "e1", EventType: model.EventTypePV, TriggerTime: 1000},
+	}
+	service := fakes.eventService()
+
+	resp, err := service.GetEventList("1", "", "2", EventFilter{
+		EventType: "click, pv",
+		StartTime: "1700000001000",
+		EndTime:   "1700000005000",
+		PageURL:   "/home",
+		UserID:    "u1",
+		Release:   "1.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := repository.EventQuery{
+		TimeRange:  repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000005000},
+		ProjectID:  1,
+		EventTypes: []string{model.EventTypeClick, model.EventTypePV},
+		PageURL:    "/home",
+		UserID:     "u1",
+		Release:    "1.0.0",
+	}
+	if !reflect.DeepEqual(fakes.events.query, want) {
+		t.Fatalf("查询条件 %+v，期望 %+v", fakes.events.query, want)
+	}
+	if len(resp.List) != 2 || !resp.HasMore {
+		t.Fatalf("返回 %d 条 hasMore=%v", len(resp.List), resp.HasMore)
+	}
+	if item := resp.List[0]; item.EventID != "e3" || item.Browser != "Chrome" || item.Release != "1.0.0" {
+		t.Fatalf("事件映射错误 %+v", item)
+	}
+
+	// 下一页从上一页最后一条之后继续
+	if _, err := service.GetEventList("1", resp.NextCursor, "2", EventFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if cursor := fakes.events.cursor; cursor == nil || cursor.TriggerTime != 2000 || cursor.ID != 2 {
+		t.Fatalf("游标 %+v，期望 {2000 2}", cursor)
+	}
+
+	if _, err := service.GetEventList("1", "invalid", "2", EventFilter{}); err == nil {
+		t.Fatal("无效游标应返回错误")
+	}
+}
+
+func TestGetEventStatsMergesSlotsIntoBuckets(t *testing.T) {
+	fakes := newFakeRepositories()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	hour := int64(time.Hour / time.Millisecond)
+	fakes.events.slots = map[int64]int64{
+		day/hour + 1:  2,
+		day/hour + 30: 3,
+	}
+	service := fakes.eventService()
+
+	resp, err := service.GetEventStats("1", EventFilter{EventType: model.EventTypeError}, StatsQuery{
+		StartTime:   strconv.FormatInt(day, 10),
+		EndTime:     strconv.FormatInt(day+2*24*hour-1, 10),
+		Granularity: GranularityDay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp.Counts, []int64{2, 3}) {
+		t.Fatalf("按天统计 %v，期望 [2 3]", resp.Counts)
+	}
+	if got := fakes.events.query.EventTypes; !reflect.DeepEqual(got, []string{model.EventTypeError}) {
+		t.Fatalf("事件类型 %v", got)
+	}
+}
+
+func TestGetEventDetailLoadsRegisteredDetails(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	req := trackRequest("error", model.ErrorTypeHttp, map[string]interface{}{
+		"message": "Bad Gateway",
+		"url":     "https://api.example.com/orders",
+		"method":  "post",
+		"status":  502,
+	})
+	if err := service.ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := service.GetEventDetail(strconv.Itoa(int(fakes.events.events[0].ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Event.EventType != model.EventTypeError || len(resp.Details) != 2 {
+		t.Fatalf("事件详情 %+v", resp)
+	}
+	httpDetail, ok := resp.Details["httpError"].(*model.HttpErrorDetail)
+	if !ok || httpDetail.Method != "POST" || httpDetail.Status != 502 {
+		t.Fatalf("HTTP 错误详情 %+v", resp.Details["httpError"])
+	}
+
+	if _, err := service.GetEventDetail("99"); err == nil {
+		t.Fatal("不存在的事件应返回错误")
+	}
+}
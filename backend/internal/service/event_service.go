@@ -1,14 +1,15 @@
 package service
 
 import (
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
 )
 
 // TrackRequest SDK上报数据请求
@@ -33,6 +34,10 @@ type TrackRequest struct {
 	Release string `json:"release,omitempty"`
 	// 应用标识（从请求头或查询参数获取）
 	AppKey string `json:"appKey,omitempty"`
+	// 客户端信息，由服务端根据请求填充
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+	Region    string `json:"-"`
 }
 
 // ErrorListResponse 错误列表响应
@@ -78,12 +83,20 @@ type ErrorEventItem struct {
 	Browser      string `json:"browser"`
 	OS           string `json:"os"`
 	Device       string `json:"device"`
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	TraceURL     string `json:"traceUrl"`
+	// 错误发生前的用户操作，按时间正序
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs"`
 }
 
-// ErrorStatsResponse 错误统计响应
+// ErrorStatsResponse 错误统计响应，Previous 为上一周期的对比数据
 type ErrorStatsResponse struct {
-	Stats ErrorStatsData   `json:"stats"`
-	Trend []ErrorTrendItem `json:"trend"`
+	Range         StatsRangeInfo   `json:"range"`
+	Stats         ErrorStatsData   `json:"stats"`
+	Trend         []ErrorTrendItem `json:"trend"`
+	Previous      ErrorStatsData   `json:"previous"`
+	PreviousTrend []ErrorTrendItem `json:"previousTrend"`
 }
 
 // ErrorStatsData 错误统计数据
@@ -97,7 +110,7 @@ type ErrorStatsData struct {
 	OSDistribution      map[string]int64 `json:"osDistribution"`
 }
 
-// ErrorTrendItem 错误趋势项
+// ErrorTrendItem 错误趋势项，Date 为时间桶的起始时间
 type ErrorTrendItem struct {
 	Date  string `json:"date"`
 	Count int64  `json:"count"`
@@ -120,6 +133,7 @@ type PerformancePageItem struct {
 	FCP         int64   `json:"fcp"`
 	LCP         int64   `json:"lcp"`
 	FID         int64   `json:"fid"`
+	INP         int64   `json:"inp"`
 	CLS         float64 `json:"cls"`
 	TTFB        int64   `json:"ttfb"`
 	DomReady    int64   `json:"domReady"`
@@ -148,10 +162,15 @@ type ResourcePerformanceItem struct {
 	PageURL       string `json:"pageUrl"`
 }
 
-// PerformanceStatsResponse 性能统计响应
+// PerformanceStatsResponse 性能统计响应，Previous 为上一周期的对比数据
 type PerformanceStatsResponse struct {
-	Stats PerformanceStatsData   `json:"stats"`
-	Trend []PerformanceTrendItem `json:"trend"`
+	Range          StatsRangeInfo         `json:"range"`
+	Stats          PerformanceStatsData   `json:"stats"`
+	Trend          []PerformanceTrendItem `json:"trend"`
+	Vitals         []WebVitalMetric       `json:"vitals"`
+	Previous       PerformanceStatsData   `json:"previous"`
+	PreviousTrend  []PerformanceTrendItem `json:"previousTrend"`
+	PreviousVitals []WebVitalMetric       `json:"previousVitals"`
 }
 
 // PerformanceStatsData 性能统计数据
@@ -160,6 +179,7 @@ type PerformanceStatsData struct {
 	AvgFCP      int64   `json:"avgFCP"`
 	AvgLCP      int64   `json:"avgLCP"`
 	AvgFID      int64   `json:"avgFID"`
+	AvgINP      int64   `json:"avgINP"`
 	AvgCLS      float64 `json:"avgCLS"`
 	AvgTTFB     int64   `json:"avgTTFB"`
 	AvgDomReady int64   `json:"avgDomReady"`
@@ -213,11 +233,15 @@ type ClickItem struct {
 	PageURL     string `json:"pageUrl"`
 }
 
-// BehaviorStatsResponse 用户行为统计响应
+// BehaviorStatsResponse 用户行为统计响应，Previous 开头的字段为上一周期的对比数据
 type BehaviorStatsResponse struct {
-	PVStats    PVStatsData    `json:"pvStats"`
-	ClickStats ClickStatsData `json:"clickStats"`
-	PVTrend    []PVTrendItem  `json:"pvTrend"`
+	Range              StatsRangeInfo `json:"range"`
+	PVStats            PVStatsData    `json:"pvStats"`
+	ClickStats         ClickStatsData `json:"clickStats"`
+	PVTrend            []PVTrendItem  `json:"pvTrend"`
+	PreviousPVStats    PVStatsData    `json:"previousPvStats"`
+	PreviousClickStats ClickStatsData `json:"previousClickStats"`
+	PreviousPVTrend    []PVTrendItem  `json:"previousPvTrend"`
 }
 
 // PVStatsData 页面访问统计数据
@@ -245,7 +269,35 @@ type PVTrendItem struct {
 	UV   int64  `json:"uv"`
 }
 
-type EventService struct{}
+// EventService 事件服务，负责事件入库和查询。
+// 数据读写都通过仓储完成，需要扫描原始事件的统计查询由事件存储完成
+type EventService struct {
+	projects    repository.ProjectRepository
+	events      repository.EventRepository
+	errorGroups repository.ErrorGroupRepository
+	stats       repository.StatsRepository
+	behaviors   repository.BehaviorRepository
+	performance repository.PerformanceRepository
+	jobCursors  repository.JobCursorRepository
+	store       EventStore
+}
+
+// NewEventService 创建事件服务
+func NewEventService(repos *repository.Repositories, store EventStore) *EventService {
+	return &EventService{
+		projects:    repos.Projects,
+		events:      repos.Events,
+		errorGroups: repos.ErrorGroups,
+		stats:       repos.Stats,
+		behaviors:   repos.Behaviors,
+		performance: repos.Performance,
+		jobCursors:  repos.JobCursors,
+		store:       store,
+	}
+}
+
+// 同一次页面加载的性能数据合并窗口（秒）
+const performanceMergeWindow = 30 * 60
 
 // 生成事件ID
 func generateEventID() string {
@@ -254,17 +306,33 @@ func generateEventID() string {
 
 // ProcessTrackData 处理上报数据
 func (s *EventService) ProcessTrackData(req *TrackRequest) error {
+	// 成功的接口请求按配置采样，未命中采样的直接丢弃
+	if req.Category == "request" && !keepRequestSample(req) {
+		return nil
+	}
+
+	// 事件时间统一按毫秒保存，兼容秒级上报，缺失时使用服务端时间
+	if req.Timestamp <= 0 {
+		req.Timestamp = time.Now().UnixMilli()
+	}
+	req.Timestamp = timestampMillis(req.Timestamp)
+
 	// 创建基础信息
 	baseInfo := model.BaseInfo{
-		AppKey:   req.AppKey,
-		SendTime: req.Timestamp,
+		AppKey:    req.AppKey,
+		SendTime:  req.Timestamp,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		Region:    req.Region,
+		// 版本和环境用于按发布版本统计
+		Release:     req.Release,
+		Environment: req.Environment,
 		// 其他字段将从事件数据中提取
 	}
 
 	// 查找项目
-	var project model.Project
-	db := model.GetDB()
-	if err := db.Where("app_key = ?", req.AppKey).First(&project).Error; err != nil {
+	project, err := s.projects.FindByAppKey(req.AppKey)
+	if err != nil {
 		return errors.New("项目不存在")
 	}
 
@@ -281,11 +349,23 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 				baseInfo.PageURL = url
 			}
 
+			// 路由切换事件未上报URL时以目标路由为当前页面
+			if baseInfo.PageURL == "" {
+				if to, ok := dataMap["to"].(string); ok {
+					baseInfo.PageURL = to
+				}
+			}
+
 			// 提取用户ID
 			if userId, ok := dataMap["userId"].(string); ok {
 				baseInfo.UserID = userId
 			}
 
+			// 提取设备标识
+			if userUUID, ok := dataMap["userUuid"].(string); ok {
+				baseInfo.UserUUID = userUUID
+			}
+
 			// 提取会话ID
 			if sessionId, ok := dataMap["sessionId"].(string); ok {
 				baseInfo.SessionID = sessionId
@@ -295,11 +375,86 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 			if referrer, ok := dataMap["referrer"].(string); ok {
 				baseInfo.Referrer = referrer
 			}
+
+			// 提取页面加载ID
+			if pageId, ok := dataMap["pageId"].(string); ok {
+				baseInfo.PageID = pageId
+			}
+
+			// 提取地区，SDK上报的优先于请求头推断的
+			if region, ok := dataMap["region"].(string); ok && region != "" {
+				baseInfo.Region = region
+			}
+
+			// 提取浏览器和操作系统
+			if browser, ok := dataMap["browser"].(string); ok {
+				baseInfo.Browser = browser
+			}
+			if browserVersion, ok := dataMap["browserVersion"].(string); ok {
+				baseInfo.BrowserVersion = browserVersion
+			}
+			if os, ok := dataMap["os"].(string); ok {
+				baseInfo.OS = os
+			}
+			if osVersion, ok := dataMap["osVersion"].(string); ok {
+				baseInfo.OSVersion = osVersion
+			}
+
+			// 提取设备和屏幕信息
+			if deviceType, ok := dataMap["deviceType"].(string); ok {
+				baseInfo.DeviceType = deviceType
+			}
+			if device, ok := dataMap["device"].(string); ok {
+				baseInfo.Device = device
+			}
+			if screenWidth, ok := dataMap["screenWidth"].(float64); ok {
+				baseInfo.ScreenWidth = int(screenWidth)
+			}
+			if screenHeight, ok := dataMap["screenHeight"].(float64); ok {
+				baseInfo.ScreenHeight = int(screenHeight)
+			}
+			if sdkVersion, ok := dataMap["sdkVersion"].(string); ok {
+				baseInfo.SDKVersion = sdkVersion
+			}
+
+			// 提取扩展信息，如 Sentry 标签
+			if ext, ok := dataMap["ext"].(map[string]interface{}); ok {
+				if extJSON, err := json.Marshal(ext); err == nil {
+					baseInfo.Ext = string(extJSON)
+				}
+			}
+		}
+	}
+
+	// SDK未上报的浏览器、操作系统和设备类型从 User-Agent 解析
+	if baseInfo.Browser == "" || baseInfo.OS == "" || baseInfo.DeviceType == "" {
+		uaInfo := ParseUserAgent(baseInfo.UserAgent)
+		if baseInfo.Browser == "" {
+			baseInfo.Browser = uaInfo.Browser
+			baseInfo.BrowserVersion = uaInfo.BrowserVersion
+		}
+		if baseInfo.OS == "" {
+			baseInfo.OS = uaInfo.OS
+			baseInfo.OSVersion = uaInfo.OSVersion
+		}
+		if baseInfo.DeviceType == "" {
+			baseInfo.DeviceType = uaInfo.DeviceType
+		}
+	}
+
+	// 同一次页面加载的性能指标合并到已有记录
+	if req.Category == "performance" && (req.Type == "web_vitals" || req.Type == "page_load") {
+		merged, err := s.mergePerformancePageEvent(req, &baseInfo)
+		if err != nil {
+			return err
+		}
+		if merged {
+			return nil
 		}
 	}
 
 	// 保存基础信息
-	if err := db.Create(&baseInfo).Error; err != nil {
+	if err := s.events.CreateBaseInfo(&baseInfo); err != nil {
 		return err
 	}
 
@@ -313,6 +468,10 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 			eventType = model.EventTypePerformancePage
 		} else if req.Type == "resource_load" {
 			eventType = model.EventTypePerformanceResource
+		} else if req.Type == "long_task" || req.Type == "long_animation_frame" {
+			eventType = model.EventTypeLongTask
+		} else if req.Type == "interaction" || req.Type == "event_timing" {
+			eventType = model.EventTypeInteraction
 		} else {
 			eventType = model.EventTypePerformancePage // 默认
 		}
@@ -323,11 +482,17 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 			eventType = model.EventTypeClick
 		} else if req.Type == "stay_time" {
 			eventType = model.EventTypeDwell
+		} else if req.Type == "route_change" || req.Type == "route" {
+			eventType = model.EventTypeRoute
 		} else {
 			eventType = model.EventTypePV // 默认
 		}
 	case "custom":
 		eventType = model.EventTypeCustom
+	case "log":
+		eventType = model.EventTypeLog
+	case "request":
+		eventType = model.EventTypeRequest
 	case "system":
 		if req.Type == "batch_report" {
 			// 处理批量上报
@@ -340,6 +505,16 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 				for _, eventData := range batchData.Events {
 					var batchEvent TrackRequest
 					if err := json.Unmarshal(eventData, &batchEvent); err == nil {
+						batchEvent.IP = req.IP
+						batchEvent.UserAgent = req.UserAgent
+						batchEvent.Region = req.Region
+						// 子事件未单独上报版本和环境时沿用批量请求的
+						if batchEvent.Release == "" {
+							batchEvent.Release = req.Release
+						}
+						if batchEvent.Environment == "" {
+							batchEvent.Environment = req.Environment
+						}
 						// 递归处理每个事件
 						if err := s.ProcessTrackData(&batchEvent); err != nil {
 							// 记录错误但继续处理
@@ -363,13 +538,16 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 		ProjectID:      project.ID,
 		BaseInfoID:     baseInfo.ID,
 		TriggerTime:    req.Timestamp,
-		SendTime:       time.Now().Unix(),
+		SendTime:       time.Now().UnixMilli(),
 		TriggerPageURL: baseInfo.PageURL,
 		Title:          "",
 		Referer:        baseInfo.Referrer,
 	}
 
-	if err := db.Create(&eventMain).Error; err != nil {
+	// 提取链路信息，用于关联后端追踪
+	eventMain.TraceID, eventMain.SpanID = extractTraceContext(req.Data)
+
+	if err := s.events.CreateEvent(&eventMain); err != nil {
 		return err
 	}
 
@@ -385,8 +563,18 @@ func (s *EventService) ProcessTrackData(req *TrackRequest) error {
 		return s.processPVEventFromSDK(req, eventMain.ID)
 	case model.EventTypeClick:
 		return s.processClickEventFromSDK(req, eventMain.ID)
+	case model.EventTypeLongTask:
+		return s.processLongTaskEventFromSDK(req, eventMain.ID)
+	case model.EventTypeInteraction:
+		return s.processInteractionEventFromSDK(req, eventMain.ID)
+	case model.EventTypeLog:
+		return s.processLogEventFromSDK(req, eventMain.ID, project.ID)
 	case model.EventTypeDwell:
 		return s.processDwellEventFromSDK(req, eventMain.ID)
+	case model.EventTypeRequest:
+		return s.processRequestEventFromSDK(req, eventMain.ID)
+	case model.EventTypeRoute:
+		return s.processRouteEventFromSDK(req, &eventMain, baseInfo.SessionID)
 	case model.EventTypeCustom:
 		return s.processCustomEventFromSDK(req, eventMain.ID)
 	default:
@@ -402,21 +590,12 @@ func (s *EventService) processErrorEvent(detail json.RawMessage, eventID uint, p
 	}
 
 	errorDetail.EventID = eventID
-	db := model.GetDB()
-	if err := db.Create(&errorDetail).Error; err != nil {
+	if err := s.events.CreateDetail(&errorDetail); err != nil {
 		return err
 	}
 
 	// 创建或更新错误分组
-	_, err := model.CreateOrUpdateErrorGroup(
-		errorDetail.Fingerprint,
-		errorDetail.ErrorType,
-		errorDetail.ErrorMessage,
-		projectID,
-		eventID,
-		errorDetail.Severity,
-		errorDetail.SubType,
-	)
+	_, err := s.errorGroups.Record(projectID, eventID, &errorDetail)
 	return err
 }
 
@@ -428,8 +607,7 @@ func (s *EventService) processPerformancePageEvent(detail json.RawMessage, event
 	}
 
 	perfDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&perfDetail).Error
+	return s.events.CreateDetail(&perfDetail)
 }
 
 // 处理性能资源事件
@@ -440,8 +618,7 @@ func (s *EventService) processPerformanceResourceEvent(detail json.RawMessage, e
 	}
 
 	resourceDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&resourceDetail).Error
+	return s.events.CreateDetail(&resourceDetail)
 }
 
 // 处理页面访问事件
@@ -452,8 +629,7 @@ func (s *EventService) processPVEvent(detail json.RawMessage, eventID uint) erro
 	}
 
 	pvDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&pvDetail).Error
+	return s.events.CreateDetail(&pvDetail)
 }
 
 // 处理点击事件
@@ -464,8 +640,7 @@ func (s *EventService) processClickEvent(detail json.RawMessage, eventID uint) e
 	}
 
 	clickDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&clickDetail).Error
+	return s.events.CreateDetail(&clickDetail)
 }
 
 // 处理停留事件
@@ -476,8 +651,7 @@ func (s *EventService) processDwellEvent(detail json.RawMessage, eventID uint) e
 	}
 
 	dwellDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&dwellDetail).Error
+	return s.events.CreateDetail(&dwellDetail)
 }
 
 // 处理曝光事件
@@ -488,8 +662,7 @@ func (s *EventService) processIntersectionEvent(detail json.RawMessage, eventID
 	}
 
 	intersectionDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&intersectionDetail).Error
+	return s.events.CreateDetail(&intersectionDetail)
 }
 
 // 处理自定义事件
@@ -500,8 +673,7 @@ func (s *EventService) processCustomEvent(detail json.RawMessage, eventID uint)
 	}
 
 	customDetail.EventID = eventID
-	db := model.GetDB()
-	return db.Create(&customDetail).Error
+	return s.events.CreateDetail(&customDetail)
 }
 
 // 从SDK错误事件处理
@@ -547,27 +719,76 @@ func (s *EventService) processErrorEventFromSDK(req *TrackRequest, eventID uint,
 		if componentName, ok := dataMap["componentName"].(string); ok {
 			errorDetail.ComponentName = componentName
 		}
+
+		// 提取上下文和面包屑
+		context, _ := dataMap["context"].(map[string]interface{})
+		if breadcrumbs, ok := dataMap["breadcrumbs"].([]interface{}); ok && len(breadcrumbs) > 0 {
+			if context == nil {
+				context = make(map[string]interface{})
+			}
+			context["breadcrumbs"] = normalizeBreadcrumbs(breadcrumbs)
+		}
+		if len(context) > 0 {
+			if contextJSON, err := json.Marshal(context); err == nil {
+				errorDetail.Context = string(contextJSON)
+			}
+		}
 	}
 
 	// 保存错误详情
-	db := model.GetDB()
-	if err := db.Create(&errorDetail).Error; err != nil {
+	if err := s.events.CreateDetail(&errorDetail); err != nil {
 		return err
 	}
 
+	// HTTP 错误额外保存请求信息
+	if req.Type == model.ErrorTypeHttp {
+		if err := s.processHttpErrorDetailFromSDK(req, eventID, &errorDetail); err != nil {
+			return err
+		}
+	}
+
 	// 创建或更新错误分组
-	_, err := model.CreateOrUpdateErrorGroup(
-		errorDetail.Fingerprint,
-		errorDetail.ErrorType,
-		errorDetail.ErrorMessage,
-		projectID,
-		eventID,
-		errorDetail.Severity,
-		errorDetail.SubType,
-	)
+	_, err := s.errorGroups.Record(projectID, eventID, &errorDetail)
 	return err
 }
 
+// 从SDK HTTP 错误事件中提取请求信息
+func (s *EventService) processHttpErrorDetailFromSDK(req *TrackRequest, eventID uint, errorDetail *model.ErrorDetail) error {
+	httpDetail := model.HttpErrorDetail{
+		EventID:      eventID,
+		ErrorType:    errorDetail.SubType,
+		ErrorMessage: errorDetail.ErrorMessage,
+	}
+	httpDetail.TraceID, _ = extractTraceContext(req.Data)
+
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
+		if url, ok := dataMap["url"].(string); ok {
+			httpDetail.URL = url
+		}
+		if method, ok := dataMap["method"].(string); ok {
+			httpDetail.Method = strings.ToUpper(method)
+		}
+		if status, ok := firstNumber(dataMap, "status"); ok {
+			httpDetail.Status = int(status)
+		}
+		if statusText, ok := dataMap["statusText"].(string); ok {
+			httpDetail.StatusText = statusText
+		}
+		if duration, ok := firstNumber(dataMap, "duration"); ok {
+			httpDetail.Duration = int64(duration)
+		}
+		if requestData, ok := dataMap["requestData"].(string); ok {
+			httpDetail.RequestData = requestData
+		}
+		if responseData, ok := dataMap["responseData"].(string); ok {
+			httpDetail.ResponseData = responseData
+		}
+	}
+
+	return s.events.CreateDetail(&httpDetail)
+}
+
 // 从SDK性能页面事件处理
 func (s *EventService) processPerformancePageEventFromSDK(req *TrackRequest, eventID uint) error {
 	// 创建性能页面详情
@@ -576,66 +797,159 @@ func (s *EventService) processPerformancePageEventFromSDK(req *TrackRequest, eve
 	}
 
 	// 从事件数据中提取性能信息
+	applyPerformancePageData(req, &perfDetail)
+
+	// 保存性能页面详情
+	return s.events.CreateDetail(&perfDetail)
+}
+
+// 合并同一次页面加载的性能数据，返回是否已合并到已有记录
+func (s *EventService) mergePerformancePageEvent(req *TrackRequest, baseInfo *model.BaseInfo) (bool, error) {
+	if baseInfo.PageID == "" && baseInfo.SessionID == "" {
+		return false, nil
+	}
+
+	window := int64(performanceMergeWindow) * 1000
+
+	match := repository.PerformancePageMatch{
+		ProjectID: baseInfo.ProjectID,
+		From:      req.Timestamp - window,
+		To:        req.Timestamp + window,
+		PageID:    baseInfo.PageID,
+	}
+	if baseInfo.PageID == "" {
+		// 没有页面加载ID时按会话和页面匹配，只合并到尚未上报该指标的记录，避免把多次加载合成一条
+		column := performancePageMetricColumn(req)
+		if column == "" {
+			return false, nil
+		}
+		match.SessionID = baseInfo.SessionID
+		match.PageURL = baseInfo.PageURL
+		match.MetricColumn = column
+	}
+
+	perfDetail, err := s.events.FindPerformancePage(match)
+	if err != nil || perfDetail == nil {
+		return false, err
+	}
+
+	applyPerformancePageData(req, perfDetail)
+	return true, s.events.SaveDetail(perfDetail)
+}
+
+// 上报数据对应的性能指标列
+func performancePageMetricColumn(req *TrackRequest) string {
+	if req.Type == "page_load" {
+		return "load"
+	}
+
+	var metric struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(req.Data, &metric); err != nil {
+		return ""
+	}
+	switch metric.Name {
+	case "FID":
+		// 按 GORM 命名规则 FID 字段为 f_id
+		return "f_id"
+	case "FP", "FCP", "LCP", "INP", "CLS", "TTFB":
+		return strings.ToLower(metric.Name)
+	}
+	return ""
+}
+
+// 将SDK上报的性能数据写入性能页面详情，只覆盖本次上报的字段
+func applyPerformancePageData(req *TrackRequest, perfDetail *model.PerformancePageDetail) {
 	var dataMap map[string]interface{}
-	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
-		// Web Vitals事件
-		if req.Type == "web_vitals" {
-			// 提取指标名称和值
-			if name, ok := dataMap["name"].(string); ok {
-				switch name {
-				case "FP":
-					if value, ok := dataMap["value"].(float64); ok {
-						perfDetail.FP = int64(value)
-					}
-				case "FCP":
-					if value, ok := dataMap["value"].(float64); ok {
-						perfDetail.FCP = int64(value)
-					}
-				case "LCP":
-					if value, ok := dataMap["value"].(float64); ok {
-						perfDetail.LCP = int64(value)
-					}
-				case "FID":
-					if value, ok := dataMap["value"].(float64); ok {
-						perfDetail.FID = int64(value)
-					}
-				case "CLS":
-					if value, ok := dataMap["value"].(float64); ok {
-						perfDetail.CLS = value
-					}
-				case "TTFB":
-					if value, ok := dataMap["value"].(float64); ok {
-						perfDetail.TTFB = int64(value)
-					}
-				}
-			}
-		} else if req.Type == "page_load" {
-			// 页面加载事件
-			// 提取导航计时信息
-			if paintTiming, ok := dataMap["paintTiming"].(map[string]interface{}); ok {
-				if fp, ok := paintTiming["FP"].(float64); ok {
-					perfDetail.FP = int64(fp)
-				}
-				if fcp, ok := paintTiming["FCP"].(float64); ok {
-					perfDetail.FCP = int64(fcp)
-				}
-			}
+	if err := json.Unmarshal(req.Data, &dataMap); err != nil {
+		return
+	}
 
-			// 提取加载时间
-			if loadTime, ok := dataMap["loadTime"].(float64); ok {
-				perfDetail.Load = int64(loadTime)
-			}
+	// Web Vitals事件，每次只上报一个指标
+	if req.Type == "web_vitals" {
+		name, _ := dataMap["name"].(string)
+		value, ok := dataMap["value"].(float64)
+		if !ok || value < 0 {
+			return
+		}
+		switch name {
+		case "FP":
+			perfDetail.FP = millisValue(value)
+		case "FCP":
+			perfDetail.FCP = millisValue(value)
+		case "LCP":
+			perfDetail.LCP = millisValue(value)
+		case "FID":
+			perfDetail.FID = millisValue(value)
+		case "INP":
+			perfDetail.INP = millisValue(value)
+		case "CLS":
+			perfDetail.CLS = &value
+		case "TTFB":
+			perfDetail.TTFB = millisValue(value)
+		}
+		return
+	}
 
-			// 提取DOM内容加载时间
-			if domContentLoadedTime, ok := dataMap["domContentLoadedTime"].(float64); ok {
-				perfDetail.DomReady = int64(domContentLoadedTime)
-			}
+	if req.Type != "page_load" {
+		return
+	}
+
+	// 页面加载事件，计时为 0 表示浏览器未采集，保持未上报
+	// 提取绘制计时信息
+	if paintTiming, ok := dataMap["paintTiming"].(map[string]interface{}); ok {
+		if fp, ok := paintTiming["FP"].(float64); ok && fp > 0 {
+			perfDetail.FP = millisValue(fp)
+		}
+		if fcp, ok := paintTiming["FCP"].(float64); ok && fcp > 0 {
+			perfDetail.FCP = millisValue(fcp)
 		}
 	}
 
-	// 保存性能页面详情
-	db := model.GetDB()
-	return db.Create(&perfDetail).Error
+	// 提取加载时间
+	if loadTime, ok := dataMap["loadTime"].(float64); ok && loadTime > 0 {
+		perfDetail.Load = millisValue(loadTime)
+	}
+
+	// 提取DOM内容加载时间
+	if domContentLoadedTime, ok := dataMap["domContentLoadedTime"].(float64); ok && domContentLoadedTime > 0 {
+		perfDetail.DomReady = millisValue(domContentLoadedTime)
+	}
+
+	// 提取导航计时信息，兼容嵌套和平铺两种结构
+	timing := dataMap
+	if navigationTiming, ok := dataMap["navigationTiming"].(map[string]interface{}); ok {
+		timing = navigationTiming
+	}
+	timingFields := []struct {
+		target *int64
+		keys   []string
+	}{
+		{&perfDetail.DNS, []string{"dns", "dnsTime"}},
+		{&perfDetail.TCP, []string{"tcp", "tcpTime"}},
+		{&perfDetail.SSL, []string{"ssl", "sslTime"}},
+		{&perfDetail.FirstByte, []string{"firstByte", "fb"}},
+		{&perfDetail.Trans, []string{"trans", "transTime"}},
+		{&perfDetail.DomParse, []string{"domParse", "domParseTime"}},
+		{&perfDetail.ResourceLoad, []string{"res", "resourceLoad"}},
+		{&perfDetail.DomContentLoad, []string{"domContentLoad", "dcl"}},
+		{&perfDetail.FirstScreenTime, []string{"firstScreenTime", "fmp"}},
+	}
+	for _, field := range timingFields {
+		if value, ok := firstNumber(timing, field.keys...); ok {
+			*field.target = int64(value)
+		}
+	}
+	if ttfb, ok := firstNumber(timing, "ttfb"); ok && ttfb > 0 {
+		perfDetail.TTFB = millisValue(ttfb)
+	}
+}
+
+// 将上报的指标值转换为整数毫秒，用于可为空的性能指标
+func millisValue(value float64) *int64 {
+	millis := int64(value)
+	return &millis
 }
 
 // 从SDK性能资源事件处理
@@ -675,11 +989,49 @@ func (s *EventService) processPerformanceResourceEventFromSDK(req *TrackRequest,
 		if decodedBodySize, ok := dataMap["decodedBodySize"].(float64); ok {
 			resourceDetail.DecodedBodySize = int64(decodedBodySize)
 		}
+
+		// 提取编码体大小
+		if encodedBodySize, ok := dataMap["encodedBodySize"].(float64); ok {
+			resourceDetail.EncodedBodySize = int64(encodedBodySize)
+		}
+
+		// 提取开始时间
+		if startTime, ok := dataMap["startTime"].(float64); ok {
+			resourceDetail.StartTime = int64(startTime)
+		}
+
+		// 提取响应状态
+		if status, ok := dataMap["responseStatus"]; ok && status != nil {
+			resourceDetail.ResponseStatus = fmt.Sprintf("%v", status)
+		}
+
+		// 提取各阶段耗时
+		timingFields := []struct {
+			target *int64
+			keys   []string
+		}{
+			{&resourceDetail.DNSTime, []string{"dnsTime", "dns"}},
+			{&resourceDetail.TCPTime, []string{"tcpTime", "tcp"}},
+			{&resourceDetail.SSLTime, []string{"sslTime", "ssl"}},
+			{&resourceDetail.TTFB, []string{"ttfb"}},
+			{&resourceDetail.DownloadTime, []string{"downloadTime", "contentDownload"}},
+		}
+		for _, field := range timingFields {
+			if value, ok := firstNumber(dataMap, field.keys...); ok {
+				*field.target = int64(value)
+			}
+		}
+
+		// 提取缓存命中，未上报时根据传输大小推断：有内容但没有网络传输即为命中缓存
+		if fromCache, ok := dataMap["fromCache"].(bool); ok {
+			resourceDetail.FromCache = fromCache
+		} else {
+			resourceDetail.FromCache = resourceDetail.TransferSize == 0 && resourceDetail.DecodedBodySize > 0
+		}
 	}
 
 	// 保存性能资源详情
-	db := model.GetDB()
-	return db.Create(&resourceDetail).Error
+	return s.events.CreateDetail(&resourceDetail)
 }
 
 // 从SDK页面访问事件处理
@@ -716,8 +1068,7 @@ func (s *EventService) processPVEventFromSDK(req *TrackRequest, eventID uint) er
 	}
 
 	// 保存页面访问详情
-	db := model.GetDB()
-	return db.Create(&pvDetail).Error
+	return s.events.CreateDetail(&pvDetail)
 }
 
 // 从SDK点击事件处理
@@ -752,11 +1103,163 @@ func (s *EventService) processClickEventFromSDK(req *TrackRequest, eventID uint)
 		if innerText, ok := dataMap["innerText"].(string); ok {
 			clickDetail.InnerText = innerText
 		}
+
+		// 提取点击坐标，优先使用页面坐标
+		if x, ok := firstNumber(dataMap, "x", "pageX", "clientX"); ok {
+			clickDetail.X = int(x)
+		}
+		if y, ok := firstNumber(dataMap, "y", "pageY", "clientY"); ok {
+			clickDetail.Y = int(y)
+		}
+
+		// 提取视口尺寸
+		if width, ok := firstNumber(dataMap, "viewportWidth", "innerWidth"); ok {
+			clickDetail.ViewportWidth = int(width)
+		}
+		if height, ok := firstNumber(dataMap, "viewportHeight", "innerHeight"); ok {
+			clickDetail.ViewportHeight = int(height)
+		}
 	}
 
 	// 保存点击详情
-	db := model.GetDB()
-	return db.Create(&clickDetail).Error
+	return s.events.CreateDetail(&clickDetail)
+}
+
+// 从SDK长任务事件处理
+func (s *EventService) processLongTaskEventFromSDK(req *TrackRequest, eventID uint) error {
+	// 创建长任务详情
+	longTaskDetail := model.LongTaskDetail{
+		EventID: eventID,
+		Kind:    model.LongTaskKindTask,
+	}
+	if req.Type == "long_animation_frame" {
+		longTaskDetail.Kind = model.LongTaskKindAnimationFrame
+	}
+
+	// 从事件数据中提取长任务信息
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
+		if startTime, ok := firstNumber(dataMap, "startTime"); ok {
+			longTaskDetail.StartTime = int64(startTime)
+		}
+		if duration, ok := firstNumber(dataMap, "duration"); ok {
+			longTaskDetail.Duration = int64(duration)
+		}
+
+		// 阻塞时长：未上报时按超过 50ms 的部分计算
+		if blocking, ok := firstNumber(dataMap, "blockingDuration"); ok {
+			longTaskDetail.BlockingDuration = int64(blocking)
+		} else if longTaskDetail.Duration > 50 {
+			longTaskDetail.BlockingDuration = longTaskDetail.Duration - 50
+		}
+
+		// 长动画帧的脚本归因，取耗时最长的脚本
+		if scripts, ok := dataMap["scripts"].([]interface{}); ok {
+			for _, item := range scripts {
+				script, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				duration, _ := firstNumber(script, "duration")
+				if longTaskDetail.ScriptURL != "" && int64(duration) <= longTaskDetail.ScriptDuration {
+					continue
+				}
+				longTaskDetail.ScriptURL, _ = script["sourceURL"].(string)
+				longTaskDetail.ScriptFunction, _ = script["sourceFunctionName"].(string)
+				longTaskDetail.ScriptInvoker, _ = script["invoker"].(string)
+				longTaskDetail.ScriptDuration = int64(duration)
+			}
+		}
+
+		// 长任务的归因只能定位到容器（iframe 等）
+		if attribution, ok := dataMap["attribution"].([]interface{}); ok && len(attribution) > 0 && longTaskDetail.ScriptURL == "" {
+			if item, ok := attribution[0].(map[string]interface{}); ok {
+				longTaskDetail.ScriptURL, _ = item["containerSrc"].(string)
+				longTaskDetail.ScriptInvoker, _ = item["containerName"].(string)
+			}
+		}
+
+		// SDK 已整理好的归因字段
+		if scriptURL, ok := dataMap["scriptUrl"].(string); ok && longTaskDetail.ScriptURL == "" {
+			longTaskDetail.ScriptURL = scriptURL
+		}
+		if functionName, ok := dataMap["functionName"].(string); ok && longTaskDetail.ScriptFunction == "" {
+			longTaskDetail.ScriptFunction = functionName
+		}
+	}
+
+	// 保存长任务详情
+	return s.events.CreateDetail(&longTaskDetail)
+}
+
+// 从SDK交互事件处理
+func (s *EventService) processInteractionEventFromSDK(req *TrackRequest, eventID uint) error {
+	// 创建交互详情
+	interactionDetail := model.InteractionDetail{
+		EventID: eventID,
+	}
+
+	// 从事件数据中提取交互信息
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
+		// 提取交互类型
+		if eventType, ok := dataMap["eventType"].(string); ok {
+			interactionDetail.InteractionType = eventType
+		} else if name, ok := dataMap["name"].(string); ok {
+			interactionDetail.InteractionType = name
+		}
+
+		// 提取目标元素，与点击事件保持一致的路径格式
+		if path, ok := dataMap["path"].([]interface{}); ok && len(path) > 0 {
+			parts := make([]string, 0, len(path))
+			for _, p := range path {
+				parts = append(parts, fmt.Sprintf("%v", p))
+			}
+			interactionDetail.ElementPath = strings.Join(parts, " > ")
+		} else if target, ok := dataMap["target"].(string); ok {
+			interactionDetail.ElementPath = target
+		}
+		if tagName, ok := dataMap["tagName"].(string); ok {
+			interactionDetail.ElementType = tagName
+		}
+
+		startTime, _ := firstNumber(dataMap, "startTime")
+		duration, _ := firstNumber(dataMap, "duration")
+		interactionDetail.StartTime = int64(startTime)
+		interactionDetail.Duration = int64(duration)
+
+		// 优先使用SDK计算好的分段耗时，否则根据 Event Timing 的时间点计算
+		processingStart, hasStart := firstNumber(dataMap, "processingStart")
+		processingEnd, hasEnd := firstNumber(dataMap, "processingEnd")
+		if inputDelay, ok := firstNumber(dataMap, "inputDelay"); ok {
+			interactionDetail.InputDelay = int64(inputDelay)
+		} else if hasStart {
+			interactionDetail.InputDelay = int64(processingStart - startTime)
+		}
+		if processingTime, ok := firstNumber(dataMap, "processingTime", "processingDuration"); ok {
+			interactionDetail.ProcessingTime = int64(processingTime)
+		} else if hasStart && hasEnd {
+			interactionDetail.ProcessingTime = int64(processingEnd - processingStart)
+		}
+		if presentationDelay, ok := firstNumber(dataMap, "presentationDelay"); ok {
+			interactionDetail.PresentationDelay = int64(presentationDelay)
+		} else if hasEnd && duration > 0 {
+			interactionDetail.PresentationDelay = int64(startTime + duration - processingEnd)
+		}
+	}
+
+	// 保存交互详情
+	return s.events.CreateDetail(&interactionDetail)
+}
+
+// 按顺序读取第一个存在的数值字段
+func firstNumber(dataMap map[string]interface{}, keys ...string) (float64, bool) {
+	for _, key := range keys {
+		if value, ok := dataMap[key].(float64); ok {
+			return value, true
+		}
+	}
+	return 0, false
 }
 
 // 从SDK停留事件处理
@@ -786,8 +1289,7 @@ func (s *EventService) processDwellEventFromSDK(req *TrackRequest, eventID uint)
 	}
 
 	// 保存停留详情
-	db := model.GetDB()
-	return db.Create(&dwellDetail).Error
+	return s.events.CreateDetail(&dwellDetail)
 }
 
 // 从SDK自定义事件处理
@@ -811,8 +1313,7 @@ func (s *EventService) processCustomEventFromSDK(req *TrackRequest, eventID uint
 	}
 
 	// 保存自定义事件详情
-	db := model.GetDB()
-	return db.Create(&customDetail).Error
+	return s.events.CreateDetail(&customDetail)
 }
 
 // GetErrorList 获取错误列表
@@ -834,42 +1335,18 @@ func (s *EventService) GetErrorList(projectIDStr, pageStr, pageSizeStr, startTim
 
 	offset := (page - 1) * pageSize
 
-	// 构建查询条件
-	query := model.GetDB().Model(&model.ErrorGroup{}).Where("project_id = ?", projectID)
-
-	// 添加时间范围过滤
-	if startTimeStr != "" {
-		startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("last_seen >= ?", startTime)
-		}
-	}
-
-	if endTimeStr != "" {
-		endTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("last_seen <= ?", endTime)
-		}
-	}
-
-	// 添加错误类型过滤
-	if errorType != "" {
-		query = query.Where("error_type = ?", errorType)
-	}
-
-	// 添加严重程度过滤
-	if severity != "" {
-		query = query.Where("severity = ?", severity)
+	// 构建查询条件，时间范围统一为毫秒
+	timeRange := parseTimeRange(startTimeStr, endTimeStr)
+	filter := repository.ErrorGroupFilter{
+		StartTime: timeRange.StartTime,
+		EndTime:   timeRange.EndTime,
+		ErrorType: errorType,
+		Severity:  severity,
 	}
 
 	// 获取错误分组列表
-	var groups []model.ErrorGroup
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
-	}
-
-	if err := query.Order("last_seen DESC").Limit(pageSize).Offset(offset).Find(&groups).Error; err != nil {
+	groups, total, err := s.errorGroups.List(uint(projectID), filter, pageSize, offset)
+	if err != nil {
 		return nil, err
 	}
 
@@ -890,8 +1367,12 @@ func (s *EventService) GetErrorList(projectIDStr, pageStr, pageSizeStr, startTim
 		})
 	}
 
-	// 获取统计数据
-	stats, err := s.getErrorStatsData(uint(projectID))
+	// 获取同一时间范围的统计数据
+	r, err := parseStatsQuery(StatsQuery{StartTime: startTimeStr, EndTime: endTimeStr})
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.getErrorStatsData(uint(projectID), r)
 	if err != nil {
 		return nil, err
 	}
@@ -911,17 +1392,14 @@ func (s *EventService) GetErrorDetail(idStr string) (*ErrorDetailResponse, error
 	}
 
 	// 获取错误分组
-	var group model.ErrorGroup
-	if err := model.GetDB().First(&group, id).Error; err != nil {
+	group, err := s.errorGroups.FindByID(uint(id))
+	if err != nil {
 		return nil, errors.New("错误不存在")
 	}
 
 	// 获取错误事件列表
-	var errorDetails []model.ErrorDetail
-	if err := model.GetDB().Where("fingerprint = ?", group.Fingerprint).
-		Order("created_at DESC").
-		Limit(10).
-		Find(&errorDetails).Error; err != nil {
+	errorDetails, err := s.errorGroups.RecentDetails(group.Fingerprint, 10)
+	if err != nil {
 		return nil, err
 	}
 
@@ -941,17 +1419,12 @@ func (s *EventService) GetErrorDetail(idStr string) (*ErrorDetailResponse, error
 
 	events := make([]ErrorEventItem, 0, len(errorDetails))
 	for _, detail := range errorDetails {
-		// 获取事件主信息
-		var eventMain model.EventMain
-		if err := model.GetDB().First(&eventMain, detail.EventID).Error; err != nil {
-			continue
-		}
-
-		// 获取基础信息
-		var baseInfo model.BaseInfo
-		if err := model.GetDB().First(&baseInfo, eventMain.BaseInfoID).Error; err != nil {
+		// 获取事件主信息和基础信息
+		eventMain, err := s.events.FindEvent(detail.EventID)
+		if err != nil || eventMain.BaseInfo == nil {
 			continue
 		}
+		baseInfo := eventMain.BaseInfo
 
 		events = append(events, ErrorEventItem{
 			ID:           detail.ID,
@@ -967,6 +1440,10 @@ func (s *EventService) GetErrorDetail(idStr string) (*ErrorDetailResponse, error
 			Browser:      baseInfo.Browser,
 			OS:           baseInfo.OS,
 			Device:       baseInfo.Device,
+			TraceID:      eventMain.TraceID,
+			SpanID:       eventMain.SpanID,
+			TraceURL:     TraceURL(eventMain.TraceID, eventMain.SpanID),
+			Breadcrumbs:  parseBreadcrumbs(detail.Context),
 		})
 	}
 
@@ -977,193 +1454,107 @@ func (s *EventService) GetErrorDetail(idStr string) (*ErrorDetailResponse, error
 	}, nil
 }
 
-// GetErrorStats 获取错误统计信息
-func (s *EventService) GetErrorStats(projectIDStr, startTimeStr, endTimeStr string) (*ErrorStatsResponse, error) {
+// GetErrorStats 获取时间范围内的错误统计信息，并返回上一周期的对比数据
+func (s *EventService) GetErrorStats(projectIDStr string, query StatsQuery) (*ErrorStatsResponse, error) {
 	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
 	if err != nil {
 		return nil, errors.New("无效的项目ID")
 	}
 
-	// 获取统计数据
-	stats, err := s.getErrorStatsData(uint(projectID))
+	r, err := parseStatsQuery(query)
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取趋势数据
-	trend, err := s.getErrorTrendData(uint(projectID), startTimeStr, endTimeStr)
-	if err != nil {
-		return nil, err
+	resp := &ErrorStatsResponse{Range: r.info()}
+	for _, period := range []struct {
+		r     *statsRange
+		stats *ErrorStatsData
+		trend *[]ErrorTrendItem
+	}{
+		{r, &resp.Stats, &resp.Trend},
+		{r.previous(), &resp.Previous, &resp.PreviousTrend},
+	} {
+		// 获取统计数据
+		if *period.stats, err = s.getErrorStatsData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
+
+		// 获取趋势数据
+		if *period.trend, err = s.getErrorTrendData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
 	}
 
-	return &ErrorStatsResponse{
-		Stats: stats,
-		Trend: trend,
-	}, nil
+	return resp, nil
 }
 
 // 获取错误统计数据
-func (s *EventService) getErrorStatsData(projectID uint) (ErrorStatsData, error) {
-	db := model.GetDB()
+func (s *EventService) getErrorStatsData(projectID uint, r *statsRange) (ErrorStatsData, error) {
 	var stats ErrorStatsData
 
 	// 获取总错误数
-	var totalErrors struct {
-		TotalErrors sql.NullInt64 `gorm:"column:total_errors"`
-	}
-	if err := db.Model(&model.ErrorGroup{}).Where("project_id = ?", projectID).
-		Select("COALESCE(SUM(count), 0) as total_errors").
-		Scan(&totalErrors).Error; err != nil {
+	total, err := s.rollupMetricTotal(projectID, rollupMetricError, r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
-
-	if totalErrors.TotalErrors.Valid {
-		stats.TotalErrors = totalErrors.TotalErrors.Int64
-	} else {
-		stats.TotalErrors = 0
-	}
+	stats.TotalErrors = total.Count
 
 	// 获取受影响用户数
-	var affectedUsers int64
-	if err := db.Model(&model.BaseInfo{}).
-		Joins("JOIN wt_event_main ON wt_event_main.base_info_id = wt_base_info.id").
-		Joins("JOIN wt_error_detail ON wt_error_detail.event_id = wt_event_main.id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Distinct("wt_base_info.user_uuid").
-		Count(&affectedUsers).Error; err != nil {
+	if stats.AffectedUsers, err = s.rollupDistinct(projectID, rollupMetricErrorUsers, r.Start, r.End); err != nil {
 		return stats, err
 	}
-	stats.AffectedUsers = affectedUsers
 
 	// 获取今天的错误数
-	todayStart := time.Now().Truncate(24 * time.Hour).Unix()
-	if err := db.Model(&model.ErrorDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_error_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ?", projectID, todayStart).
-		Count(&stats.ErrorsToday).Error; err != nil {
+	todayStart := r.todayStart()
+	today, err := s.rollupMetricTotal(projectID, rollupMetricError, todayStart, time.Now().UnixMilli()+1)
+	if err != nil {
 		return stats, err
 	}
+	stats.ErrorsToday = today.Count
 
 	// 获取昨天的错误数
-	yesterdayStart := todayStart - 86400
-	if err := db.Model(&model.ErrorDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_error_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ? AND wt_event_main.trigger_time < ?",
-			projectID, yesterdayStart, todayStart).
-		Count(&stats.ErrorsYesterday).Error; err != nil {
-		return stats, err
-	}
-
-	// 获取错误类型分布
-	var typeDistribution []struct {
-		ErrorType string
-		Count     int64
-	}
-	if err := db.Model(&model.ErrorDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_error_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("wt_error_detail.error_type, COUNT(*) as count").
-		Group("wt_error_detail.error_type").
-		Scan(&typeDistribution).Error; err != nil {
-		return stats, err
-	}
-
-	stats.TypeDistribution = make(map[string]int64)
-	for _, item := range typeDistribution {
-		stats.TypeDistribution[item.ErrorType] = item.Count
-	}
-
-	// 获取浏览器分布
-	var browserDistribution []struct {
-		Browser string
-		Count   int64
-	}
-	if err := db.Model(&model.BaseInfo{}).
-		Joins("JOIN wt_event_main ON wt_event_main.base_info_id = wt_base_info.id").
-		Joins("JOIN wt_error_detail ON wt_error_detail.event_id = wt_event_main.id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("wt_base_info.browser, COUNT(*) as count").
-		Group("wt_base_info.browser").
-		Scan(&browserDistribution).Error; err != nil {
+	yesterday, err := s.rollupMetricTotal(projectID, rollupMetricError, r.yesterdayStart(), todayStart)
+	if err != nil {
 		return stats, err
 	}
+	stats.ErrorsYesterday = yesterday.Count
 
-	stats.BrowserDistribution = make(map[string]int64)
-	for _, item := range browserDistribution {
-		stats.BrowserDistribution[item.Browser] = item.Count
-	}
-
-	// 获取操作系统分布
-	var osDistribution []struct {
-		OS    string
-		Count int64
-	}
-	if err := db.Model(&model.BaseInfo{}).
-		Joins("JOIN wt_event_main ON wt_event_main.base_info_id = wt_base_info.id").
-		Joins("JOIN wt_error_detail ON wt_error_detail.event_id = wt_event_main.id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("wt_base_info.os, COUNT(*) as count").
-		Group("wt_base_info.os").
-		Scan(&osDistribution).Error; err != nil {
-		return stats, err
+	// 获取错误类型、浏览器和操作系统分布
+	distributions := map[string]*map[string]int64{
+		"type":    &stats.TypeDistribution,
+		"browser": &stats.BrowserDistribution,
+		"os":      &stats.OSDistribution,
 	}
-
-	stats.OSDistribution = make(map[string]int64)
-	for _, item := range osDistribution {
-		stats.OSDistribution[item.OS] = item.Count
+	for dimension, distribution := range distributions {
+		totals, err := s.rollupTotals(projectID, rollupMetricError, dimension, r.Start, r.End)
+		if err != nil {
+			return stats, err
+		}
+		*distribution = make(map[string]int64, len(totals))
+		for value, total := range totals {
+			(*distribution)[value] = total.Count
+		}
 	}
 
 	return stats, nil
 }
 
 // 获取错误趋势数据
-func (s *EventService) getErrorTrendData(projectID uint, startTimeStr, endTimeStr string) ([]ErrorTrendItem, error) {
-	db := model.GetDB()
-	var trend []ErrorTrendItem
-
-	// 设置默认时间范围为最近7天
-	endTime := time.Now().Unix()
-	startTime := endTime - 7*86400
-
-	// 解析用户提供的时间范围
-	if startTimeStr != "" {
-		parsedStartTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			startTime = parsedStartTime
-		}
-	}
-
-	if endTimeStr != "" {
-		parsedEndTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			endTime = parsedEndTime
-		}
-	}
-
-	// 按天分组查询错误数量
-	var results []struct {
-		Date  string
-		Count int64
-	}
-
-	// 使用SQL函数将时间戳转换为日期字符串
-	query := db.Model(&model.EventMain{}).
-		Joins("JOIN wt_error_detail ON wt_error_detail.event_id = wt_event_main.id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ? AND wt_event_main.trigger_time <= ?",
-			projectID, startTime, endTime).
-		Select("DATE_FORMAT(FROM_UNIXTIME(wt_event_main.trigger_time), '%Y-%m-%d') as date, COUNT(*) as count").
-		Group("date").
-		Order("date")
-
-	if err := query.Scan(&results).Error; err != nil {
+func (s *EventService) getErrorTrendData(projectID uint, r *statsRange) ([]ErrorTrendItem, error) {
+	// 按时间桶汇总错误数量
+	series, err := s.rollupCountSeries(projectID, rollupMetricError, "", r)
+	if err != nil {
 		return nil, err
 	}
 
-	// 转换为响应格式
-	for _, result := range results {
+	// 转换为响应格式，没有数据的时间桶补零
+	buckets := r.buckets()
+	trend := make([]ErrorTrendItem, 0, len(buckets))
+	for _, bucket := range buckets {
 		trend = append(trend, ErrorTrendItem{
-			Date:  result.Date,
-			Count: result.Count,
+			Date:  r.label(bucket),
+			Count: series[bucket][""].Count,
 		})
 	}
 
@@ -1189,63 +1580,8 @@ func (s *EventService) GetPerformanceList(projectIDStr, pageStr, pageSizeStr, st
 
 	offset := (page - 1) * pageSize
 
-	// 构建查询条件
-	db := model.GetDB()
-	query := db.Model(&model.PerformancePageDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_performance_page_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID)
-
-	// 添加时间范围过滤
-	if startTimeStr != "" {
-		startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time >= ?", startTime)
-		}
-	}
-
-	if endTimeStr != "" {
-		endTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time <= ?", endTime)
-		}
-	}
-
-	// 获取性能数据列表
-	// 使用中间结构体处理MySQL返回的浮点数字符串
-	var perfDetails []struct {
-		ID          uint
-		EventID     string
-		PageURL     string
-		TriggerTime int64
-		FP          sql.NullFloat64 `gorm:"column:fp"`
-		FCP         sql.NullFloat64 `gorm:"column:fcp"`
-		LCP         sql.NullFloat64 `gorm:"column:lcp"`
-		FID         sql.NullFloat64 `gorm:"column:fid"`
-		CLS         sql.NullFloat64 `gorm:"column:cls"`
-		TTFB        sql.NullFloat64 `gorm:"column:ttfb"`
-		DomReady    sql.NullFloat64 `gorm:"column:dom_ready"`
-		Load        sql.NullFloat64 `gorm:"column:load"`
-		Browser     string
-		OS          string
-		Device      string
-	}
-
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
-	}
-
-	if err := query.
-		Select("wt_performance_page_detail.id, wt_event_main.event_id, wt_event_main.trigger_page_url as page_url, wt_event_main.trigger_time, " +
-			"wt_performance_page_detail.fp, wt_performance_page_detail.fcp, wt_performance_page_detail.lcp, " +
-			"0 as fid, " + // 使用固定值0替代不存在的字段
-			"wt_performance_page_detail.cls, wt_performance_page_detail.ttfb, wt_performance_page_detail.dom_ready, wt_performance_page_detail.load, " +
-			"wt_base_info.browser, wt_base_info.os, wt_base_info.device").
-		Joins("JOIN wt_base_info ON wt_base_info.id = wt_event_main.base_info_id").
-		Order("wt_event_main.trigger_time DESC").
-		Limit(pageSize).
-		Offset(offset).
-		Scan(&perfDetails).Error; err != nil {
+	perfDetails, total, err := s.performance.ListPages(uint(projectID), parseTimeRange(startTimeStr, endTimeStr), pageSize, offset)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1275,6 +1611,9 @@ func (s *EventService) GetPerformanceList(projectIDStr, pageStr, pageSizeStr, st
 		if detail.FID.Valid {
 			item.FID = int64(detail.FID.Float64)
 		}
+		if detail.INP.Valid {
+			item.INP = int64(detail.INP.Float64)
+		}
 		if detail.CLS.Valid {
 			item.CLS = detail.CLS.Float64
 		}
@@ -1291,8 +1630,12 @@ func (s *EventService) GetPerformanceList(projectIDStr, pageStr, pageSizeStr, st
 		list = append(list, item)
 	}
 
-	// 获取统计数据
-	stats, err := s.getPerformanceStatsData(uint(projectID))
+	// 获取同一时间范围的统计数据
+	r, err := parseStatsQuery(StatsQuery{StartTime: startTimeStr, EndTime: endTimeStr})
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.getPerformanceStatsData(uint(projectID), r)
 	if err != nil {
 		return nil, err
 	}
@@ -1304,166 +1647,93 @@ func (s *EventService) GetPerformanceList(projectIDStr, pageStr, pageSizeStr, st
 	}, nil
 }
 
-// GetPerformanceStats 获取性能统计信息
-func (s *EventService) GetPerformanceStats(projectIDStr, startTimeStr, endTimeStr string) (*PerformanceStatsResponse, error) {
+// GetPerformanceStats 获取时间范围内的性能统计信息，并返回上一周期的对比数据
+func (s *EventService) GetPerformanceStats(projectIDStr string, query StatsQuery) (*PerformanceStatsResponse, error) {
 	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
 	if err != nil {
 		return nil, errors.New("无效的项目ID")
 	}
 
-	// 获取统计数据
-	stats, err := s.getPerformanceStatsData(uint(projectID))
+	r, err := parseStatsQuery(query)
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取趋势数据
-	trend, err := s.getPerformanceTrendData(uint(projectID), startTimeStr, endTimeStr)
-	if err != nil {
-		return nil, err
+	resp := &PerformanceStatsResponse{Range: r.info()}
+	for _, period := range []struct {
+		r      *statsRange
+		stats  *PerformanceStatsData
+		trend  *[]PerformanceTrendItem
+		vitals *[]WebVitalMetric
+	}{
+		{r, &resp.Stats, &resp.Trend, &resp.Vitals},
+		{r.previous(), &resp.Previous, &resp.PreviousTrend, &resp.PreviousVitals},
+	} {
+		// 获取统计数据
+		if *period.stats, err = s.getPerformanceStatsData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
+
+		// 获取趋势数据
+		if *period.trend, err = s.getPerformanceTrendData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
+
+		// 获取 Web Vitals 分位数
+		if *period.vitals, err = s.getWebVitalMetrics(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
 	}
 
-	return &PerformanceStatsResponse{
-		Stats: stats,
-		Trend: trend,
-	}, nil
+	return resp, nil
 }
 
 // 获取性能统计数据
-func (s *EventService) getPerformanceStatsData(projectID uint) (PerformanceStatsData, error) {
-	db := model.GetDB()
+func (s *EventService) getPerformanceStatsData(projectID uint, r *statsRange) (PerformanceStatsData, error) {
 	var stats PerformanceStatsData
 
-	// 使用中间结构体处理MySQL返回的浮点数字符串
-	var rawStats struct {
-		AvgFP       sql.NullFloat64 `gorm:"column:avg_fp"`
-		AvgFCP      sql.NullFloat64 `gorm:"column:avg_fcp"`
-		AvgLCP      sql.NullFloat64 `gorm:"column:avg_lcp"`
-		AvgFID      sql.NullFloat64 `gorm:"column:avg_fid"`
-		AvgCLS      sql.NullFloat64 `gorm:"column:avg_cls"`
-		AvgTTFB     sql.NullFloat64 `gorm:"column:avg_ttfb"`
-		AvgDomReady sql.NullFloat64 `gorm:"column:avg_dom_ready"`
-		AvgLoad     sql.NullFloat64 `gorm:"column:avg_load"`
-	}
-
-	// 获取平均性能指标
-	query := db.Model(&model.PerformancePageDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_performance_page_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("AVG(wt_performance_page_detail.fp) as avg_fp, " +
-			"AVG(wt_performance_page_detail.fcp) as avg_fcp, " +
-			"AVG(wt_performance_page_detail.lcp) as avg_lcp, " +
-			"0 as avg_fid, " + // 使用固定值0替代不存在的字段
-			"AVG(wt_performance_page_detail.cls) as avg_cls, " +
-			"AVG(wt_performance_page_detail.ttfb) as avg_ttfb, " +
-			"AVG(wt_performance_page_detail.dom_ready) as avg_dom_ready, " +
-			"AVG(wt_performance_page_detail.load) as avg_load")
-
-	if err := query.Scan(&rawStats).Error; err != nil {
+	// 获取平均性能指标，只统计上报了该指标的记录
+	totals, err := s.rollupTotals(projectID, rollupMetricVital, "name", r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
-
-	// 将浮点数转换为整数
-	if rawStats.AvgFP.Valid {
-		stats.AvgFP = int64(rawStats.AvgFP.Float64)
-	}
-	if rawStats.AvgFCP.Valid {
-		stats.AvgFCP = int64(rawStats.AvgFCP.Float64)
-	}
-	if rawStats.AvgLCP.Valid {
-		stats.AvgLCP = int64(rawStats.AvgLCP.Float64)
-	}
-	if rawStats.AvgFID.Valid {
-		stats.AvgFID = int64(rawStats.AvgFID.Float64)
-	}
-	if rawStats.AvgCLS.Valid {
-		stats.AvgCLS = rawStats.AvgCLS.Float64
-	}
-	if rawStats.AvgTTFB.Valid {
-		stats.AvgTTFB = int64(rawStats.AvgTTFB.Float64)
-	}
-	if rawStats.AvgDomReady.Valid {
-		stats.AvgDomReady = int64(rawStats.AvgDomReady.Float64)
-	}
-	if rawStats.AvgLoad.Valid {
-		stats.AvgLoad = int64(rawStats.AvgLoad.Float64)
+	avg := func(name string) float64 {
+		return averageRollup(totals[name])
 	}
 
+	stats.AvgFP = int64(avg("FP"))
+	stats.AvgFCP = int64(avg("FCP"))
+	stats.AvgLCP = int64(avg("LCP"))
+	stats.AvgFID = int64(avg("FID"))
+	stats.AvgINP = int64(avg("INP"))
+	stats.AvgCLS = avg("CLS")
+	stats.AvgTTFB = int64(avg("TTFB"))
+	stats.AvgDomReady = int64(avg("DomReady"))
+	stats.AvgLoad = int64(avg("Load"))
+
 	return stats, nil
 }
 
 // 获取性能趋势数据
-func (s *EventService) getPerformanceTrendData(projectID uint, startTimeStr, endTimeStr string) ([]PerformanceTrendItem, error) {
-	db := model.GetDB()
-	var trend []PerformanceTrendItem
-
-	// 设置默认时间范围为最近7天
-	endTime := time.Now().Unix()
-	startTime := endTime - 7*86400
-
-	// 解析用户提供的时间范围
-	if startTimeStr != "" {
-		parsedStartTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			startTime = parsedStartTime
-		}
-	}
-
-	if endTimeStr != "" {
-		parsedEndTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			endTime = parsedEndTime
-		}
-	}
-
-	// 按天分组查询性能指标
-	// 使用中间结构体处理MySQL返回的浮点数字符串
-	var rawResults []struct {
-		Date string
-		FP   sql.NullFloat64 `gorm:"column:fp"`
-		FCP  sql.NullFloat64 `gorm:"column:fcp"`
-		LCP  sql.NullFloat64 `gorm:"column:lcp"`
-		TTFB sql.NullFloat64 `gorm:"column:ttfb"`
-	}
-
-	// 使用SQL函数将时间戳转换为日期字符串
-	query := db.Model(&model.PerformancePageDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_performance_page_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ? AND wt_event_main.trigger_time <= ?",
-			projectID, startTime, endTime).
-		Select("DATE_FORMAT(FROM_UNIXTIME(wt_event_main.trigger_time), '%Y-%m-%d') as date, " +
-			"AVG(wt_performance_page_detail.fp) as fp, " +
-			"AVG(wt_performance_page_detail.fcp) as fcp, " +
-			"AVG(wt_performance_page_detail.lcp) as lcp, " +
-			"AVG(wt_performance_page_detail.ttfb) as ttfb").
-		Group("date").
-		Order("date")
-
-	if err := query.Scan(&rawResults).Error; err != nil {
+func (s *EventService) getPerformanceTrendData(projectID uint, r *statsRange) ([]PerformanceTrendItem, error) {
+	// 按时间桶汇总性能指标
+	series, err := s.rollupCountSeries(projectID, rollupMetricVital, "name", r)
+	if err != nil {
 		return nil, err
 	}
 
-	// 转换为响应格式
-	for _, result := range rawResults {
-		item := PerformanceTrendItem{
-			Date: result.Date,
-		}
-
-		// 将浮点数转换为整数
-		if result.FP.Valid {
-			item.FP = int64(result.FP.Float64)
-		}
-		if result.FCP.Valid {
-			item.FCP = int64(result.FCP.Float64)
-		}
-		if result.LCP.Valid {
-			item.LCP = int64(result.LCP.Float64)
-		}
-		if result.TTFB.Valid {
-			item.TTFB = int64(result.TTFB.Float64)
-		}
-
-		trend = append(trend, item)
+	// 转换为响应格式，没有数据的时间桶补零
+	buckets := r.buckets()
+	trend := make([]PerformanceTrendItem, 0, len(buckets))
+	for _, bucket := range buckets {
+		totals := series[bucket]
+		trend = append(trend, PerformanceTrendItem{
+			Date: r.label(bucket),
+			FP:   int64(averageRollup(totals["FP"])),
+			FCP:  int64(averageRollup(totals["FCP"])),
+			LCP:  int64(averageRollup(totals["LCP"])),
+			TTFB: int64(averageRollup(totals["TTFB"])),
+		})
 	}
 
 	return trend, nil
@@ -1488,59 +1758,8 @@ func (s *EventService) GetResourcePerformanceList(projectIDStr, pageStr, pageSiz
 
 	offset := (page - 1) * pageSize
 
-	// 构建查询条件
-	db := model.GetDB()
-	query := db.Model(&model.PerformanceResourceDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_performance_resource_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID)
-
-	// 添加时间范围过滤
-	if startTimeStr != "" {
-		startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time >= ?", startTime)
-		}
-	}
-
-	if endTimeStr != "" {
-		endTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time <= ?", endTime)
-		}
-	}
-
-	// 添加资源类型过滤
-	if resourceType != "" {
-		query = query.Where("wt_performance_resource_detail.resource_type = ?", resourceType)
-	}
-
-	// 获取资源性能数据列表
-	var resourceDetails []struct {
-		ID            uint
-		EventID       string
-		ResourceURL   string
-		ResourceType  string
-		InitiatorType string
-		StartTime     int64
-		Duration      int64
-		TransferSize  int64
-		PageURL       string
-	}
-
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
-	}
-
-	if err := query.
-		Select("wt_performance_resource_detail.id, wt_event_main.event_id, wt_performance_resource_detail.resource_url, " +
-			"wt_performance_resource_detail.resource_type, wt_performance_resource_detail.initiator_type, " +
-			"wt_performance_resource_detail.start_time, wt_performance_resource_detail.duration, " +
-			"wt_performance_resource_detail.transfer_size, wt_event_main.trigger_page_url as page_url").
-		Order("wt_event_main.trigger_time DESC").
-		Limit(pageSize).
-		Offset(offset).
-		Scan(&resourceDetails).Error; err != nil {
+	resourceDetails, total, err := s.performance.ListResources(uint(projectID), parseTimeRange(startTimeStr, endTimeStr), resourceType, pageSize, offset)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1585,56 +1804,8 @@ func (s *EventService) GetPageViewList(projectIDStr, pageStr, pageSizeStr, start
 
 	offset := (page - 1) * pageSize
 
-	// 构建查询条件
-	db := model.GetDB()
-	query := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID)
-
-	// 添加时间范围过滤
-	if startTimeStr != "" {
-		startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time >= ?", startTime)
-		}
-	}
-
-	if endTimeStr != "" {
-		endTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time <= ?", endTime)
-		}
-	}
-
-	// 获取页面访问数据列表
-	var pvDetails []struct {
-		ID          uint
-		EventID     string
-		PageURL     string
-		Title       string
-		Referrer    string
-		TriggerTime int64
-		StayTime    int64
-		IsNewVisit  bool
-		Browser     string
-		OS          string
-		Device      string
-	}
-
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
-	}
-
-	if err := query.
-		Select("wt_pv_detail.id, wt_event_main.event_id, wt_pv_detail.page_url, wt_pv_detail.title, " +
-			"wt_pv_detail.referrer, wt_event_main.trigger_time, wt_pv_detail.stay_time, wt_pv_detail.is_new_visit, " +
-			"wt_base_info.browser, wt_base_info.os, wt_base_info.device").
-		Joins("JOIN wt_base_info ON wt_base_info.id = wt_event_main.base_info_id").
-		Order("wt_event_main.trigger_time DESC").
-		Limit(pageSize).
-		Offset(offset).
-		Scan(&pvDetails).Error; err != nil {
+	pvDetails, total, err := s.behaviors.ListPageViews(uint(projectID), parseTimeRange(startTimeStr, endTimeStr), pageSize, offset)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1681,51 +1852,8 @@ func (s *EventService) GetClickList(projectIDStr, pageStr, pageSizeStr, startTim
 
 	offset := (page - 1) * pageSize
 
-	// 构建查询条件
-	db := model.GetDB()
-	query := db.Model(&model.ClickDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_click_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID)
-
-	// 添加时间范围过滤
-	if startTimeStr != "" {
-		startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time >= ?", startTime)
-		}
-	}
-
-	if endTimeStr != "" {
-		endTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			query = query.Where("wt_event_main.trigger_time <= ?", endTime)
-		}
-	}
-
-	// 获取点击数据列表
-	var clickDetails []struct {
-		ID          uint
-		EventID     string
-		ElementPath string
-		ElementType string
-		InnerText   string
-		TriggerTime int64
-		PageURL     string
-	}
-
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
-	}
-
-	if err := query.
-		Select("wt_click_detail.id, wt_event_main.event_id, wt_click_detail.element_path, " +
-			"wt_click_detail.element_type, wt_click_detail.inner_text, wt_event_main.trigger_time, " +
-			"wt_event_main.trigger_page_url as page_url").
-		Order("wt_event_main.trigger_time DESC").
-		Limit(pageSize).
-		Offset(offset).
-		Scan(&clickDetails).Error; err != nil {
+	clickDetails, total, err := s.behaviors.ListClicks(uint(projectID), parseTimeRange(startTimeStr, endTimeStr), pageSize, offset)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1749,243 +1877,151 @@ func (s *EventService) GetClickList(projectIDStr, pageStr, pageSizeStr, startTim
 	}, nil
 }
 
-// GetBehaviorStats 获取用户行为统计信息
-func (s *EventService) GetBehaviorStats(projectIDStr, startTimeStr, endTimeStr string) (*BehaviorStatsResponse, error) {
+// GetBehaviorStats 获取时间范围内的用户行为统计信息，并返回上一周期的对比数据
+func (s *EventService) GetBehaviorStats(projectIDStr string, query StatsQuery) (*BehaviorStatsResponse, error) {
 	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
 	if err != nil {
 		return nil, errors.New("无效的项目ID")
 	}
 
-	// 获取PV统计数据
-	pvStats, err := s.getPVStatsData(uint(projectID))
+	r, err := parseStatsQuery(query)
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取点击统计数据
-	clickStats, err := s.getClickStatsData(uint(projectID))
-	if err != nil {
-		return nil, err
-	}
+	resp := &BehaviorStatsResponse{Range: r.info()}
+	for _, period := range []struct {
+		r          *statsRange
+		pvStats    *PVStatsData
+		clickStats *ClickStatsData
+		pvTrend    *[]PVTrendItem
+	}{
+		{r, &resp.PVStats, &resp.ClickStats, &resp.PVTrend},
+		{r.previous(), &resp.PreviousPVStats, &resp.PreviousClickStats, &resp.PreviousPVTrend},
+	} {
+		// 获取PV统计数据
+		if *period.pvStats, err = s.getPVStatsData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
 
-	// 获取PV趋势数据
-	pvTrend, err := s.getPVTrendData(uint(projectID), startTimeStr, endTimeStr)
-	if err != nil {
-		return nil, err
+		// 获取点击统计数据
+		if *period.clickStats, err = s.getClickStatsData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
+
+		// 获取PV趋势数据
+		if *period.pvTrend, err = s.getPVTrendData(uint(projectID), period.r); err != nil {
+			return nil, err
+		}
 	}
 
-	return &BehaviorStatsResponse{
-		PVStats:    pvStats,
-		ClickStats: clickStats,
-		PVTrend:    pvTrend,
-	}, nil
+	return resp, nil
 }
 
 // 获取PV统计数据
-func (s *EventService) getPVStatsData(projectID uint) (PVStatsData, error) {
-	db := model.GetDB()
+func (s *EventService) getPVStatsData(projectID uint, r *statsRange) (PVStatsData, error) {
 	var stats PVStatsData
 
 	// 获取总PV数
-	if err := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Count(&stats.TotalPV).Error; err != nil {
+	pv, err := s.rollupMetricTotal(projectID, rollupMetricPV, r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
+	stats.TotalPV = pv.Count
 
-	// 获取总UV数
-	if err := db.Model(&model.BaseInfo{}).
-		Joins("JOIN wt_event_main ON wt_event_main.base_info_id = wt_base_info.id").
-		Joins("JOIN wt_pv_detail ON wt_pv_detail.event_id = wt_event_main.id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Distinct("wt_base_info.user_uuid").
-		Count(&stats.TotalUV).Error; err != nil {
+	// 平均停留时间和跳出率只统计停留时间已知的访问
+	stayed, err := s.rollupMetricTotal(projectID, rollupMetricPVStay, r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
+	stats.AvgStayTime = int64(averageRollup(stayed))
 
-	// 获取今天的PV数
-	todayStart := time.Now().Truncate(24 * time.Hour).Unix()
-	if err := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ?", projectID, todayStart).
-		Count(&stats.PVToday).Error; err != nil {
+	// 获取总UV数
+	if stats.TotalUV, err = s.rollupDistinct(projectID, rollupMetricUV, r.Start, r.End); err != nil {
 		return stats, err
 	}
 
-	// 获取今天的UV数
-	if err := db.Model(&model.BaseInfo{}).
-		Joins("JOIN wt_event_main ON wt_event_main.base_info_id = wt_base_info.id").
-		Joins("JOIN wt_pv_detail ON wt_pv_detail.event_id = wt_event_main.id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ?", projectID, todayStart).
-		Distinct("wt_base_info.user_uuid").
-		Count(&stats.UVToday).Error; err != nil {
+	// 获取今天的PV数
+	todayStart, now := r.todayStart(), time.Now().UnixMilli()+1
+	pvToday, err := s.rollupMetricTotal(projectID, rollupMetricPV, todayStart, now)
+	if err != nil {
 		return stats, err
 	}
+	stats.PVToday = pvToday.Count
 
-	// 获取平均停留时间
-	var avgStayTime struct {
-		AvgStayTime int64
-	}
-	if err := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("AVG(wt_pv_detail.stay_time) as avg_stay_time").
-		Scan(&avgStayTime).Error; err != nil {
+	// 获取今天的UV数
+	if stats.UVToday, err = s.rollupDistinct(projectID, rollupMetricUV, todayStart, now); err != nil {
 		return stats, err
 	}
-	stats.AvgStayTime = avgStayTime.AvgStayTime
 
 	// 获取跳出率
-	var bounceCount int64
-	if err := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_pv_detail.stay_time < 10", projectID).
-		Count(&bounceCount).Error; err != nil {
+	bounce, err := s.rollupMetricTotal(projectID, rollupMetricBounce, r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
-	if stats.TotalPV > 0 {
-		stats.BounceRate = float64(bounceCount) / float64(stats.TotalPV) * 100
+	if stayed.Count > 0 {
+		stats.BounceRate = float64(bounce.Count) / float64(stayed.Count) * 100
 	}
 
 	// 获取热门页面
-	var topPages []struct {
-		PageURL string
-		Count   int64
-	}
-	if err := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("wt_pv_detail.page_url, COUNT(*) as count").
-		Group("wt_pv_detail.page_url").
-		Order("count DESC").
-		Limit(10).
-		Scan(&topPages).Error; err != nil {
+	pages, err := s.rollupTotals(projectID, rollupMetricPV, "page", r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
-
-	stats.TopPages = make(map[string]int64)
-	for _, page := range topPages {
-		stats.TopPages[page.PageURL] = page.Count
-	}
+	// 预聚合按归一化后的页面保存，早期写入的原始URL在这里合并
+	stats.TopPages = topRollupValues(mergeRollupValues(pages, NormalizeURL), 10)
 
 	return stats, nil
 }
 
 // 获取点击统计数据
-func (s *EventService) getClickStatsData(projectID uint) (ClickStatsData, error) {
-	db := model.GetDB()
+func (s *EventService) getClickStatsData(projectID uint, r *statsRange) (ClickStatsData, error) {
 	var stats ClickStatsData
 
 	// 获取总点击数
-	if err := db.Model(&model.ClickDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_click_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Count(&stats.TotalClicks).Error; err != nil {
+	clicks, err := s.rollupMetricTotal(projectID, rollupMetricClick, r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
+	stats.TotalClicks = clicks.Count
 
 	// 获取今天的点击数
-	todayStart := time.Now().Truncate(24 * time.Hour).Unix()
-	if err := db.Model(&model.ClickDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_click_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ?", projectID, todayStart).
-		Count(&stats.ClicksToday).Error; err != nil {
+	clicksToday, err := s.rollupMetricTotal(projectID, rollupMetricClick, r.todayStart(), time.Now().UnixMilli()+1)
+	if err != nil {
 		return stats, err
 	}
+	stats.ClicksToday = clicksToday.Count
 
 	// 获取热门元素
-	var topElements []struct {
-		ElementPath string
-		Count       int64
-	}
-	if err := db.Model(&model.ClickDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_click_detail.event_id").
-		Where("wt_event_main.project_id = ?", projectID).
-		Select("wt_click_detail.element_path, COUNT(*) as count").
-		Group("wt_click_detail.element_path").
-		Order("count DESC").
-		Limit(10).
-		Scan(&topElements).Error; err != nil {
+	elements, err := s.rollupTotals(projectID, rollupMetricClick, "element", r.Start, r.End)
+	if err != nil {
 		return stats, err
 	}
-
-	stats.TopElements = make(map[string]int64)
-	for _, element := range topElements {
-		stats.TopElements[element.ElementPath] = element.Count
-	}
+	stats.TopElements = topRollupValues(elements, 10)
 
 	return stats, nil
 }
 
 // 获取PV趋势数据
-func (s *EventService) getPVTrendData(projectID uint, startTimeStr, endTimeStr string) ([]PVTrendItem, error) {
-	db := model.GetDB()
-	var trend []PVTrendItem
-
-	// 设置默认时间范围为最近7天
-	endTime := time.Now().Unix()
-	startTime := endTime - 7*86400
-
-	// 解析用户提供的时间范围
-	if startTimeStr != "" {
-		parsedStartTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-		if err == nil {
-			startTime = parsedStartTime
-		}
-	}
-
-	if endTimeStr != "" {
-		parsedEndTime, err := strconv.ParseInt(endTimeStr, 10, 64)
-		if err == nil {
-			endTime = parsedEndTime
-		}
-	}
-
-	// 按天分组查询PV和UV
-	var results []struct {
-		Date string
-		PV   int64
-		UV   int64
-	}
-
-	// 获取PV数据
-	pvQuery := db.Model(&model.PVDetail{}).
-		Joins("JOIN wt_event_main ON wt_event_main.id = wt_pv_detail.event_id").
-		Where("wt_event_main.project_id = ? AND wt_event_main.trigger_time >= ? AND wt_event_main.trigger_time <= ?",
-			projectID, startTime, endTime).
-		Select("DATE_FORMAT(FROM_UNIXTIME(wt_event_main.trigger_time), '%Y-%m-%d') as date, COUNT(*) as pv").
-		Group("date").
-		Order("date")
-
-	if err := pvQuery.Scan(&results).Error; err != nil {
+func (s *EventService) getPVTrendData(projectID uint, r *statsRange) ([]PVTrendItem, error) {
+	// 按时间桶汇总PV和UV
+	pvSeries, err := s.rollupCountSeries(projectID, rollupMetricPV, "", r)
+	if err != nil {
 		return nil, err
 	}
-
-	// 获取UV数据
-	for i, result := range results {
-		var uv int64
-		uvQuery := db.Model(&model.BaseInfo{}).
-			Joins("JOIN wt_event_main ON wt_event_main.base_info_id = wt_base_info.id").
-			Joins("JOIN wt_pv_detail ON wt_pv_detail.event_id = wt_event_main.id").
-			Where("wt_event_main.project_id = ? AND DATE_FORMAT(FROM_UNIXTIME(wt_event_main.trigger_time), '%Y-%m-%d') = ?",
-				projectID, result.Date).
-			Distinct("wt_base_info.user_uuid").
-			Count(&uv)
-
-		if uvQuery.Error != nil {
-			return nil, uvQuery.Error
-		}
-
-		results[i].UV = uv
+	uvSeries, err := s.rollupDistinctSeries(projectID, rollupMetricUV, r)
+	if err != nil {
+		return nil, err
 	}
 
-	// 转换为响应格式
-	for _, result := range results {
+	// 转换为响应格式，没有数据的时间桶补零
+	buckets := r.buckets()
+	trend := make([]PVTrendItem, 0, len(buckets))
+	for _, bucket := range buckets {
 		trend = append(trend, PVTrendItem{
-			Date: result.Date,
-			PV:   result.PV,
-			UV:   result.UV,
+			Date: r.label(bucket),
+			PV:   pvSeries[bucket][""].Count,
+			UV:   uvSeries[bucket],
 		})
 	}
 
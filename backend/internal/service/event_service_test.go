@@ -0,0 +1,496 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 构造测试项目的上报请求
+func trackRequest(category, eventType string, data map[string]interface{}) *TrackRequest {
+	raw, _ := json.Marshal(data)
+	return &TrackRequest{
+		Category:  category,
+		Type:      eventType,
+		Timestamp: 1700000000000,
+		Data:      raw,
+		AppKey:    testProjectKey,
+	}
+}
+
+func TestProcessTrackDataMapsBaseInfo(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	req := trackRequest("user", "page_view", map[string]interface{}{
+		"url":          "https://example.com/home",
+		"userId":       "u1",
+		"userUuid":     "uuid-1",
+		"sessionId":    "s1",
+		"pageId":       "p1",
+		"browser":      "Chrome",
+		"os":           "macOS",
+		"deviceType":   "desktop",
+		"screenWidth":  1440,
+		"screenHeight": 900,
+		"ext":          map[string]interface{}{"tag": "a"},
+	})
+	// 秒级时间戳按毫秒保存
+	req.Timestamp = 1700000000
+	req.Release = "1.2.0"
+	req.Environment = "prod"
+	if err := service.ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fakes.events.baseInfos) != 1 || len(fakes.events.events) != 1 {
+		t.Fatalf("保存了 %d 条基础信息和 %d 条事件", len(fakes.events.baseInfos), len(fakes.events.events))
+	}
+	base := fakes.events.baseInfos[0]
+	if base.ProjectID != testProjectID || base.SendTime != 1700000000000 || base.PageURL != "https://example.com/home" ||
+		base.UserID != "u1" || base.UserUUID != "uuid-1" || base.SessionID != "s1" || base.PageID != "p1" ||
+		base.Browser != "Chrome" || base.OS != "macOS" || base.DeviceType != "desktop" ||
+		base.ScreenWidth != 1440 || base.ScreenHeight != 900 || base.Ext != `{"tag":"a"}` ||
+		base.Release != "1.2.0" || base.Environment != "prod" {
+		t.Fatalf("基础信息 %+v", base)
+	}
+
+	event := fakes.events.events[0]
+	if event.EventType != model.EventTypePV || event.ProjectID != testProjectID || event.BaseInfoID != base.ID ||
+		event.TriggerTime != 1700000000000 || event.TriggerPageURL != "https://example.com/home" || event.EventID == "" {
+		t.Fatalf("事件主记录 %+v", event)
+	}
+	if detail := findDetail[model.PVDetail](fakes.events.details); detail == nil || detail.EventID != event.ID || detail.PageURL != "https://example.com/home" {
+		t.Fatalf("页面访问详情 %+v", detail)
+	}
+}
+
+func TestProcessTrackDataMapsEventTypes(t *testing.T) {
+	tests := []struct {
+		category, eventType string
+		want                string
+		detail              func(details []interface{}) bool
+	}{
+		{"error", model.ErrorTypeJS, model.EventTypeError, func(d []interface{}) bool { return findDetail[model.ErrorDetail](d) != nil }},
+		{"performance", "web_vitals", model.EventTypePerformancePage, func(d []interface{}) bool { return findDetail[model.PerformancePageDetail](d) != nil }},
+		{"performance", "resource_load", model.EventTypePerformanceResource, func(d []interface{}) bool { return findDetail[model.PerformanceResourceDetail](d) != nil }},
+		{"performance", "long_animation_frame", model.EventTypeLongTask, func(d []interface{}) bool { return findDetail[model.LongTaskDetail](d) != nil }},
+		{"performance", "event_timing", model.EventTypeInteraction, func(d []interface{}) bool { return findDetail[model.InteractionDetail](d) != nil }},
+		{"user", "click", model.EventTypeClick, func(d []interface{}) bool { return findDetail[model.ClickDetail](d) != nil }},
+		{"user", "stay_time", model.EventTypeDwell, func(d []interface{}) bool { return findDetail[model.DwellDetail](d) != nil }},
+		{"user", "route", model.EventTypeRoute, func(d []interface{}) bool { return findDetail[model.RouteDetail](d) != nil }},
+		{"custom", "signup", model.EventTypeCustom, func(d []interface{}) bool { return findDetail[model.CustomDetail](d) != nil }},
+		{"log", "console", model.EventTypeLog, func(d []interface{}) bool { return findDetail[model.LogDetail](d) != nil }},
+		{"request", "fetch", model.EventTypeRequest, func(d []interface{}) bool { return findDetail[model.RequestDetail](d) != nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category+"/"+tt.eventType, func(t *testing.T) {
+			fakes := newFakeRepositories()
+			// 失败的请求不参与采样，保证一定入库
+			req := trackRequest(tt.category, tt.eventType, map[string]interface{}{"status": 500})
+			if err := fakes.eventService().ProcessTrackData(req); err != nil {
+				t.Fatal(err)
+			}
+			if len(fakes.events.events) != 1 || fakes.events.events[0].EventType != tt.want {
+				t.Fatalf("事件类型映射错误，期望 %s", tt.want)
+			}
+			if !tt.detail(fakes.events.details) {
+				t.Fatalf("未保存 %s 详情", tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessTrackDataMapsDetails(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	requests := []*TrackRequest{
+		trackRequest("user", "click", map[string]interface{}{
+			"path":    []interface{}{"body", "div#app", "button"},
+			"tagName": "BUTTON",
+			"pageX":   120,
+			"y":       80,
+		}),
+		trackRequest("performance", "long_task", map[string]interface{}{"startTime": 10, "duration": 180}),
+		trackRequest("request", "xhr", map[string]interface{}{
+			"requestUrl": "https://api.example.com/users/42",
+			"method":     "post",
+			"status":     502,
+			"duration":   300,
+		}),
+	}
+	for _, req := range requests {
+		if err := service.ProcessTrackData(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	click := findDetail[model.ClickDetail](fakes.events.details)
+	if click.ElementPath != "body > div#app > button" || click.ElementType != "BUTTON" || click.X != 120 || click.Y != 80 {
+		t.Fatalf("点击详情 %+v", click)
+	}
+	// 未上报阻塞时长时按超过 50ms 的部分计算
+	longTask := findDetail[model.LongTaskDetail](fakes.events.details)
+	if longTask.Kind != model.LongTaskKindTask || longTask.Duration != 180 || longTask.BlockingDuration != 130 {
+		t.Fatalf("长任务详情 %+v", longTask)
+	}
+	request := findDetail[model.RequestDetail](fakes.events.details)
+	if request.Method != "POST" || request.Status != 502 || request.Success || request.SampleRate != 1 || request.URLTemplate != NormalizeAPIURL(request.URL) {
+		t.Fatalf("接口请求详情 %+v", request)
+	}
+}
+
+func TestProcessTrackDataRecordsErrorGroup(t *testing.T) {
+	fakes := newFakeRepositories()
+	req := trackRequest("error", model.ErrorTypeJS, map[string]interface{}{
+		"message":  "boom",
+		"stack":    "at main.js:1:2",
+		"filename": "main.js",
+		"lineno":   1,
+		"colno":    2,
+	})
+	req.Fingerprint = "fp-1"
+	req.Severity = "error"
+	if err := fakes.eventService().ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fakes.errorGroups.recorded) != 1 {
+		t.Fatalf("记录了 %d 个错误分组", len(fakes.errorGroups.recorded))
+	}
+	detail := fakes.errorGroups.recorded[0]
+	if detail.ErrorMessage != "boom" || detail.FilePath != "main.js" || detail.LineNumber != 1 || detail.ColumnNumber != 2 ||
+		detail.Fingerprint != "fp-1" || detail.Severity != "error" || detail.EventID != fakes.events.events[0].ID {
+		t.Fatalf("错误详情 %+v", detail)
+	}
+}
+
+func TestProcessTrackDataMergesPerformancePage(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.events.performancePage = &model.PerformancePageDetail{EventID: 9}
+
+	req := trackRequest("performance", "web_vitals", map[string]interface{}{
+		"pageId": "p1",
+		"name":   "LCP",
+		"value":  1234.5,
+	})
+	if err := fakes.eventService().ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一次页面加载的指标合并到已有记录，不再新建事件
+	if len(fakes.events.events) != 0 || len(fakes.events.saved) != 1 {
+		t.Fatalf("新建 %d 条事件，更新 %d 条详情", len(fakes.events.events), len(fakes.events.saved))
+	}
+	if lcp := fakes.events.performancePage.LCP; lcp == nil || *lcp != 1234 {
+		t.Fatalf("LCP %v", lcp)
+	}
+}
+
+func TestProcessTrackDataRouteUpdatesPreviousStayTime(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.events.previousPageView = &repository.PageViewRef{ID: 5, TriggerTime: 1700000000000 - 4000}
+
+	req := trackRequest("user", "route", map[string]interface{}{
+		"sessionId": "s1",
+		"from":      "/home",
+		"to":        "/users/42",
+		"title":     "用户",
+		"duration":  35,
+	})
+	if err := fakes.eventService().ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	route := findDetail[model.RouteDetail](fakes.events.details)
+	if route.FromURL != "/home" || route.ToURL != "/users/42" || route.Duration != 35 || route.StayTime != 4000 {
+		t.Fatalf("路由详情 %+v", route)
+	}
+	// 每次路由切换同时计为一次页面访问
+	pv := findDetail[model.PVDetail](fakes.events.details)
+	if pv == nil || pv.PageURL != "/users/42" || pv.Referrer != "/home" || pv.Title != "用户" {
+		t.Fatalf("页面访问详情 %+v", pv)
+	}
+	if fakes.events.updated[5] != 4000 {
+		t.Fatalf("上一次访问的停留时间 %v", fakes.events.updated)
+	}
+}
+
+func TestProcessTrackDataBatchReport(t *testing.T) {
+	fakes := newFakeRepositories()
+
+	click := trackRequest("user", "click", nil)
+	click.Release = ""
+	unknown := trackRequest("unknown", "x", nil)
+	req := trackRequest("system", "batch_report", map[string]interface{}{
+		"events": []interface{}{click, unknown},
+		"count":  2,
+	})
+	req.Release = "2.0.0"
+	// 单个子事件失败不影响其他事件
+	if err := fakes.eventService().ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fakes.events.events) != 1 || fakes.events.events[0].EventType != model.EventTypeClick {
+		t.Fatalf("批量上报保存了 %d 条事件", len(fakes.events.events))
+	}
+	if release := fakes.events.baseInfos[0].Release; release != "2.0.0" {
+		t.Fatalf("子事件版本 %q，期望沿用批量请求的版本", release)
+	}
+}
+
+func TestProcessTrackDataRejectsInvalidRequest(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	req := trackRequest("user", "click", nil)
+	req.AppKey = "missing"
+	if err := service.ProcessTrackData(req); err == nil {
+		t.Fatal("未知项目应返回错误")
+	}
+	if err := service.ProcessTrackData(trackRequest("unknown", "x", nil)); err == nil {
+		t.Fatal("不支持的事件类别应返回错误")
+	}
+	if len(fakes.events.events) != 0 {
+		t.Fatalf("不应保存事件，实际保存 %d 条", len(fakes.events.events))
+	}
+}
+
+func TestGetPageViewAndClickLists(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.behaviors.pageViews = []repository.PageViewRow{{ID: 1, EventID: "e1", PageURL: "/home", StayTime: 3000, IsNewVisit: true, Browser: "Chrome"}}
+	fakes.behaviors.clicks = []repository.ClickRow{{ID: 2, EventID: "e2", ElementPath: "body > button", ElementType: "BUTTON", PageURL: "/home"}}
+	service := fakes.eventService()
+
+	pvs, err := service.GetPageViewList("1", "1", "10", "1700000001000", "1700000002000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakes.behaviors.timeRange != (repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}) {
+		t.Fatalf("时间范围 %+v", fakes.behaviors.timeRange)
+	}
+	if pvs.Total != 1 || pvs.List[0].PageURL != "/home" || pvs.List[0].StayTime != 3000 || !pvs.List[0].IsNewVisit || pvs.List[0].Browser != "Chrome" {
+		t.Fatalf("页面访问列表 %+v", pvs)
+	}
+
+	clicks, err := service.GetClickList("1", "1", "10", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clicks.Total != 1 || clicks.List[0].ElementPath != "body > button" || clicks.List[0].PageURL != "/home" {
+		t.Fatalf("点击列表 %+v", clicks)
+	}
+
+	if _, err := service.GetPageViewList("x", "1", "10", "", ""); err == nil {
+		t.Fatal("无效项目ID应返回错误")
+	}
+}
+
+func TestGetPerformanceAndResourceLists(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.performance.pages = []repository.PerformancePageRow{{
+		ID:      1,
+		EventID: "e1",
+		PageURL: "/home",
+		LCP:     sqlFloat(2500.7),
+		CLS:     sqlFloat(0.12),
+	}}
+	fakes.performance.resources = []repository.ResourceRow{{ID: 2, ResourceURL: "/app.js", ResourceType: "script", Duration: 120}}
+	fakes.stats.sums = map[string][]repository.RollupCountSum{
+		rollupMetricVital + "/name": {{Value: "LCP", Count: 4, Sum: 10000}},
+	}
+	service := fakes.eventService()
+
+	perf, err := service.GetPerformanceList("1", "1", "10", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 未上报的指标保持为 0
+	item := perf.List[0]
+	if item.LCP != 2500 || item.CLS != 0.12 || item.FCP != 0 || perf.Stats.AvgLCP != 2500 || perf.Stats.AvgFCP != 0 {
+		t.Fatalf("性能列表 %+v，统计 %+v", item, perf.Stats)
+	}
+
+	resources, err := service.GetResourcePerformanceList("1", "1", "10", "", "", "script")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakes.performance.resourceType != "script" || resources.Total != 1 || resources.List[0].ResourceURL != "/app.js" || resources.List[0].Duration != 120 {
+		t.Fatalf("资源列表 %+v", resources)
+	}
+}
+
+func TestGetErrorListAndStats(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.errorGroups.groups = []model.ErrorGroup{{Fingerprint: "fp-1", ErrorType: model.ErrorTypeJS, ErrorMessage: "boom", Count: 3}}
+	fakes.stats.sums = map[string][]repository.RollupCountSum{
+		rollupMetricError + "/":        {{Count: 3}},
+		rollupMetricError + "/type":    {{Value: model.ErrorTypeJS, Count: 3}},
+		rollupMetricError + "/browser": {{Value: "Chrome", Count: 2}, {Value: "Safari", Count: 1}},
+	}
+	fakes.stats.sketches = map[string][][]byte{rollupMetricErrorUsers: {sketchOf("u1", "u2")}}
+	service := fakes.eventService()
+
+	resp, err := service.GetErrorList("1", "1", "10", "1700000001000", "1700000002000", model.ErrorTypeJS, "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := repository.ErrorGroupFilter{ErrorType: model.ErrorTypeJS, Severity: "error", StartTime: 1700000001000, EndTime: 1700000002000}
+	if fakes.errorGroups.filter != want {
+		t.Fatalf("筛选条件 %+v，期望 %+v", fakes.errorGroups.filter, want)
+	}
+	if resp.Total != 1 || resp.List[0].Fingerprint != "fp-1" || resp.List[0].Count != 3 {
+		t.Fatalf("错误列表 %+v", resp)
+	}
+	stats := resp.Stats
+	if stats.TotalErrors != 3 || stats.AffectedUsers != 2 || stats.TypeDistribution[model.ErrorTypeJS] != 3 ||
+		stats.BrowserDistribution["Safari"] != 1 || len(stats.OSDistribution) != 0 {
+		t.Fatalf("错误统计 %+v", stats)
+	}
+}
+
+func TestGetBehaviorStatsReadsRollups(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.stats.sums = map[string][]repository.RollupCountSum{
+		rollupMetricPV + "/":           {{Count: 10}},
+		rollupMetricPVStay + "/":       {{Count: 8, Sum: 40000}},
+		rollupMetricBounce + "/":       {{Count: 2}},
+		rollupMetricPV + "/page":       {{Value: "https://example.com/users/1", Count: 3}, {Value: "https://example.com/users/2", Count: 4}},
+		rollupMetricClick + "/":        {{Count: 5}},
+		rollupMetricClick + "/element": {{Value: "button", Count: 5}},
+	}
+	fakes.stats.sketches = map[string][][]byte{rollupMetricUV: {sketchOf("u1", "u2"), sketchOf("u2", "u3")}}
+
+	now := time.Now().UnixMilli()
+	resp, err := fakes.eventService().GetBehaviorStats("1", StatsQuery{
+		StartTime: strconv.FormatInt(now-int64(24*time.Hour/time.Millisecond), 10),
+		EndTime:   strconv.FormatInt(now, 10),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pv := resp.PVStats
+	// 平均停留时间和跳出率只按停留时间已知的访问计算
+	if pv.TotalPV != 10 || pv.TotalUV != 3 || pv.AvgStayTime != 5000 || pv.BounceRate != 25 {
+		t.Fatalf("PV统计 %+v", pv)
+	}
+	// 不同ID的页面合并为同一个归一化路径
+	if len(pv.TopPages) != 1 || pv.TopPages[NormalizeURL("https://example.com/users/1")] != 7 {
+		t.Fatalf("热门页面 %v", pv.TopPages)
+	}
+	if resp.ClickStats.TotalClicks != 5 || resp.ClickStats.TopElements["button"] != 5 {
+		t.Fatalf("点击统计 %+v", resp.ClickStats)
+	}
+}
+
+func TestGetErrorDetailListsRecentEvents(t *testing.T) {
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	req := trackRequest("error", model.ErrorTypeJS, map[string]interface{}{
+		"message":     "boom",
+		"browser":     "Firefox",
+		"breadcrumbs": []interface{}{map[string]interface{}{"type": "click", "message": "button#buy", "timestamp": 1700000000000}},
+	})
+	req.Fingerprint = "fp-1"
+	if err := service.ProcessTrackData(req); err != nil {
+		t.Fatal(err)
+	}
+	group := model.ErrorGroup{Fingerprint: "fp-1", ErrorType: model.ErrorTypeJS, ErrorMessage: "boom", Count: 1}
+	group.ID = 3
+	fakes.errorGroups.groups = []model.ErrorGroup{group}
+
+	resp, err := service.GetErrorDetail("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Group.Fingerprint != "fp-1" || resp.Total != 1 {
+		t.Fatalf("错误详情 %+v", resp)
+	}
+	event := resp.Events[0]
+	if event.EventID != fakes.events.events[0].EventID || event.ErrorMessage != "boom" || event.Browser != "Firefox" ||
+		event.TriggerTime != 1700000000000 || len(event.Breadcrumbs) != 1 {
+		t.Fatalf("错误事件 %+v", event)
+	}
+
+	if _, err := service.GetErrorDetail("4"); err == nil {
+		t.Fatal("不存在的错误分组应返回错误")
+	}
+}
+
+func TestGetErrorStatsFillsTrend(t *testing.T) {
+	fakes := newFakeRepositories()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	hour := int64(time.Hour / time.Millisecond)
+	fakes.stats.sums = map[string][]repository.RollupCountSum{
+		rollupMetricError + "/":   {{Count: 5}},
+		rollupMetricError + "/os": {{Value: "Windows", Count: 5}},
+	}
+	fakes.stats.counts = map[string][]model.RollupCount{
+		rollupMetricError + "/": {
+			{BucketStart: day + hour, Count: 3},
+			{BucketStart: day + 2*hour, Count: 1},
+			{BucketStart: day + 30*hour, Count: 1},
+		},
+	}
+
+	resp, err := fakes.eventService().GetErrorStats("1", StatsQuery{
+		StartTime:   strconv.FormatInt(day, 10),
+		EndTime:     strconv.FormatInt(day+48*hour-1, 10),
+		Granularity: GranularityDay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stats.TotalErrors != 5 || resp.Stats.OSDistribution["Windows"] != 5 {
+		t.Fatalf("错误统计 %+v", resp.Stats)
+	}
+	if len(resp.Trend) != 2 || resp.Trend[0].Count != 4 || resp.Trend[1].Count != 1 {
+		t.Fatalf("错误趋势 %+v", resp.Trend)
+	}
+	// 上一周期没有数据的时间桶补零
+	if len(resp.PreviousTrend) != 2 || resp.PreviousTrend[0].Count != 0 {
+		t.Fatalf("上一周期趋势 %+v", resp.PreviousTrend)
+	}
+}
+
+func TestGetPerformanceStatsAveragesVitals(t *testing.T) {
+	fakes := newFakeRepositories()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	hour := int64(time.Hour / time.Millisecond)
+	fakes.stats.sums = map[string][]repository.RollupCountSum{
+		rollupMetricVital + "/name": {{Value: "FCP", Count: 2, Sum: 3000}, {Value: "CLS", Count: 4, Sum: 0.4}},
+	}
+	fakes.stats.counts = map[string][]model.RollupCount{
+		rollupMetricVital + "/name": {
+			{BucketStart: day + hour, Value: "LCP", Count: 2, Sum: 5000},
+			{BucketStart: day + 30*hour, Value: "TTFB", Count: 1, Sum: 300},
+		},
+	}
+
+	resp, err := fakes.eventService().GetPerformanceStats("1", StatsQuery{
+		StartTime:   strconv.FormatInt(day, 10),
+		EndTime:     strconv.FormatInt(day+48*hour-1, 10),
+		Granularity: GranularityDay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只统计上报了该指标的记录
+	if resp.Stats.AvgFCP != 1500 || resp.Stats.AvgCLS != 0.1 || resp.Stats.AvgLCP != 0 {
+		t.Fatalf("性能统计 %+v", resp.Stats)
+	}
+	if len(resp.Trend) != 2 || resp.Trend[0].LCP != 2500 || resp.Trend[1].TTFB != 300 {
+		t.Fatalf("性能趋势 %+v", resp.Trend)
+	}
+	if len(resp.Vitals) != len(vitalMetrics) {
+		t.Fatalf("Web Vitals %+v", resp.Vitals)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 事件分析存储类型
+const (
+	EventStoreGORM       = "gorm"
+	EventStoreClickHouse = "clickhouse"
+	EventStoreMemory     = "memory"
+)
+
+// EventStore 事件分析存储，保存从关系库复制的事件并负责需要扫描原始事件的统计查询。
+// 默认直接查询关系库，此时不需要同步；数据量大时可切换为列式存储，由同步任务从关系库复制事件。
+// 存储只是关系库的分析副本，只有事件趋势（GetEventStats）、分布统计（GetDistribution）和
+// 接口性能（GetAPIPerformance）读取存储，事件浏览、预聚合、路径、热力图、Web Vitals、挫败点击等查询仍读取关系库
+type EventStore interface {
+	// Replicated 是否需要从关系库同步事件
+	Replicated() bool
+	// SyncCursor 已同步到的事件主表ID
+	SyncCursor() (uint, error)
+	// Write 写入一批事件并记录同步位置
+	Write(events []repository.StoredEvent, lastID uint) error
+	// Purge 删除项目在时间范围 [startTime, endTime) 内指定类型的事件
+	Purge(projectID uint, eventTypes []string, startTime, endTime int64) error
+
+	// CountSlots 按固定长度的时间片统计事件数量，返回时间片序号（触发时间整除时间片长度）到数量
+	CountSlots(projectID uint64, filter EventFilter, slotSize int64) (map[int64]int64, error)
+	// Distribution 按字段分组统计事件数量，字段为 distributionFields 中的字段名，包含 error_type 时只统计错误事件
+	Distribution(projectID uint64, fields []string, filter EventFilter) ([]DistributionRow, error)
+	// APIStats 按请求方法和接口模板聚合接口请求，返回的列表未排序
+	APIStats(projectID uint64, filter APIPerformanceFilter) ([]APIPerformanceItem, error)
+}
+
+// DistributionRow 分布统计的一组字段值和数量
+type DistributionRow struct {
+	Values []string
+	Count  int64
+}
+
+// NewEventStore 根据配置创建事件存储，列式存储会先创建所需的表
+func NewEventStore(repos *repository.Repositories, setting *model.EventStore) (EventStore, error) {
+	switch setting.Type {
+	case "", EventStoreGORM:
+		return gormEventStore{events: repos.Events, performance: repos.Performance}, nil
+	case EventStoreClickHouse:
+		store := newClickHouseEventStore(repos.JobCursors, setting)
+		if err := store.ensureSchema(); err != nil {
+			return nil, err
+		}
+		return store, nil
+	case EventStoreMemory:
+		return newMemoryEventStore(), nil
+	default:
+		return nil, errors.New("不支持的事件存储类型")
+	}
+}
+
+// 同步任务，事件存储不需要同步时返回 nil
+func eventStoreSyncJob(events repository.EventRepository, store EventStore) func() error {
+	if !store.Replicated() {
+		return nil
+	}
+	return func() error {
+		_, err := syncEventStore(events, store)
+		return err
+	}
+}
+
+// 将关系库中新入库的事件分批复制到事件存储，返回同步的事件数量。
+// 事件详情在事件主表之后写入，只同步入库超过等待时间的事件
+func syncEventStore(repo repository.EventRepository, store EventStore) (int, error) {
+	setting := model.EventStoreSetting
+	settleBefore := time.Now().Add(-time.Duration(setting.SettleDelay) * time.Second)
+
+	total := 0
+	for {
+		cursor, err := store.SyncCursor()
+		if err != nil {
+			return total, err
+		}
+
+		events, err := repo.ListStoredEvents(cursor, settleBefore, setting.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(events) == 0 {
+			return total, nil
+		}
+
+		if err := store.Write(events, events[len(events)-1].ID); err != nil {
+			return total, err
+		}
+		total += len(events)
+		if len(events) < setting.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// 拆分逗号分隔的事件类型
+func filterEventTypes(filter EventFilter) []string {
+	var eventTypes []string
+	for _, eventType := range strings.Split(filter.EventType, ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes
+}
+
+// 解析筛选条件中的时间范围，秒级时间戳转换为毫秒，未指定的一端返回 ok=false
+func filterTimeRange(startTimeStr, endTimeStr string) (start int64, hasStart bool, end int64, hasEnd bool) {
+	if startTimeStr != "" {
+		if parsed, err := strconv.ParseInt(startTimeStr, 10, 64); err == nil {
+			start, hasStart = timestampMillis(parsed), true
+		}
+	}
+	if endTimeStr != "" {
+		if parsed, err := strconv.ParseInt(endTimeStr, 10, 64); err == nil {
+			end, hasEnd = timestampMillis(parsed), true
+		}
+	}
+	return
+}
+
+// 转换为仓储的事件查询条件
+func (filter EventFilter) query(projectID uint64) repository.EventQuery {
+	return repository.EventQuery{
+		TimeRange:  parseTimeRange(filter.StartTime, filter.EndTime),
+		ProjectID:  uint(projectID),
+		EventTypes: filterEventTypes(filter),
+		PageURL:    filter.PageURL,
+		UserID:     filter.UserID,
+		SessionID:  filter.SessionID,
+		Browser:    filter.Browser,
+		OS:         filter.OS,
+		DeviceType: filter.DeviceType,
+		Release:    filter.Release,
+	}
+}
+
+// 解析筛选条件中的时间范围，未指定或无法解析的一端不限制
+func parseTimeRange(startTimeStr, endTimeStr string) repository.TimeRange {
+	start, _, end, _ := filterTimeRange(startTimeStr, endTimeStr)
+	return repository.TimeRange{StartTime: start, EndTime: end}
+}
+
+// 采样记录代表的调用量，为采样率的倒数
+func requestSampleWeight(sampleRate float64) float64 {
+	if sampleRate > 0 && sampleRate < 1 {
+		return 1 / sampleRate
+	}
+	return 1
+}
+
+// 按请求方法和接口模板聚合请求记录，分位数在内存中精确计算
+func aggregateAPIRequests(rows []repository.APIRequestSample) []APIPerformanceItem {
+	type apiKey struct{ method, template string }
+	groups := make(map[apiKey]*apiRequestGroup)
+	var keys []apiKey
+	for _, row := range rows {
+		key := apiKey{method: row.Method, template: row.URLTemplate}
+		group, ok := groups[key]
+		if !ok {
+			group = &apiRequestGroup{item: APIPerformanceItem{
+				Method:             row.Method,
+				URLTemplate:        row.URLTemplate,
+				StatusDistribution: make(map[string]int64),
+			}}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+
+		weight := requestSampleWeight(row.SampleRate)
+		group.item.Samples++
+		group.calls += weight
+		if row.Success {
+			group.successes += weight
+		}
+		group.item.StatusDistribution[strconv.Itoa(row.Status)]++
+		if row.Duration > 0 {
+			group.durations = append(group.durations, row.Duration)
+		}
+	}
+
+	list := make([]APIPerformanceItem, 0, len(groups))
+	for _, key := range keys {
+		group := groups[key]
+		item := group.item
+		item.Calls = int64(math.Round(group.calls))
+		if group.calls > 0 {
+			item.SuccessRate = roundVital(group.successes / group.calls)
+		}
+		item.AvgDuration = roundVital(average(group.durations))
+		item.P50Duration = percentile(group.durations, 0.5)
+		item.P95Duration = percentile(group.durations, 0.95)
+		list = append(list, item)
+	}
+	return list
+}
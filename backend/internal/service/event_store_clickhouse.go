@@ -0,0 +1,497 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+const (
+	// 同步到 ClickHouse 的任务游标名称
+	clickHouseSyncJob = "clickhouse_sync"
+	// 小时预聚合的时间片长度（毫秒）
+	clickHouseHourSlot = int64(time.Hour / time.Millisecond)
+)
+
+// ClickHouse 事件存储，事件写入按项目、类型和时间排序的宽表，
+// 物化视图按小时汇总事件数量，分位数使用 ClickHouse 的 quantiles 计算。
+// 通过 HTTP 接口访问，查询条件使用查询参数传递
+type clickHouseEventStore struct {
+	mu       sync.Mutex                     // 清理和同步写入互斥，保证小时汇总与宽表一致
+	cursors  repository.JobCursorRepository // 保存同步游标
+	endpoint string
+	database string
+	user     string
+	password string
+	ttlDays  int
+	client   *http.Client
+}
+
+func newClickHouseEventStore(cursors repository.JobCursorRepository, setting *model.EventStore) *clickHouseEventStore {
+	return &clickHouseEventStore{
+		cursors:  cursors,
+		endpoint: strings.TrimRight(setting.Url, "/") + "/",
+		database: setting.Database,
+		user:     setting.User,
+		password: setting.Password,
+		ttlDays:  setting.TTLDays,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// 宽表名称
+func (c *clickHouseEventStore) eventsTable() string {
+	return model.TableName("events")
+}
+
+// 小时汇总表名称
+func (c *clickHouseEventStore) hourlyTable() string {
+	return model.TableName("events_hourly")
+}
+
+// 创建数据库、宽表和小时汇总的物化视图，已存在时跳过。
+// 保留天数只在建表时生效，修改后需要手动执行 ALTER TABLE ... MODIFY TTL
+func (c *clickHouseEventStore) ensureSchema() error {
+	eventsTTL, hourlyTTL := "", ""
+	if c.ttlDays > 0 {
+		eventsTTL = fmt.Sprintf("TTL toDateTime(intDiv(trigger_time, 1000)) + INTERVAL %d DAY\n", c.ttlDays)
+		hourlyTTL = fmt.Sprintf("TTL toDateTime(hour * 3600) + INTERVAL %d DAY\n", c.ttlDays)
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", c.database),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id UInt64,
+	event_id String,
+	event_type LowCardinality(String),
+	project_id UInt32,
+	trigger_time Int64,
+	page_url String,
+	title String,
+	trace_id String,
+	user_id String,
+	user_uuid String,
+	session_id String,
+	browser LowCardinality(String),
+	browser_version LowCardinality(String),
+	os LowCardinality(String),
+	os_version LowCardinality(String),
+	device_type LowCardinality(String),
+	screen_width UInt32,
+	sdk_version LowCardinality(String),
+	release LowCardinality(String),
+	environment LowCardinality(String),
+	region LowCardinality(String),
+	error_type LowCardinality(String),
+	method LowCardinality(String),
+	url_template String,
+	status Int32,
+	duration Float64,
+	success Bool,
+	sample_rate Float64
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(toDateTime(intDiv(trigger_time, 1000)))
+ORDER BY (project_id, event_type, trigger_time, id)
+%sSETTINGS non_replicated_deduplication_window = 1000`, c.eventsTable(), eventsTTL),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	project_id UInt32,
+	event_type LowCardinality(String),
+	hour Int64,
+	count UInt64
+)
+ENGINE = SummingMergeTree
+ORDER BY (project_id, event_type, hour)
+%s`, c.hourlyTable(), hourlyTTL),
+		fmt.Sprintf(`CREATE MATERIALIZED VIEW IF NOT EXISTS %s_mv TO %s AS
+SELECT project_id, event_type, intDiv(trigger_time, %d) AS hour, count() AS count
+FROM %s
+GROUP BY project_id, event_type, hour`, c.hourlyTable(), c.hourlyTable(), clickHouseHourSlot, c.eventsTable()),
+	}
+	for i, statement := range statements {
+		var params url.Values
+		if i == 0 {
+			// 数据库尚未创建，在默认数据库中执行
+			params = url.Values{"database": {"default"}}
+		}
+		if _, err := c.do(statement, params, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 执行查询，body 不为空时作为 INSERT 的数据，否则查询语句作为请求体
+func (c *clickHouseEventStore) do(query string, params url.Values, body []byte) ([]byte, error) {
+	values := url.Values{}
+	for key, value := range params {
+		values[key] = value
+	}
+	if values.Get("database") == "" {
+		values.Set("database", c.database)
+	}
+	// 64 位整数按数字输出，便于直接解析
+	values.Set("output_format_json_quote_64bit_integers", "0")
+
+	var reader io.Reader
+	if body != nil {
+		values.Set("query", query)
+		reader = bytes.NewReader(body)
+	} else {
+		reader = strings.NewReader(query)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint+"?"+values.Encode(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("clickhouse: %s", strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// 执行查询并按行解析 JSONEachRow 结果
+func (c *clickHouseEventStore) query(query string, params url.Values, row func() interface{}) error {
+	data, err := c.do(query+" FORMAT JSONEachRow", params, nil)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		if err := decoder.Decode(row()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clickHouseEventStore) Replicated() bool {
+	return true
+}
+
+func (c *clickHouseEventStore) SyncCursor() (uint, error) {
+	return c.cursors.Get(clickHouseSyncJob)
+}
+
+// 写入一批事件后推进游标，写入成功但游标未保存时会重新写入同一批事件，
+// 相同批次以游标区间作为去重标识，ClickHouse 会忽略重复写入
+func (c *clickHouseEventStore) Write(events []repository.StoredEvent, lastID uint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return err
+		}
+	}
+
+	params := url.Values{}
+	params.Set("insert_deduplication_token", fmt.Sprintf("%d-%d", events[0].ID, lastID))
+	if _, err := c.do("INSERT INTO "+c.eventsTable()+" FORMAT JSONEachRow", params, body.Bytes()); err != nil {
+		return err
+	}
+	return c.cursors.Save(clickHouseSyncJob, lastID)
+}
+
+// 删除事件并同步修正小时汇总：先删除涉及的全部小时，再按剩余事件重新统计只删除了部分事件的首尾两个小时。
+// 删除等待执行完成后再重新统计，执行期间暂停同步写入，避免新写入的事件被重复计入
+func (c *clickHouseEventStore) Purge(projectID uint, eventTypes []string, startTime, endTime int64) error {
+	if len(eventTypes) == 0 || startTime >= endTime {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	params := url.Values{}
+	params.Set("mutations_sync", "1")
+	params.Set("param_project", strconv.FormatUint(uint64(projectID), 10))
+	params.Set("param_types", clickHouseStringArray(eventTypes))
+	params.Set("param_start", strconv.FormatInt(startTime, 10))
+	params.Set("param_end", strconv.FormatInt(endTime, 10))
+	if _, err := c.do("ALTER TABLE "+c.eventsTable()+" DELETE WHERE project_id = {project:UInt32} AND event_type IN {types:Array(String)}"+
+		" AND trigger_time >= {start:Int64} AND trigger_time < {end:Int64}", params, nil); err != nil {
+		return err
+	}
+
+	firstHour := startTime / clickHouseHourSlot
+	lastHour := (endTime - 1) / clickHouseHourSlot
+	params.Set("param_start", strconv.FormatInt(firstHour, 10))
+	params.Set("param_end", strconv.FormatInt(lastHour, 10))
+	if _, err := c.do("ALTER TABLE "+c.hourlyTable()+" DELETE WHERE project_id = {project:UInt32} AND event_type IN {types:Array(String)}"+
+		" AND hour >= {start:Int64} AND hour <= {end:Int64}", params, nil); err != nil {
+		return err
+	}
+
+	// 时间范围两端整点对齐时没有部分删除的小时
+	if startTime%clickHouseHourSlot == 0 && endTime%clickHouseHourSlot == 0 {
+		return nil
+	}
+	// 范围内的事件已删除，剩余的只有首尾两个小时中范围之外的事件
+	params.Del("mutations_sync")
+	params.Set("param_start", strconv.FormatInt(firstHour*clickHouseHourSlot, 10))
+	params.Set("param_end", strconv.FormatInt((lastHour+1)*clickHouseHourSlot, 10))
+	params.Set("param_slot", strconv.FormatInt(clickHouseHourSlot, 10))
+	_, err := c.do(fmt.Sprintf(`INSERT INTO %s (project_id, event_type, hour, count)
+SELECT project_id, event_type, intDiv(trigger_time, {slot:Int64}) AS hour, count() AS count
+FROM %s
+WHERE project_id = {project:UInt32} AND event_type IN {types:Array(String)} AND trigger_time >= {start:Int64} AND trigger_time < {end:Int64}
+GROUP BY project_id, event_type, hour`, c.hourlyTable(), c.eventsTable()), params, nil)
+	return err
+}
+
+// 将筛选条件转换为查询条件和参数，条件与关系库仓储的事件查询一致
+func clickHouseEventFilter(projectID uint64, filter EventFilter) ([]string, url.Values) {
+	conditions := []string{"project_id = {project:UInt32}"}
+	params := url.Values{}
+	params.Set("param_project", strconv.FormatUint(projectID, 10))
+
+	if eventTypes := filterEventTypes(filter); len(eventTypes) > 0 {
+		conditions = append(conditions, "event_type IN {types:Array(String)}")
+		params.Set("param_types", clickHouseStringArray(eventTypes))
+	}
+
+	start, hasStart, end, hasEnd := filterTimeRange(filter.StartTime, filter.EndTime)
+	if hasStart {
+		conditions = append(conditions, "trigger_time >= {start:Int64}")
+		params.Set("param_start", strconv.FormatInt(start, 10))
+	}
+	if hasEnd {
+		conditions = append(conditions, "trigger_time <= {end:Int64}")
+		params.Set("param_end", strconv.FormatInt(end, 10))
+	}
+
+	if filter.PageURL != "" {
+		conditions = append(conditions, "positionCaseInsensitiveUTF8(page_url, {page:String}) > 0")
+		params.Set("param_page", filter.PageURL)
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, "(user_id = {user:String} OR user_uuid = {user:String})")
+		params.Set("param_user", filter.UserID)
+	}
+
+	equals := []struct{ column, value string }{
+		{"session_id", filter.SessionID},
+		{"browser", filter.Browser},
+		{"os", filter.OS},
+		{"device_type", filter.DeviceType},
+		{"release", filter.Release},
+	}
+	for _, item := range equals {
+		if item.value != "" {
+			conditions = append(conditions, fmt.Sprintf("%s = {%s:String}", item.column, item.column))
+			params.Set("param_"+item.column, item.value)
+		}
+	}
+	return conditions, params
+}
+
+// 筛选条件是否只包含项目、事件类型和整点对齐的时间范围，此时可以读取小时汇总
+func clickHouseHourlyFilter(filter EventFilter, slotSize int64) bool {
+	if slotSize != clickHouseHourSlot {
+		return false
+	}
+	if filter.PageURL != "" || filter.UserID != "" || filter.SessionID != "" || filter.Browser != "" ||
+		filter.OS != "" || filter.DeviceType != "" || filter.Release != "" {
+		return false
+	}
+	start, hasStart, end, hasEnd := filterTimeRange(filter.StartTime, filter.EndTime)
+	return hasStart && hasEnd && start%clickHouseHourSlot == 0 && (end+1)%clickHouseHourSlot == 0
+}
+
+func (c *clickHouseEventStore) CountSlots(projectID uint64, filter EventFilter, slotSize int64) (map[int64]int64, error) {
+	conditions, params := clickHouseEventFilter(projectID, filter)
+
+	var query string
+	if clickHouseHourlyFilter(filter, slotSize) {
+		// 小时汇总的时间条件换算为小时序号
+		start, _, end, _ := filterTimeRange(filter.StartTime, filter.EndTime)
+		conditions = conditions[:1]
+		if params.Has("param_types") {
+			conditions = append(conditions, "event_type IN {types:Array(String)}")
+		}
+		conditions = append(conditions, "hour >= {start:Int64}", "hour < {end:Int64}")
+		params.Set("param_start", strconv.FormatInt(start/clickHouseHourSlot, 10))
+		params.Set("param_end", strconv.FormatInt((end+1)/clickHouseHourSlot, 10))
+		query = fmt.Sprintf("SELECT hour AS slot, sum(count) AS count FROM %s WHERE %s GROUP BY slot",
+			c.hourlyTable(), strings.Join(conditions, " AND "))
+	} else {
+		params.Set("param_slot", strconv.FormatInt(slotSize, 10))
+		query = fmt.Sprintf("SELECT intDiv(trigger_time, {slot:Int64}) AS slot, count() AS count FROM %s WHERE %s GROUP BY slot",
+			c.eventsTable(), strings.Join(conditions, " AND "))
+	}
+
+	type slotRow struct {
+		Slot  int64 `json:"slot"`
+		Count int64 `json:"count"`
+	}
+	var rows []*slotRow
+	if err := c.query(query, params, func() interface{} {
+		rows = append(rows, &slotRow{})
+		return rows[len(rows)-1]
+	}); err != nil {
+		return nil, err
+	}
+
+	slots := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		slots[row.Slot] += row.Count
+	}
+	return slots, nil
+}
+
+func (c *clickHouseEventStore) Distribution(projectID uint64, fields []string, filter EventFilter) ([]DistributionRow, error) {
+	conditions, params := clickHouseEventFilter(projectID, filter)
+
+	selects := make([]string, 0, len(fields)+1)
+	groups := make([]string, 0, len(fields))
+	for i, field := range fields {
+		if !isDistributionField(field) {
+			return nil, errors.New("不支持的统计维度")
+		}
+		if field == "error_type" {
+			conditions = append(conditions, "event_type = {error:String}")
+			params.Set("param_error", model.EventTypeError)
+		}
+		selects = append(selects, fmt.Sprintf("toString(%s) AS v%d", field, i))
+		groups = append(groups, "v"+strconv.Itoa(i))
+	}
+	selects = append(selects, "count() AS count")
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s GROUP BY %s",
+		strings.Join(selects, ", "), c.eventsTable(), strings.Join(conditions, " AND "), strings.Join(groups, ", "))
+
+	var raw []map[string]interface{}
+	if err := c.query(query, params, func() interface{} {
+		raw = append(raw, map[string]interface{}{})
+		return &raw[len(raw)-1]
+	}); err != nil {
+		return nil, err
+	}
+
+	rows := make([]DistributionRow, 0, len(raw))
+	for _, item := range raw {
+		values := make([]string, len(fields))
+		for i := range fields {
+			values[i], _ = item["v"+strconv.Itoa(i)].(string)
+		}
+		count, _ := item["count"].(float64)
+		rows = append(rows, DistributionRow{Values: values, Count: int64(count)})
+	}
+	return rows, nil
+}
+
+// 接口聚合结果
+type clickHouseAPIRow struct {
+	Method       string    `json:"method"`
+	URLTemplate  string    `json:"url_template"`
+	Samples      int64     `json:"samples"`
+	Calls        float64   `json:"calls"`
+	Successes    float64   `json:"successes"`
+	AvgDuration  float64   `json:"avg_duration"`
+	Quantiles    []float64 `json:"quantiles"`
+	StatusCodes  []string  `json:"status_codes"`
+	StatusCounts []int64   `json:"status_counts"`
+}
+
+func (c *clickHouseEventStore) APIStats(projectID uint64, filter APIPerformanceFilter) ([]APIPerformanceItem, error) {
+	conditions, params := clickHouseEventFilter(projectID, EventFilter{
+		EventType: model.EventTypeRequest,
+		StartTime: filter.StartTime,
+		EndTime:   filter.EndTime,
+	})
+	if filter.Method != "" {
+		conditions = append(conditions, "method = {method:String}")
+		params.Set("param_method", strings.ToUpper(filter.Method))
+	}
+	if filter.Keyword != "" {
+		conditions = append(conditions, "positionCaseInsensitiveUTF8(url_template, {keyword:String}) > 0")
+		params.Set("param_keyword", filter.Keyword)
+	}
+
+	// 每条记录按采样率的倒数计入调用量，没有耗时的记录不参与耗时统计
+	weight := "if(sample_rate > 0 AND sample_rate < 1, 1 / sample_rate, 1)"
+	query := fmt.Sprintf(`SELECT method, url_template,
+	count() AS samples,
+	sum(%s) AS calls,
+	sumIf(%s, success) AS successes,
+	ifNotFinite(avgIf(duration, duration > 0), 0) AS avg_duration,
+	arrayMap(x -> ifNotFinite(x, 0), quantilesIf(0.5, 0.95)(duration, duration > 0)) AS quantiles,
+	sumMap([toString(status)], [toUInt64(1)]).1 AS status_codes,
+	sumMap([toString(status)], [toUInt64(1)]).2 AS status_counts
+FROM %s
+WHERE %s
+GROUP BY method, url_template`, weight, weight, c.eventsTable(), strings.Join(conditions, " AND "))
+
+	var rows []clickHouseAPIRow
+	if err := c.query(query, params, func() interface{} {
+		rows = append(rows, clickHouseAPIRow{})
+		return &rows[len(rows)-1]
+	}); err != nil {
+		return nil, err
+	}
+
+	list := make([]APIPerformanceItem, 0, len(rows))
+	for _, row := range rows {
+		item := APIPerformanceItem{
+			Method:             row.Method,
+			URLTemplate:        row.URLTemplate,
+			Samples:            row.Samples,
+			Calls:              int64(row.Calls + 0.5),
+			AvgDuration:        roundVital(row.AvgDuration),
+			StatusDistribution: make(map[string]int64, len(row.StatusCodes)),
+		}
+		if row.Calls > 0 {
+			item.SuccessRate = roundVital(row.Successes / row.Calls)
+		}
+		if len(row.Quantiles) == 2 {
+			item.P50Duration = roundVital(row.Quantiles[0])
+			item.P95Duration = roundVital(row.Quantiles[1])
+		}
+		for i, code := range row.StatusCodes {
+			if i < len(row.StatusCounts) {
+				item.StatusDistribution[code] = row.StatusCounts[i]
+			}
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// 格式化字符串数组，作为 Array(String) 类型的查询参数值
+func clickHouseStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	for i, value := range values {
+		quoted[i] = "'" + replacer.Replace(value) + "'"
+	}
+	return "[" + strings.Join(quoted, ",") + "]"
+}
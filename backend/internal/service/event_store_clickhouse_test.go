@@ -0,0 +1,249 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// ClickHouse 收到的请求
+type clickHouseRequest struct {
+	query  string
+	params url.Values
+	body   string
+}
+
+// 模拟 ClickHouse HTTP 接口，记录收到的请求，按查询语句返回预置结果
+type fakeClickHouse struct {
+	requests  []clickHouseRequest
+	responses map[string]string // 查询语句包含的片段到 JSONEachRow 结果
+}
+
+func (f *fakeClickHouse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	params := r.URL.Query()
+	req := clickHouseRequest{query: string(data), params: params}
+	if query := params.Get("query"); query != "" {
+		req.query, req.body = query, string(data)
+	}
+	f.requests = append(f.requests, req)
+
+	for fragment, response := range f.responses {
+		if strings.Contains(req.query, fragment) {
+			io.WriteString(w, response)
+			return
+		}
+	}
+}
+
+func newTestClickHouseEventStore(t *testing.T, responses map[string]string) (*clickHouseEventStore, *fakeClickHouse, *fakeJobCursors) {
+	server := &fakeClickHouse{responses: responses}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	cursors := &fakeJobCursors{}
+	store := newClickHouseEventStore(cursors, &model.EventStore{Url: httpServer.URL, Database: "tracing"})
+	return store, server, cursors
+}
+
+func TestClickHouseEventStorePurgeUsesQueryParameters(t *testing.T) {
+	store, server, _ := newTestClickHouseEventStore(t, nil)
+
+	hour := clickHouseHourSlot
+	if err := store.Purge(testProjectID, []string{model.EventTypePV, "x') OR 1=1 --"}, hour/2, 3*hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.requests) != 3 {
+		t.Fatalf("执行了 %d 条语句", len(server.requests))
+	}
+
+	events, hourly, recount := server.requests[0], server.requests[1], server.requests[2]
+	if !strings.HasPrefix(events.query, "ALTER TABLE "+store.eventsTable()+" DELETE") ||
+		!strings.HasPrefix(hourly.query, "ALTER TABLE "+store.hourlyTable()+" DELETE") ||
+		!strings.HasPrefix(recount.query, "INSERT INTO "+store.hourlyTable()) {
+		t.Fatalf("语句 %q %q %q", events.query, hourly.query, recount.query)
+	}
+	for _, req := range server.requests {
+		// 条件值只通过查询参数传递
+		if strings.Contains(req.query, model.EventTypePV) || strings.Contains(req.query, "OR 1=1") {
+			t.Fatalf("语句中包含条件值 %q", req.query)
+		}
+		if req.params.Get("param_project") != "1" || req.params.Get("param_types") != `['pv','x\') OR 1=1 --']` ||
+			req.params.Get("database") != "tracing" {
+			t.Fatalf("查询参数 %v", req.params)
+		}
+	}
+	// 删除执行完成后才重新统计
+	if events.params.Get("mutations_sync") != "1" || hourly.params.Get("mutations_sync") != "1" {
+		t.Fatalf("删除未等待执行完成 %v %v", events.params, hourly.params)
+	}
+	if events.params.Get("param_start") != "1800000" || events.params.Get("param_end") != "10800000" {
+		t.Fatalf("事件时间范围 %v", events.params)
+	}
+	// 小时汇总删除涉及的全部小时，再重新统计部分删除的小时
+	if hourly.params.Get("param_start") != "0" || hourly.params.Get("param_end") != "2" {
+		t.Fatalf("小时范围 %v", hourly.params)
+	}
+	if recount.params.Get("param_start") != "0" || recount.params.Get("param_end") != "10800000" {
+		t.Fatalf("重新统计的时间范围 %v", recount.params)
+	}
+}
+
+func TestClickHouseEventStorePurgeAlignedHours(t *testing.T) {
+	store, server, _ := newTestClickHouseEventStore(t, nil)
+
+	// 整点对齐的范围不需要重新统计
+	hour := clickHouseHourSlot
+	if err := store.Purge(testProjectID, []string{model.EventTypePV}, hour, 3*hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.requests) != 2 {
+		t.Fatalf("执行了 %d 条语句", len(server.requests))
+	}
+	if hourly := server.requests[1]; hourly.params.Get("param_start") != "1" || hourly.params.Get("param_end") != "2" {
+		t.Fatalf("小时范围 %v", hourly.params)
+	}
+
+	if err := store.Purge(testProjectID, nil, 0, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Purge(testProjectID, []string{model.EventTypePV}, 2000, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.requests) != 2 {
+		t.Fatal("没有事件类型或时间范围为空时不应执行删除")
+	}
+}
+
+func TestClickHouseEventStoreWrite(t *testing.T) {
+	store, server, cursors := newTestClickHouseEventStore(t, nil)
+
+	events := []repository.StoredEvent{
+		{ID: 3, EventType: model.EventTypePV, ProjectID: testProjectID, TriggerTime: 1000},
+		{ID: 5, EventType: model.EventTypeError, ProjectID: testProjectID, TriggerTime: 2000},
+	}
+	if err := store.Write(events, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	req := server.requests[0]
+	if !strings.HasPrefix(req.query, "INSERT INTO "+store.eventsTable()) || req.params.Get("insert_deduplication_token") != "3-5" {
+		t.Fatalf("写入请求 %q %v", req.query, req.params)
+	}
+	if lines := strings.Split(strings.TrimSpace(req.body), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"event_type":"error"`) {
+		t.Fatalf("写入数据 %q", req.body)
+	}
+	if cursor, _ := cursors.Get(clickHouseSyncJob); cursor != 5 {
+		t.Fatalf("同步游标 %d", cursor)
+	}
+}
+
+func TestClickHouseEventStoreWriteKeepsCursorOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Code: 241. Memory limit exceeded", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cursors := &fakeJobCursors{}
+	store := newClickHouseEventStore(cursors, &model.EventStore{Url: server.URL, Database: "tracing"})
+	err := store.Write([]repository.StoredEvent{{ID: 1}}, 1)
+	if err == nil || !strings.Contains(err.Error(), "Memory limit exceeded") {
+		t.Fatalf("错误 %v", err)
+	}
+	if cursor, _ := cursors.Get(clickHouseSyncJob); cursor != 0 {
+		t.Fatalf("写入失败时不应推进游标 %d", cursor)
+	}
+}
+
+func TestClickHouseEventStoreCountSlots(t *testing.T) {
+	store, server, _ := newTestClickHouseEventStore(t, map[string]string{
+		"SELECT": `{"slot":1,"count":2}` + "\n" + `{"slot":2,"count":3}` + "\n",
+	})
+
+	// 整点对齐的时间范围读取小时汇总
+	hour := clickHouseHourSlot
+	slots, err := store.CountSlots(testProjectID, EventFilter{EventType: "pv", StartTime: "1699999200000", EndTime: "1700009999999"}, hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int64]int64{1: 2, 2: 3}; !reflect.DeepEqual(slots, want) {
+		t.Fatalf("时间片 %v", slots)
+	}
+	req := server.requests[0]
+	if !strings.Contains(req.query, store.hourlyTable()) || req.params.Get("param_start") != "472222" ||
+		req.params.Get("param_end") != "472225" || req.params.Get("param_types") != "['pv']" {
+		t.Fatalf("小时汇总查询 %q %v", req.query, req.params)
+	}
+
+	// 包含其他筛选条件时扫描宽表
+	if _, err := store.CountSlots(testProjectID, EventFilter{StartTime: "1699999200000", EndTime: "1700009999999", Browser: "Chrome"}, hour); err != nil {
+		t.Fatal(err)
+	}
+	req = server.requests[1]
+	if !strings.Contains(req.query, "FROM "+store.eventsTable()+" ") || strings.Contains(req.query, "Chrome") ||
+		req.params.Get("param_browser") != "Chrome" || req.params.Get("param_slot") != "3600000" {
+		t.Fatalf("宽表查询 %q %v", req.query, req.params)
+	}
+}
+
+func TestClickHouseEventStoreDistribution(t *testing.T) {
+	store, server, _ := newTestClickHouseEventStore(t, map[string]string{
+		"SELECT": `{"v0":"TypeError","count":4}` + "\n",
+	})
+
+	rows, err := store.Distribution(testProjectID, []string{"error_type"}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []DistributionRow{{Values: []string{"TypeError"}, Count: 4}}; !reflect.DeepEqual(rows, want) {
+		t.Fatalf("分布 %+v", rows)
+	}
+	// 错误类型只统计错误事件
+	if req := server.requests[0]; !strings.Contains(req.query, "event_type = {error:String}") || req.params.Get("param_error") != model.EventTypeError {
+		t.Fatalf("分布查询 %q %v", req.query, req.params)
+	}
+
+	if _, err := store.Distribution(testProjectID, []string{"password"}, EventFilter{}); err == nil {
+		t.Fatal("不支持的字段应返回错误")
+	}
+	if len(server.requests) != 1 {
+		t.Fatal("不支持的字段不应执行查询")
+	}
+}
+
+func TestClickHouseEventStoreAPIStats(t *testing.T) {
+	store, server, _ := newTestClickHouseEventStore(t, map[string]string{
+		"SELECT": `{"method":"GET","url_template":"/api/users/:id","samples":2,"calls":3,"successes":2,` +
+			`"avg_duration":200,"quantiles":[150,290],"status_codes":["200","500"],"status_counts":[1,1]}` + "\n",
+	})
+
+	list, err := store.APIStats(testProjectID, APIPerformanceFilter{Method: "get", Keyword: "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []APIPerformanceItem{{
+		Method:             "GET",
+		URLTemplate:        "/api/users/:id",
+		Samples:            2,
+		Calls:              3,
+		SuccessRate:        roundVital(2.0 / 3),
+		AvgDuration:        200,
+		P50Duration:        150,
+		P95Duration:        290,
+		StatusDistribution: map[string]int64{"200": 1, "500": 1},
+	}}
+	if !reflect.DeepEqual(list, want) {
+		t.Fatalf("接口统计 %+v", list)
+	}
+	if req := server.requests[0]; req.params.Get("param_method") != "GET" || req.params.Get("param_keyword") != "users" ||
+		req.params.Get("param_types") != "['request']" {
+		t.Fatalf("接口查询参数 %v", req.params)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 直接查询关系库的事件存储
+type gormEventStore struct {
+	events      repository.EventRepository
+	performance repository.PerformanceRepository
+}
+
+// 事件就在关系库中，不需要同步
+func (g gormEventStore) Replicated() bool {
+	return false
+}
+
+func (g gormEventStore) SyncCursor() (uint, error) {
+	return 0, nil
+}
+
+func (g gormEventStore) Write(events []repository.StoredEvent, lastID uint) error {
+	return nil
+}
+
+// 关系库中的事件由数据清理直接删除
+func (g gormEventStore) Purge(projectID uint, eventTypes []string, startTime, endTime int64) error {
+	return nil
+}
+
+func (g gormEventStore) CountSlots(projectID uint64, filter EventFilter, slotSize int64) (map[int64]int64, error) {
+	return g.events.CountSlots(filter.query(projectID), slotSize)
+}
+
+func (g gormEventStore) Distribution(projectID uint64, fields []string, filter EventFilter) ([]DistributionRow, error) {
+	counts, err := g.events.CountByFields(filter.query(projectID), fields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]DistributionRow, 0, len(counts))
+	for _, count := range counts {
+		rows = append(rows, DistributionRow{Values: count.Values, Count: count.Count})
+	}
+	return rows, nil
+}
+
+func (g gormEventStore) APIStats(projectID uint64, filter APIPerformanceFilter) ([]APIPerformanceItem, error) {
+	rows, err := g.performance.APIRequests(uint(projectID), repository.APIRequestFilter{
+		TimeRange: parseTimeRange(filter.StartTime, filter.EndTime),
+		Method:    filter.Method,
+		Keyword:   filter.Keyword,
+	}, maxAPIPerformanceRows)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateAPIRequests(rows), nil
+}
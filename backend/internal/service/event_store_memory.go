@@ -0,0 +1,183 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 内存中的事件存储，从关系库同步事件后在内存中统计，重启后重新同步。
+// 用于本地开发和验证其他存储的查询结果，不适合生产环境
+type memoryEventStore struct {
+	mu     sync.RWMutex
+	events []repository.StoredEvent
+	lastID uint
+}
+
+func newMemoryEventStore() *memoryEventStore {
+	return &memoryEventStore{}
+}
+
+func (m *memoryEventStore) Replicated() bool {
+	return true
+}
+
+func (m *memoryEventStore) SyncCursor() (uint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastID, nil
+}
+
+func (m *memoryEventStore) Write(events []repository.StoredEvent, lastID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	m.lastID = lastID
+	return nil
+}
+
+func (m *memoryEventStore) Purge(projectID uint, eventTypes []string, startTime, endTime int64) error {
+	types := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		types[eventType] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.events[:0]
+	for _, event := range m.events {
+		if event.ProjectID == projectID && types[event.EventType] &&
+			event.TriggerTime >= startTime && event.TriggerTime < endTime {
+			continue
+		}
+		kept = append(kept, event)
+	}
+	m.events = kept
+	return nil
+}
+
+// 遍历项目中满足筛选条件的事件，条件与关系库仓储的事件查询一致
+func (m *memoryEventStore) each(projectID uint64, filter EventFilter, fn func(event *repository.StoredEvent)) {
+	types := make(map[string]bool)
+	for _, eventType := range filterEventTypes(filter) {
+		types[eventType] = true
+	}
+	start, hasStart, end, hasEnd := filterTimeRange(filter.StartTime, filter.EndTime)
+	pageURL := strings.ToLower(filter.PageURL)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := range m.events {
+		event := &m.events[i]
+		switch {
+		case uint64(event.ProjectID) != projectID,
+			len(types) > 0 && !types[event.EventType],
+			hasStart && event.TriggerTime < start,
+			hasEnd && event.TriggerTime > end,
+			pageURL != "" && !strings.Contains(strings.ToLower(event.PageURL), pageURL),
+			filter.UserID != "" && event.UserID != filter.UserID && event.UserUUID != filter.UserID,
+			filter.SessionID != "" && event.SessionID != filter.SessionID,
+			filter.Browser != "" && event.Browser != filter.Browser,
+			filter.OS != "" && event.OS != filter.OS,
+			filter.DeviceType != "" && event.DeviceType != filter.DeviceType,
+			filter.Release != "" && event.Release != filter.Release:
+			continue
+		}
+		fn(event)
+	}
+}
+
+func (m *memoryEventStore) CountSlots(projectID uint64, filter EventFilter, slotSize int64) (map[int64]int64, error) {
+	slots := make(map[int64]int64)
+	m.each(projectID, filter, func(event *repository.StoredEvent) {
+		slots[event.TriggerTime/slotSize]++
+	})
+	return slots, nil
+}
+
+func (m *memoryEventStore) Distribution(projectID uint64, fields []string, filter EventFilter) ([]DistributionRow, error) {
+	errorsOnly := false
+	for _, field := range fields {
+		if field == "error_type" {
+			errorsOnly = true
+		}
+	}
+
+	counts := make(map[string]*DistributionRow)
+	var keys []string
+	m.each(projectID, filter, func(event *repository.StoredEvent) {
+		if errorsOnly && event.EventType != model.EventTypeError {
+			return
+		}
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = storedEventField(event, field)
+		}
+		key := strings.Join(values, "\x00")
+		if counts[key] == nil {
+			counts[key] = &DistributionRow{Values: values}
+			keys = append(keys, key)
+		}
+		counts[key].Count++
+	})
+
+	rows := make([]DistributionRow, 0, len(keys))
+	for _, key := range keys {
+		rows = append(rows, *counts[key])
+	}
+	return rows, nil
+}
+
+func (m *memoryEventStore) APIStats(projectID uint64, filter APIPerformanceFilter) ([]APIPerformanceItem, error) {
+	method := strings.ToUpper(filter.Method)
+	keyword := strings.ToLower(filter.Keyword)
+
+	var rows []repository.APIRequestSample
+	m.each(projectID, EventFilter{EventType: model.EventTypeRequest, StartTime: filter.StartTime, EndTime: filter.EndTime}, func(event *repository.StoredEvent) {
+		if method != "" && event.Method != method {
+			return
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(event.URLTemplate), keyword) {
+			return
+		}
+		rows = append(rows, repository.APIRequestSample{
+			Method:      event.Method,
+			URLTemplate: event.URLTemplate,
+			Status:      event.Status,
+			Duration:    event.Duration,
+			Success:     event.Success,
+			SampleRate:  event.SampleRate,
+		})
+	})
+	return aggregateAPIRequests(rows), nil
+}
+
+// 分布字段的值
+func storedEventField(e *repository.StoredEvent, name string) string {
+	switch name {
+	case "browser":
+		return e.Browser
+	case "browser_version":
+		return e.BrowserVersion
+	case "os":
+		return e.OS
+	case "os_version":
+		return e.OSVersion
+	case "device_type":
+		return e.DeviceType
+	case "screen_width":
+		return strconv.Itoa(e.ScreenWidth)
+	case "sdk_version":
+		return e.SDKVersion
+	case "release":
+		return e.Release
+	case "region":
+		return e.Region
+	case "error_type":
+		return e.ErrorType
+	}
+	return ""
+}
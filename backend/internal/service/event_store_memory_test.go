@@ -0,0 +1,172 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 测试事件的时间基准（毫秒）
+const memoryTestBase = 1700000000000
+
+// 内存存储中的测试事件
+func memoryTestEvents() []repository.StoredEvent {
+	return []repository.StoredEvent{
+		{ID: 1, EventType: model.EventTypePV, ProjectID: testProjectID, TriggerTime: memoryTestBase + 1000, PageURL: "https://example.com/Home", UserID: "u1", Browser: "Chrome", BrowserVersion: "120.1"},
+		{ID: 2, EventType: model.EventTypePV, ProjectID: testProjectID, TriggerTime: memoryTestBase + 2500, PageURL: "https://example.com/cart", UserUUID: "u1", Browser: "Firefox"},
+		{ID: 3, EventType: model.EventTypeError, ProjectID: testProjectID, TriggerTime: memoryTestBase + 3000, Browser: "Chrome", ErrorType: model.ErrorTypeJS},
+		{ID: 4, EventType: model.EventTypeRequest, ProjectID: testProjectID, TriggerTime: memoryTestBase + 4000, Method: "GET", URLTemplate: "/api/users/:id", Status: 200, Duration: 100, Success: true, SampleRate: 0.5},
+		{ID: 5, EventType: model.EventTypeRequest, ProjectID: testProjectID, TriggerTime: memoryTestBase + 5000, Method: "GET", URLTemplate: "/api/users/:id", Status: 500, Duration: 300, SampleRate: 1},
+		{ID: 6, EventType: model.EventTypePV, ProjectID: testProjectID + 1, TriggerTime: memoryTestBase + 1000, Browser: "Chrome"},
+	}
+}
+
+func newTestMemoryEventStore(t *testing.T) *memoryEventStore {
+	store := newMemoryEventStore()
+	if err := store.Write(memoryTestEvents(), 6); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestMemoryEventStoreCountSlots(t *testing.T) {
+	store := newTestMemoryEventStore(t)
+
+	slots, err := store.CountSlots(testProjectID, EventFilter{}, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := int64(memoryTestBase / 1000)
+	if want := map[int64]int64{base + 1: 1, base + 2: 1, base + 3: 1, base + 4: 1, base + 5: 1}; !reflect.DeepEqual(slots, want) {
+		t.Fatalf("时间片 %v", slots)
+	}
+
+	// 结束时间包含在内，页面按不区分大小写的包含匹配，用户同时匹配 user_uuid
+	slots, err = store.CountSlots(testProjectID, EventFilter{
+		EventType: "pv, error",
+		StartTime: "1700000001000",
+		EndTime:   "1700000003000",
+		UserID:    "u1",
+		PageURL:   "EXAMPLE.com",
+	}, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int64]int64{base / 2: 1, base/2 + 1: 1}; !reflect.DeepEqual(slots, want) {
+		t.Fatalf("筛选后的时间片 %v", slots)
+	}
+
+	// 秒级时间戳转换为毫秒
+	seconds, err := store.CountSlots(testProjectID, EventFilter{StartTime: "1700000001", EndTime: "1700000003"}, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int64]int64{base + 1: 1, base + 2: 1, base + 3: 1}; !reflect.DeepEqual(seconds, want) {
+		t.Fatalf("秒级时间范围的时间片 %v", seconds)
+	}
+}
+
+func TestMemoryEventStoreDistribution(t *testing.T) {
+	store := newTestMemoryEventStore(t)
+
+	rows, err := store.Distribution(testProjectID, []string{"browser", "browser_version"}, EventFilter{EventType: model.EventTypePV})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DistributionRow{
+		{Values: []string{"Chrome", "120.1"}, Count: 1},
+		{Values: []string{"Firefox", ""}, Count: 1},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("分布 %+v", rows)
+	}
+
+	// 错误类型只统计错误事件
+	rows, err = store.Distribution(testProjectID, []string{"error_type"}, EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Values[0] != model.ErrorTypeJS || rows[0].Count != 1 {
+		t.Fatalf("错误类型分布 %+v", rows)
+	}
+}
+
+func TestMemoryEventStoreAPIStats(t *testing.T) {
+	store := newTestMemoryEventStore(t)
+
+	list, err := store.APIStats(testProjectID, APIPerformanceFilter{Method: "get", Keyword: "USERS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("接口 %+v", list)
+	}
+	// 采样率 0.5 的记录计为两次调用
+	item := list[0]
+	if item.Samples != 2 || item.Calls != 3 || item.SuccessRate != roundVital(2.0/3) ||
+		item.StatusDistribution["200"] != 1 || item.StatusDistribution["500"] != 1 {
+		t.Fatalf("接口统计 %+v", item)
+	}
+
+	list, err = store.APIStats(testProjectID, APIPerformanceFilter{Method: "POST"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("不应匹配 %+v", list)
+	}
+}
+
+func TestMemoryEventStorePurge(t *testing.T) {
+	store := newTestMemoryEventStore(t)
+
+	// 时间范围不包含结束时间，其他项目和类型的事件保留
+	if err := store.Purge(testProjectID, []string{model.EventTypePV, model.EventTypeError}, memoryTestBase+1000, memoryTestBase+3000); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, event := range store.events {
+		ids = append(ids, int(event.ID))
+	}
+	sort.Ints(ids)
+	if want := []int{3, 4, 5, 6}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("剩余事件 %v", ids)
+	}
+}
+
+func TestSyncEventStoreCopiesInBatches(t *testing.T) {
+	saved := *model.EventStoreSetting
+	t.Cleanup(func() { *model.EventStoreSetting = saved })
+	model.EventStoreSetting.BatchSize = 4
+
+	fakes := newFakeRepositories()
+	fakes.events.stored = memoryTestEvents()
+	store := newMemoryEventStore()
+
+	synced, err := syncEventStore(fakes.events, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, _ := store.SyncCursor()
+	if synced != 6 || len(store.events) != 6 || cursor != 6 {
+		t.Fatalf("同步了 %d 条，存储中 %d 条，游标 %d", synced, len(store.events), cursor)
+	}
+
+	// 没有新事件时不重复写入
+	if synced, err = syncEventStore(fakes.events, store); err != nil || synced != 0 || len(store.events) != 6 {
+		t.Fatalf("重复同步 %d 条，%v", synced, err)
+	}
+}
+
+func TestEventStoreSyncJobSkipsRelationalStore(t *testing.T) {
+	fakes := newFakeRepositories()
+	if job := eventStoreSyncJob(fakes.events, gormEventStore{}); job != nil {
+		t.Fatal("关系库存储不需要同步任务")
+	}
+	if job := eventStoreSyncJob(fakes.events, newMemoryEventStore()); job == nil {
+		t.Fatal("内存存储需要同步任务")
+	}
+}
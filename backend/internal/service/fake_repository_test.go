@@ -0,0 +1,516 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 测试用的仓储实现，嵌入仓储接口后只实现用到的方法，调用其他方法会 panic。
+// 写入方法把记录保存在内存中，查询方法返回预置的结果并记录收到的条件
+
+type fakeProjects struct {
+	repository.ProjectRepository
+	project  model.Project
+	budgets  []model.PerformanceBudget
+	policies []model.RetentionPolicy
+}
+
+func (f *fakeProjects) ListIDs() ([]uint, error) {
+	return []uint{f.project.ID}, nil
+}
+
+func (f *fakeProjects) ListRetentionPolicies(projectID uint) ([]model.RetentionPolicy, error) {
+	return f.policies, nil
+}
+
+func (f *fakeProjects) FindByAppKey(appKey string) (*model.Project, error) {
+	if appKey != f.project.AppKey {
+		return nil, errors.New("not found")
+	}
+	return &f.project, nil
+}
+
+func (f *fakeProjects) FindByID(id uint) (*model.Project, error) {
+	if id != f.project.ID {
+		return nil, errors.New("not found")
+	}
+	return &f.project, nil
+}
+
+func (f *fakeProjects) ListBudgets(projectID uint) ([]model.PerformanceBudget, error) {
+	return f.budgets, nil
+}
+
+type fakeEvents struct {
+	repository.EventRepository
+
+	baseInfos []*model.BaseInfo
+	events    []*model.EventMain
+	details   []interface{}
+	saved     []interface{}
+	updated   map[uint]int64 // 页面访问ID到更新后的停留时间
+
+	performancePage  *model.PerformancePageDetail
+	previousPageView *repository.PageViewRef
+	rows             []repository.EventRow
+	slots            map[int64]int64
+	fieldCounts      []repository.FieldCount
+	logs             []repository.LogRow
+	logTotal         int64
+	levels           map[string]int64
+	stored           []repository.StoredEvent
+
+	query    repository.EventQuery
+	cursor   *repository.EventCursor
+	fields   []string
+	logQuery repository.LogQuery
+}
+
+// 关系库中的事件由集成测试覆盖，这里没有可清理的事件
+func (f *fakeEvents) FindEventRefs(projectID uint, eventTypes []string, startTime, endTime int64, limit int) ([]repository.EventRef, error) {
+	return nil, nil
+}
+
+func (f *fakeEvents) ListStoredEvents(afterID uint, settleBefore time.Time, limit int) ([]repository.StoredEvent, error) {
+	var list []repository.StoredEvent
+	for _, event := range f.stored {
+		if event.ID > afterID && len(list) < limit {
+			list = append(list, event)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeEvents) CreateBaseInfo(baseInfo *model.BaseInfo) error {
+	baseInfo.ID = uint(len(f.baseInfos) + 1)
+	f.baseInfos = append(f.baseInfos, baseInfo)
+	return nil
+}
+
+func (f *fakeEvents) CreateEvent(event *model.EventMain) error {
+	event.ID = uint(len(f.events) + 1)
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeEvents) FindEvent(id uint) (*model.EventMain, error) {
+	for _, event := range f.events {
+		if event.ID == id {
+			found := *event
+			if found.BaseInfoID > 0 && int(found.BaseInfoID) <= len(f.baseInfos) {
+				found.BaseInfo = f.baseInfos[found.BaseInfoID-1]
+			}
+			return &found, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// 按详情类型和事件ID查找保存的详情，找到时写入 detail
+func (f *fakeEvents) FindDetail(eventID uint, detail interface{}) (bool, error) {
+	target := reflect.ValueOf(detail).Elem()
+	for _, saved := range f.details {
+		value := reflect.ValueOf(saved).Elem()
+		if value.Type() == target.Type() && uint(value.FieldByName("EventID").Uint()) == eventID {
+			target.Set(value)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeEvents) FindDetails(eventIDs []uint, details interface{}) error {
+	list := reflect.ValueOf(details).Elem()
+	for _, saved := range f.details {
+		value := reflect.ValueOf(saved).Elem()
+		if value.Type() != list.Type().Elem() {
+			continue
+		}
+		for _, id := range eventIDs {
+			if uint(value.FieldByName("EventID").Uint()) == id {
+				list.Set(reflect.Append(list, value))
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeEvents) FindByTraceID(projectID uint, traceID string, limit int) ([]model.EventMain, error) {
+	var events []model.EventMain
+	for _, event := range f.events {
+		if event.ProjectID == projectID && event.TraceID == traceID {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeEvents) ExistsEventID(eventID string) (bool, error) {
+	for _, event := range f.events {
+		if event.EventID == eventID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeEvents) CreateDetail(detail interface{}) error {
+	f.details = append(f.details, detail)
+	return nil
+}
+
+func (f *fakeEvents) SaveDetail(detail interface{}) error {
+	f.saved = append(f.saved, detail)
+	return nil
+}
+
+func (f *fakeEvents) FindPerformancePage(match repository.PerformancePageMatch) (*model.PerformancePageDetail, error) {
+	return f.performancePage, nil
+}
+
+func (f *fakeEvents) FindPreviousPageView(projectID uint, sessionID string, before int64, excludeEventID uint) (*repository.PageViewRef, error) {
+	return f.previousPageView, nil
+}
+
+func (f *fakeEvents) UpdatePageViewStayTime(id uint, stayTime int64) error {
+	if f.updated == nil {
+		f.updated = make(map[uint]int64)
+	}
+	f.updated[id] = stayTime
+	return nil
+}
+
+func (f *fakeEvents) SearchEvents(filter repository.EventQuery, cursor *repository.EventCursor, limit int) ([]repository.EventRow, error) {
+	f.query, f.cursor = filter, cursor
+	if len(f.rows) > limit {
+		return f.rows[:limit], nil
+	}
+	return f.rows, nil
+}
+
+func (f *fakeEvents) CountSlots(filter repository.EventQuery, slotSize int64) (map[int64]int64, error) {
+	f.query = filter
+	return f.slots, nil
+}
+
+func (f *fakeEvents) CountByFields(filter repository.EventQuery, fields []string) ([]repository.FieldCount, error) {
+	f.query, f.fields = filter, fields
+	return f.fieldCounts, nil
+}
+
+func (f *fakeEvents) ListLogs(filter repository.LogQuery, limit, offset int) ([]repository.LogRow, int64, error) {
+	f.logQuery = filter
+	return f.logs, f.logTotal, nil
+}
+
+func (f *fakeEvents) CountLogLevels(filter repository.LogQuery) (map[string]int64, error) {
+	return f.levels, nil
+}
+
+// 按类型查找保存的详情
+func findDetail[T any](details []interface{}) *T {
+	for _, detail := range details {
+		if typed, ok := detail.(*T); ok {
+			return typed
+		}
+	}
+	return nil
+}
+
+type fakeErrorGroups struct {
+	repository.ErrorGroupRepository
+	recorded []*model.ErrorDetail
+	groups   []model.ErrorGroup
+	filter   repository.ErrorGroupFilter
+}
+
+func (f *fakeErrorGroups) Record(projectID, eventID uint, detail *model.ErrorDetail) (*model.ErrorGroup, error) {
+	f.recorded = append(f.recorded, detail)
+	return &model.ErrorGroup{Fingerprint: detail.Fingerprint, ProjectID: projectID}, nil
+}
+
+func (f *fakeErrorGroups) FindByID(id uint) (*model.ErrorGroup, error) {
+	for i := range f.groups {
+		if f.groups[i].ID == id {
+			return &f.groups[i], nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeErrorGroups) RecentDetails(fingerprint string, limit int) ([]model.ErrorDetail, error) {
+	var details []model.ErrorDetail
+	for _, detail := range f.recorded {
+		if detail.Fingerprint == fingerprint {
+			details = append(details, *detail)
+		}
+	}
+	return details, nil
+}
+
+func (f *fakeErrorGroups) List(projectID uint, filter repository.ErrorGroupFilter, limit, offset int) ([]model.ErrorGroup, int64, error) {
+	f.filter = filter
+	return f.groups, int64(len(f.groups)), nil
+}
+
+type fakeBehaviors struct {
+	repository.BehaviorRepository
+
+	pageViews    []repository.PageViewRow
+	clicks       []repository.ClickRow
+	pageCounts   []repository.PageCount
+	heatmap      []repository.HeatmapClick
+	sessionViews []repository.SessionPageView
+	routes       []repository.RouteChange
+	pending      []repository.PendingClick
+	reactions    map[string][]int64 // 事件类型到触发时间
+	frustrations []repository.FrustrationRow
+
+	timeRange    repository.TimeRange
+	pagePatterns []string
+	eventTypes   []string
+}
+
+func (f *fakeBehaviors) ListPageViews(projectID uint, timeRange repository.TimeRange, limit, offset int) ([]repository.PageViewRow, int64, error) {
+	f.timeRange = timeRange
+	return f.pageViews, int64(len(f.pageViews)), nil
+}
+
+func (f *fakeBehaviors) ListClicks(projectID uint, timeRange repository.TimeRange, limit, offset int) ([]repository.ClickRow, int64, error) {
+	f.timeRange = timeRange
+	return f.clicks, int64(len(f.clicks)), nil
+}
+
+func (f *fakeBehaviors) CountClicksByPage(projectID uint, timeRange repository.TimeRange, limit int) ([]repository.PageCount, error) {
+	f.timeRange = timeRange
+	return f.pageCounts, nil
+}
+
+func (f *fakeBehaviors) ListHeatmapClicks(projectID uint, timeRange repository.TimeRange, pagePatterns []string, limit int) ([]repository.HeatmapClick, error) {
+	f.timeRange, f.pagePatterns = timeRange, pagePatterns
+	return f.heatmap, nil
+}
+
+func (f *fakeBehaviors) ListSessionPageViews(projectID uint, timeRange repository.TimeRange, maxSessions, limit int) ([]repository.SessionPageView, error) {
+	f.timeRange = timeRange
+	return f.sessionViews, nil
+}
+
+func (f *fakeBehaviors) ListRouteChanges(projectID uint, timeRange repository.TimeRange, limit int) ([]repository.RouteChange, error) {
+	f.timeRange = timeRange
+	return f.routes, nil
+}
+
+func (f *fakeBehaviors) ListPendingClicks(afterID uint, settleBefore time.Time, limit int) ([]repository.PendingClick, error) {
+	var clicks []repository.PendingClick
+	for _, click := range f.pending {
+		if click.ID > afterID {
+			clicks = append(clicks, click)
+		}
+	}
+	return clicks, nil
+}
+
+func (f *fakeBehaviors) ReactionTimes(projectID uint, sessionID, userUUID string, eventTypes []string, from, to int64) ([]int64, error) {
+	var times []int64
+	for _, eventType := range eventTypes {
+		for _, reaction := range f.reactions[eventType] {
+			if reaction >= from && reaction <= to {
+				times = append(times, reaction)
+			}
+		}
+	}
+	return times, nil
+}
+
+func (f *fakeBehaviors) ListFrustrations(projectID uint, eventTypes []string, timeRange repository.TimeRange, pagePatterns []string, limit int) ([]repository.FrustrationRow, error) {
+	f.eventTypes, f.timeRange, f.pagePatterns = eventTypes, timeRange, pagePatterns
+	return f.frustrations, nil
+}
+
+type fakePerformance struct {
+	repository.PerformanceRepository
+
+	pages        []repository.PerformancePageRow
+	resources    []repository.ResourceRow
+	pageSamples  []repository.PagePerformanceSample
+	resSamples   []repository.ResourceSample
+	budget       map[string][]repository.BudgetSample // 预算指标到样本
+	release      string
+	longTasks    []repository.LongTaskSample
+	interactions []repository.InteractionSample
+	requests     []repository.APIRequestSample
+
+	timeRange     repository.TimeRange
+	resourceType  string
+	sampleFilter  repository.PageSampleFilter
+	budgetFilters []repository.BudgetSampleFilter
+	requestFilter repository.APIRequestFilter
+}
+
+func (f *fakePerformance) ListPages(projectID uint, timeRange repository.TimeRange, limit, offset int) ([]repository.PerformancePageRow, int64, error) {
+	f.timeRange = timeRange
+	return f.pages, int64(len(f.pages)), nil
+}
+
+func (f *fakePerformance) ListResources(projectID uint, timeRange repository.TimeRange, resourceType string, limit, offset int) ([]repository.ResourceRow, int64, error) {
+	f.timeRange, f.resourceType = timeRange, resourceType
+	return f.resources, int64(len(f.resources)), nil
+}
+
+func (f *fakePerformance) PageSamples(projectID uint, filter repository.PageSampleFilter, limit int) ([]repository.PagePerformanceSample, error) {
+	f.sampleFilter = filter
+	return f.pageSamples, nil
+}
+
+func (f *fakePerformance) ResourceSamples(projectID uint, timeRange repository.TimeRange, resourceType string, limit int) ([]repository.ResourceSample, error) {
+	f.timeRange, f.resourceType = timeRange, resourceType
+	return f.resSamples, nil
+}
+
+func (f *fakePerformance) BudgetSamples(filter repository.BudgetSampleFilter, limit int) ([]repository.BudgetSample, error) {
+	f.budgetFilters = append(f.budgetFilters, filter)
+	return f.budget[filter.Metric], nil
+}
+
+func (f *fakePerformance) LatestRelease(projectID uint) (string, error) {
+	return f.release, nil
+}
+
+func (f *fakePerformance) LongTasks(projectID uint, timeRange repository.TimeRange, limit int) ([]repository.LongTaskSample, error) {
+	f.timeRange = timeRange
+	return f.longTasks, nil
+}
+
+func (f *fakePerformance) Interactions(projectID uint, timeRange repository.TimeRange, limit int) ([]repository.InteractionSample, error) {
+	return f.interactions, nil
+}
+
+func (f *fakePerformance) APIRequests(projectID uint, filter repository.APIRequestFilter, limit int) ([]repository.APIRequestSample, error) {
+	f.requestFilter = filter
+	return f.requests, nil
+}
+
+type fakeStats struct {
+	repository.StatsRepository
+	sums       map[string][]repository.RollupCountSum // 指标和维度（以 / 分隔）到汇总结果
+	sketches   map[string][][]byte
+	histograms map[string][]repository.RollupBucketSum
+	counts     map[string][]model.RollupCount // 指标和维度到各时间桶的预聚合
+}
+
+func (f *fakeStats) SumCounts(projectID uint, metric, dimension string, segments []repository.RollupSegment) ([]repository.RollupCountSum, error) {
+	return f.sums[metric+"/"+dimension], nil
+}
+
+func (f *fakeStats) Sketches(projectID uint, metric string, segments []repository.RollupSegment) ([][]byte, error) {
+	return f.sketches[metric], nil
+}
+
+func (f *fakeStats) SumHistogram(projectID uint, metric string, segments []repository.RollupSegment) ([]repository.RollupBucketSum, error) {
+	return f.histograms[metric], nil
+}
+
+func (f *fakeStats) Counts(projectID uint, metric, dimension, granularity string, start, end int64) ([]model.RollupCount, error) {
+	var rows []model.RollupCount
+	for _, row := range f.counts[metric+"/"+dimension] {
+		if row.BucketStart >= start && row.BucketStart < end {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeStats) SketchBuckets(projectID uint, metric, granularity string, start, end int64) ([]model.RollupSketch, error) {
+	return nil, nil
+}
+
+func (f *fakeStats) HistogramBuckets(projectID uint, metric, granularity string, start, end int64) ([]model.RollupHistogram, error) {
+	return nil, nil
+}
+
+type fakeJobCursors struct {
+	repository.JobCursorRepository
+	cursors map[string]uint
+}
+
+func (f *fakeJobCursors) Get(name string) (uint, error) {
+	return f.cursors[name], nil
+}
+
+func (f *fakeJobCursors) Save(name string, lastID uint) error {
+	if f.cursors == nil {
+		f.cursors = make(map[string]uint)
+	}
+	f.cursors[name] = lastID
+	return nil
+}
+
+// 可为空的浮点数
+func sqlFloat(value float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: value, Valid: true}
+}
+
+// 包含给定值的去重草图
+func sketchOf(values ...string) []byte {
+	sketch := newHyperLogLog()
+	for _, value := range values {
+		sketch.Add(value)
+	}
+	return sketch.Bytes()
+}
+
+// 使用假仓储的事件服务
+type fakeRepositories struct {
+	projects    *fakeProjects
+	events      *fakeEvents
+	errorGroups *fakeErrorGroups
+	behaviors   *fakeBehaviors
+	performance *fakePerformance
+	stats       *fakeStats
+	jobCursors  *fakeJobCursors
+}
+
+// 测试项目的ID和 AppKey
+const (
+	testProjectID  = 1
+	testProjectKey = "test-app"
+	testUserID     = 7
+)
+
+func newFakeRepositories() *fakeRepositories {
+	project := model.Project{Name: "demo", AppKey: testProjectKey, UserID: testUserID}
+	project.ID = testProjectID
+	return &fakeRepositories{
+		projects:    &fakeProjects{project: project},
+		events:      &fakeEvents{},
+		errorGroups: &fakeErrorGroups{},
+		behaviors:   &fakeBehaviors{},
+		performance: &fakePerformance{},
+		stats:       &fakeStats{},
+		jobCursors:  &fakeJobCursors{},
+	}
+}
+
+func (f *fakeRepositories) repositories() *repository.Repositories {
+	return &repository.Repositories{
+		Projects:    f.projects,
+		Events:      f.events,
+		ErrorGroups: f.errorGroups,
+		Stats:       f.stats,
+		Behaviors:   f.behaviors,
+		Performance: f.performance,
+		JobCursors:  f.jobCursors,
+	}
+}
+
+// 使用关系库事件存储的事件服务，事件存储同样读取假仓储
+func (f *fakeRepositories) eventService() *EventService {
+	repos := f.repositories()
+	return NewEventService(repos, gormEventStore{events: repos.Events, performance: repos.Performance})
+}
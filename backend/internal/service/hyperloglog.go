@@ -0,0 +1,90 @@
+package service
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const (
+	// HyperLogLog 精度，2^12 个寄存器，标准误差约 1.6%
+	hllPrecision = 12
+	hllRegisters = 1 << hllPrecision
+)
+
+// 基于 HyperLogLog 的去重计数，可合并，用于按时间桶预聚合 UV
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{registers: make([]uint8, hllRegisters)}
+}
+
+// 从序列化的寄存器恢复，长度不符时返回空的计数器
+func hyperLogLogFromBytes(data []byte) *hyperLogLog {
+	h := newHyperLogLog()
+	if len(data) == hllRegisters {
+		copy(h.registers, data)
+	}
+	return h
+}
+
+// 64 位哈希，FNV 的低位分布不均匀，再做一次混淆
+func hllHash(value string) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(value))
+	x := hasher.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Add 添加元素
+func (h *hyperLogLog) Add(value string) {
+	hash := hllHash(value)
+	index := hash >> (64 - hllPrecision)
+	// 低位补一个标志位，保证秩不超过 64-precision+1
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+// Merge 合并另一个计数器
+func (h *hyperLogLog) Merge(other *hyperLogLog) {
+	for i, rank := range other.registers {
+		if rank > h.registers[i] {
+			h.registers[i] = rank
+		}
+	}
+}
+
+// Count 估算去重数量，基数较小时使用线性计数修正
+func (h *hyperLogLog) Count() int64 {
+	m := float64(hllRegisters)
+	var sum float64
+	zeros := 0
+	for _, rank := range h.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
+
+// Bytes 序列化寄存器
+func (h *hyperLogLog) Bytes() []byte {
+	data := make([]byte, len(h.registers))
+	copy(data, h.registers)
+	return data
+}
@@ -0,0 +1,597 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+	"github.com/akinoccc/web-tracing-admin/migrations"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// 集成测试在真实数据库上执行迁移、通过上报接口写入事件，再调用全部查询接口。
+// 默认使用临时目录中的 SQLite；设置 TEST_POSTGRES_DSN 时同时在 PostgreSQL 上执行，
+// 表名使用独立的前缀，测试结束后删除
+
+func TestQuerySuiteSQLite(t *testing.T) {
+	prefix := useDatabase(t, model.DatabaseSQLite, "wt_")
+	dsn := filepath.Join(t.TempDir(), "web_tracing.db") + "?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)"
+	runQuerySuite(t, openTestDB(t, sqlite.Open(dsn), prefix))
+}
+
+func TestQuerySuitePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("未设置 TEST_POSTGRES_DSN")
+	}
+	prefix := useDatabase(t, model.DatabasePostgres, fmt.Sprintf("it%d_", time.Now().UnixNano()))
+	db := openTestDB(t, postgres.Open(dsn), prefix)
+	t.Cleanup(func() { dropPrefixedTables(t, db, prefix) })
+	runQuerySuite(t, db)
+}
+
+// 切换数据库类型和表前缀，测试结束后恢复
+func useDatabase(t *testing.T, dbType, prefix string) string {
+	t.Helper()
+	saved := *model.DatabaseSetting
+	t.Cleanup(func() { *model.DatabaseSetting = saved })
+	model.DatabaseSetting.Type = dbType
+	model.DatabaseSetting.TablePrefix = prefix
+	return prefix
+}
+
+// 打开数据库并执行全部迁移
+func openTestDB(t *testing.T, dialector gorm.Dialector, prefix string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix:   prefix,
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	return db
+}
+
+// 删除带测试前缀的表
+func dropPrefixedTables(t *testing.T, db *gorm.DB, prefix string) {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Errorf("读取表失败: %v", err)
+		return
+	}
+	for _, table := range tables {
+		if strings.HasPrefix(table, prefix) {
+			if err := db.Migrator().DropTable(table); err != nil {
+				t.Errorf("删除表 %s 失败: %v", table, err)
+			}
+		}
+	}
+}
+
+// 测试数据的上报器，事件时间为 base 加偏移（毫秒）
+type testTracker struct {
+	t       *testing.T
+	service *EventService
+	appKey  string
+	base    int64
+}
+
+func (tr testTracker) track(category, eventType string, offset int64, data map[string]interface{}) {
+	tr.t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		tr.t.Fatal(err)
+	}
+	req := &TrackRequest{
+		Category:  category,
+		Type:      eventType,
+		Timestamp: tr.base + offset,
+		AppKey:    tr.appKey,
+		Release:   "1.0.0",
+		Data:      raw,
+	}
+	if err := tr.service.ProcessTrackData(req); err != nil {
+		tr.t.Fatalf("上报 %s/%s 失败: %v", category, eventType, err)
+	}
+}
+
+// 会话公共字段
+func sessionData(sessionID, userUUID, browser, os, deviceType string, fields map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{
+		"sessionId":  sessionID,
+		"userUuid":   userUUID,
+		"browser":    browser,
+		"os":         os,
+		"deviceType": deviceType,
+	}
+	for key, value := range fields {
+		data[key] = value
+	}
+	return data
+}
+
+// 按字段值排序分布统计结果，便于比较不同存储的结果
+func sortedDistributionRows(rows []DistributionRow) []DistributionRow {
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.Join(rows[i].Values, "\x00") < strings.Join(rows[j].Values, "\x00")
+	})
+	return rows
+}
+
+func runQuerySuite(t *testing.T, db *gorm.DB) {
+	repos := repository.New(db)
+	user, err := repos.Users.Create("tester", "password", "tester@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := repos.Projects.Create("demo", "", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewEventStore(repos, &model.EventStore{Type: EventStoreGORM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := NewEventService(repos, store)
+	budgets := NewBudgetService(repos.Projects, repos.Performance)
+	retention := NewRetentionService(repos, store)
+	rollups := NewRollupService(repos)
+
+	base := time.Now().Add(-3 * time.Hour).UnixMilli()
+	tr := testTracker{t: t, service: events, appKey: project.AppKey, base: base}
+	s1 := func(fields map[string]interface{}) map[string]interface{} {
+		return sessionData("s1", "u1", "Chrome", "Windows", "desktop", fields)
+	}
+	s2 := func(fields map[string]interface{}) map[string]interface{} {
+		return sessionData("s2", "u2", "Firefox", "macOS", "mobile", fields)
+	}
+
+	// 会话 s1：首页加载、性能指标、点击后跳转商品页
+	home, product := "https://example.com/home", "https://example.com/products/42"
+	tr.track("user", "page_view", 0, s1(map[string]interface{}{"url": home, "title": "Home", "pageId": "p1"}))
+	tr.track("performance", "page_load", 100, s1(map[string]interface{}{
+		"url": home, "pageId": "p1", "loadTime": 1200, "domContentLoadedTime": 800,
+		"paintTiming": map[string]interface{}{"FP": 500, "FCP": 900}, "navigationTiming": map[string]interface{}{"ttfb": 300},
+	}))
+	tr.track("performance", "web_vitals", 200, s1(map[string]interface{}{"url": home, "pageId": "p1", "name": "LCP", "value": 2000}))
+	tr.track("performance", "web_vitals", 300, s1(map[string]interface{}{"url": home, "pageId": "p1", "name": "CLS", "value": 0.05}))
+	tr.track("performance", "resource_load", 400, s1(map[string]interface{}{
+		"url": home, "pageId": "p1", "requestUrl": "https://cdn.example.com/app.js", "initiatorType": "script",
+		"duration": 150, "transferSize": 20000, "decodedBodySize": 60000,
+	}))
+	// 无响应点击和狂点
+	tr.track("user", "click", 1000, s1(map[string]interface{}{
+		"url": home, "path": []string{"button#buy"}, "tagName": "button", "x": 100, "y": 200, "viewportWidth": 1000, "viewportHeight": 800,
+	}))
+	for i := int64(0); i < 3; i++ {
+		tr.track("user", "click", 3000+i*200, s1(map[string]interface{}{
+			"url": home, "path": []string{"a#more"}, "tagName": "a", "x": 500, "y": 600, "viewportWidth": 1000, "viewportHeight": 800,
+		}))
+	}
+	tr.track("user", "route_change", 5000, s1(map[string]interface{}{"from": home, "to": product, "duration": 120}))
+	tr.track("performance", "long_task", 6000, s1(map[string]interface{}{"url": product, "duration": 200}))
+	tr.track("performance", "interaction", 6500, s1(map[string]interface{}{
+		"url": product, "eventType": "click", "target": "button#add", "duration": 300,
+	}))
+	tr.track("error", "js", 7000, s1(map[string]interface{}{"url": product, "message": "boom"}))
+	tr.track("log", "warn", 8000, s1(map[string]interface{}{"url": product, "message": "slow response"}))
+	tr.track("log", "info", 8100, s1(map[string]interface{}{"url": product, "message": "loaded"}))
+	tr.track("request", "xhr", 9000, s1(map[string]interface{}{
+		"url": product, "requestUrl": "https://api.example.com/items/42", "method": "get", "status": 200, "duration": 250,
+	}))
+	tr.track("request", "xhr", 9100, s1(map[string]interface{}{
+		"url": product, "requestUrl": "https://api.example.com/items/7", "method": "get", "status": 500, "duration": 50,
+	}))
+	tr.track("custom", "custom", 10000, s1(map[string]interface{}{"url": product, "name": "checkout"}))
+
+	// 会话 s2：首页跳转到关于页
+	tr.track("user", "page_view", 20000, s2(map[string]interface{}{"url": home, "title": "Home"}))
+	tr.track("user", "route_change", 22000, s2(map[string]interface{}{"from": home, "to": "https://example.com/about", "duration": 80}))
+
+	// 将入库时间提前，越过挫败检测和预聚合的等待时间
+	settled := time.Now().Add(-time.Hour)
+	for _, table := range []string{"base_info", "event_main", "pv_detail", "click_detail", "error_detail", "performance_page_detail"} {
+		if err := db.Exec(model.SQL("UPDATE {"+table+"} SET created_at = ?"), settled).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := events.DetectClickFrustrations(); err != nil {
+		t.Fatalf("挫败检测失败: %v", err)
+	}
+	if _, err := rollups.BuildRollups(); err != nil {
+		t.Fatalf("预聚合失败: %v", err)
+	}
+
+	projectID := strconv.FormatUint(uint64(project.ID), 10)
+	start, end := strconv.FormatInt(base-1000, 10), strconv.FormatInt(base+60000, 10)
+	stats := StatsQuery{StartTime: start, EndTime: end, Granularity: GranularityHour}
+
+	t.Run("EventList", func(t *testing.T) {
+		resp, err := events.GetEventList(projectID, "", "50", EventFilter{EventType: model.EventTypeClick})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.List) != 4 || resp.HasMore {
+			t.Fatalf("点击事件 %d 条 hasMore=%v，期望 4 条", len(resp.List), resp.HasMore)
+		}
+
+		// 游标分页不重复
+		first, err := events.GetEventList(projectID, "", "2", EventFilter{PageURL: "products"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !first.HasMore || len(first.List) != 2 {
+			t.Fatalf("第一页 %d 条 hasMore=%v", len(first.List), first.HasMore)
+		}
+		second, err := events.GetEventList(projectID, first.NextCursor, "2", EventFilter{PageURL: "products"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range second.List {
+			for _, prev := range first.List {
+				if item.ID == prev.ID {
+					t.Fatalf("分页返回重复事件 %d", item.ID)
+				}
+			}
+		}
+
+		filtered, err := events.GetEventList(projectID, "", "50", EventFilter{Browser: "Firefox", Release: "1.0.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filtered.List) != 2 {
+			t.Fatalf("Firefox 事件 %d 条，期望 2 条", len(filtered.List))
+		}
+
+		detail, err := events.GetEventDetail(strconv.FormatUint(uint64(resp.List[0].ID), 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(detail.Details) == 0 {
+			t.Fatal("事件详情为空")
+		}
+	})
+
+	t.Run("EventStats", func(t *testing.T) {
+		resp, err := events.GetEventStats(projectID, EventFilter{EventType: model.EventTypeClick}, stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var total int64
+		for _, count := range resp.Counts {
+			total += count
+		}
+		if total != 4 {
+			t.Fatalf("点击趋势合计 %d，期望 4", total)
+		}
+	})
+
+	t.Run("Distribution", func(t *testing.T) {
+		resp, err := events.GetDistribution(projectID, DimensionBrowser, "10", EventFilter{StartTime: start, EndTime: end})
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[string]int64)
+		for i, label := range resp.Labels {
+			counts[label] = resp.Values[i]
+		}
+		if counts["Firefox"] != 2 || counts["Chrome"] == 0 {
+			t.Fatalf("浏览器分布 %v", counts)
+		}
+	})
+
+	t.Run("MemoryStore", func(t *testing.T) {
+		// 从关系库同步后，内存存储的统计结果与直接查询关系库一致。
+		// 挫败检测生成的事件刚入库，不等待即同步
+		saved := *model.EventStoreSetting
+		t.Cleanup(func() { *model.EventStoreSetting = saved })
+		model.EventStoreSetting.SettleDelay = 0
+
+		memory := newMemoryEventStore()
+		if _, err := syncEventStore(repos.Events, memory); err != nil {
+			t.Fatalf("同步失败: %v", err)
+		}
+		filter := EventFilter{StartTime: start, EndTime: end}
+
+		wantSlots, err := store.CountSlots(uint64(project.ID), filter, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slots, err := memory.CountSlots(uint64(project.ID), filter, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(slots, wantSlots) {
+			t.Fatalf("时间片 %v，期望 %v", slots, wantSlots)
+		}
+
+		fields := []string{"browser", "os_version", "screen_width"}
+		wantRows, err := store.Distribution(uint64(project.ID), fields, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := memory.Distribution(uint64(project.ID), fields, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sortedDistributionRows(rows), sortedDistributionRows(wantRows)) {
+			t.Fatalf("分布 %v，期望 %v", rows, wantRows)
+		}
+
+		apiFilter := APIPerformanceFilter{StartTime: start, EndTime: end}
+		wantAPIs, err := store.APIStats(uint64(project.ID), apiFilter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		apis, err := memory.APIStats(uint64(project.ID), apiFilter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(apis, wantAPIs) {
+			t.Fatalf("接口统计 %+v，期望 %+v", apis, wantAPIs)
+		}
+	})
+
+	t.Run("Logs", func(t *testing.T) {
+		resp, err := events.GetLogs(projectID, "1", "20", LogFilter{StartTime: start, EndTime: end})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 2 || resp.LevelCounts[model.LogLevelWarn] != 1 || resp.LevelCounts[model.LogLevelInfo] != 1 {
+			t.Fatalf("日志 total=%d levels=%v", resp.Total, resp.LevelCounts)
+		}
+		resp, err = events.GetLogs(projectID, "1", "20", LogFilter{Keyword: "slow"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 1 || resp.List[0].Message != "slow response" {
+			t.Fatalf("关键词筛选 total=%d", resp.Total)
+		}
+		resp, err = events.GetLogs(projectID, "1", "20", LogFilter{Keyword: "100%"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 0 {
+			t.Fatalf("通配符未转义，匹配到 %d 条", resp.Total)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		// 错误分组按入库时间记录首次和最近出现时间
+		list, err := events.GetErrorList(projectID, "1", "20", "", "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list.Total != 1 {
+			t.Fatalf("错误分组 %d 个，期望 1 个", list.Total)
+		}
+		resp, err := events.GetErrorStats(projectID, stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Stats.TotalErrors != 1 || resp.Stats.AffectedUsers != 1 {
+			t.Fatalf("错误统计 %+v", resp.Stats)
+		}
+	})
+
+	t.Run("PageViews", func(t *testing.T) {
+		resp, err := events.GetPageViewList(projectID, "1", "20", start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 4 {
+			t.Fatalf("页面访问 %d 条，期望 4 条", resp.Total)
+		}
+		for _, item := range resp.List {
+			if item.PageURL == home && item.Browser == "Chrome" && item.StayTime != 5000 {
+				t.Fatalf("首页停留时间 %d，期望 5000", item.StayTime)
+			}
+		}
+
+		clicks, err := events.GetClickList(projectID, "1", "20", start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if clicks.Total != 4 {
+			t.Fatalf("点击 %d 条，期望 4 条", clicks.Total)
+		}
+	})
+
+	t.Run("BehaviorStats", func(t *testing.T) {
+		resp, err := events.GetBehaviorStats(projectID, stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.PVStats.TotalPV != 4 || resp.PVStats.TotalUV != 2 || resp.ClickStats.TotalClicks != 4 {
+			t.Fatalf("行为统计 pv=%+v click=%+v", resp.PVStats, resp.ClickStats)
+		}
+	})
+
+	t.Run("Performance", func(t *testing.T) {
+		list, err := events.GetPerformanceList(projectID, "1", "20", start, end, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list.Total != 1 {
+			t.Fatalf("页面性能 %d 条，期望合并为 1 条", list.Total)
+		}
+
+		resources, err := events.GetResourcePerformanceList(projectID, "1", "20", start, end, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resources.Total != 1 {
+			t.Fatalf("资源 %d 条，期望 1 条", resources.Total)
+		}
+
+		pages, err := events.GetPagePerformanceList(projectID, "1", "20", PagePerformanceFilter{StartTime: start, EndTime: end})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pages.List) != 1 || pages.List[0].P75LCP != 2000 {
+			t.Fatalf("页面性能汇总 %+v", pages.List)
+		}
+
+		aggregate, err := events.GetResourceAggregate(projectID, "1", "20", ResourceAggregateFilter{StartTime: start, EndTime: end})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(aggregate.List) != 1 || aggregate.List[0].Count != 1 {
+			t.Fatalf("资源汇总 %+v", aggregate.List)
+		}
+
+		perfStats, err := events.GetPerformanceStats(projectID, stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perfStats.Stats.AvgLCP != 2000 || perfStats.Stats.AvgFCP != 900 {
+			t.Fatalf("性能统计 %+v", perfStats.Stats)
+		}
+
+		vitals, err := events.GetWebVitals(projectID, stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(vitals.Metrics) == 0 {
+			t.Fatal("Web Vitals 为空")
+		}
+	})
+
+	t.Run("APIPerformance", func(t *testing.T) {
+		resp, err := events.GetAPIPerformance(projectID, "1", "20", APIPerformanceFilter{StartTime: start, EndTime: end, Method: "get"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Total != 1 || resp.List[0].Samples != 2 || resp.List[0].SuccessRate != 0.5 {
+			t.Fatalf("接口性能 %+v", resp.List)
+		}
+	})
+
+	t.Run("Jank", func(t *testing.T) {
+		resp, err := events.GetJankRanking(projectID, "", start, end, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var longTasks, interactions int64
+		for _, page := range resp.Pages {
+			longTasks += page.LongTaskCount
+			interactions += page.InteractionCount
+		}
+		if longTasks != 1 || interactions != 1 {
+			t.Fatalf("卡顿排行 longTasks=%d interactions=%d", longTasks, interactions)
+		}
+	})
+
+	t.Run("Routes", func(t *testing.T) {
+		resp, err := events.GetRouteTiming(projectID, "", start, end, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Routes) != 2 {
+			t.Fatalf("路由切换 %d 组，期望 2 组", len(resp.Routes))
+		}
+	})
+
+	t.Run("Heatmap", func(t *testing.T) {
+		resp, err := events.GetClickHeatmap(projectID, "", start, end, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.TotalClicks != 4 || len(resp.Pages) != 1 {
+			t.Fatalf("热力图 total=%d pages=%v", resp.TotalClicks, resp.Pages)
+		}
+	})
+
+	t.Run("Paths", func(t *testing.T) {
+		resp, err := events.GetPagePaths(projectID, start, end, "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Sessions != 2 || len(resp.Links) == 0 {
+			t.Fatalf("路径分析 sessions=%d links=%v", resp.Sessions, resp.Links)
+		}
+	})
+
+	t.Run("Frustrations", func(t *testing.T) {
+		resp, err := events.GetFrustrations(projectID, "", "", start, end, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rage, dead int64
+		for _, item := range resp.List {
+			rage += item.RageClicks
+			dead += item.DeadClicks
+		}
+		if rage != 1 || dead != 1 {
+			t.Fatalf("挫败点击 rage=%d dead=%d", rage, dead)
+		}
+	})
+
+	t.Run("Budgets", func(t *testing.T) {
+		for _, req := range []BudgetRequest{
+			{Name: "LCP", Metric: model.BudgetMetricLCP, PageURL: "/home", Threshold: 2500},
+			{Name: "资源大小", Metric: model.BudgetMetricResourceSize, Threshold: 10000},
+		} {
+			req := req
+			if _, err := budgets.CreateBudget(project.ID, &req, user.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		resp, err := budgets.GetBudgetStatus(project.ID, "", start, end, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Release != "1.0.0" || resp.Passed || len(resp.Budgets) != 2 {
+			t.Fatalf("预算状态 %+v", resp)
+		}
+		for _, item := range resp.Budgets {
+			want := BudgetStatusPass
+			if item.Metric == model.BudgetMetricResourceSize {
+				want = BudgetStatusFail
+			}
+			if item.Status != want || item.Samples != 1 {
+				t.Fatalf("预算 %s 状态 %s 样本 %d", item.Name, item.Status, item.Samples)
+			}
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		resp, err := retention.PurgeProjectData(project.ID, &PurgeRequest{
+			Categories: []string{model.RetentionCategoryLog},
+			EndTime:    base + 60000,
+		}, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Deleted != 2 {
+			t.Fatalf("清理 %d 条，期望 2 条", resp.Deleted)
+		}
+		logs, err := events.GetLogs(projectID, "1", "20", LogFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if logs.Total != 0 {
+			t.Fatalf("清理后仍有 %d 条日志", logs.Total)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+const (
+	// 单次聚合最多读取的记录数量
+	maxJankRows = 100000
+	// 默认排行数量
+	defaultJankLimit = 20
+	// 超过该耗时的交互视为慢交互，与 INP 良好阈值一致
+	slowInteractionThreshold = 200
+	// 无法归因的脚本
+	unknownJankScript = "(unknown)"
+)
+
+// JankResponse 卡顿归因排行响应
+type JankResponse struct {
+	Pages    []JankPage    `json:"pages"`
+	Scripts  []JankScript  `json:"scripts"`
+	Elements []JankElement `json:"elements"`
+}
+
+// JankPage 页面卡顿汇总
+type JankPage struct {
+	PageURL                string  `json:"pageUrl"`
+	LongTaskCount          int64   `json:"longTaskCount"`
+	TotalBlockingTime      int64   `json:"totalBlockingTime"`
+	InteractionCount       int64   `json:"interactionCount"`
+	SlowInteractionCount   int64   `json:"slowInteractionCount"`
+	P75InteractionDuration float64 `json:"p75InteractionDuration"`
+}
+
+// JankScript 导致长任务的脚本
+type JankScript struct {
+	PageURL       string  `json:"pageUrl"`
+	ScriptURL     string  `json:"scriptUrl"`
+	FunctionName  string  `json:"functionName"`
+	Count         int64   `json:"count"`
+	TotalDuration int64   `json:"totalDuration"`
+	TotalBlocking int64   `json:"totalBlocking"`
+	MaxDuration   int64   `json:"maxDuration"`
+	AvgDuration   float64 `json:"avgDuration"`
+}
+
+// JankElement 交互延迟高的元素
+type JankElement struct {
+	PageURL              string  `json:"pageUrl"`
+	ElementPath          string  `json:"elementPath"`
+	ElementType          string  `json:"elementType"`
+	InteractionType      string  `json:"interactionType"`
+	Count                int64   `json:"count"`
+	SlowCount            int64   `json:"slowCount"`
+	P75Duration          float64 `json:"p75Duration"`
+	AvgInputDelay        float64 `json:"avgInputDelay"`
+	AvgProcessingTime    float64 `json:"avgProcessingTime"`
+	AvgPresentationDelay float64 `json:"avgPresentationDelay"`
+}
+
+// GetJankRanking 按页面排行导致长任务的脚本和交互延迟高的元素
+func (s *EventService) GetJankRanking(projectIDStr, pageURL, startTimeStr, endTimeStr, limitStr string) (*JankResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = defaultJankLimit
+	}
+	pageURL = NormalizeURL(pageURL)
+
+	timeRange := parseTimeRange(startTimeStr, endTimeStr)
+	longTasks, err := s.performance.LongTasks(uint(projectID), timeRange, maxJankRows)
+	if err != nil {
+		return nil, err
+	}
+
+	interactions, err := s.performance.Interactions(uint(projectID), timeRange, maxJankRows)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make(map[string]*JankPage)
+	pageDurations := make(map[string][]float64)
+	getPage := func(url string) *JankPage {
+		page, ok := pages[url]
+		if !ok {
+			page = &JankPage{PageURL: url}
+			pages[url] = page
+		}
+		return page
+	}
+
+	// 按页面和脚本汇总长任务
+	type scriptKey struct{ page, url, function string }
+	scripts := make(map[scriptKey]*JankScript)
+	for _, row := range longTasks {
+		page := NormalizeURL(row.PageURL)
+		if pageURL != "" && page != pageURL {
+			continue
+		}
+
+		pageItem := getPage(page)
+		pageItem.LongTaskCount++
+		pageItem.TotalBlockingTime += row.BlockingDuration
+
+		scriptURL := unknownJankScript
+		if row.ScriptURL != "" {
+			scriptURL = NormalizeResourceURL(row.ScriptURL)
+		}
+		key := scriptKey{page: page, url: scriptURL, function: row.ScriptFunction}
+		script, ok := scripts[key]
+		if !ok {
+			script = &JankScript{PageURL: page, ScriptURL: scriptURL, FunctionName: row.ScriptFunction}
+			scripts[key] = script
+		}
+		script.Count++
+		script.TotalDuration += row.Duration
+		script.TotalBlocking += row.BlockingDuration
+		if row.Duration > script.MaxDuration {
+			script.MaxDuration = row.Duration
+		}
+	}
+
+	// 按页面和元素汇总交互
+	type elementKey struct{ page, path, interactionType string }
+	type elementGroup struct {
+		item                                 JankElement
+		durations                            []float64
+		inputDelay, processing, presentation float64
+	}
+	elements := make(map[elementKey]*elementGroup)
+	for _, row := range interactions {
+		page := NormalizeURL(row.PageURL)
+		if pageURL != "" && page != pageURL {
+			continue
+		}
+
+		pageItem := getPage(page)
+		pageItem.InteractionCount++
+		pageDurations[page] = append(pageDurations[page], float64(row.Duration))
+
+		key := elementKey{page: page, path: row.ElementPath, interactionType: row.InteractionType}
+		group, ok := elements[key]
+		if !ok {
+			group = &elementGroup{item: JankElement{
+				PageURL:         page,
+				ElementPath:     row.ElementPath,
+				ElementType:     row.ElementType,
+				InteractionType: row.InteractionType,
+			}}
+			elements[key] = group
+		}
+		group.item.Count++
+		group.durations = append(group.durations, float64(row.Duration))
+		group.inputDelay += float64(row.InputDelay)
+		group.processing += float64(row.ProcessingTime)
+		group.presentation += float64(row.PresentationDelay)
+		if row.Duration > slowInteractionThreshold {
+			group.item.SlowCount++
+			pageItem.SlowInteractionCount++
+		}
+	}
+
+	resp := &JankResponse{
+		Pages:    make([]JankPage, 0, len(pages)),
+		Scripts:  make([]JankScript, 0, len(scripts)),
+		Elements: make([]JankElement, 0, len(elements)),
+	}
+
+	for url, page := range pages {
+		page.P75InteractionDuration = percentile(pageDurations[url], 0.75)
+		resp.Pages = append(resp.Pages, *page)
+	}
+	sort.Slice(resp.Pages, func(i, j int) bool {
+		if resp.Pages[i].TotalBlockingTime != resp.Pages[j].TotalBlockingTime {
+			return resp.Pages[i].TotalBlockingTime > resp.Pages[j].TotalBlockingTime
+		}
+		return resp.Pages[i].PageURL < resp.Pages[j].PageURL
+	})
+
+	for _, script := range scripts {
+		script.AvgDuration = roundVital(float64(script.TotalDuration) / float64(script.Count))
+		resp.Scripts = append(resp.Scripts, *script)
+	}
+	sort.Slice(resp.Scripts, func(i, j int) bool {
+		if resp.Scripts[i].TotalBlocking != resp.Scripts[j].TotalBlocking {
+			return resp.Scripts[i].TotalBlocking > resp.Scripts[j].TotalBlocking
+		}
+		return resp.Scripts[i].Count > resp.Scripts[j].Count
+	})
+
+	for _, group := range elements {
+		count := float64(group.item.Count)
+		group.item.P75Duration = percentile(group.durations, 0.75)
+		group.item.AvgInputDelay = roundVital(group.inputDelay / count)
+		group.item.AvgProcessingTime = roundVital(group.processing / count)
+		group.item.AvgPresentationDelay = roundVital(group.presentation / count)
+		resp.Elements = append(resp.Elements, group.item)
+	}
+	sort.Slice(resp.Elements, func(i, j int) bool {
+		if resp.Elements[i].SlowCount != resp.Elements[j].SlowCount {
+			return resp.Elements[i].SlowCount > resp.Elements[j].SlowCount
+		}
+		return resp.Elements[i].P75Duration > resp.Elements[j].P75Duration
+	})
+
+	if len(resp.Pages) > limit {
+		resp.Pages = resp.Pages[:limit]
+	}
+	if len(resp.Scripts) > limit {
+		resp.Scripts = resp.Scripts[:limit]
+	}
+	if len(resp.Elements) > limit {
+		resp.Elements = resp.Elements[:limit]
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetJankRankingAggregatesByPage(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.performance.longTasks = []repository.LongTaskSample{
+		{PageURL: "https://example.com/items/1", ScriptURL: "https://cdn.example.com/app.js?v=1", ScriptFunction: "render", Duration: 200, BlockingDuration: 150},
+		{PageURL: "https://example.com/items/2", ScriptURL: "https://cdn.example.com/app.js?v=2", ScriptFunction: "render", Duration: 100, BlockingDuration: 50},
+		{PageURL: "https://example.com/about", Duration: 80, BlockingDuration: 30},
+	}
+	fakes.performance.interactions = []repository.InteractionSample{
+		{PageURL: "https://example.com/items/1", ElementPath: "button#buy", InteractionType: "click", Duration: 300, InputDelay: 50, ProcessingTime: 200, PresentationDelay: 50},
+		{PageURL: "https://example.com/items/2", ElementPath: "button#buy", InteractionType: "click", Duration: 100, InputDelay: 10, ProcessingTime: 80, PresentationDelay: 10},
+	}
+
+	resp, err := fakes.eventService().GetJankRanking("1", "", "1700000001000", "1700000002000", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakes.performance.timeRange != (repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}) {
+		t.Fatalf("时间范围 %+v", fakes.performance.timeRange)
+	}
+
+	items := NormalizeURL("https://example.com/items/1")
+	if len(resp.Pages) != 2 {
+		t.Fatalf("页面 %+v", resp.Pages)
+	}
+	page := resp.Pages[0]
+	if page.PageURL != items || page.LongTaskCount != 2 || page.TotalBlockingTime != 200 || page.InteractionCount != 2 || page.SlowInteractionCount != 1 {
+		t.Fatalf("页面汇总 %+v", page)
+	}
+
+	// 同一脚本的不同版本合并，无法归因的长任务单独统计
+	if len(resp.Scripts) != 2 {
+		t.Fatalf("脚本 %+v", resp.Scripts)
+	}
+	script := resp.Scripts[0]
+	if script.ScriptURL != NormalizeResourceURL("https://cdn.example.com/app.js?v=1") || script.Count != 2 ||
+		script.TotalBlocking != 200 || script.MaxDuration != 200 || script.AvgDuration != 150 {
+		t.Fatalf("脚本汇总 %+v", script)
+	}
+	if resp.Scripts[1].ScriptURL != unknownJankScript {
+		t.Fatalf("未归因脚本 %+v", resp.Scripts[1])
+	}
+
+	if len(resp.Elements) != 1 {
+		t.Fatalf("元素 %+v", resp.Elements)
+	}
+	element := resp.Elements[0]
+	if element.Count != 2 || element.SlowCount != 1 || element.AvgInputDelay != 30 || element.AvgProcessingTime != 140 || element.AvgPresentationDelay != 30 {
+		t.Fatalf("元素汇总 %+v", element)
+	}
+
+	// 按页面筛选
+	resp, err = fakes.eventService().GetJankRanking("1", "/about", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Pages) != 1 || resp.Pages[0].PageURL != "/about" || len(resp.Elements) != 0 {
+		t.Fatalf("按页面筛选 %+v", resp)
+	}
+}
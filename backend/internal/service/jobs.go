@@ -0,0 +1,41 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// StartBackgroundJobs 启动后台定时任务
+func StartBackgroundJobs(events repository.EventRepository, eventService *EventService, retentionService *RetentionService, rollupService *RollupService, store EventStore) {
+	go runPeriodically("点击挫败检测", time.Duration(model.FrustrationSetting.Interval)*time.Second, func() error {
+		_, err := eventService.DetectClickFrustrations()
+		return err
+	})
+
+	go runPeriodically("过期数据清理", time.Duration(model.RetentionSetting.Interval)*time.Second, func() error {
+		_, err := retentionService.PurgeExpiredData()
+		return err
+	})
+
+	go runPeriodically("数据预聚合", time.Duration(model.RollupSetting.Interval)*time.Second, rollupService.runRollupJob)
+
+	if job := eventStoreSyncJob(events, store); job != nil {
+		go runPeriodically("事件存储同步", time.Duration(model.EventStoreSetting.SyncInterval)*time.Second, job)
+	}
+}
+
+// 按固定间隔循环执行任务，出错时记录日志并等待下一轮
+func runPeriodically(name string, interval time.Duration, job func() error) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	for {
+		if err := job(); err != nil {
+			log.Printf("后台任务 %s 执行失败: %v", name, err)
+		}
+		time.Sleep(interval)
+	}
+}
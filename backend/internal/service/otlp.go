@@ -0,0 +1,482 @@
+package service
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// OTLP 数据中未能映射的部分直接忽略，接收端只关注错误、页面加载和请求三类数据
+
+// OTLP Span 类型与状态
+const (
+	otlpSpanKindClient    = 3
+	otlpStatusCodeError   = 2
+	otlpSeverityNumberErr = 17
+)
+
+// OTLPTraceRequest OTLP 链路数据（ExportTraceServiceRequest）
+type OTLPTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// OTLPLogsRequest OTLP 日志数据（ExportLogsServiceRequest）
+type OTLPLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano otlpUint64      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   otlpUint64      `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue  `json:"attributes"`
+	Events            []otlpSpanEvent `json:"events"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpSpanEvent struct {
+	TimeUnixNano otlpUint64     `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpScopeLogs struct {
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         otlpUint64     `json:"timeUnixNano"`
+	ObservedTimeUnixNano otlpUint64     `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string    `json:"stringValue,omitempty"`
+	BoolValue   *bool      `json:"boolValue,omitempty"`
+	IntValue    *otlpInt64 `json:"intValue,omitempty"`
+	DoubleValue *float64   `json:"doubleValue,omitempty"`
+}
+
+// OTLP JSON 中 64 位整数按字符串编码，也兼容数字
+type otlpUint64 uint64
+
+func (v *otlpUint64) UnmarshalJSON(data []byte) error {
+	parsed, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*v = otlpUint64(parsed)
+	return nil
+}
+
+type otlpInt64 int64
+
+func (v *otlpInt64) UnmarshalJSON(data []byte) error {
+	parsed, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*v = otlpInt64(parsed)
+	return nil
+}
+
+// 转为普通值
+func (v otlpAnyValue) value() interface{} {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return float64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	}
+	return nil
+}
+
+// OTLP 属性集合
+type otlpAttributes map[string]interface{}
+
+func newOTLPAttributes(lists ...[]otlpKeyValue) otlpAttributes {
+	attrs := make(otlpAttributes)
+	for _, list := range lists {
+		for _, kv := range list {
+			attrs[kv.Key] = kv.Value.value()
+		}
+	}
+	return attrs
+}
+
+// 按顺序读取第一个存在的字符串属性
+func (a otlpAttributes) str(keys ...string) string {
+	for _, key := range keys {
+		if value, ok := a[key]; ok && value != nil {
+			return fmt.Sprintf("%v", value)
+		}
+	}
+	return ""
+}
+
+// 按顺序读取第一个存在的数值属性
+func (a otlpAttributes) num(keys ...string) (float64, bool) {
+	for _, key := range keys {
+		switch value := a[key].(type) {
+		case float64:
+			return value, true
+		case string:
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// 纳秒转毫秒
+func nanoToMillis(nano otlpUint64) int64 {
+	return int64(nano / 1e6)
+}
+
+// 纳秒时间差转毫秒
+func nanoDiffMillis(end, start otlpUint64) float64 {
+	if end == 0 || start == 0 || end < start {
+		return 0
+	}
+	return float64(end-start) / 1e6
+}
+
+// 根据资源属性构造上报请求的公共部分
+func otlpBaseRequest(base TrackRequest, resource otlpAttributes) TrackRequest {
+	req := base
+	if req.AppKey == "" {
+		req.AppKey = resource.str("app.key", "web_tracing.app_key")
+	}
+	req.Release = resource.str("service.version")
+	req.Environment = resource.str("deployment.environment.name", "deployment.environment")
+	return req
+}
+
+// 组装上报数据，附带会话、用户和链路信息
+func otlpEventData(attrs otlpAttributes, traceID, spanID string, fields map[string]interface{}) json.RawMessage {
+	data := map[string]interface{}{
+		"traceId": traceID,
+		"spanId":  spanID,
+	}
+	if sessionID := attrs.str("session.id"); sessionID != "" {
+		data["sessionId"] = sessionID
+	}
+	if userID := attrs.str("enduser.id", "user.id"); userID != "" {
+		data["userId"] = userID
+	}
+	for key, value := range fields {
+		data[key] = value
+	}
+	raw, _ := json.Marshal(data)
+	return raw
+}
+
+// 异常指纹：类型 + 消息 + 堆栈首个调用帧
+func otlpExceptionFingerprint(exceptionType, message, stack string) string {
+	frame := ""
+	for _, line := range strings.Split(stack, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "at ") || strings.Contains(line, "@") {
+			frame = line
+			break
+		}
+	}
+	sum := md5.Sum([]byte(exceptionType + "|" + message + "|" + frame))
+	return hex.EncodeToString(sum[:])
+}
+
+// 将异常属性映射为错误上报
+func otlpExceptionRequest(base TrackRequest, attrs otlpAttributes, timestamp int64, traceID, spanID string) *TrackRequest {
+	exceptionType := attrs.str("exception.type")
+	message := attrs.str("exception.message")
+	stack := attrs.str("exception.stacktrace")
+	if exceptionType != "" && !strings.HasPrefix(message, exceptionType) {
+		message = exceptionType + ": " + message
+	}
+
+	req := base
+	req.Category = "error"
+	req.Type = model.ErrorTypeJS
+	req.Severity = model.ErrorSeverityError
+	req.Timestamp = timestamp
+	req.Fingerprint = otlpExceptionFingerprint(exceptionType, message, stack)
+	req.Data = otlpEventData(attrs, traceID, spanID, map[string]interface{}{
+		"message": message,
+		"stack":   stack,
+		"url":     attrs.str("url.full", "http.url", "location.href"),
+	})
+	return &req
+}
+
+// 将 documentLoad Span 映射为页面加载性能上报
+func otlpDocumentLoadRequest(base TrackRequest, span otlpSpan, attrs otlpAttributes) *TrackRequest {
+	marks := make(map[string]otlpUint64)
+	for _, event := range span.Events {
+		marks[event.Name] = event.TimeUnixNano
+	}
+	fetchStart := marks["fetchStart"]
+	if fetchStart == 0 {
+		fetchStart = span.StartTimeUnixNano
+	}
+	since := func(name string) float64 { return nanoDiffMillis(marks[name], fetchStart) }
+	between := func(end, start string) float64 { return nanoDiffMillis(marks[end], marks[start]) }
+
+	navigationTiming := map[string]interface{}{
+		"dns":            between("domainLookupEnd", "domainLookupStart"),
+		"tcp":            between("connectEnd", "connectStart"),
+		"ssl":            between("connectEnd", "secureConnectionStart"),
+		"ttfb":           between("responseStart", "requestStart"),
+		"firstByte":      since("responseStart"),
+		"trans":          between("responseEnd", "responseStart"),
+		"domParse":       between("domInteractive", "responseEnd"),
+		"domContentLoad": between("domContentLoadedEventEnd", "domContentLoadedEventStart"),
+		"res":            between("loadEventStart", "domContentLoadedEventEnd"),
+	}
+	// 缺失的时间点不上报，避免覆盖已合并的数据
+	for key, value := range navigationTiming {
+		if value.(float64) <= 0 {
+			delete(navigationTiming, key)
+		}
+	}
+	paintTiming := map[string]interface{}{}
+	if fp := since("firstPaint"); fp > 0 {
+		paintTiming["FP"] = fp
+	}
+	if fcp := since("firstContentfulPaint"); fcp > 0 {
+		paintTiming["FCP"] = fcp
+	}
+
+	req := base
+	req.Category = "performance"
+	req.Type = "page_load"
+	req.Timestamp = nanoToMillis(span.StartTimeUnixNano)
+	fields := map[string]interface{}{
+		"url":              attrs.str("url.full", "http.url", "location.href"),
+		"navigationTiming": navigationTiming,
+		"paintTiming":      paintTiming,
+	}
+	if loadTime := since("loadEventEnd"); loadTime > 0 {
+		fields["loadTime"] = loadTime
+	}
+	if domContentLoadedTime := since("domContentLoadedEventEnd"); domContentLoadedTime > 0 {
+		fields["domContentLoadedTime"] = domContentLoadedTime
+	}
+	req.Data = otlpEventData(attrs, span.TraceID, span.SpanID, fields)
+	return &req
+}
+
+// 根据资源扩展名推断资源类型
+func otlpResourceType(resourceURL string) string {
+	ext := strings.ToLower(path.Ext(strings.SplitN(strings.SplitN(resourceURL, "?", 2)[0], "#", 2)[0]))
+	switch ext {
+	case ".js", ".mjs":
+		return "script"
+	case ".css":
+		return "stylesheet"
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".svg", ".ico":
+		return "image"
+	case ".woff", ".woff2", ".ttf", ".otf", ".eot":
+		return "font"
+	}
+	return "other"
+}
+
+// 将请求类 Span 映射为资源性能、接口请求或 HTTP 错误上报
+func otlpRequestSpanRequest(base TrackRequest, span otlpSpan, attrs otlpAttributes) *TrackRequest {
+	requestURL := attrs.str("url.full", "http.url")
+	duration := nanoDiffMillis(span.EndTimeUnixNano, span.StartTimeUnixNano)
+	req := base
+	req.Timestamp = nanoToMillis(span.StartTimeUnixNano)
+
+	// 资源加载 Span 由 documentLoad 插件产生
+	if span.Name == "resourceFetch" {
+		fields := map[string]interface{}{
+			"url":           requestURL,
+			"initiatorType": "other",
+			"duration":      duration,
+		}
+		if size, ok := attrs.num("http.response_content_length"); ok {
+			fields["transferSize"] = size
+			fields["encodedBodySize"] = size
+		}
+		if size, ok := attrs.num("http.response_content_length_uncompressed"); ok {
+			fields["decodedBodySize"] = size
+		}
+		req.Category = "performance"
+		req.Type = "resource_load"
+		req.SubType = otlpResourceType(requestURL)
+		req.Data = otlpEventData(attrs, span.TraceID, span.SpanID, fields)
+		return &req
+	}
+
+	method := strings.ToUpper(attrs.str("http.request.method", "http.method"))
+	status, _ := attrs.num("http.response.status_code", "http.status_code")
+	initiatorType := "fetch"
+	if strings.Contains(strings.ToLower(attrs.str("component")), "xml") {
+		initiatorType = "xmlhttprequest"
+	}
+
+	// 失败的请求记录为 HTTP 错误
+	if status >= 400 || (status == 0 && span.Status.Code == otlpStatusCodeError) {
+		message := fmt.Sprintf("%s %s %d", method, requestURL, int(status))
+		if span.Status.Message != "" {
+			message += " " + span.Status.Message
+		}
+		sum := md5.Sum([]byte(fmt.Sprintf("%s|%s|%d", method, NormalizeResourceURL(requestURL), int(status))))
+
+		req.Category = "error"
+		req.Type = model.ErrorTypeHttp
+		req.Severity = model.ErrorSeverityError
+		req.Fingerprint = hex.EncodeToString(sum[:])
+		req.Data = otlpEventData(attrs, span.TraceID, span.SpanID, map[string]interface{}{
+			"message":    message,
+			"url":        requestURL,
+			"method":     method,
+			"status":     status,
+			"statusText": span.Status.Message,
+			"duration":   duration,
+		})
+		return &req
+	}
+
+	// 成功的请求记录为接口请求
+	req.Category = "request"
+	req.Type = initiatorType
+	req.Data = otlpEventData(attrs, span.TraceID, span.SpanID, map[string]interface{}{
+		"url":      requestURL,
+		"method":   method,
+		"status":   status,
+		"duration": duration,
+	})
+	return &req
+}
+
+// ProcessOTLPTraces 将 OTLP Span 转换为上报数据处理，返回成功处理的事件数量
+func (s *EventService) ProcessOTLPTraces(otlpReq *OTLPTraceRequest, base TrackRequest) (int, error) {
+	var requests []*TrackRequest
+	for _, resourceSpans := range otlpReq.ResourceSpans {
+		resourceAttrs := newOTLPAttributes(resourceSpans.Resource.Attributes)
+		spanBase := otlpBaseRequest(base, resourceAttrs)
+
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				attrs := newOTLPAttributes(resourceSpans.Resource.Attributes, span.Attributes)
+
+				// Span 事件中的异常
+				for _, event := range span.Events {
+					if event.Name == "exception" {
+						eventAttrs := newOTLPAttributes(resourceSpans.Resource.Attributes, span.Attributes, event.Attributes)
+						requests = append(requests, otlpExceptionRequest(spanBase, eventAttrs, nanoToMillis(event.TimeUnixNano), span.TraceID, span.SpanID))
+					}
+				}
+
+				switch {
+				case span.Name == "documentLoad":
+					requests = append(requests, otlpDocumentLoadRequest(spanBase, span, attrs))
+				case span.Name == "resourceFetch",
+					span.Kind == otlpSpanKindClient && attrs.str("url.full", "http.url") != "":
+					requests = append(requests, otlpRequestSpanRequest(spanBase, span, attrs))
+				}
+			}
+		}
+	}
+
+	return s.processOTLPRequests(requests)
+}
+
+// ProcessOTLPLogs 将 OTLP 日志中的异常转换为错误上报，返回成功处理的事件数量
+func (s *EventService) ProcessOTLPLogs(otlpReq *OTLPLogsRequest, base TrackRequest) (int, error) {
+	var requests []*TrackRequest
+	for _, resourceLogs := range otlpReq.ResourceLogs {
+		resourceAttrs := newOTLPAttributes(resourceLogs.Resource.Attributes)
+		logBase := otlpBaseRequest(base, resourceAttrs)
+
+		for _, scopeLogs := range resourceLogs.ScopeLogs {
+			for _, record := range scopeLogs.LogRecords {
+				attrs := newOTLPAttributes(resourceLogs.Resource.Attributes, record.Attributes)
+
+				// 只处理异常日志，以及没有异常属性但级别为 ERROR 及以上的日志
+				if attrs.str("exception.type", "exception.message") == "" {
+					if record.SeverityNumber < otlpSeverityNumberErr {
+						continue
+					}
+					body, _ := record.Body.value().(string)
+					attrs["exception.message"] = body
+				}
+
+				timestamp := record.TimeUnixNano
+				if timestamp == 0 {
+					timestamp = record.ObservedTimeUnixNano
+				}
+				requests = append(requests, otlpExceptionRequest(logBase, attrs, nanoToMillis(timestamp), record.TraceID, record.SpanID))
+			}
+		}
+	}
+
+	return s.processOTLPRequests(requests)
+}
+
+// 逐条处理转换后的上报数据，单条失败不影响其他数据
+func (s *EventService) processOTLPRequests(requests []*TrackRequest) (int, error) {
+	processed := 0
+	var lastErr error
+	for _, req := range requests {
+		if err := s.ProcessTrackData(req); err != nil {
+			lastErr = err
+			continue
+		}
+		processed++
+	}
+	if processed == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return processed, nil
+}
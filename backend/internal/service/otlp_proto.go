@@ -0,0 +1,309 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// OTLP protobuf 解码，只解析映射所需的字段，其余字段直接跳过
+// 字段编号参考 opentelemetry-proto 的 trace/v1、logs/v1、common/v1 定义
+
+var errInvalidProtobuf = errors.New("无效的protobuf数据")
+
+// 遍历消息中的字段
+func walkProtobuf(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return errInvalidProtobuf
+		}
+		data = data[n:]
+
+		var value []byte
+		var scalar uint64
+		switch typ {
+		case protowire.VarintType:
+			scalar, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			scalar, n = protowire.ConsumeFixed64(data)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(data)
+			scalar = uint64(v)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return errInvalidProtobuf
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value, scalar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeOTLPTraceProto 解码 ExportTraceServiceRequest
+func DecodeOTLPTraceProto(data []byte) (*OTLPTraceRequest, error) {
+	req := &OTLPTraceRequest{}
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		resourceSpans, err := decodeResourceSpans(value)
+		if err != nil {
+			return err
+		}
+		req.ResourceSpans = append(req.ResourceSpans, resourceSpans)
+		return nil
+	})
+	return req, err
+}
+
+// DecodeOTLPLogsProto 解码 ExportLogsServiceRequest
+func DecodeOTLPLogsProto(data []byte) (*OTLPLogsRequest, error) {
+	req := &OTLPLogsRequest{}
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		resourceLogs, err := decodeResourceLogs(value)
+		if err != nil {
+			return err
+		}
+		req.ResourceLogs = append(req.ResourceLogs, resourceLogs)
+		return nil
+	})
+	return req, err
+}
+
+func decodeResourceSpans(data []byte) (otlpResourceSpans, error) {
+	var result otlpResourceSpans
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			resource, err := decodeResource(value)
+			result.Resource = resource
+			return err
+		case 2:
+			scopeSpans, err := decodeScopeSpans(value)
+			result.ScopeSpans = append(result.ScopeSpans, scopeSpans)
+			return err
+		}
+		return nil
+	})
+	return result, err
+}
+
+func decodeScopeSpans(data []byte) (otlpScopeSpans, error) {
+	var result otlpScopeSpans
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != 2 || typ != protowire.BytesType {
+			return nil
+		}
+		span, err := decodeSpan(value)
+		result.Spans = append(result.Spans, span)
+		return err
+	})
+	return result, err
+}
+
+func decodeSpan(data []byte) (otlpSpan, error) {
+	var span otlpSpan
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+		switch num {
+		case 1:
+			span.TraceID = hex.EncodeToString(value)
+		case 2:
+			span.SpanID = hex.EncodeToString(value)
+		case 4:
+			span.ParentSpanID = hex.EncodeToString(value)
+		case 5:
+			span.Name = string(value)
+		case 6:
+			span.Kind = int(scalar)
+		case 7:
+			span.StartTimeUnixNano = otlpUint64(scalar)
+		case 8:
+			span.EndTimeUnixNano = otlpUint64(scalar)
+		case 9:
+			if typ == protowire.BytesType {
+				kv, err := decodeKeyValue(value)
+				span.Attributes = append(span.Attributes, kv)
+				return err
+			}
+		case 11:
+			if typ == protowire.BytesType {
+				event, err := decodeSpanEvent(value)
+				span.Events = append(span.Events, event)
+				return err
+			}
+		case 15:
+			if typ == protowire.BytesType {
+				return walkProtobuf(value, func(num protowire.Number, _ protowire.Type, value []byte, scalar uint64) error {
+					switch num {
+					case 2:
+						span.Status.Message = string(value)
+					case 3:
+						span.Status.Code = int(scalar)
+					}
+					return nil
+				})
+			}
+		}
+		return nil
+	})
+	return span, err
+}
+
+func decodeSpanEvent(data []byte) (otlpSpanEvent, error) {
+	var event otlpSpanEvent
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+		switch num {
+		case 1:
+			event.TimeUnixNano = otlpUint64(scalar)
+		case 2:
+			event.Name = string(value)
+		case 3:
+			if typ == protowire.BytesType {
+				kv, err := decodeKeyValue(value)
+				event.Attributes = append(event.Attributes, kv)
+				return err
+			}
+		}
+		return nil
+	})
+	return event, err
+}
+
+func decodeResourceLogs(data []byte) (otlpResourceLogs, error) {
+	var result otlpResourceLogs
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			resource, err := decodeResource(value)
+			result.Resource = resource
+			return err
+		case 2:
+			scopeLogs, err := decodeScopeLogs(value)
+			result.ScopeLogs = append(result.ScopeLogs, scopeLogs)
+			return err
+		}
+		return nil
+	})
+	return result, err
+}
+
+func decodeScopeLogs(data []byte) (otlpScopeLogs, error) {
+	var result otlpScopeLogs
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != 2 || typ != protowire.BytesType {
+			return nil
+		}
+		record, err := decodeLogRecord(value)
+		result.LogRecords = append(result.LogRecords, record)
+		return err
+	})
+	return result, err
+}
+
+func decodeLogRecord(data []byte) (otlpLogRecord, error) {
+	var record otlpLogRecord
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, scalar uint64) error {
+		switch num {
+		case 1:
+			record.TimeUnixNano = otlpUint64(scalar)
+		case 11:
+			record.ObservedTimeUnixNano = otlpUint64(scalar)
+		case 2:
+			record.SeverityNumber = int(scalar)
+		case 3:
+			record.SeverityText = string(value)
+		case 5:
+			if typ == protowire.BytesType {
+				body, err := decodeAnyValue(value)
+				record.Body = body
+				return err
+			}
+		case 6:
+			if typ == protowire.BytesType {
+				kv, err := decodeKeyValue(value)
+				record.Attributes = append(record.Attributes, kv)
+				return err
+			}
+		case 9:
+			record.TraceID = hex.EncodeToString(value)
+		case 10:
+			record.SpanID = hex.EncodeToString(value)
+		}
+		return nil
+	})
+	return record, err
+}
+
+func decodeResource(data []byte) (otlpResource, error) {
+	var resource otlpResource
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		kv, err := decodeKeyValue(value)
+		resource.Attributes = append(resource.Attributes, kv)
+		return err
+	})
+	return resource, err
+}
+
+func decodeKeyValue(data []byte) (otlpKeyValue, error) {
+	var kv otlpKeyValue
+	err := walkProtobuf(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch num {
+		case 1:
+			kv.Key = string(value)
+		case 2:
+			if typ == protowire.BytesType {
+				anyValue, err := decodeAnyValue(value)
+				kv.Value = anyValue
+				return err
+			}
+		}
+		return nil
+	})
+	return kv, err
+}
+
+// 解码 AnyValue，数组和键值列表只保留字符串形式
+func decodeAnyValue(data []byte) (otlpAnyValue, error) {
+	var anyValue otlpAnyValue
+	err := walkProtobuf(data, func(num protowire.Number, _ protowire.Type, value []byte, scalar uint64) error {
+		switch num {
+		case 1:
+			s := string(value)
+			anyValue.StringValue = &s
+		case 2:
+			b := scalar != 0
+			anyValue.BoolValue = &b
+		case 3:
+			i := otlpInt64(int64(scalar))
+			anyValue.IntValue = &i
+		case 4:
+			f := math.Float64frombits(scalar)
+			anyValue.DoubleValue = &f
+		}
+		return nil
+	})
+	return anyValue, err
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// OpenTelemetry Web SDK 导出的链路数据
+const otlpTestTraces = `{"resourceSpans": [{
+	"resource": {"attributes": [
+		{"key": "app.key", "value": {"stringValue": "test-app"}},
+		{"key": "service.version", "value": {"stringValue": "2.0.0"}}
+	]},
+	"scopeSpans": [{"spans": [
+		{
+			"traceId": "t1", "spanId": "s1", "name": "GET", "kind": 3,
+			"startTimeUnixNano": "1700000000000000000", "endTimeUnixNano": "1700000000250000000",
+			"attributes": [
+				{"key": "url.full", "value": {"stringValue": "https://api.example.com/users/1"}},
+				{"key": "http.request.method", "value": {"stringValue": "GET"}},
+				{"key": "http.response.status_code", "value": {"intValue": "500"}}
+			]
+		},
+		{
+			"traceId": "t2", "spanId": "s2", "name": "POST", "kind": 3,
+			"startTimeUnixNano": "1700000001000000000", "endTimeUnixNano": "1700000001100000000",
+			"attributes": [
+				{"key": "url.full", "value": {"stringValue": "https://api.example.com/orders"}},
+				{"key": "http.method", "value": {"stringValue": "post"}},
+				{"key": "http.status_code", "value": {"intValue": 201}}
+			]
+		},
+		{
+			"traceId": "t3", "spanId": "s3", "name": "click", "kind": 1,
+			"startTimeUnixNano": "1700000002000000000", "endTimeUnixNano": "1700000002000000000",
+			"events": [{
+				"name": "exception", "timeUnixNano": "1700000002000000000",
+				"attributes": [
+					{"key": "exception.type", "value": {"stringValue": "TypeError"}},
+					{"key": "exception.message", "value": {"stringValue": "x is undefined"}}
+				]
+			}]
+		}
+	]}]
+}]}`
+
+// OpenTelemetry 导出的日志数据
+const otlpTestLogs = `{"resourceLogs": [{
+	"resource": {"attributes": [{"key": "app.key", "value": {"stringValue": "test-app"}}]},
+	"scopeLogs": [{"logRecords": [
+		{"timeUnixNano": "1700000000000000000", "severityNumber": 9, "body": {"stringValue": "loaded"}},
+		{"timeUnixNano": "1700000001000000000", "severityNumber": 17, "body": {"stringValue": "payment failed"}},
+		{"observedTimeUnixNano": "1700000002000000000", "severityNumber": 9, "attributes": [
+			{"key": "exception.type", "value": {"stringValue": "RangeError"}},
+			{"key": "exception.message", "value": {"stringValue": "invalid length"}}
+		]}
+	]}]
+}]}`
+
+// 保存的错误消息及其触发时间
+func otlpErrorTimes(fakes *fakeRepositories) map[string]int64 {
+	triggerTimes := map[uint]int64{}
+	for _, event := range fakes.events.events {
+		triggerTimes[event.ID] = event.TriggerTime
+	}
+	messages := map[string]int64{}
+	for _, detail := range fakes.events.details {
+		if detail, ok := detail.(*model.ErrorDetail); ok {
+			messages[detail.ErrorMessage] = triggerTimes[detail.EventID]
+		}
+	}
+	return messages
+}
+
+func TestProcessOTLPTracesMapsSpans(t *testing.T) {
+	var req OTLPTraceRequest
+	if err := json.Unmarshal([]byte(otlpTestTraces), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	fakes := newFakeRepositories()
+	processed, err := fakes.eventService().ProcessOTLPTraces(&req, TrackRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed != 3 || len(fakes.events.events) != 3 {
+		t.Fatalf("处理了 %d 条，保存了 %d 条事件", processed, len(fakes.events.events))
+	}
+	for _, base := range fakes.events.baseInfos {
+		if base.ProjectID != testProjectID || base.Release != "2.0.0" {
+			t.Fatalf("基础信息 %+v", base)
+		}
+	}
+
+	// 失败的请求记录为 HTTP 错误
+	httpError := findDetail[model.HttpErrorDetail](fakes.events.details)
+	if httpError == nil || httpError.URL != "https://api.example.com/users/1" || httpError.Method != "GET" ||
+		httpError.Status != 500 || httpError.Duration != 250 {
+		t.Fatalf("HTTP 错误详情 %+v", httpError)
+	}
+
+	// 成功的请求记录为接口请求
+	request := findDetail[model.RequestDetail](fakes.events.details)
+	if request == nil || request.URL != "https://api.example.com/orders" || request.Method != "POST" || request.Status != 201 {
+		t.Fatalf("接口请求详情 %+v", request)
+	}
+
+	// Span 事件中的异常
+	errorTimes := otlpErrorTimes(fakes)
+	if errorTimes["TypeError: x is undefined"] != 1700000002000 || errorTimes["GET https://api.example.com/users/1 500"] != 1700000000000 {
+		t.Fatalf("错误详情 %v", errorTimes)
+	}
+}
+
+func TestProcessOTLPLogsMapsErrors(t *testing.T) {
+	var req OTLPLogsRequest
+	if err := json.Unmarshal([]byte(otlpTestLogs), &req); err != nil {
+		t.Fatal(err)
+	}
+
+	fakes := newFakeRepositories()
+	processed, err := fakes.eventService().ProcessOTLPLogs(&req, TrackRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 普通级别且没有异常属性的日志被忽略
+	if processed != 2 || len(fakes.events.events) != 2 {
+		t.Fatalf("处理了 %d 条，保存了 %d 条事件", processed, len(fakes.events.events))
+	}
+
+	messages := otlpErrorTimes(fakes)
+	if messages["payment failed"] != 1700000001000 || messages["RangeError: invalid length"] != 1700000002000 {
+		t.Fatalf("错误消息 %v", messages)
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 单次聚合最多读取的页面性能记录数量
+const maxPagePerformanceRows = 100000
+
+// PagePerformanceListResponse 页面性能排行响应
+type PagePerformanceListResponse struct {
+	Total int64                 `json:"total"`
+	List  []PagePerformanceItem `json:"list"`
+}
+
+// PagePerformanceItem 单个页面的性能汇总
+type PagePerformanceItem struct {
+	PageURL   string                `json:"pageUrl"`
+	Samples   int64                 `json:"samples"`
+	P75LCP    float64               `json:"p75LCP"`
+	P75FCP    float64               `json:"p75FCP"`
+	P75TTFB   float64               `json:"p75TTFB"`
+	P75CLS    float64               `json:"p75CLS"`
+	P75INP    float64               `json:"p75INP"`
+	Waterfall PageTimingWaterfall   `json:"waterfall"`
+	Ratings   map[string]VitalScore `json:"ratings"`
+}
+
+// PageTimingWaterfall 页面导航各阶段平均耗时（毫秒）
+type PageTimingWaterfall struct {
+	DNS      float64 `json:"dns"`
+	TCP      float64 `json:"tcp"`
+	SSL      float64 `json:"ssl"`
+	TTFB     float64 `json:"ttfb"`
+	Trans    float64 `json:"trans"`
+	DomParse float64 `json:"domParse"`
+}
+
+// VitalScore 指标 p75 对应的评级
+type VitalScore struct {
+	Value  float64 `json:"value"`
+	Rating string  `json:"rating"`
+}
+
+// PagePerformanceFilter 页面性能筛选条件
+type PagePerformanceFilter struct {
+	StartTime  string
+	EndTime    string
+	Browser    string
+	OS         string
+	DeviceType string
+	Region     string
+	SortBy     string
+	Order      string
+}
+
+// GetPagePerformanceList 按页面聚合性能数据，用于找出最慢的页面
+func (s *EventService) GetPagePerformanceList(projectIDStr, pageStr, pageSizeStr string, filter PagePerformanceFilter) (*PagePerformanceListResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	rows, err := s.performance.PageSamples(uint(projectID), repository.PageSampleFilter{
+		TimeRange:  parseTimeRange(filter.StartTime, filter.EndTime),
+		Browser:    filter.Browser,
+		OS:         filter.OS,
+		DeviceType: filter.DeviceType,
+		Region:     filter.Region,
+	}, maxPagePerformanceRows)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按归一化页面分组
+	byPage := make(map[string][]repository.PagePerformanceSample)
+	for _, row := range rows {
+		pageURL := NormalizeURL(row.PageURL)
+		byPage[pageURL] = append(byPage[pageURL], row)
+	}
+
+	list := make([]PagePerformanceItem, 0, len(byPage))
+	for pageURL, pageRows := range byPage {
+		list = append(list, summarizePagePerformance(pageURL, pageRows))
+	}
+	sortPagePerformance(list, filter.SortBy, filter.Order != "asc")
+
+	total := int64(len(list))
+	offset := (page - 1) * pageSize
+	if offset > len(list) {
+		offset = len(list)
+	}
+	end := offset + pageSize
+	if end > len(list) {
+		end = len(list)
+	}
+
+	return &PagePerformanceListResponse{
+		Total: total,
+		List:  list[offset:end],
+	}, nil
+}
+
+// 汇总单个页面的性能数据，未上报的指标和值为0的网络耗时不参与统计
+func summarizePagePerformance(pageURL string, rows []repository.PagePerformanceSample) PagePerformanceItem {
+	var lcp, fcp, ttfb, cls, inp, dns, tcp, ssl, trans, domParse []float64
+	for _, row := range rows {
+		lcp = appendReported(lcp, row.LCP)
+		fcp = appendReported(fcp, row.FCP)
+		ttfb = appendReported(ttfb, row.TTFB)
+		cls = appendReported(cls, row.CLS)
+		inp = appendReported(inp, row.INP)
+		dns = appendPositive(dns, row.DNS)
+		tcp = appendPositive(tcp, row.TCP)
+		ssl = appendPositive(ssl, row.SSL)
+		trans = appendPositive(trans, row.Trans)
+		domParse = appendPositive(domParse, row.DomParse)
+	}
+
+	item := PagePerformanceItem{
+		PageURL: pageURL,
+		Samples: int64(len(rows)),
+		P75LCP:  percentile(lcp, 0.75),
+		P75FCP:  percentile(fcp, 0.75),
+		P75TTFB: percentile(ttfb, 0.75),
+		P75CLS:  percentile(cls, 0.75),
+		P75INP:  percentile(inp, 0.75),
+		Waterfall: PageTimingWaterfall{
+			DNS:      average(dns),
+			TCP:      average(tcp),
+			SSL:      average(ssl),
+			TTFB:     average(ttfb),
+			Trans:    average(trans),
+			DomParse: average(domParse),
+		},
+		Ratings: make(map[string]VitalScore),
+	}
+
+	// 只评级有样本的指标
+	values := map[string]struct {
+		value   float64
+		samples int
+	}{
+		"LCP":  {item.P75LCP, len(lcp)},
+		"FCP":  {item.P75FCP, len(fcp)},
+		"TTFB": {item.P75TTFB, len(ttfb)},
+		"CLS":  {item.P75CLS, len(cls)},
+		"INP":  {item.P75INP, len(inp)},
+	}
+	for _, metric := range vitalMetrics {
+		if v, ok := values[metric.Name]; ok && v.samples > 0 {
+			item.Ratings[metric.Name] = VitalScore{Value: v.value, Rating: rateVital(metric, v.value)}
+		}
+	}
+
+	return item
+}
+
+// 页面排序，默认按样本数排序
+func sortPagePerformance(list []PagePerformanceItem, sortBy string, desc bool) {
+	value := func(item PagePerformanceItem) float64 {
+		switch sortBy {
+		case "lcp":
+			return item.P75LCP
+		case "fcp":
+			return item.P75FCP
+		case "ttfb":
+			return item.P75TTFB
+		case "cls":
+			return item.P75CLS
+		case "inp":
+			return item.P75INP
+		default:
+			return float64(item.Samples)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		vi, vj := value(list[i]), value(list[j])
+		if vi != vj {
+			if desc {
+				return vi > vj
+			}
+			return vi < vj
+		}
+		return list[i].PageURL < list[j].PageURL
+	})
+}
+
+// 只收集大于0的值
+func appendPositive(values []float64, value float64) []float64 {
+	if value > 0 {
+		return append(values, value)
+	}
+	return values
+}
+
+// 追加已上报的性能指标值，值为 0 同样有效
+func appendReported(values []float64, value *float64) []float64 {
+	if value != nil {
+		return append(values, *value)
+	}
+	return values
+}
+
+// 计算平均值
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return roundVital(sum / float64(len(values)))
+}
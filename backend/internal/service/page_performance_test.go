@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetPagePerformanceListAggregatesByPage(t *testing.T) {
+	lcp := func(value float64) *float64 { return &value }
+	fakes := newFakeRepositories()
+	fakes.performance.pageSamples = []repository.PagePerformanceSample{
+		{PageURL: "https://example.com/items/1", LCP: lcp(2000), DNS: 10},
+		// 未上报的指标和值为 0 的网络耗时不参与统计
+		{PageURL: "https://example.com/items/2", LCP: lcp(3000), CLS: lcp(0)},
+		{PageURL: "https://example.com/about", LCP: lcp(5000), DNS: 30},
+	}
+	service := fakes.eventService()
+
+	resp, err := service.GetPagePerformanceList("1", "1", "10", PagePerformanceFilter{
+		StartTime: "1700000001000",
+		EndTime:   "1700000002000",
+		Browser:   "Chrome",
+		Region:    "CN",
+		SortBy:    "lcp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := repository.PageSampleFilter{TimeRange: repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}, Browser: "Chrome", Region: "CN"}
+	if fakes.performance.sampleFilter != want {
+		t.Fatalf("筛选条件 %+v，期望 %+v", fakes.performance.sampleFilter, want)
+	}
+
+	// 按 LCP 降序，最慢的页面排在前面
+	if resp.Total != 2 || resp.List[0].PageURL != "/about" || resp.List[0].Ratings["LCP"].Rating != VitalRatingPoor {
+		t.Fatalf("页面排行 %+v", resp)
+	}
+	items := resp.List[1]
+	if items.PageURL != NormalizeURL("https://example.com/items/1") || items.Samples != 2 || items.P75LCP != 2750 || items.Waterfall.DNS != 10 {
+		t.Fatalf("页面汇总 %+v", items)
+	}
+	if items.Ratings["LCP"].Rating != VitalRatingNeedsImprovement || items.Ratings["CLS"].Rating != VitalRatingGood {
+		t.Fatalf("指标评级 %+v", items.Ratings)
+	}
+	if _, ok := items.Ratings["TTFB"]; ok {
+		t.Fatal("没有样本的指标不应评级")
+	}
+
+	// 分页超出范围时返回空列表
+	resp, err = service.GetPagePerformanceList("1", "3", "1", PagePerformanceFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || len(resp.List) != 0 {
+		t.Fatalf("分页 %+v", resp)
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	// 默认路径深度
+	defaultPathDepth = 5
+	// 最大路径深度
+	maxPathDepth = 10
+	// 每一步最多保留的节点数，其余合并为其他
+	maxPathNodesPerStep = 10
+	// 单次分析最多读取的会话数量，取时间范围内最近活跃的会话
+	maxPathSessions = 5000
+	// 单次分析最多读取的PV数量，超出时丢弃最后一个不完整的会话
+	maxPathRows = 100000
+	// 合并后的节点名称
+	pathOtherNode = "(other)"
+)
+
+// PathAnalysisResponse 用户路径分析响应
+type PathAnalysisResponse struct {
+	Sessions int64      `json:"sessions"`
+	Nodes    []PathNode `json:"nodes"`
+	Links    []PathLink `json:"links"`
+}
+
+// PathNode 路径节点（桑基图节点）
+type PathNode struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Step  int    `json:"step"`
+	Count int64  `json:"count"`
+}
+
+// PathLink 路径连线（桑基图连线）
+type PathLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Value  int64  `json:"value"`
+}
+
+// 路径中的节点键
+type pathNodeKey struct {
+	step int
+	page string
+}
+
+// 路径中的连线键
+type pathLinkKey struct {
+	source pathNodeKey
+	target pathNodeKey
+}
+
+// GetPagePaths 获取用户页面跳转路径
+func (s *EventService) GetPagePaths(projectIDStr, startTimeStr, endTimeStr, startPage, endPage, depthStr string) (*PathAnalysisResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	depth, err := strconv.Atoi(depthStr)
+	if err != nil || depth < 1 {
+		depth = defaultPathDepth
+	}
+	if depth > maxPathDepth {
+		depth = maxPathDepth
+	}
+
+	sessions, err := s.getSessionPageSequences(uint(projectID), startTimeStr, endTimeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	startPage = NormalizeURL(startPage)
+	endPage = NormalizeURL(endPage)
+
+	// 截取每个会话中需要分析的路径片段
+	paths := make([][]string, 0, len(sessions))
+	for _, pages := range sessions {
+		path := slicePagePath(pages, startPage, endPage, depth)
+		if len(path) > 0 {
+			paths = append(paths, path)
+		}
+	}
+
+	return buildPathGraph(paths, endPage != "" && startPage == "", depth), nil
+}
+
+// 按会话读取有序的页面访问序列，先在 SQL 中选出最近活跃的会话，再读取这些会话的完整访问记录
+func (s *EventService) getSessionPageSequences(projectID uint, startTimeStr, endTimeStr string) ([][]string, error) {
+	rows, err := s.behaviors.ListSessionPageViews(projectID, parseTimeRange(startTimeStr, endTimeStr), maxPathSessions, maxPathRows)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKey := func(sessionID, userUUID string) string {
+		if sessionID == "" {
+			return "user:" + userUUID
+		}
+		return sessionID
+	}
+
+	// 达到行数上限时最后一个会话可能不完整，不参与分析
+	if len(rows) == maxPathRows {
+		last := sessionKey(rows[len(rows)-1].SessionID, rows[len(rows)-1].UserUUID)
+		for len(rows) > 0 && sessionKey(rows[len(rows)-1].SessionID, rows[len(rows)-1].UserUUID) == last {
+			rows = rows[:len(rows)-1]
+		}
+	}
+
+	// 按会话分组
+	index := make(map[string]int)
+	var sessions [][]string
+	for _, row := range rows {
+		key := sessionKey(row.SessionID, row.UserUUID)
+
+		page := NormalizeURL(row.PageURL)
+		if page == "" {
+			continue
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(sessions)
+			index[key] = i
+			sessions = append(sessions, nil)
+		}
+
+		// 合并连续访问的同一页面（如刷新）
+		if n := len(sessions[i]); n > 0 && sessions[i][n-1] == page {
+			continue
+		}
+		sessions[i] = append(sessions[i], page)
+	}
+
+	return sessions, nil
+}
+
+// 根据起始页和结束页截取路径片段，最多包含 depth+1 个页面
+func slicePagePath(pages []string, startPage, endPage string, depth int) []string {
+	switch {
+	case startPage != "":
+		start := indexOfPage(pages, startPage)
+		if start < 0 {
+			return nil
+		}
+		end := start + depth + 1
+		if end > len(pages) {
+			end = len(pages)
+		}
+		path := pages[start:end]
+
+		// 同时指定结束页时，只保留能到达结束页的路径
+		if endPage != "" {
+			stop := indexOfPage(path[1:], endPage)
+			if stop < 0 {
+				return nil
+			}
+			path = path[:stop+2]
+		}
+		return path
+	case endPage != "":
+		end := -1
+		for i := len(pages) - 1; i >= 0; i-- {
+			if pages[i] == endPage {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil
+		}
+		start := end - depth
+		if start < 0 {
+			start = 0
+		}
+		return pages[start : end+1]
+	default:
+		if len(pages) > depth+1 {
+			return pages[:depth+1]
+		}
+		return pages
+	}
+}
+
+// 查找页面首次出现的位置
+func indexOfPage(pages []string, page string) int {
+	for i, p := range pages {
+		if p == page {
+			return i
+		}
+	}
+	return -1
+}
+
+// 将路径片段汇总为桑基图节点和连线
+func buildPathGraph(paths [][]string, alignEnd bool, depth int) *PathAnalysisResponse {
+	nodeCounts := make(map[pathNodeKey]int64)
+	linkCounts := make(map[pathLinkKey]int64)
+
+	for _, path := range paths {
+		// 以结束页为终点时右对齐，保证结束页处于同一步
+		offset := 0
+		if alignEnd {
+			offset = depth + 1 - len(path)
+		}
+
+		var prev *pathNodeKey
+		for i, page := range path {
+			node := pathNodeKey{step: i + offset, page: page}
+			nodeCounts[node]++
+			if prev != nil {
+				linkCounts[pathLinkKey{source: *prev, target: node}]++
+			}
+			prev = &node
+		}
+	}
+
+	// 每一步只保留访问量最高的节点，其余合并
+	byStep := make(map[int][]pathNodeKey)
+	for node := range nodeCounts {
+		byStep[node.step] = append(byStep[node.step], node)
+	}
+	merged := make(map[pathNodeKey]pathNodeKey)
+	for step, nodes := range byStep {
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodeCounts[nodes[i]] != nodeCounts[nodes[j]] {
+				return nodeCounts[nodes[i]] > nodeCounts[nodes[j]]
+			}
+			return nodes[i].page < nodes[j].page
+		})
+		for i, node := range nodes {
+			if i < maxPathNodesPerStep {
+				merged[node] = node
+			} else {
+				merged[node] = pathNodeKey{step: step, page: pathOtherNode}
+			}
+		}
+	}
+
+	finalNodes := make(map[pathNodeKey]int64)
+	for node, count := range nodeCounts {
+		finalNodes[merged[node]] += count
+	}
+	finalLinks := make(map[pathLinkKey]int64)
+	for link, count := range linkCounts {
+		finalLinks[pathLinkKey{source: merged[link.source], target: merged[link.target]}] += count
+	}
+
+	resp := &PathAnalysisResponse{
+		Sessions: int64(len(paths)),
+		Nodes:    make([]PathNode, 0, len(finalNodes)),
+		Links:    make([]PathLink, 0, len(finalLinks)),
+	}
+	for node, count := range finalNodes {
+		resp.Nodes = append(resp.Nodes, PathNode{
+			ID:    pathNodeID(node),
+			Name:  node.page,
+			Step:  node.step,
+			Count: count,
+		})
+	}
+	for link, count := range finalLinks {
+		resp.Links = append(resp.Links, PathLink{
+			Source: pathNodeID(link.source),
+			Target: pathNodeID(link.target),
+			Value:  count,
+		})
+	}
+
+	sort.Slice(resp.Nodes, func(i, j int) bool {
+		if resp.Nodes[i].Step != resp.Nodes[j].Step {
+			return resp.Nodes[i].Step < resp.Nodes[j].Step
+		}
+		return resp.Nodes[i].Count > resp.Nodes[j].Count
+	})
+	sort.Slice(resp.Links, func(i, j int) bool {
+		return resp.Links[i].Value > resp.Links[j].Value
+	})
+
+	return resp
+}
+
+// 节点唯一标识，同一页面在不同步骤中是不同节点
+func pathNodeID(node pathNodeKey) string {
+	return fmt.Sprintf("%d:%s", node.step, node.page)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetPagePathsFromStartPage(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.behaviors.sessionViews = []repository.SessionPageView{
+		{SessionID: "s1", PageURL: "https://example.com/home"},
+		// 刷新同一页面只算一次
+		{SessionID: "s1", PageURL: "https://example.com/home?from=reload"},
+		{SessionID: "s1", PageURL: "https://example.com/items/1"},
+		{SessionID: "s1", PageURL: "https://example.com/cart"},
+		{SessionID: "s2", PageURL: "https://example.com/home"},
+		{SessionID: "s2", PageURL: "https://example.com/items/2"},
+		// 没有会话ID时按用户标识分组，未访问起始页不参与分析
+		{UserUUID: "u3", PageURL: "https://example.com/about"},
+	}
+
+	resp, err := fakes.eventService().GetPagePaths("1", "1700000001000", "1700000002000", "/home", "", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakes.behaviors.timeRange != (repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}) {
+		t.Fatalf("时间范围 %+v", fakes.behaviors.timeRange)
+	}
+
+	items := NormalizeURL("https://example.com/items/1")
+	wantNodes := []PathNode{
+		{ID: "0:/home", Name: "/home", Step: 0, Count: 2},
+		{ID: "1:" + items, Name: items, Step: 1, Count: 2},
+		{ID: "2:/cart", Name: "/cart", Step: 2, Count: 1},
+	}
+	wantLinks := []PathLink{
+		{Source: "0:/home", Target: "1:" + items, Value: 2},
+		{Source: "1:" + items, Target: "2:/cart", Value: 1},
+	}
+	if resp.Sessions != 2 || !reflect.DeepEqual(resp.Nodes, wantNodes) || !reflect.DeepEqual(resp.Links, wantLinks) {
+		t.Fatalf("路径 %+v", resp)
+	}
+}
+
+func TestGetPagePathsAlignsEndPage(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.behaviors.sessionViews = []repository.SessionPageView{
+		{SessionID: "s1", PageURL: "/home"},
+		{SessionID: "s1", PageURL: "/cart"},
+		{SessionID: "s2", PageURL: "/home"},
+		{SessionID: "s2", PageURL: "/about"},
+		{SessionID: "s2", PageURL: "/cart"},
+	}
+
+	resp, err := fakes.eventService().GetPagePaths("1", "", "", "", "/cart", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 以结束页为终点时右对齐，结束页都在最后一步
+	for _, node := range resp.Nodes {
+		if node.Name == "/cart" && (node.Step != 2 || node.Count != 2) {
+			t.Fatalf("结束页节点 %+v", node)
+		}
+	}
+	if resp.Sessions != 2 {
+		t.Fatalf("会话数 %d", resp.Sessions)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
 )
 
 // 项目创建请求
@@ -19,11 +20,18 @@ type UpdateProjectRequest struct {
 }
 
 // 项目服务
-type ProjectService struct{}
+type ProjectService struct {
+	projects repository.ProjectRepository
+}
+
+// NewProjectService 创建项目服务
+func NewProjectService(projects repository.ProjectRepository) *ProjectService {
+	return &ProjectService{projects: projects}
+}
 
 // 创建项目
 func (s *ProjectService) CreateProject(req *CreateProjectRequest, userID uint) (*model.Project, error) {
-	project, err := model.CreateProject(req.Name, req.Description, userID)
+	project, err := s.projects.Create(req.Name, req.Description, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +40,7 @@ func (s *ProjectService) CreateProject(req *CreateProjectRequest, userID uint) (
 
 // 获取用户的所有项目
 func (s *ProjectService) GetUserProjects(userID uint) ([]model.Project, error) {
-	projects, err := model.GetProjectsByUserID(userID)
+	projects, err := s.projects.ListByUser(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +49,7 @@ func (s *ProjectService) GetUserProjects(userID uint) ([]model.Project, error) {
 
 // 获取项目详情
 func (s *ProjectService) GetProject(id uint, userID uint) (*model.Project, error) {
-	project, err := model.GetProjectByID(id)
+	project, err := s.projects.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +64,7 @@ func (s *ProjectService) GetProject(id uint, userID uint) (*model.Project, error
 
 // 更新项目
 func (s *ProjectService) UpdateProject(id uint, req *UpdateProjectRequest, userID uint) (*model.Project, error) {
-	project, err := model.GetProjectByID(id)
+	project, err := s.projects.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -66,8 +74,9 @@ func (s *ProjectService) UpdateProject(id uint, req *UpdateProjectRequest, userI
 		return nil, errors.New("无权修改该项目")
 	}
 
-	project, err = model.UpdateProject(id, req.Name, req.Description)
-	if err != nil {
+	project.Name = req.Name
+	project.Description = req.Description
+	if err := s.projects.Save(project); err != nil {
 		return nil, err
 	}
 
@@ -76,7 +85,7 @@ func (s *ProjectService) UpdateProject(id uint, req *UpdateProjectRequest, userI
 
 // 删除项目
 func (s *ProjectService) DeleteProject(id uint, userID uint) error {
-	project, err := model.GetProjectByID(id)
+	project, err := s.projects.FindByID(id)
 	if err != nil {
 		return err
 	}
@@ -86,5 +95,5 @@ func (s *ProjectService) DeleteProject(id uint, userID uint) error {
 		return errors.New("无权删除该项目")
 	}
 
-	return model.DeleteProject(id)
+	return s.projects.Delete(id)
 }
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// 单次聚合最多读取的资源记录数量
+const maxResourceAggregateRows = 200000
+
+// 资源归属
+const (
+	ResourcePartyFirst = "first"
+	ResourcePartyThird = "third"
+)
+
+// ResourceAggregateResponse 资源性能聚合响应
+type ResourceAggregateResponse struct {
+	Total int64                   `json:"total"`
+	List  []ResourceAggregateItem `json:"list"`
+}
+
+// ResourceAggregateItem 按资源或域名聚合的性能数据
+type ResourceAggregateItem struct {
+	Key                string  `json:"key"`
+	Domain             string  `json:"domain"`
+	ResourceType       string  `json:"resourceType"`
+	Party              string  `json:"party"`
+	Count              int64   `json:"count"`
+	P75Duration        float64 `json:"p75Duration"`
+	P95Duration        float64 `json:"p95Duration"`
+	AvgTransferSize    float64 `json:"avgTransferSize"`
+	AvgDecodedBodySize float64 `json:"avgDecodedBodySize"`
+	CacheHitRatio      float64 `json:"cacheHitRatio"`
+}
+
+// ResourceAggregateFilter 资源聚合筛选条件
+type ResourceAggregateFilter struct {
+	StartTime    string
+	EndTime      string
+	ResourceType string
+	GroupBy      string
+	Party        string
+	SortBy       string
+	Order        string
+}
+
+// 资源聚合中间结果
+type resourceGroup struct {
+	item         ResourceAggregateItem
+	durations    []float64
+	transferSum  float64
+	decodedSum   float64
+	cacheHits    int64
+	resourceType map[string]int64
+}
+
+// GetResourceAggregate 按资源URL或域名聚合资源性能，用于找出最慢、最大的资源
+func (s *EventService) GetResourceAggregate(projectIDStr, pageStr, pageSizeStr string, filter ResourceAggregateFilter) (*ResourceAggregateResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	rows, err := s.performance.ResourceSamples(uint(projectID), parseTimeRange(filter.StartTime, filter.EndTime),
+		filter.ResourceType, maxResourceAggregateRows)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*resourceGroup)
+	for _, row := range rows {
+		domain := URLHost(row.ResourceURL)
+		party := ResourcePartyThird
+		if IsFirstPartyHost(domain, URLHost(row.PageURL)) {
+			party = ResourcePartyFirst
+		}
+		if filter.Party != "" && filter.Party != party {
+			continue
+		}
+
+		key := NormalizeResourceURL(row.ResourceURL)
+		if filter.GroupBy == "domain" {
+			key = domain
+		}
+
+		group, ok := groups[key]
+		if !ok {
+			group = &resourceGroup{
+				item:         ResourceAggregateItem{Key: key, Domain: domain, Party: party},
+				resourceType: make(map[string]int64),
+			}
+			groups[key] = group
+		}
+		group.item.Count++
+		group.durations = append(group.durations, row.Duration)
+		group.transferSum += row.TransferSize
+		group.decodedSum += row.DecodedBodySize
+		group.resourceType[row.ResourceType]++
+		if row.FromCache {
+			group.cacheHits++
+		}
+	}
+
+	list := make([]ResourceAggregateItem, 0, len(groups))
+	for _, group := range groups {
+		item := group.item
+		count := float64(item.Count)
+		item.P75Duration = percentile(group.durations, 0.75)
+		item.P95Duration = percentile(group.durations, 0.95)
+		item.AvgTransferSize = roundVital(group.transferSum / count)
+		item.AvgDecodedBodySize = roundVital(group.decodedSum / count)
+		item.CacheHitRatio = roundVital(float64(group.cacheHits) / count * 100)
+		item.ResourceType = dominantResourceType(group.resourceType)
+		list = append(list, item)
+	}
+	sortResourceAggregate(list, filter.SortBy, filter.Order != "asc")
+
+	total := int64(len(list))
+	offset := (page - 1) * pageSize
+	if offset > len(list) {
+		offset = len(list)
+	}
+	end := offset + pageSize
+	if end > len(list) {
+		end = len(list)
+	}
+
+	return &ResourceAggregateResponse{
+		Total: total,
+		List:  list[offset:end],
+	}, nil
+}
+
+// 取出现次数最多的资源类型，按域名聚合时一个分组可能包含多种类型
+func dominantResourceType(counts map[string]int64) string {
+	var result string
+	var max int64
+	for resourceType, count := range counts {
+		if count > max || (count == max && resourceType < result) {
+			result = resourceType
+			max = count
+		}
+	}
+	return result
+}
+
+// 资源排序，默认按请求次数排序
+func sortResourceAggregate(list []ResourceAggregateItem, sortBy string, desc bool) {
+	value := func(item ResourceAggregateItem) float64 {
+		switch sortBy {
+		case "p75":
+			return item.P75Duration
+		case "p95":
+			return item.P95Duration
+		case "size":
+			return item.AvgTransferSize
+		case "decodedSize":
+			return item.AvgDecodedBodySize
+		case "cacheHitRatio":
+			return item.CacheHitRatio
+		default:
+			return float64(item.Count)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		vi, vj := value(list[i]), value(list[j])
+		if vi != vj {
+			if desc {
+				return vi > vj
+			}
+			return vi < vj
+		}
+		return list[i].Key < list[j].Key
+	})
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetResourceAggregate(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.performance.resSamples = []repository.ResourceSample{
+		{PageURL: "https://example.com/home", ResourceURL: "https://example.com/app.js?v=1", ResourceType: "script", Duration: 100, TransferSize: 1000},
+		{PageURL: "https://example.com/cart", ResourceURL: "https://example.com/app.js?v=2", ResourceType: "script", Duration: 300, FromCache: true},
+		{PageURL: "https://example.com/home", ResourceURL: "https://cdn.other.com/lib.js", ResourceType: "script", Duration: 50, TransferSize: 200},
+	}
+	service := fakes.eventService()
+
+	resp, err := service.GetResourceAggregate("1", "1", "10", ResourceAggregateFilter{
+		StartTime:    "1700000001000",
+		EndTime:      "1700000002000",
+		ResourceType: "script",
+		Party:        ResourcePartyFirst,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakes.performance.resourceType != "script" || fakes.performance.timeRange != (repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000002000}) {
+		t.Fatalf("筛选条件 %s %+v", fakes.performance.resourceType, fakes.performance.timeRange)
+	}
+	// 同一资源的不同版本合并，第三方资源被过滤
+	if resp.Total != 1 {
+		t.Fatalf("资源 %+v", resp.List)
+	}
+	item := resp.List[0]
+	if item.Key != NormalizeResourceURL("https://example.com/app.js?v=1") || item.Party != ResourcePartyFirst || item.Count != 2 ||
+		item.AvgTransferSize != 500 || item.CacheHitRatio != 50 || item.ResourceType != "script" {
+		t.Fatalf("资源汇总 %+v", item)
+	}
+
+	// 按域名聚合
+	resp, err = service.GetResourceAggregate("1", "1", "10", ResourceAggregateFilter{GroupBy: "domain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || resp.List[0].Key != "example.com" || resp.List[0].Count != 2 ||
+		resp.List[1].Key != "cdn.other.com" || resp.List[1].Party != ResourcePartyThird {
+		t.Fatalf("域名汇总 %+v", resp.List)
+	}
+}
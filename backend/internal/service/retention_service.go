@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 每批删除的默认事件数量
+const defaultPurgeBatchSize = 1000
+
+// RetentionItem 项目某一类别的数据保留策略
+type RetentionItem struct {
+	Category   string   `json:"category"`
+	EventTypes []string `json:"eventTypes"`
+	Days       int      `json:"days"`      // 0 表示永久保留
+	IsDefault  bool     `json:"isDefault"` // 是否使用配置中的默认值
+}
+
+// RetentionRequest 数据保留策略更新请求
+type RetentionRequest struct {
+	Policies []RetentionPolicyRequest `json:"policies" binding:"required"`
+}
+
+// RetentionPolicyRequest 单个类别的保留天数
+type RetentionPolicyRequest struct {
+	Category string `json:"category" binding:"required"`
+	Days     int    `json:"days"`
+}
+
+// PurgeRequest 数据清理请求，时间戳为毫秒，分类和事件类型都为空时清理所有类型
+type PurgeRequest struct {
+	Categories []string `json:"categories"`
+	EventTypes []string `json:"eventTypes"`
+	StartTime  int64    `json:"startTime"`
+	EndTime    int64    `json:"endTime"`
+}
+
+// PurgeResponse 数据清理响应
+type PurgeResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// 数据保留服务
+type RetentionService struct {
+	repos    *repository.Repositories
+	projects repository.ProjectRepository
+	events   repository.EventRepository
+	store    EventStore
+}
+
+// NewRetentionService 创建数据保留服务
+func NewRetentionService(repos *repository.Repositories, store EventStore) *RetentionService {
+	return &RetentionService{repos: repos, projects: repos.Projects, events: repos.Events, store: store}
+}
+
+// 检查项目是否属于该用户
+func (s *RetentionService) checkProject(projectID, userID uint) error {
+	project, err := s.projects.FindByID(projectID)
+	if err != nil {
+		return errors.New("项目不存在")
+	}
+	if project.UserID != userID {
+		return errors.New("无权访问该项目")
+	}
+	return nil
+}
+
+// 所有保留类别，按名称排序
+func retentionCategories() []string {
+	categories := make([]string, 0, len(model.RetentionCategoryEventTypes))
+	for category := range model.RetentionCategoryEventTypes {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// 获取项目各类别生效的保留天数
+func (s *RetentionService) projectRetention(projectID uint) ([]RetentionItem, error) {
+	policies, err := s.projects.ListRetentionPolicies(projectID)
+	if err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]int, len(policies))
+	for _, policy := range policies {
+		overrides[policy.Category] = policy.Days
+	}
+
+	items := make([]RetentionItem, 0, len(model.RetentionCategoryEventTypes))
+	for _, category := range retentionCategories() {
+		item := RetentionItem{
+			Category:   category,
+			EventTypes: model.RetentionCategoryEventTypes[category],
+			Days:       model.DefaultRetentionDays(category),
+			IsDefault:  true,
+		}
+		if days, ok := overrides[category]; ok {
+			item.Days = days
+			item.IsDefault = false
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetRetention 获取项目的数据保留策略
+func (s *RetentionService) GetRetention(projectID uint, userID uint) ([]RetentionItem, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+	return s.projectRetention(projectID)
+}
+
+// UpdateRetention 更新项目的数据保留策略，未包含的类别保持不变
+func (s *RetentionService) UpdateRetention(projectID uint, req *RetentionRequest, userID uint) ([]RetentionItem, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+
+	for _, policy := range req.Policies {
+		if _, ok := model.RetentionCategoryEventTypes[policy.Category]; !ok {
+			return nil, errors.New("不支持的数据类别")
+		}
+		if policy.Days < 0 {
+			return nil, errors.New("保留天数不能小于0")
+		}
+	}
+	for _, policy := range req.Policies {
+		if err := s.projects.SaveRetentionPolicy(projectID, policy.Category, policy.Days); err != nil {
+			return nil, err
+		}
+	}
+	return s.projectRetention(projectID)
+}
+
+// PurgeProjectData 按类别或事件类型和时间范围清理项目数据
+func (s *RetentionService) PurgeProjectData(projectID uint, req *PurgeRequest, userID uint) (*PurgeResponse, error) {
+	if err := s.checkProject(projectID, userID); err != nil {
+		return nil, err
+	}
+	if req.EndTime <= 0 {
+		return nil, errors.New("请指定结束时间")
+	}
+	// 事件时间按毫秒保存，兼容秒级参数
+	startTime, endTime := timestampMillis(req.StartTime), timestampMillis(req.EndTime)
+	if startTime > endTime {
+		return nil, errors.New("开始时间不能晚于结束时间")
+	}
+
+	// 汇总需要清理的事件类型
+	eventTypes := append([]string{}, req.EventTypes...)
+	for _, category := range req.Categories {
+		types, ok := model.RetentionCategoryEventTypes[category]
+		if !ok {
+			return nil, errors.New("不支持的数据类别")
+		}
+		eventTypes = append(eventTypes, types...)
+	}
+	if len(eventTypes) == 0 {
+		for _, category := range retentionCategories() {
+			eventTypes = append(eventTypes, model.RetentionCategoryEventTypes[category]...)
+		}
+	}
+
+	deleted, err := s.purgeEvents(projectID, eventTypes, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Purge(projectID, eventTypes, startTime, endTime); err != nil {
+		return nil, err
+	}
+	// 清理的记录已计入预聚合，重建受影响的时间桶，避免统计中仍包含已清理的数据
+	if deleted > 0 {
+		if err := rebuildProjectRollups(s.repos, projectID, eventDetailModels(eventTypes), startTime, endTime); err != nil {
+			return nil, err
+		}
+	}
+	return &PurgeResponse{Deleted: deleted}, nil
+}
+
+// PurgeExpiredData 按各项目的保留策略清理过期数据，返回删除的事件数量
+func (s *RetentionService) PurgeExpiredData() (int64, error) {
+	projectIDs, err := s.projects.ListIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	now := time.Now()
+	for _, projectID := range projectIDs {
+		items, err := s.projectRetention(projectID)
+		if err != nil {
+			return total, err
+		}
+		for _, item := range items {
+			if item.Days <= 0 {
+				continue
+			}
+			cutoff := now.AddDate(0, 0, -item.Days).UnixMilli()
+			deleted, err := s.purgeEvents(projectID, item.EventTypes, 0, cutoff)
+			total += deleted
+			if err != nil {
+				return total, err
+			}
+			if deleted > 0 {
+				log.Printf("项目 %d 清理过期 %s 数据 %d 条", projectID, item.Category, deleted)
+			}
+			// 无论关系库是否有过期数据都清理事件存储，上次清理失败时关系库中已没有对应数据
+			if err := s.store.Purge(projectID, item.EventTypes, 0, cutoff); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, nil
+}
+
+// 事件类型对应的详情表，多个类型共用的表只返回一次
+func eventDetailModels(eventTypes []string) []interface{} {
+	var detailModels []interface{}
+	seen := make(map[reflect.Type]bool)
+	for _, eventType := range eventTypes {
+		for _, detailModel := range model.GetEventDetailModels(eventType) {
+			detail := detailModel.New()
+			if t := reflect.TypeOf(detail); !seen[t] {
+				seen[t] = true
+				detailModels = append(detailModels, detail)
+			}
+		}
+	}
+	return detailModels
+}
+
+// 分批删除触发时间在 [startTime, endTime) 内的事件及其详情和基础信息，每批一个事务以避免长时间锁表
+func (s *RetentionService) purgeEvents(projectID uint, eventTypes []string, startTime, endTime int64) (int64, error) {
+	batchSize := model.RetentionSetting.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+
+	detailModels := eventDetailModels(eventTypes)
+
+	var total int64
+	for {
+		events, err := s.events.FindEventRefs(projectID, eventTypes, startTime, endTime, batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(events) == 0 {
+			return total, nil
+		}
+		if err := s.events.DeleteEvents(events, detailModels); err != nil {
+			return total, err
+		}
+		total += int64(len(events))
+
+		if len(events) < batchSize {
+			return total, nil
+		}
+	}
+}
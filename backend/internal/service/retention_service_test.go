@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestPurgeExpiredDataPurgesStoreWithoutRelationalRows(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.projects.policies = []model.RetentionPolicy{{ProjectID: testProjectID, Category: model.RetentionCategoryBehavior, Days: 30}}
+
+	// 上次清理已删除关系库中的数据，但事件存储清理失败，仍保留过期事件
+	expired := time.Now().AddDate(0, 0, -31).UnixMilli()
+	store := newMemoryEventStore()
+	if err := store.Write([]repository.StoredEvent{
+		{ID: 1, EventType: model.EventTypePV, ProjectID: testProjectID, TriggerTime: expired},
+		{ID: 2, EventType: model.EventTypePV, ProjectID: testProjectID, TriggerTime: time.Now().UnixMilli()},
+	}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := NewRetentionService(fakes.repositories(), store).PurgeExpiredData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Fatalf("关系库删除 %d 条，期望 0", deleted)
+	}
+	if len(store.events) != 1 || store.events[0].ID != 2 {
+		t.Fatalf("事件存储剩余 %+v", store.events)
+	}
+}
@@ -0,0 +1,513 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 预聚合指标
+const (
+	rollupMetricError       = "error"        // 错误数，维度 type/browser/os
+	rollupMetricErrorUsers  = "error_users"  // 受影响用户去重
+	rollupMetricPV          = "pv"           // 页面访问数，Sum 为停留时间，维度 page
+	rollupMetricPVStay      = "pv_stay"      // 停留时间已知的页面访问数，Sum 为停留时间
+	rollupMetricBounce      = "pv_bounce"    // 跳出的页面访问数，只统计停留时间已知的访问
+	rollupMetricUV          = "uv"           // 访问用户去重
+	rollupMetricClick       = "click"        // 点击数，维度 element
+	rollupMetricVital       = "vital"        // 性能指标，Sum 为指标值之和，维度 name
+	rollupMetricVitalRating = "vital_rating" // 性能指标评级，维度为指标名，值为 good/poor
+)
+
+const (
+	// 默认每批处理的记录数量
+	defaultRollupBatchSize = 5000
+	// 维度值最大长度
+	maxRollupValueLength = 255
+	// 停留时间小于该值（毫秒）视为跳出
+	bounceStayTime = 10 * 1000
+	// 直方图对数桶的增长因子，估算分位数的相对误差约 2.5%
+	rollupHistogramGrowth = 1.05
+	// 值为 0 的样本所在的直方图桶，小于所有对数桶
+	vitalZeroBucket = math.MinInt32
+	// 延迟聚合的记录在性能合并窗口之外额外等待的时间（秒），覆盖上报和入库的延迟
+	lateSettleMargin = 10 * 60
+)
+
+// 带维度的计数只保存到小时和天粒度，总量额外保存分钟粒度
+var (
+	rollupDimensionGranularities = []string{model.RollupHour, model.RollupDay}
+	rollupTotalGranularities     = []string{model.RollupMinute, model.RollupHour, model.RollupDay}
+)
+
+type rollupCountKey struct {
+	projectID   uint
+	granularity string
+	bucketStart int64
+	metric      string
+	dimension   string
+	value       string
+}
+
+type rollupCountValue struct {
+	count int64
+	sum   float64
+}
+
+type rollupSketchKey struct {
+	projectID   uint
+	granularity string
+	bucketStart int64
+	metric      string
+}
+
+type rollupHistogramKey struct {
+	projectID   uint
+	granularity string
+	bucketStart int64
+	metric      string
+	bucket      int
+}
+
+// 一批记录在内存中的聚合结果
+type rollupBatch struct {
+	counts     map[rollupCountKey]*rollupCountValue
+	sketches   map[rollupSketchKey]*hyperLogLog
+	histograms map[rollupHistogramKey]int64
+}
+
+func newRollupBatch() *rollupBatch {
+	return &rollupBatch{
+		counts:     make(map[rollupCountKey]*rollupCountValue),
+		sketches:   make(map[rollupSketchKey]*hyperLogLog),
+		histograms: make(map[rollupHistogramKey]int64),
+	}
+}
+
+// 累加计数，维度为空时同时累加分钟粒度
+func (b *rollupBatch) addCount(projectID uint, ts int64, metric, dimension, value string, sum float64) {
+	granularities := rollupDimensionGranularities
+	if dimension == "" {
+		granularities = rollupTotalGranularities
+	}
+	value = truncateRunes(value, maxRollupValueLength)
+	for _, granularity := range granularities {
+		key := rollupCountKey{projectID, granularity, model.RollupBucketStart(granularity, ts), metric, dimension, value}
+		item, ok := b.counts[key]
+		if !ok {
+			item = &rollupCountValue{}
+			b.counts[key] = item
+		}
+		item.count++
+		item.sum += sum
+	}
+}
+
+// 记录去重元素
+func (b *rollupBatch) addDistinct(projectID uint, ts int64, metric, value string) {
+	if value == "" {
+		return
+	}
+	for _, granularity := range rollupDimensionGranularities {
+		key := rollupSketchKey{projectID, granularity, model.RollupBucketStart(granularity, ts), metric}
+		sketch, ok := b.sketches[key]
+		if !ok {
+			sketch = newHyperLogLog()
+			b.sketches[key] = sketch
+		}
+		sketch.Add(value)
+	}
+}
+
+// 记录直方图样本
+func (b *rollupBatch) addHistogram(projectID uint, ts int64, metric string, bucket int) {
+	for _, granularity := range rollupDimensionGranularities {
+		b.histograms[rollupHistogramKey{projectID, granularity, model.RollupBucketStart(granularity, ts), metric, bucket}]++
+	}
+}
+
+// 将聚合结果累加到预聚合表
+func (b *rollupBatch) flush(repo repository.RollupRepository) error {
+	for key, item := range b.counts {
+		if err := repo.AddCount(&model.RollupCount{
+			ProjectID:   key.projectID,
+			Granularity: key.granularity,
+			BucketStart: key.bucketStart,
+			Metric:      key.metric,
+			Dimension:   key.dimension,
+			Value:       key.value,
+			Count:       item.count,
+			Sum:         item.sum,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for key, count := range b.histograms {
+		if err := repo.AddHistogram(&model.RollupHistogram{
+			ProjectID:   key.projectID,
+			Granularity: key.granularity,
+			BucketStart: key.bucketStart,
+			Metric:      key.metric,
+			Bucket:      key.bucket,
+			Count:       count,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 去重计数器需要与已有的寄存器合并
+	for key, sketch := range b.sketches {
+		row, err := repo.FindSketch(key.projectID, key.granularity, key.bucketStart, key.metric)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			row = &model.RollupSketch{
+				ProjectID:   key.projectID,
+				Granularity: key.granularity,
+				BucketStart: key.bucketStart,
+				Metric:      key.metric,
+			}
+		} else {
+			sketch.Merge(hyperLogLogFromBytes(row.Sketch))
+		}
+		row.Sketch = sketch.Bytes()
+		if err := repo.SaveSketch(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 性能指标值所在的直方图对数桶，桶 i 覆盖 (width*γ^(i-1), width*γ^i]，值为 0 时使用单独的桶
+func vitalHistogramBucket(metric vitalMetric, value float64) int {
+	if value <= 0 {
+		return vitalZeroBucket
+	}
+	return int(math.Ceil(math.Log(value/metric.BucketWidth) / math.Log(rollupHistogramGrowth)))
+}
+
+// 预聚合数据源，每个数据源使用独立的游标
+type rollupSource struct {
+	cursor string
+	late   bool // 入库后仍会更新，需要等待更久
+	// 数据源的详情表和写入的指标，清理原始数据时用于重建受影响的预聚合
+	detail  interface{}
+	metrics []string
+	// 读取游标之后的一批记录并累加到聚合结果，返回最后一条记录的ID和记录数
+	collect func(repo repository.RollupRepository, scope repository.RollupScope, limit int, batch *rollupBatch) (uint, int, error)
+}
+
+var rollupSources = []rollupSource{
+	{
+		cursor:  "rollup_error",
+		detail:  &model.ErrorDetail{},
+		metrics: []string{rollupMetricError, rollupMetricErrorUsers},
+		collect: collectErrorRollups,
+	},
+	{
+		cursor:  "rollup_pv",
+		late:    true,
+		detail:  &model.PVDetail{},
+		metrics: []string{rollupMetricPV, rollupMetricPVStay, rollupMetricBounce, rollupMetricUV},
+		collect: collectPVRollups,
+	},
+	{
+		cursor:  "rollup_click",
+		detail:  &model.ClickDetail{},
+		metrics: []string{rollupMetricClick},
+		collect: collectClickRollups,
+	},
+	{
+		cursor:  "rollup_performance",
+		late:    true,
+		detail:  &model.PerformancePageDetail{},
+		metrics: append([]string{rollupMetricVital, rollupMetricVitalRating}, vitalMetricNames()...),
+		collect: collectPerformanceRollups,
+	},
+}
+
+// 用于去重计数的访客标识，依次使用设备标识、用户ID和会话ID，都为空时返回空字符串
+func visitorKey(userUUID, userID, sessionID string) string {
+	switch {
+	case userUUID != "":
+		return "uuid:" + userUUID
+	case userID != "":
+		return "user:" + userID
+	case sessionID != "":
+		return "session:" + sessionID
+	}
+	return ""
+}
+
+// 错误数和受影响用户
+func collectErrorRollups(repo repository.RollupRepository, scope repository.RollupScope, limit int, batch *rollupBatch) (uint, int, error) {
+	rows, err := repo.ErrorRows(scope, limit)
+	if err != nil || len(rows) == 0 {
+		return scope.AfterID, 0, err
+	}
+
+	for _, row := range rows {
+		ts := timestampMillis(row.TriggerTime)
+		batch.addCount(row.ProjectID, ts, rollupMetricError, "", "", 0)
+		batch.addCount(row.ProjectID, ts, rollupMetricError, "type", row.ErrorType, 0)
+		batch.addCount(row.ProjectID, ts, rollupMetricError, "browser", row.Browser, 0)
+		batch.addCount(row.ProjectID, ts, rollupMetricError, "os", row.OS, 0)
+		batch.addDistinct(row.ProjectID, ts, rollupMetricErrorUsers, visitorKey(row.UserUUID, row.UserID, row.SessionID))
+	}
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+// 页面访问数、停留时间、跳出数和访问用户
+func collectPVRollups(repo repository.RollupRepository, scope repository.RollupScope, limit int, batch *rollupBatch) (uint, int, error) {
+	rows, err := repo.PageViewRows(scope, limit)
+	if err != nil || len(rows) == 0 {
+		return scope.AfterID, 0, err
+	}
+
+	for _, row := range rows {
+		ts := timestampMillis(row.TriggerTime)
+		batch.addCount(row.ProjectID, ts, rollupMetricPV, "", "", float64(row.StayTime))
+		batch.addCount(row.ProjectID, ts, rollupMetricPV, "page", NormalizeURL(row.PageURL), float64(row.StayTime))
+		// 停留时间为 0 表示未上报，不计入平均停留时间和跳出
+		if row.StayTime > 0 {
+			batch.addCount(row.ProjectID, ts, rollupMetricPVStay, "", "", float64(row.StayTime))
+			if row.StayTime < bounceStayTime {
+				batch.addCount(row.ProjectID, ts, rollupMetricBounce, "", "", 0)
+			}
+		}
+		batch.addDistinct(row.ProjectID, ts, rollupMetricUV, visitorKey(row.UserUUID, row.UserID, row.SessionID))
+	}
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+// 点击数和热门元素
+func collectClickRollups(repo repository.RollupRepository, scope repository.RollupScope, limit int, batch *rollupBatch) (uint, int, error) {
+	rows, err := repo.ClickRows(scope, limit)
+	if err != nil || len(rows) == 0 {
+		return scope.AfterID, 0, err
+	}
+
+	for _, row := range rows {
+		ts := timestampMillis(row.TriggerTime)
+		batch.addCount(row.ProjectID, ts, rollupMetricClick, "", "", 0)
+		batch.addCount(row.ProjectID, ts, rollupMetricClick, "element", row.ElementPath, 0)
+	}
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+// 性能指标均值、评级和直方图
+func collectPerformanceRollups(repo repository.RollupRepository, scope repository.RollupScope, limit int, batch *rollupBatch) (uint, int, error) {
+	rows, err := repo.PerformanceRows(scope, limit)
+	if err != nil || len(rows) == 0 {
+		return scope.AfterID, 0, err
+	}
+
+	for _, row := range rows {
+		ts := timestampMillis(row.TriggerTime)
+		// 只统计上报了该指标的记录，值为 0 的指标（如 CLS）同样计入
+		values := make(map[string]float64)
+		for name, value := range map[string]*float64{
+			"FP": row.FP, "FCP": row.FCP, "LCP": row.LCP, "FID": row.FID, "INP": row.INP,
+			"CLS": row.CLS, "TTFB": row.TTFB, "DomReady": row.DomReady, "Load": row.Load,
+		} {
+			if value != nil && *value >= 0 {
+				values[name] = *value
+				batch.addCount(row.ProjectID, ts, rollupMetricVital, "name", name, *value)
+			}
+		}
+		for _, metric := range vitalMetrics {
+			value, ok := values[metric.Name]
+			if !ok {
+				continue
+			}
+			batch.addHistogram(row.ProjectID, ts, metric.Name, vitalHistogramBucket(metric, value))
+			if metric.Rated {
+				switch {
+				case value <= metric.Good:
+					batch.addCount(row.ProjectID, ts, rollupMetricVitalRating, metric.Name, VitalRatingGood, 0)
+				case value > metric.Poor:
+					batch.addCount(row.ProjectID, ts, rollupMetricVitalRating, metric.Name, VitalRatingPoor, 0)
+				}
+			}
+		}
+	}
+	return rows[len(rows)-1].ID, len(rows), nil
+}
+
+// 预聚合服务
+type RollupService struct {
+	repos *repository.Repositories
+}
+
+// NewRollupService 创建预聚合服务
+func NewRollupService(repos *repository.Repositories) *RollupService {
+	return &RollupService{repos: repos}
+}
+
+// BuildRollups 聚合各数据源新入库的记录，返回处理的记录数
+func (s *RollupService) BuildRollups() (int, error) {
+	total := 0
+	for _, source := range rollupSources {
+		n, err := s.buildSource(source)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", source.cursor, err)
+		}
+	}
+	return total, nil
+}
+
+// 分批聚合一个数据源，每批的累加和游标推进在同一事务中完成，避免重复计数
+func (s *RollupService) buildSource(source rollupSource) (int, error) {
+	setting := model.RollupSetting
+	batchSize := setting.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRollupBatchSize
+	}
+	delay := setting.SettleDelay
+	if source.late {
+		// 性能指标在合并窗口内仍可能合并到已入库的记录，等待时间需长于合并窗口
+		delay = setting.LateSettleDelay
+		if delay <= performanceMergeWindow {
+			delay = performanceMergeWindow + lateSettleMargin
+		}
+	}
+	settleBefore := time.Now().Add(-time.Duration(delay) * time.Second)
+
+	total := 0
+	for {
+		cursor, err := s.repos.JobCursors.Get(source.cursor)
+		if err != nil {
+			return total, err
+		}
+
+		batch := newRollupBatch()
+		scope := repository.RollupScope{AfterID: cursor, SettleBefore: settleBefore}
+		lastID, n, err := source.collect(s.repos.Rollups, scope, batchSize, batch)
+		if err != nil || n == 0 {
+			return total, err
+		}
+
+		if err := s.repos.Transaction(func(tx *repository.Repositories) error {
+			if err := batch.flush(tx.Rollups); err != nil {
+				return err
+			}
+			return tx.JobCursors.Advance(source.cursor, cursor, lastID)
+		}); err != nil {
+			return total, err
+		}
+		total += n
+
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// RebuildRollups 清空预聚合数据并从头聚合全部历史记录
+func (s *RollupService) RebuildRollups() (int, error) {
+	cursors := make([]string, 0, len(rollupSources))
+	for _, source := range rollupSources {
+		cursors = append(cursors, source.cursor)
+	}
+
+	if err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Rollups.DeleteAll(); err != nil {
+			return err
+		}
+		return tx.JobCursors.Delete(cursors)
+	}); err != nil {
+		return 0, err
+	}
+
+	return s.BuildRollups()
+}
+
+// 重建项目在 [startTime, endTime) 内受影响的预聚合，用于清理原始数据后修正统计。
+// 范围按天对齐，删除各粒度的桶后重新聚合剩余记录；只聚合游标之前的记录，之后的记录仍由预聚合任务处理
+func rebuildProjectRollups(repos *repository.Repositories, projectID uint, details []interface{}, startTime, endTime int64) error {
+	batchSize := model.RollupSetting.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRollupBatchSize
+	}
+	from := model.RollupBucketStart(model.RollupDay, startTime)
+	to := model.RollupBucketStart(model.RollupDay, endTime-1) + model.RollupBucketSize(model.RollupDay)
+
+	affected := make(map[reflect.Type]bool, len(details))
+	for _, detail := range details {
+		affected[reflect.TypeOf(detail)] = true
+	}
+
+	return repos.Transaction(func(tx *repository.Repositories) error {
+		for _, source := range rollupSources {
+			if !affected[reflect.TypeOf(source.detail)] {
+				continue
+			}
+			cursor, err := tx.JobCursors.Get(source.cursor)
+			if err != nil {
+				return err
+			}
+			if err := tx.Rollups.DeleteProject(projectID, source.metrics, from, to); err != nil {
+				return err
+			}
+			if cursor == 0 {
+				continue
+			}
+
+			batch := newRollupBatch()
+			scope := repository.RollupScope{ThroughID: cursor, ProjectID: projectID, From: from, To: to}
+			for {
+				lastID, n, err := source.collect(tx.Rollups, scope, batchSize, batch)
+				if err != nil {
+					return err
+				}
+				if n < batchSize {
+					break
+				}
+				scope.AfterID = lastID
+			}
+			if err := batch.flush(tx.Rollups); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeExpiredRollups 清理超过保留天数的分钟和小时粒度数据
+func (s *RollupService) PurgeExpiredRollups() error {
+	now := time.Now()
+	retention := map[string]int{
+		model.RollupMinute: model.RollupSetting.MinuteDays,
+		model.RollupHour:   model.RollupSetting.HourDays,
+	}
+	for granularity, days := range retention {
+		if days <= 0 {
+			continue
+		}
+		if err := s.repos.Rollups.DeleteBefore(granularity, now.AddDate(0, 0, -days).UnixMilli()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 预聚合任务，聚合新数据后清理过期的细粒度数据
+func (s *RollupService) runRollupJob() error {
+	n, err := s.BuildRollups()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("预聚合处理记录 %d 条", n)
+	}
+	return s.PurgeExpiredRollups()
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+// 从粗到细的预聚合粒度
+var rollupLevels = []string{model.RollupDay, model.RollupHour, model.RollupMinute}
+
+// 计数汇总结果
+type rollupTotal struct {
+	Count int64
+	Sum   float64
+}
+
+// 某一粒度在 start 之后的数据是否仍然保留
+func rollupAvailable(granularity string, start int64) bool {
+	days := 0
+	switch granularity {
+	case model.RollupMinute:
+		days = model.RollupSetting.MinuteDays
+	case model.RollupHour:
+		days = model.RollupSetting.HourDays
+	}
+	return days <= 0 || start >= time.Now().AddDate(0, 0, -days).UnixMilli()
+}
+
+// 将时间范围拆分为尽量粗的粒度，完整的天使用天粒度，首尾不足一天的部分逐级使用更细的粒度；
+// 细粒度数据不可用时向外取整到完整的桶
+func rollupSegments(start, end int64, finest string) []repository.RollupSegment {
+	return appendRollupSegments(nil, start, end, 0, finest)
+}
+
+func appendRollupSegments(segments []repository.RollupSegment, start, end int64, level int, finest string) []repository.RollupSegment {
+	if start >= end {
+		return segments
+	}
+
+	granularity := rollupLevels[level]
+	size := model.RollupBucketSize(granularity)
+	first := model.RollupBucketStart(granularity, start)
+	if first < start {
+		first += size
+	}
+	last := model.RollupBucketStart(granularity, end)
+
+	if granularity == finest || !rollupAvailable(rollupLevels[level+1], start) {
+		if last < end {
+			last += size
+		}
+		return append(segments, repository.RollupSegment{Granularity: granularity, Start: model.RollupBucketStart(granularity, start), End: last})
+	}
+	if first >= last {
+		return appendRollupSegments(segments, start, end, level+1, finest)
+	}
+
+	segments = appendRollupSegments(segments, start, first, level+1, finest)
+	segments = append(segments, repository.RollupSegment{Granularity: granularity, Start: first, End: last})
+	return appendRollupSegments(segments, last, end, level+1, finest)
+}
+
+// 汇总时间范围 [start, end) 内的计数，按维度值分组，只有总量可以使用分钟粒度
+func (s *EventService) rollupTotals(projectID uint, metric, dimension string, start, end int64) (map[string]rollupTotal, error) {
+	finest := model.RollupHour
+	if dimension == "" {
+		finest = model.RollupMinute
+	}
+
+	rows, err := s.stats.SumCounts(projectID, metric, dimension, rollupSegments(start, end, finest))
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]rollupTotal, len(rows))
+	for _, row := range rows {
+		totals[row.Value] = rollupTotal{Count: int64(math.Round(row.Count)), Sum: row.Sum}
+	}
+	return totals, nil
+}
+
+// 按归一化后的取值合并汇总结果
+func mergeRollupValues(totals map[string]rollupTotal, normalize func(string) string) map[string]rollupTotal {
+	merged := make(map[string]rollupTotal, len(totals))
+	for value, total := range totals {
+		key := normalize(value)
+		item := merged[key]
+		item.Count += total.Count
+		item.Sum += total.Sum
+		merged[key] = item
+	}
+	return merged
+}
+
+// 汇总时间范围 [start, end) 内指标的总量
+func (s *EventService) rollupMetricTotal(projectID uint, metric string, start, end int64) (rollupTotal, error) {
+	totals, err := s.rollupTotals(projectID, metric, "", start, end)
+	if err != nil {
+		return rollupTotal{}, err
+	}
+	return totals[""], nil
+}
+
+// 时间范围 [start, end) 内的去重数量，按小时对齐
+func (s *EventService) rollupDistinct(projectID uint, metric string, start, end int64) (int64, error) {
+	sketches, err := s.stats.Sketches(projectID, metric, rollupSegments(start, end, model.RollupHour))
+	if err != nil {
+		return 0, err
+	}
+
+	merged := newHyperLogLog()
+	for _, sketch := range sketches {
+		merged.Merge(hyperLogLogFromBytes(sketch))
+	}
+	return merged.Count(), nil
+}
+
+// 时间范围 [start, end) 内的直方图，按小时对齐
+func (s *EventService) rollupHistogram(projectID uint, metric string, start, end int64) ([]vitalBucket, error) {
+	rows, err := s.stats.SumHistogram(projectID, metric, rollupSegments(start, end, model.RollupHour))
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]vitalBucket, 0, len(rows))
+	for _, row := range rows {
+		buckets = append(buckets, vitalBucket{Bucket: row.Bucket, Count: int64(math.Round(row.Count))})
+	}
+	return buckets, nil
+}
+
+// 计数最多的若干个维度值
+func topRollupValues(totals map[string]rollupTotal, limit int) map[string]int64 {
+	values := make([]string, 0, len(totals))
+	for value := range totals {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if totals[values[i]].Count != totals[values[j]].Count {
+			return totals[values[i]].Count > totals[values[j]].Count
+		}
+		return values[i] < values[j]
+	})
+	if len(values) > limit {
+		values = values[:limit]
+	}
+
+	top := make(map[string]int64, len(values))
+	for _, value := range values {
+		top[value] = totals[value].Count
+	}
+	return top
+}
+
+// 计数汇总的平均值
+func averageRollup(total rollupTotal) float64 {
+	if total.Count == 0 {
+		return 0
+	}
+	return total.Sum / float64(total.Count)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+const (
+	// 单次聚合最多读取的路由切换数量
+	maxRouteRows = 100000
+	// 默认返回的路由数量
+	defaultRouteLimit = 20
+	// 默认慢路由切换阈值（毫秒）
+	defaultSlowRouteThreshold = 1000
+	// 返回的最慢切换样本数量
+	slowRouteSampleLimit = 20
+)
+
+// RouteTimingResponse 路由切换耗时响应
+type RouteTimingResponse struct {
+	Threshold int64         `json:"threshold"`
+	Routes    []RouteTiming `json:"routes"`
+	Slowest   []RouteSample `json:"slowest"`
+}
+
+// RouteTiming 按来源和目标路由汇总的切换耗时
+type RouteTiming struct {
+	FromRoute   string  `json:"fromRoute"`
+	ToRoute     string  `json:"toRoute"`
+	Count       int64   `json:"count"`
+	SlowCount   int64   `json:"slowCount"`
+	AvgDuration float64 `json:"avgDuration"`
+	P75Duration float64 `json:"p75Duration"`
+	P95Duration float64 `json:"p95Duration"`
+	MaxDuration int64   `json:"maxDuration"`
+	AvgStayTime float64 `json:"avgStayTime"`
+}
+
+// RouteSample 单次慢路由切换
+type RouteSample struct {
+	EventID     string `json:"eventId"`
+	SessionID   string `json:"sessionId"`
+	FromURL     string `json:"fromUrl"`
+	ToURL       string `json:"toUrl"`
+	Mode        string `json:"mode"`
+	Action      string `json:"action"`
+	Duration    int64  `json:"duration"`
+	TriggerTime int64  `json:"triggerTime"`
+}
+
+// 从SDK路由切换事件处理
+func (s *EventService) processRouteEventFromSDK(req *TrackRequest, eventMain *model.EventMain, sessionID string) error {
+	// 创建路由切换详情
+	routeDetail := model.RouteDetail{
+		EventID: eventMain.ID,
+	}
+
+	// 从事件数据中提取路由信息
+	var dataMap map[string]interface{}
+	title := ""
+	if err := json.Unmarshal(req.Data, &dataMap); err == nil {
+		routeDetail.FromURL = stringField(dataMap, "from")
+		routeDetail.ToURL = stringField(dataMap, "to")
+		routeDetail.Mode = stringField(dataMap, "mode")
+		routeDetail.Action = stringField(dataMap, "action")
+		title = stringField(dataMap, "title")
+
+		if duration, ok := firstNumber(dataMap, "duration", "transitionDuration"); ok && duration > 0 {
+			routeDetail.Duration = int64(duration)
+		}
+		if stayTime, ok := firstNumber(dataMap, "stayTime"); ok && stayTime > 0 {
+			routeDetail.StayTime = int64(stayTime)
+		}
+	}
+	if routeDetail.ToURL == "" {
+		routeDetail.ToURL = eventMain.TriggerPageURL
+	}
+
+	// 以路由切换时间补全同一会话上一次页面访问的停留时间
+	if sessionID != "" {
+		previous, err := s.events.FindPreviousPageView(eventMain.ProjectID, sessionID, eventMain.TriggerTime, eventMain.ID)
+		if err != nil {
+			return err
+		}
+
+		if previous != nil {
+			// 触发时间统一按毫秒计算，与上报的停留时间单位一致
+			triggerTime, previousTime := timestampMillis(eventMain.TriggerTime), timestampMillis(previous.TriggerTime)
+			if routeDetail.StayTime == 0 && triggerTime > previousTime {
+				routeDetail.StayTime = triggerTime - previousTime
+			}
+			if previous.StayTime == 0 && routeDetail.StayTime > 0 {
+				if err := s.events.UpdatePageViewStayTime(previous.ID, routeDetail.StayTime); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// 保存路由切换详情
+	if err := s.events.CreateDetail(&routeDetail); err != nil {
+		return err
+	}
+
+	// 每次路由切换计为一次页面访问
+	pvDetail := model.PVDetail{
+		EventID:  eventMain.ID,
+		PageURL:  routeDetail.ToURL,
+		Title:    title,
+		Referrer: routeDetail.FromURL,
+	}
+	return s.events.CreateDetail(&pvDetail)
+}
+
+// GetRouteTiming 按来源和目标路由汇总切换耗时，并返回最慢的切换样本
+func (s *EventService) GetRouteTiming(projectIDStr, pageURL, startTimeStr, endTimeStr, thresholdStr, limitStr string) (*RouteTimingResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = defaultRouteLimit
+	}
+
+	threshold, err := strconv.ParseInt(thresholdStr, 10, 64)
+	if err != nil || threshold <= 0 {
+		threshold = defaultSlowRouteThreshold
+	}
+	pageURL = NormalizeURL(pageURL)
+
+	rows, err := s.behaviors.ListRouteChanges(uint(projectID), parseTimeRange(startTimeStr, endTimeStr), maxRouteRows)
+	if err != nil {
+		return nil, err
+	}
+
+	type routeKey struct{ from, to string }
+	type routeGroup struct {
+		item      RouteTiming
+		durations []float64
+		stayTimes []float64
+	}
+	groups := make(map[routeKey]*routeGroup)
+	slowest := []RouteSample{}
+	for _, row := range rows {
+		from := NormalizeURL(row.FromURL)
+		to := NormalizeURL(row.ToURL)
+		if pageURL != "" && from != pageURL && to != pageURL {
+			continue
+		}
+
+		key := routeKey{from: from, to: to}
+		group, ok := groups[key]
+		if !ok {
+			group = &routeGroup{item: RouteTiming{FromRoute: from, ToRoute: to}}
+			groups[key] = group
+		}
+		group.item.Count++
+		group.stayTimes = appendPositive(group.stayTimes, float64(row.StayTime))
+
+		// 未上报切换耗时的记录只计入次数
+		if row.Duration <= 0 {
+			continue
+		}
+		group.durations = append(group.durations, float64(row.Duration))
+		if row.Duration > group.item.MaxDuration {
+			group.item.MaxDuration = row.Duration
+		}
+		if row.Duration >= threshold {
+			group.item.SlowCount++
+			slowest = append(slowest, RouteSample{
+				EventID:     row.EventID,
+				SessionID:   row.SessionID,
+				FromURL:     row.FromURL,
+				ToURL:       row.ToURL,
+				Mode:        row.Mode,
+				Action:      row.Action,
+				Duration:    row.Duration,
+				TriggerTime: row.TriggerTime,
+			})
+		}
+	}
+
+	routes := make([]RouteTiming, 0, len(groups))
+	for _, group := range groups {
+		item := group.item
+		item.AvgDuration = roundVital(average(group.durations))
+		item.P75Duration = percentile(group.durations, 0.75)
+		item.P95Duration = percentile(group.durations, 0.95)
+		item.AvgStayTime = roundVital(average(group.stayTimes))
+		routes = append(routes, item)
+	}
+
+	// 按 P75 耗时降序，慢的切换排在前面
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].P75Duration != routes[j].P75Duration {
+			return routes[i].P75Duration > routes[j].P75Duration
+		}
+		return routes[i].Count > routes[j].Count
+	})
+	if len(routes) > limit {
+		routes = routes[:limit]
+	}
+
+	sort.Slice(slowest, func(i, j int) bool {
+		return slowest[i].Duration > slowest[j].Duration
+	})
+	if len(slowest) > slowRouteSampleLimit {
+		slowest = slowest[:slowRouteSampleLimit]
+	}
+
+	return &RouteTimingResponse{
+		Threshold: threshold,
+		Routes:    routes,
+		Slowest:   slowest,
+	}, nil
+}
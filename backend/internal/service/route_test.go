@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetRouteTimingGroupsByRoute(t *testing.T) {
+	fakes := newFakeRepositories()
+	fakes.behaviors.routes = []repository.RouteChange{
+		{EventID: "e1", SessionID: "s1", FromURL: "/home", ToURL: "/items/1", Duration: 1200, StayTime: 3000, TriggerTime: 1000},
+		{EventID: "e2", SessionID: "s2", FromURL: "/home", ToURL: "/items/2", Duration: 400, StayTime: 5000, TriggerTime: 2000},
+		// 未上报切换耗时的记录只计入次数
+		{EventID: "e3", SessionID: "s3", FromURL: "/home", ToURL: "/items/3", TriggerTime: 3000},
+		{EventID: "e4", SessionID: "s4", FromURL: "/about", ToURL: "/contact", Duration: 2000, TriggerTime: 4000},
+	}
+
+	resp, err := fakes.eventService().GetRouteTiming("1", "/items/9", "1700000001000", "1700000005000", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fakes.behaviors.timeRange != (repository.TimeRange{StartTime: 1700000001000, EndTime: 1700000005000}) {
+		t.Fatalf("时间范围 %+v", fakes.behaviors.timeRange)
+	}
+	if resp.Threshold != defaultSlowRouteThreshold || len(resp.Routes) != 1 {
+		t.Fatalf("路由切换 %+v", resp)
+	}
+
+	route := resp.Routes[0]
+	if route.FromRoute != "/home" || route.ToRoute != NormalizeURL("/items/1") || route.Count != 3 || route.SlowCount != 1 ||
+		route.MaxDuration != 1200 || route.AvgDuration != 800 || route.AvgStayTime != 4000 {
+		t.Fatalf("路由汇总 %+v", route)
+	}
+	// 慢切换样本保留原始URL
+	if len(resp.Slowest) != 1 || resp.Slowest[0].EventID != "e1" || resp.Slowest[0].ToURL != "/items/1" {
+		t.Fatalf("慢切换样本 %+v", resp.Slowest)
+	}
+}
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// Sentry 协议兼容：sentry_key 即项目的 AppKey，DSN 形如 http://<AppKey>@<host>/sentry/<任意项目编号>
+
+// SentryResponse Sentry 上报响应
+type SentryResponse struct {
+	ID string `json:"id"`
+}
+
+// Sentry 事件中用到的字段
+type sentryEvent struct {
+	EventID     string          `json:"event_id"`
+	Timestamp   json.RawMessage `json:"timestamp"`
+	Platform    string          `json:"platform"`
+	Level       string          `json:"level"`
+	Logger      string          `json:"logger"`
+	Transaction string          `json:"transaction"`
+	Release     string          `json:"release"`
+	Environment string          `json:"environment"`
+	Message     json.RawMessage `json:"message"`
+	LogEntry    *struct {
+		Message   string `json:"message"`
+		Formatted string `json:"formatted"`
+	} `json:"logentry"`
+	Exception   json.RawMessage        `json:"exception"`
+	Breadcrumbs json.RawMessage        `json:"breadcrumbs"`
+	Tags        json.RawMessage        `json:"tags"`
+	Fingerprint []string               `json:"fingerprint"`
+	User        map[string]interface{} `json:"user"`
+	Request     *sentryRequest         `json:"request"`
+	Contexts    map[string]interface{} `json:"contexts"`
+	Extra       map[string]interface{} `json:"extra"`
+}
+
+type sentryRequest struct {
+	URL     string          `json:"url"`
+	Method  string          `json:"method"`
+	Headers json.RawMessage `json:"headers"`
+}
+
+type sentryException struct {
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	Module     string `json:"module"`
+	Stacktrace *struct {
+		Frames []sentryFrame `json:"frames"`
+	} `json:"stacktrace"`
+}
+
+type sentryFrame struct {
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Function string `json:"function"`
+	Module   string `json:"module"`
+	Lineno   int    `json:"lineno"`
+	Colno    int    `json:"colno"`
+	InApp    *bool  `json:"in_app"`
+}
+
+// SentryKeyFromAuth 从 X-Sentry-Auth 请求头中解析 sentry_key
+func SentryKeyFromAuth(header string) string {
+	header = strings.TrimSpace(header)
+	if strings.HasPrefix(strings.ToLower(header), "sentry ") {
+		header = header[len("sentry "):]
+	}
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && strings.TrimSpace(key) == "sentry_key" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// 从 DSN 中解析 sentry_key
+func sentryKeyFromDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	return u.User.Username()
+}
+
+// ProcessSentryStore 处理 store 接口上报的单个事件
+func (s *EventService) ProcessSentryStore(body []byte, base TrackRequest) (*SentryResponse, error) {
+	if base.AppKey == "" {
+		return nil, errors.New("缺少sentry_key")
+	}
+	return s.processSentryEvent(body, base)
+}
+
+// ProcessSentryEnvelope 处理 envelope 接口上报的数据，只处理 event 类型的条目
+func (s *EventService) ProcessSentryEnvelope(body []byte, base TrackRequest) (*SentryResponse, error) {
+	source := bytes.NewReader(body)
+	reader := bufio.NewReader(source)
+
+	// 信封头
+	headerLine, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var envelopeHeader struct {
+		EventID string `json:"event_id"`
+		DSN     string `json:"dsn"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(headerLine), &envelopeHeader); err != nil {
+		return nil, errors.New("无效的envelope数据")
+	}
+	if base.AppKey == "" {
+		base.AppKey = sentryKeyFromDSN(envelopeHeader.DSN)
+	}
+	if base.AppKey == "" {
+		return nil, errors.New("缺少sentry_key")
+	}
+
+	resp := &SentryResponse{ID: envelopeHeader.EventID}
+	for {
+		itemHeaderLine, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(itemHeaderLine)) == 0 {
+			if err != nil {
+				break
+			}
+			continue
+		}
+
+		var itemHeader struct {
+			Type   string `json:"type"`
+			Length *int   `json:"length"`
+		}
+		if err := json.Unmarshal(bytes.TrimSpace(itemHeaderLine), &itemHeader); err != nil {
+			return nil, errors.New("无效的envelope数据")
+		}
+
+		// 条目内容：指定长度时按长度读取，否则读到换行
+		var payload []byte
+		if itemHeader.Length != nil {
+			// 长度由客户端指定，不能超过尚未读取的数据
+			if *itemHeader.Length < 0 || *itemHeader.Length > source.Len()+reader.Buffered() {
+				return nil, errors.New("无效的envelope数据")
+			}
+			payload = make([]byte, *itemHeader.Length)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return nil, errors.New("无效的envelope数据")
+			}
+			reader.ReadBytes('\n')
+		} else {
+			payload, err = reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+		}
+
+		if itemHeader.Type == "event" {
+			eventResp, err := s.processSentryEvent(bytes.TrimSpace(payload), base)
+			if err != nil {
+				return nil, err
+			}
+			resp.ID = eventResp.ID
+		}
+	}
+
+	return resp, nil
+}
+
+// 将 Sentry 事件转换为错误上报
+func (s *EventService) processSentryEvent(body []byte, base TrackRequest) (*SentryResponse, error) {
+	var event sentryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, errors.New("无效的Sentry事件")
+	}
+
+	req := base
+	req.Category = "error"
+	req.Type = model.ErrorTypeJS
+	if event.Platform != "" && event.Platform != "javascript" && event.Platform != "node" {
+		req.Type = model.ErrorTypeCustom
+	}
+	req.Severity = sentrySeverity(event.Level)
+	req.Release = event.Release
+	req.Environment = event.Environment
+	req.Timestamp = sentryTimestamp(event.Timestamp)
+
+	// 取最后一个异常（Sentry 中最外层的异常排在最后）
+	exceptions := sentryExceptions(event.Exception)
+	var exception sentryException
+	if len(exceptions) > 0 {
+		exception = exceptions[len(exceptions)-1]
+	}
+
+	message := sentryMessage(event)
+	if exception.Type != "" || exception.Value != "" {
+		message = strings.TrimPrefix(exception.Type+": "+exception.Value, ": ")
+	}
+
+	var frames []sentryFrame
+	if exception.Stacktrace != nil {
+		frames = exception.Stacktrace.Frames
+	}
+	topFrame := sentryTopFrame(frames)
+
+	req.Fingerprint = sentryFingerprint(event, exception.Type, message, topFrame)
+
+	data := map[string]interface{}{
+		"message":  message,
+		"stack":    sentryStack(message, frames),
+		"filename": topFrame.file(),
+		"lineno":   topFrame.Lineno,
+		"colno":    topFrame.Colno,
+	}
+	if event.Request != nil && event.Request.URL != "" {
+		data["url"] = event.Request.URL
+	}
+	if event.User != nil {
+		if id, ok := event.User["id"]; ok && id != nil {
+			data["userId"] = fmt.Sprintf("%v", id)
+		}
+	}
+	if traceID, spanID := sentryTraceContext(event.Contexts); traceID != "" {
+		data["traceId"] = traceID
+		data["spanId"] = spanID
+	}
+	if browser := sentryContextField(event.Contexts, "browser"); browser != nil {
+		data["browser"], _ = browser["name"].(string)
+		data["browserVersion"], _ = browser["version"].(string)
+	}
+	if os := sentryContextField(event.Contexts, "os"); os != nil {
+		data["os"], _ = os["name"].(string)
+		data["osVersion"], _ = os["version"].(string)
+	}
+	if tags := sentryTags(event.Tags); len(tags) > 0 {
+		data["ext"] = map[string]interface{}{"tags": tags}
+	}
+	if breadcrumbs := sentryBreadcrumbs(event.Breadcrumbs); len(breadcrumbs) > 0 {
+		data["breadcrumbs"] = breadcrumbs
+	}
+	context := map[string]interface{}{}
+	if event.Logger != "" {
+		context["logger"] = event.Logger
+	}
+	if event.Transaction != "" {
+		context["transaction"] = event.Transaction
+	}
+	if len(event.Extra) > 0 {
+		context["extra"] = event.Extra
+	}
+	if len(context) > 0 {
+		data["context"] = context
+	}
+
+	req.Data, _ = json.Marshal(data)
+	if err := s.ProcessTrackData(&req); err != nil {
+		return nil, err
+	}
+
+	return &SentryResponse{ID: event.EventID}, nil
+}
+
+// Sentry 级别映射为错误严重程度
+func sentrySeverity(level string) string {
+	switch level {
+	case "fatal":
+		return model.ErrorSeverityFatal
+	case "warning":
+		return model.ErrorSeverityWarning
+	case "info", "debug":
+		return model.ErrorSeverityInfo
+	default:
+		return model.ErrorSeverityError
+	}
+}
+
+// 解析时间戳，支持秒级浮点数和 RFC3339 字符串，统一转为毫秒
+func sentryTimestamp(raw json.RawMessage) int64 {
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err == nil && seconds > 0 {
+		return int64(seconds * 1000)
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"} {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t.UnixMilli()
+			}
+		}
+	}
+	return time.Now().UnixMilli()
+}
+
+// 异常列表，兼容 {values: [...]} 和数组两种结构
+func sentryExceptions(raw json.RawMessage) []sentryException {
+	var wrapped struct {
+		Values []sentryException `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err == nil && len(wrapped.Values) > 0 {
+		return wrapped.Values
+	}
+	var list []sentryException
+	json.Unmarshal(raw, &list)
+	return list
+}
+
+// 没有异常时使用消息，兼容字符串和对象两种结构
+func sentryMessage(event sentryEvent) string {
+	if event.LogEntry != nil {
+		if event.LogEntry.Formatted != "" {
+			return event.LogEntry.Formatted
+		}
+		return event.LogEntry.Message
+	}
+	var text string
+	if err := json.Unmarshal(event.Message, &text); err == nil {
+		return text
+	}
+	var message struct {
+		Message   string `json:"message"`
+		Formatted string `json:"formatted"`
+	}
+	if err := json.Unmarshal(event.Message, &message); err == nil {
+		if message.Formatted != "" {
+			return message.Formatted
+		}
+		return message.Message
+	}
+	return ""
+}
+
+// 文件路径，优先使用完整路径
+func (f sentryFrame) file() string {
+	if f.AbsPath != "" {
+		return f.AbsPath
+	}
+	return f.Filename
+}
+
+// 取最内层的业务代码帧（Sentry 帧按调用顺序排列，最后一帧最内层）
+func sentryTopFrame(frames []sentryFrame) sentryFrame {
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].InApp == nil || *frames[i].InApp {
+			return frames[i]
+		}
+	}
+	if len(frames) > 0 {
+		return frames[len(frames)-1]
+	}
+	return sentryFrame{}
+}
+
+// 还原为 V8 风格的堆栈文本
+func sentryStack(message string, frames []sentryFrame) string {
+	if len(frames) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(message)
+	for i := len(frames) - 1; i >= 0; i-- {
+		frame := frames[i]
+		function := frame.Function
+		if function == "" {
+			function = "?"
+		}
+		fmt.Fprintf(&builder, "\n    at %s (%s:%d:%d)", function, frame.file(), frame.Lineno, frame.Colno)
+	}
+	return builder.String()
+}
+
+// 错误指纹，优先使用 SDK 指定的指纹，{{ default }} 替换为默认规则
+func sentryFingerprint(event sentryEvent, exceptionType, message string, frame sentryFrame) string {
+	defaultParts := exceptionType + "|" + message + "|" + frame.file() + ":" + frame.Function
+	parts := defaultParts
+	if len(event.Fingerprint) > 0 {
+		items := make([]string, 0, len(event.Fingerprint))
+		for _, item := range event.Fingerprint {
+			if item == "{{ default }}" || item == "{{default}}" {
+				item = defaultParts
+			}
+			items = append(items, item)
+		}
+		parts = strings.Join(items, "|")
+	}
+	sum := md5.Sum([]byte(parts))
+	return hex.EncodeToString(sum[:])
+}
+
+// 标签，兼容对象和 [key, value] 数组两种结构
+func sentryTags(raw json.RawMessage) map[string]string {
+	tags := make(map[string]string)
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		for key, value := range object {
+			tags[key] = fmt.Sprintf("%v", value)
+		}
+		return tags
+	}
+	var pairs [][]interface{}
+	if err := json.Unmarshal(raw, &pairs); err == nil {
+		for _, pair := range pairs {
+			if len(pair) == 2 {
+				tags[fmt.Sprintf("%v", pair[0])] = fmt.Sprintf("%v", pair[1])
+			}
+		}
+	}
+	return tags
+}
+
+// 面包屑，兼容 {values: [...]} 和数组两种结构，时间戳统一为毫秒
+func sentryBreadcrumbs(raw json.RawMessage) []map[string]interface{} {
+	var list []map[string]interface{}
+	var wrapped struct {
+		Values []map[string]interface{} `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err == nil && wrapped.Values != nil {
+		list = wrapped.Values
+	} else {
+		json.Unmarshal(raw, &list)
+	}
+
+	for _, crumb := range list {
+		if timestamp, ok := crumb["timestamp"]; ok {
+			encoded, _ := json.Marshal(timestamp)
+			crumb["timestamp"] = sentryTimestamp(encoded)
+		}
+	}
+	return list
+}
+
+// 读取上下文中的对象字段
+func sentryContextField(contexts map[string]interface{}, name string) map[string]interface{} {
+	if contexts == nil {
+		return nil
+	}
+	field, _ := contexts[name].(map[string]interface{})
+	return field
+}
+
+// 从 trace 上下文中获取链路信息
+func sentryTraceContext(contexts map[string]interface{}) (string, string) {
+	trace := sentryContextField(contexts, "trace")
+	if trace == nil {
+		return "", ""
+	}
+	traceID, _ := trace["trace_id"].(string)
+	spanID, _ := trace["span_id"].(string)
+	return traceID, spanID
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// Sentry SDK 上报的异常事件
+const sentryTestEvent = `{
+	"event_id": "abc123",
+	"timestamp": 1700000000.5,
+	"platform": "javascript",
+	"level": "warning",
+	"release": "1.0.0",
+	"environment": "prod",
+	"request": {"url": "https://example.com/cart"},
+	"user": {"id": 42},
+	"contexts": {
+		"browser": {"name": "Chrome", "version": "120.0"},
+		"os": {"name": "macOS", "version": "14.1"},
+		"trace": {"trace_id": "t1", "span_id": "s1"}
+	},
+	"exception": {"values": [{
+		"type": "TypeError",
+		"value": "x is undefined",
+		"stacktrace": {"frames": [
+			{"filename": "vendor.js", "lineno": 1, "colno": 1, "in_app": false},
+			{"filename": "app.js", "lineno": 10, "colno": 5, "in_app": true}
+		]}
+	}]}
+}`
+
+func TestProcessSentryStoreMapsException(t *testing.T) {
+	fakes := newFakeRepositories()
+	resp, err := fakes.eventService().ProcessSentryStore([]byte(sentryTestEvent), TrackRequest{AppKey: testProjectKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "abc123" {
+		t.Fatalf("响应事件ID %s", resp.ID)
+	}
+
+	if len(fakes.events.events) != 1 || fakes.events.events[0].EventType != model.EventTypeError ||
+		fakes.events.events[0].TriggerTime != 1700000000500 {
+		t.Fatalf("事件主记录 %+v", fakes.events.events)
+	}
+	base := fakes.events.baseInfos[0]
+	if base.PageURL != "https://example.com/cart" || base.UserID != "42" || base.Browser != "Chrome" ||
+		base.OS != "macOS" || base.Release != "1.0.0" || base.Environment != "prod" {
+		t.Fatalf("基础信息 %+v", base)
+	}
+
+	detail := findDetail[model.ErrorDetail](fakes.events.details)
+	if detail == nil {
+		t.Fatal("未保存错误详情")
+	}
+	// 取最内层的业务代码帧
+	if detail.ErrorType != model.ErrorTypeJS || detail.ErrorMessage != "TypeError: x is undefined" ||
+		detail.FilePath != "app.js" || detail.LineNumber != 10 || detail.ColumnNumber != 5 ||
+		detail.Severity != model.ErrorSeverityWarning || detail.Fingerprint == "" {
+		t.Fatalf("错误详情 %+v", detail)
+	}
+}
+
+func TestProcessSentryStoreRequiresKey(t *testing.T) {
+	fakes := newFakeRepositories()
+	if _, err := fakes.eventService().ProcessSentryStore([]byte(sentryTestEvent), TrackRequest{}); err == nil {
+		t.Fatal("缺少 sentry_key 时应返回错误")
+	}
+	if len(fakes.events.events) != 0 {
+		t.Fatal("不应保存事件")
+	}
+}
+
+func TestProcessSentryEnvelopeUsesDSNKey(t *testing.T) {
+	fakes := newFakeRepositories()
+	var payload bytes.Buffer
+	json.Compact(&payload, []byte(sentryTestEvent))
+	body := strings.Join([]string{
+		`{"event_id":"abc123","dsn":"https://` + testProjectKey + `@sentry.example.com/1"}`,
+		`{"type":"session"}`,
+		`{"status":"ok"}`,
+		`{"type":"event","length":` + strconv.Itoa(payload.Len()) + `}`,
+		payload.String(),
+	}, "\n")
+
+	resp, err := fakes.eventService().ProcessSentryEnvelope([]byte(body), TrackRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "abc123" {
+		t.Fatalf("响应事件ID %s", resp.ID)
+	}
+	// 只处理 event 条目
+	if len(fakes.events.events) != 1 || fakes.events.baseInfos[0].ProjectID != testProjectID {
+		t.Fatalf("保存了 %d 条事件", len(fakes.events.events))
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// 统计粒度
+const (
+	GranularityMinute = "minute"
+	GranularityHour   = "hour"
+	GranularityDay    = "day"
+	GranularityWeek   = "week"
+)
+
+const (
+	// 默认统计最近7天
+	defaultStatsRange = 7 * 86400 * 1000
+	// 趋势数据最多的时间桶数量
+	maxStatsBuckets = 2000
+)
+
+// 时区偏移，如 +08:00、-0530
+var timezoneOffsetPattern = regexp.MustCompile(`^(?:UTC|GMT)?([+-])(\d{1,2}):?(\d{2})?$`)
+
+// StatsQuery 统计查询参数，时间戳为毫秒
+type StatsQuery struct {
+	StartTime   string
+	EndTime     string
+	Granularity string // minute/hour/day/week，默认 day
+	Timezone    string // IANA 时区名称或 UTC 偏移，如 Asia/Shanghai、+08:00，默认 UTC
+}
+
+// StatsRangeInfo 统计实际使用的时间范围和对比周期
+type StatsRangeInfo struct {
+	StartTime         int64  `json:"startTime"`
+	EndTime           int64  `json:"endTime"`
+	PreviousStartTime int64  `json:"previousStartTime"`
+	PreviousEndTime   int64  `json:"previousEndTime"`
+	Granularity       string `json:"granularity"`
+	Timezone          string `json:"timezone"`
+}
+
+// 解析后的统计时间范围 [Start, End)，时间桶按时区对齐
+type statsRange struct {
+	Start       int64
+	End         int64
+	Granularity string
+	Location    *time.Location
+}
+
+// 解析时区，支持 IANA 时区名称和 UTC 偏移
+func parseTimezone(timezone string) (*time.Location, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	if match := timezoneOffsetPattern.FindStringSubmatch(timezone); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		if hours > 14 || minutes >= 60 {
+			return nil, errors.New("无效的时区")
+		}
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(timezone, offset), nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.New("无效的时区")
+	}
+	return location, nil
+}
+
+// 解析统计查询参数，时间戳兼容秒和毫秒，结束时间包含在内，默认最近7天
+func parseStatsQuery(query StatsQuery) (*statsRange, error) {
+	location, err := parseTimezone(query.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &statsRange{Granularity: query.Granularity, Location: location}
+	switch r.Granularity {
+	case "":
+		r.Granularity = GranularityDay
+	case GranularityMinute, GranularityHour, GranularityDay, GranularityWeek:
+	default:
+		return nil, errors.New("不支持的统计粒度")
+	}
+
+	end := time.Now().UnixMilli()
+	if query.EndTime != "" {
+		parsed, err := strconv.ParseInt(query.EndTime, 10, 64)
+		if err != nil {
+			return nil, errors.New("无效的结束时间")
+		}
+		end = timestampMillis(parsed)
+	}
+	start := end - defaultStatsRange
+	if query.StartTime != "" {
+		parsed, err := strconv.ParseInt(query.StartTime, 10, 64)
+		if err != nil {
+			return nil, errors.New("无效的开始时间")
+		}
+		start = timestampMillis(parsed)
+	}
+	if start > end {
+		return nil, errors.New("开始时间不能晚于结束时间")
+	}
+	r.Start, r.End = start, end+1
+
+	if len(r.buckets()) > maxStatsBuckets {
+		return nil, errors.New("时间粒度过细，请缩小时间范围或使用更大的粒度")
+	}
+	return r, nil
+}
+
+// 紧邻当前范围之前、长度相同的对比周期
+func (r *statsRange) previous() *statsRange {
+	length := r.End - r.Start
+	return &statsRange{
+		Start:       r.Start - length,
+		End:         r.Start,
+		Granularity: r.Granularity,
+		Location:    r.Location,
+	}
+}
+
+// 范围信息
+func (r *statsRange) info() StatsRangeInfo {
+	previous := r.previous()
+	return StatsRangeInfo{
+		StartTime:         r.Start,
+		EndTime:           r.End - 1,
+		PreviousStartTime: previous.Start,
+		PreviousEndTime:   previous.End - 1,
+		Granularity:       r.Granularity,
+		Timezone:          r.Location.String(),
+	}
+}
+
+// 时间戳所在时间桶的起始时间，按时区对齐，周从周一开始
+func (r *statsRange) bucketStart(ts int64) int64 {
+	t := time.UnixMilli(ts).In(r.Location)
+	switch r.Granularity {
+	case GranularityMinute:
+		return t.Truncate(time.Minute).UnixMilli()
+	case GranularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, r.Location).UnixMilli()
+	case GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.Location)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7).UnixMilli()
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.Location).UnixMilli()
+	}
+}
+
+// 下一个时间桶的起始时间
+func (r *statsRange) nextBucket(bucket int64) int64 {
+	t := time.UnixMilli(bucket).In(r.Location)
+	switch r.Granularity {
+	case GranularityMinute:
+		return t.Add(time.Minute).UnixMilli()
+	case GranularityHour:
+		return t.Add(time.Hour).UnixMilli()
+	case GranularityWeek:
+		return t.AddDate(0, 0, 7).UnixMilli()
+	default:
+		return t.AddDate(0, 0, 1).UnixMilli()
+	}
+}
+
+// 范围内全部时间桶的起始时间，超过上限时提前结束
+func (r *statsRange) buckets() []int64 {
+	var buckets []int64
+	for bucket := r.bucketStart(r.Start); bucket < r.End; bucket = r.nextBucket(bucket) {
+		buckets = append(buckets, bucket)
+		if len(buckets) > maxStatsBuckets {
+			break
+		}
+	}
+	return buckets
+}
+
+// 时间桶的显示标签
+func (r *statsRange) label(bucket int64) string {
+	t := time.UnixMilli(bucket).In(r.Location)
+	switch r.Granularity {
+	case GranularityMinute, GranularityHour:
+		return t.Format("2006-01-02 15:04")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// 时区中今天零点（毫秒）
+func (r *statsRange) todayStart() int64 {
+	t := time.Now().In(r.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.Location).UnixMilli()
+}
+
+// 时区中昨天零点（毫秒）
+func (r *statsRange) yesterdayStart() int64 {
+	return time.UnixMilli(r.todayStart()).In(r.Location).AddDate(0, 0, -1).UnixMilli()
+}
+
+// 预聚合粒度的桶边界在该时区中是否与统计时间桶对齐
+func (r *statsRange) aligned(granularity string) bool {
+	size := model.RollupBucketSize(granularity) / 1000
+	for _, ts := range []int64{r.Start, r.End - 1} {
+		_, offset := time.UnixMilli(ts).In(r.Location).Zone()
+		if int64(offset)%size != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 选择读取趋势数据的预聚合粒度，优先使用与统计时间桶对齐且仍保留的最粗粒度，
+// 都不满足时使用仍保留的最细粒度近似
+func (r *statsRange) rollupGranularity(finest string) string {
+	levels := []string{model.RollupMinute, model.RollupHour, model.RollupDay}
+	target := map[string]int{GranularityMinute: 0, GranularityHour: 1, GranularityDay: 2, GranularityWeek: 2}[r.Granularity]
+	lowest := 0
+	for i, level := range levels {
+		if level == finest {
+			lowest = i
+		}
+	}
+	if target < lowest {
+		target = lowest
+	}
+
+	for i := target; i >= lowest; i-- {
+		if r.aligned(levels[i]) && rollupAvailable(levels[i], r.Start) {
+			return levels[i]
+		}
+	}
+	for i := lowest; i < len(levels)-1; i++ {
+		if rollupAvailable(levels[i], r.Start) {
+			return levels[i]
+		}
+	}
+	return model.RollupDay
+}
+
+// 按统计时间桶汇总计数，返回时间桶起始时间到按维度值分组的计数
+func (s *EventService) rollupCountSeries(projectID uint, metric, dimension string, r *statsRange) (map[int64]map[string]rollupTotal, error) {
+	finest := model.RollupHour
+	if dimension == "" {
+		finest = model.RollupMinute
+	}
+	granularity := r.rollupGranularity(finest)
+
+	rows, err := s.stats.Counts(projectID, metric, dimension, granularity, model.RollupBucketStart(granularity, r.Start), r.End)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[int64]map[string]rollupTotal)
+	for _, row := range rows {
+		bucket := r.bucketStart(row.BucketStart)
+		if series[bucket] == nil {
+			series[bucket] = make(map[string]rollupTotal)
+		}
+		total := series[bucket][row.Value]
+		total.Count += row.Count
+		total.Sum += row.Sum
+		series[bucket][row.Value] = total
+	}
+	return series, nil
+}
+
+// 按统计时间桶的去重数量
+func (s *EventService) rollupDistinctSeries(projectID uint, metric string, r *statsRange) (map[int64]int64, error) {
+	granularity := r.rollupGranularity(model.RollupHour)
+
+	rows, err := s.stats.SketchBuckets(projectID, metric, granularity, model.RollupBucketStart(granularity, r.Start), r.End)
+	if err != nil {
+		return nil, err
+	}
+
+	sketches := make(map[int64]*hyperLogLog)
+	for _, row := range rows {
+		bucket := r.bucketStart(row.BucketStart)
+		if sketches[bucket] == nil {
+			sketches[bucket] = newHyperLogLog()
+		}
+		sketches[bucket].Merge(hyperLogLogFromBytes(row.Sketch))
+	}
+
+	series := make(map[int64]int64, len(sketches))
+	for bucket, sketch := range sketches {
+		series[bucket] = sketch.Count()
+	}
+	return series, nil
+}
+
+// 按统计时间桶的直方图
+func (s *EventService) rollupHistogramSeries(projectID uint, metric string, r *statsRange) (map[int64][]vitalBucket, error) {
+	granularity := r.rollupGranularity(model.RollupHour)
+
+	rows, err := s.stats.HistogramBuckets(projectID, metric, granularity, model.RollupBucketStart(granularity, r.Start), r.End)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int64]map[int]int64)
+	for _, row := range rows {
+		bucket := r.bucketStart(row.BucketStart)
+		if counts[bucket] == nil {
+			counts[bucket] = make(map[int]int64)
+		}
+		counts[bucket][row.Bucket] += row.Count
+	}
+
+	series := make(map[int64][]vitalBucket, len(counts))
+	for bucket, histogram := range counts {
+		for index, count := range histogram {
+			series[bucket] = append(series[bucket], vitalBucket{Bucket: index, Count: count})
+		}
+	}
+	return series, nil
+}
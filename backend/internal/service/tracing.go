@@ -0,0 +1,167 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// 单条链路最多返回的事件数量
+const maxTraceEvents = 500
+
+var (
+	// W3C traceparent：version-traceId-spanId-flags
+	traceparentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+	traceIDPattern     = regexp.MustCompile(`^[0-9a-f]{32}$`)
+	spanIDPattern      = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+// TraceEventsResponse 链路关联的前端事件
+type TraceEventsResponse struct {
+	TraceID  string           `json:"traceId"`
+	TraceURL string           `json:"traceUrl"`
+	Events   []TraceEventItem `json:"events"`
+}
+
+// TraceEventItem 链路中的单个前端事件
+type TraceEventItem struct {
+	ID          uint   `json:"id"`
+	EventID     string `json:"eventId"`
+	EventType   string `json:"eventType"`
+	SpanID      string `json:"spanId"`
+	TriggerTime int64  `json:"triggerTime"`
+	PageURL     string `json:"pageUrl"`
+	Summary     string `json:"summary"`
+}
+
+// 解析 traceparent 头，返回 traceId 和 spanId
+func parseTraceparent(value string) (string, string, bool) {
+	matches := traceparentPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if matches == nil || matches[1] == "ff" || !validTraceID(matches[2]) || !validSpanID(matches[3]) {
+		return "", "", false
+	}
+	return matches[2], matches[3], true
+}
+
+// 全 0 的 ID 在规范中无效
+func validTraceID(traceID string) bool {
+	return traceIDPattern.MatchString(traceID) && strings.Trim(traceID, "0") != ""
+}
+
+func validSpanID(spanID string) bool {
+	return spanIDPattern.MatchString(spanID) && strings.Trim(spanID, "0") != ""
+}
+
+// 从上报数据中提取链路信息，支持 traceparent 字段、请求头以及单独上报的 traceId/spanId
+func extractTraceContext(data json.RawMessage) (string, string) {
+	var dataMap map[string]interface{}
+	if err := json.Unmarshal(data, &dataMap); err != nil {
+		return "", ""
+	}
+
+	candidates := []map[string]interface{}{dataMap}
+	for _, key := range []string{"requestHeaders", "headers"} {
+		if headers, ok := dataMap[key].(map[string]interface{}); ok {
+			candidates = append(candidates, headers)
+		}
+	}
+	for _, candidate := range candidates {
+		for key, value := range candidate {
+			if !strings.EqualFold(key, "traceparent") {
+				continue
+			}
+			if traceparent, ok := value.(string); ok {
+				if traceID, spanID, ok := parseTraceparent(traceparent); ok {
+					return traceID, spanID
+				}
+			}
+		}
+	}
+
+	traceID, _ := dataMap["traceId"].(string)
+	traceID = strings.ToLower(traceID)
+	if !validTraceID(traceID) {
+		return "", ""
+	}
+	spanID, _ := dataMap["spanId"].(string)
+	spanID = strings.ToLower(spanID)
+	if !validSpanID(spanID) {
+		spanID = ""
+	}
+	return traceID, spanID
+}
+
+// TraceURL 根据配置的模板生成追踪系统链接，未配置时返回空字符串
+func TraceURL(traceID, spanID string) string {
+	template := model.TracingSetting.UrlTemplate
+	if template == "" || traceID == "" {
+		return ""
+	}
+	return strings.NewReplacer("{traceId}", traceID, "{spanId}", spanID).Replace(template)
+}
+
+// GetTraceEvents 按 traceId 查找项目内的所有前端事件
+func (s *EventService) GetTraceEvents(projectIDStr, traceID string) (*TraceEventsResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	traceID = strings.ToLower(strings.TrimSpace(traceID))
+	if !validTraceID(traceID) {
+		return nil, errors.New("无效的traceId")
+	}
+
+	events, err := s.events.FindByTraceID(uint(projectID), traceID, maxTraceEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	// 批量获取错误信息作为事件摘要
+	eventIDs := make([]uint, 0, len(events))
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+	summaries := make(map[uint]string)
+	if len(eventIDs) > 0 {
+		var errorDetails []model.ErrorDetail
+		if err := s.events.FindDetails(eventIDs, &errorDetails); err != nil {
+			return nil, err
+		}
+		for _, detail := range errorDetails {
+			summaries[detail.EventID] = detail.ErrorMessage
+		}
+
+		var httpDetails []model.HttpErrorDetail
+		if err := s.events.FindDetails(eventIDs, &httpDetails); err != nil {
+			return nil, err
+		}
+		for _, detail := range httpDetails {
+			summaries[detail.EventID] = fmt.Sprintf("%s %s %d", detail.Method, detail.URL, detail.Status)
+		}
+	}
+
+	resp := &TraceEventsResponse{
+		TraceID:  traceID,
+		TraceURL: TraceURL(traceID, ""),
+		Events:   make([]TraceEventItem, 0, len(events)),
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, TraceEventItem{
+			ID:          event.ID,
+			EventID:     event.EventID,
+			EventType:   event.EventType,
+			SpanID:      event.SpanID,
+			TriggerTime: event.TriggerTime,
+			PageURL:     event.TriggerPageURL,
+			Summary:     summaries[event.ID],
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+func TestGetTraceEventsSummarizesErrors(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	fakes := newFakeRepositories()
+	service := fakes.eventService()
+
+	requests := []*TrackRequest{
+		trackRequest("error", model.ErrorTypeHttp, map[string]interface{}{
+			"message":        "Bad Gateway",
+			"url":            "https://api.example.com/orders",
+			"method":         "post",
+			"status":         502,
+			"requestHeaders": map[string]interface{}{"traceparent": "00-" + traceID + "-" + spanID + "-01"},
+		}),
+		trackRequest("error", model.ErrorTypeJS, map[string]interface{}{"message": "boom", "traceId": traceID}),
+		// 其他链路的事件
+		trackRequest("error", model.ErrorTypeJS, map[string]interface{}{"message": "other", "traceId": strings.Repeat("1", 32)}),
+	}
+	for _, req := range requests {
+		if err := service.ProcessTrackData(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := service.GetTraceEvents("1", strings.ToUpper(traceID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TraceID != traceID || len(resp.Events) != 2 {
+		t.Fatalf("链路事件 %+v", resp)
+	}
+	// HTTP 错误以请求信息作为摘要
+	if event := resp.Events[0]; event.SpanID != spanID || event.Summary != "POST https://api.example.com/orders 502" {
+		t.Fatalf("HTTP 错误事件 %+v", event)
+	}
+	if resp.Events[1].Summary != "boom" {
+		t.Fatalf("JS 错误事件 %+v", resp.Events[1])
+	}
+
+	if _, err := service.GetTraceEvents("1", strings.Repeat("0", 32)); err == nil {
+		t.Fatal("无效的 traceId 应返回错误")
+	}
+}
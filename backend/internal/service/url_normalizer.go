@@ -0,0 +1,176 @@
+package service
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+)
+
+// 路径参数占位符
+const urlIDPlaceholder = ":id"
+
+var (
+	idPatternsOnce sync.Once
+	idPatterns     []*regexp.Regexp
+)
+
+// 编译配置中的路径参数规则
+func compiledIDPatterns() []*regexp.Regexp {
+	idPatternsOnce.Do(func() {
+		for _, pattern := range model.NormalizeSetting.IDPatterns {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				continue
+			}
+			idPatterns = append(idPatterns, re)
+		}
+	})
+	return idPatterns
+}
+
+// 将命中路径参数规则的路径片段替换为占位符，直接修改并返回传入的切片
+func replaceIDSegments(segments []string) []string {
+	patterns := compiledIDPatterns()
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		for _, re := range patterns {
+			if re.MatchString(segment) {
+				segments[i] = urlIDPlaceholder
+				break
+			}
+		}
+	}
+	return segments
+}
+
+// NormalizeURL 按配置规则将页面URL归一化为路径，去掉查询参数并将ID类路径片段替换为占位符
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	// hash 路由模式下真实路径在 fragment 中
+	path := u.Path
+	fragment := u.Fragment
+	if strings.HasPrefix(fragment, "/") {
+		path = fragment
+		fragment = ""
+		if idx := strings.Index(path, "?"); idx >= 0 {
+			path = path[:idx]
+		}
+	}
+
+	path = strings.Join(replaceIDSegments(strings.Split(path, "/")), "/")
+	if path == "" {
+		path = "/"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(path)
+	if !model.NormalizeSetting.StripQuery && u.RawQuery != "" {
+		builder.WriteString("?" + u.RawQuery)
+	}
+	if !model.NormalizeSetting.StripHash && fragment != "" {
+		builder.WriteString("#" + fragment)
+	}
+
+	return builder.String()
+}
+
+// 文件名中的构建哈希，如 app.3f9a1c2b.js、chunk-8d7e6f5a4b.css
+var assetHashPattern = regexp.MustCompile(`([.\-_])[0-9a-fA-F]{8,}(\.[A-Za-z0-9]+)$`)
+
+// NormalizeResourceURL 归一化资源URL，保留域名并将文件名中的构建哈希替换为 *
+func NormalizeResourceURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return NormalizeURL(rawURL)
+	}
+
+	// 文件名只替换构建哈希，其余路径片段替换路径参数
+	segments := strings.Split(u.Path, "/")
+	last := len(segments) - 1
+	fileName := segments[last]
+	segments = replaceIDSegments(segments[:last])
+	segments = append(segments, assetHashPattern.ReplaceAllString(fileName, "${1}*${2}"))
+
+	return u.Host + strings.Join(segments, "/")
+}
+
+// URLHost 获取URL中的域名（不含端口）
+func URLHost(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// IsFirstPartyHost 判断资源域名是否属于第一方：与页面同域或命中配置的第一方域名
+func IsFirstPartyHost(host, pageHost string) bool {
+	if host == "" || host == pageHost {
+		return true
+	}
+	for _, domain := range model.NormalizeSetting.FirstPartyDomains {
+		domain = strings.TrimSpace(domain)
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// 按归一化页面筛选原始URL的 LIKE 模式，路径参数占位符匹配任意片段，页面路径之后只能是URL结尾、查询参数或 hash。
+// 模式在 SQL 中缩小范围，可能有少量误匹配，结果仍需用 NormalizeURL 精确过滤
+func pageURLPatterns(page string) []string {
+	variants := []string{page}
+	// 上报的URL中非 ASCII 字符通常是转义后的形式
+	if escaped := escapePagePath(page); escaped != page {
+		variants = append(variants, escaped)
+	}
+
+	var patterns []string
+	for _, variant := range variants {
+		segments := strings.Split(variant, "/")
+		for i, segment := range segments {
+			if segment == urlIDPlaceholder {
+				segments[i] = "%"
+			} else {
+				segments[i] = model.EscapeLike(segment)
+			}
+		}
+		pattern := "%" + strings.Join(segments, "/")
+		patterns = append(patterns, pattern, pattern+"?%", pattern+"#%")
+	}
+	return patterns
+}
+
+// 转义页面路径的各个片段，路径参数占位符保持不变
+func escapePagePath(page string) string {
+	segments := strings.Split(page, "/")
+	for i, segment := range segments {
+		if segment != urlIDPlaceholder {
+			segments[i] = url.PathEscape(segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
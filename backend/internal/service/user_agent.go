@@ -0,0 +1,104 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 设备类型
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+)
+
+// UserAgentInfo 从 User-Agent 解析的客户端信息
+type UserAgentInfo struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	DeviceType     string
+}
+
+// 浏览器识别规则，按顺序匹配，基于 Chromium 的浏览器需排在 Chrome 之前
+var browserPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`(?:Edg|Edge|EdgA|EdgiOS)/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"UC Browser", regexp.MustCompile(`UCBrowser/([\d.]+)`)},
+	{"WeChat", regexp.MustCompile(`MicroMessenger/([\d.]+)`)},
+	{"QQ Browser", regexp.MustCompile(`MQQBrowser/([\d.]+)|QQBrowser/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"IE", regexp.MustCompile(`MSIE ([\d.]+)|Trident/.*rv:([\d.]+)`)},
+}
+
+// 操作系统识别规则，按顺序匹配
+var osPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Windows", regexp.MustCompile(`Windows NT ([\d.]+)`)},
+	{"iOS", regexp.MustCompile(`(?:iPhone|iPad|iPod).*? OS ([\d_]+)`)},
+	{"Android", regexp.MustCompile(`Android ([\d.]+)`)},
+	{"HarmonyOS", regexp.MustCompile(`HarmonyOS ([\d.]+)`)},
+	{"macOS", regexp.MustCompile(`Mac OS X ([\d_.]+)`)},
+	{"Chrome OS", regexp.MustCompile(`CrOS \S+ ([\d.]+)`)},
+	{"Linux", regexp.MustCompile(`Linux()`)},
+}
+
+var botPattern = regexp.MustCompile(`(?i)bot|crawler|spider|headless|lighthouse`)
+
+// ParseUserAgent 解析 User-Agent，无法识别的字段留空
+func ParseUserAgent(userAgent string) UserAgentInfo {
+	var info UserAgentInfo
+	if userAgent == "" {
+		return info
+	}
+
+	for _, rule := range browserPatterns {
+		if match := rule.pattern.FindStringSubmatch(userAgent); match != nil {
+			info.Browser = rule.name
+			info.BrowserVersion = firstSubmatch(match)
+			break
+		}
+	}
+
+	for _, rule := range osPatterns {
+		if match := rule.pattern.FindStringSubmatch(userAgent); match != nil {
+			info.OS = rule.name
+			info.OSVersion = strings.ReplaceAll(firstSubmatch(match), "_", ".")
+			break
+		}
+	}
+
+	switch {
+	case botPattern.MatchString(userAgent):
+		info.DeviceType = DeviceTypeBot
+	case strings.Contains(userAgent, "iPad") || strings.Contains(userAgent, "Tablet") ||
+		(strings.Contains(userAgent, "Android") && !strings.Contains(userAgent, "Mobile")):
+		info.DeviceType = DeviceTypeTablet
+	case strings.Contains(userAgent, "Mobi") || strings.Contains(userAgent, "iPhone"):
+		info.DeviceType = DeviceTypeMobile
+	default:
+		info.DeviceType = DeviceTypeDesktop
+	}
+
+	return info
+}
+
+// 返回第一个非空的捕获组
+func firstSubmatch(match []string) string {
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
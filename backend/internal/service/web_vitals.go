@@ -0,0 +1,249 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Web Vitals 评级
+const (
+	VitalRatingGood             = "good"
+	VitalRatingNeedsImprovement = "needs-improvement"
+	VitalRatingPoor             = "poor"
+)
+
+// 统计的分位数
+var vitalPercentiles = []float64{0.5, 0.75, 0.9, 0.95, 0.99}
+
+// 性能指标定义，阈值取自 web.dev 官方标准
+type vitalMetric struct {
+	Name        string
+	BucketWidth float64 // 直方图的最小分辨率，对数桶以此为单位
+	Good        float64 // 小于等于该值为良好
+	Poor        float64 // 大于该值为差
+	Rated       bool
+}
+
+var vitalMetrics = []vitalMetric{
+	{Name: "FP", BucketWidth: 10},
+	{Name: "FCP", BucketWidth: 10, Good: 1800, Poor: 3000, Rated: true},
+	{Name: "LCP", BucketWidth: 10, Good: 2500, Poor: 4000, Rated: true},
+	{Name: "FID", BucketWidth: 1, Good: 100, Poor: 300, Rated: true},
+	{Name: "INP", BucketWidth: 1, Good: 200, Poor: 500, Rated: true},
+	{Name: "CLS", BucketWidth: 0.001, Good: 0.1, Poor: 0.25, Rated: true},
+	{Name: "TTFB", BucketWidth: 10, Good: 800, Poor: 1800, Rated: true},
+}
+
+// 全部性能指标名称
+func vitalMetricNames() []string {
+	names := make([]string, 0, len(vitalMetrics))
+	for _, metric := range vitalMetrics {
+		names = append(names, metric.Name)
+	}
+	return names
+}
+
+// WebVitalsResponse Web Vitals 分位数统计响应
+type WebVitalsResponse struct {
+	Range    StatsRangeInfo       `json:"range"`
+	Metrics  []WebVitalMetric     `json:"metrics"`
+	Trend    []WebVitalsTrendItem `json:"trend"`
+	Previous []WebVitalMetric     `json:"previous"` // 上一周期的分位数和评级分布
+}
+
+// WebVitalMetric 单个指标的分位数和评级分布
+type WebVitalMetric struct {
+	Name             string  `json:"name"`
+	Samples          int64   `json:"samples"`
+	P50              float64 `json:"p50"`
+	P75              float64 `json:"p75"`
+	P90              float64 `json:"p90"`
+	P95              float64 `json:"p95"`
+	P99              float64 `json:"p99"`
+	Good             int64   `json:"good"`
+	NeedsImprovement int64   `json:"needsImprovement"`
+	Poor             int64   `json:"poor"`
+	Rating           string  `json:"rating"`
+}
+
+// WebVitalsTrendItem 每个时间桶的 p75 趋势
+type WebVitalsTrendItem struct {
+	Date string             `json:"date"`
+	P75  map[string]float64 `json:"p75"`
+}
+
+// 直方图桶
+type vitalBucket struct {
+	Bucket int
+	Count  int64
+}
+
+// GetWebVitals 获取时间范围内的 Web Vitals 分位数统计，并返回上一周期的对比数据
+func (s *EventService) GetWebVitals(projectIDStr string, query StatsQuery) (*WebVitalsResponse, error) {
+	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
+	if err != nil {
+		return nil, errors.New("无效的项目ID")
+	}
+
+	r, err := parseStatsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &WebVitalsResponse{Range: r.info()}
+	if resp.Metrics, err = s.getWebVitalMetrics(uint(projectID), r); err != nil {
+		return nil, err
+	}
+	if resp.Trend, err = s.getWebVitalsTrend(uint(projectID), r); err != nil {
+		return nil, err
+	}
+	if resp.Previous, err = s.getWebVitalMetrics(uint(projectID), r.previous()); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// 获取各指标的分位数和评级分布
+func (s *EventService) getWebVitalMetrics(projectID uint, r *statsRange) ([]WebVitalMetric, error) {
+	metrics := make([]WebVitalMetric, 0, len(vitalMetrics))
+	for _, metric := range vitalMetrics {
+		// 获取直方图
+		buckets, err := s.rollupHistogram(projectID, metric.Name, r.Start, r.End)
+		if err != nil {
+			return nil, err
+		}
+
+		item := WebVitalMetric{Name: metric.Name}
+		values := percentilesFromHistogram(buckets, metric.BucketWidth, vitalPercentiles)
+		item.P50, item.P75, item.P90, item.P95, item.P99 = values[0], values[1], values[2], values[3], values[4]
+		for _, bucket := range buckets {
+			item.Samples += bucket.Count
+		}
+
+		// 获取评级分布
+		if metric.Rated && item.Samples > 0 {
+			ratings, err := s.rollupTotals(projectID, rollupMetricVitalRating, metric.Name, r.Start, r.End)
+			if err != nil {
+				return nil, err
+			}
+			item.Good = ratings[VitalRatingGood].Count
+			item.Poor = ratings[VitalRatingPoor].Count
+			item.NeedsImprovement = item.Samples - item.Good - item.Poor
+			item.Rating = rateVital(metric, item.P75)
+		}
+
+		metrics = append(metrics, item)
+	}
+
+	return metrics, nil
+}
+
+// 获取每个时间桶的 p75 趋势
+func (s *EventService) getWebVitalsTrend(projectID uint, r *statsRange) ([]WebVitalsTrendItem, error) {
+	byBucket := make(map[int64]map[string]float64)
+	for _, metric := range vitalMetrics {
+		if !metric.Rated {
+			continue
+		}
+
+		histograms, err := s.rollupHistogramSeries(projectID, metric.Name, r)
+		if err != nil {
+			return nil, err
+		}
+		for bucket, histogram := range histograms {
+			if byBucket[bucket] == nil {
+				byBucket[bucket] = make(map[string]float64)
+			}
+			byBucket[bucket][metric.Name] = percentilesFromHistogram(histogram, metric.BucketWidth, []float64{0.75})[0]
+		}
+	}
+
+	// 没有数据的时间桶返回空的指标
+	buckets := r.buckets()
+	trend := make([]WebVitalsTrendItem, 0, len(buckets))
+	for _, bucket := range buckets {
+		p75 := byBucket[bucket]
+		if p75 == nil {
+			p75 = make(map[string]float64)
+		}
+		trend = append(trend, WebVitalsTrendItem{
+			Date: r.label(bucket),
+			P75:  p75,
+		})
+	}
+
+	return trend, nil
+}
+
+// 根据对数直方图估算分位数，桶 i 覆盖 (width*γ^(i-1), width*γ^i]，桶内按几何分布插值，零值桶的分位数为 0
+func percentilesFromHistogram(buckets []vitalBucket, width float64, percentiles []float64) []float64 {
+	result := make([]float64, len(percentiles))
+
+	var total int64
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+	if total == 0 {
+		return result
+	}
+
+	sorted := make([]vitalBucket, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Bucket < sorted[j].Bucket })
+
+	for i, p := range percentiles {
+		target := p * float64(total)
+		var cumulative int64
+		for _, bucket := range sorted {
+			if float64(cumulative+bucket.Count) >= target {
+				if bucket.Bucket == vitalZeroBucket {
+					break
+				}
+				fraction := (target - float64(cumulative)) / float64(bucket.Count)
+				result[i] = roundVital(width * math.Pow(rollupHistogramGrowth, float64(bucket.Bucket-1)+fraction))
+				break
+			}
+			cumulative += bucket.Count
+		}
+	}
+
+	return result
+}
+
+// 计算精确分位数，相邻样本之间线性插值
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	value := sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+
+	return roundVital(value)
+}
+
+// 保留三位小数，避免浮点误差
+func roundVital(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// 根据官方阈值评级
+func rateVital(metric vitalMetric, value float64) string {
+	switch {
+	case value <= metric.Good:
+		return VitalRatingGood
+	case value <= metric.Poor:
+		return VitalRatingNeedsImprovement
+	default:
+		return VitalRatingPoor
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/akinoccc/web-tracing-admin/internal/repository"
+)
+
+func TestGetWebVitalsFromHistogram(t *testing.T) {
+	var lcp vitalMetric
+	for _, metric := range vitalMetrics {
+		if metric.Name == "LCP" {
+			lcp = metric
+		}
+	}
+
+	fakes := newFakeRepositories()
+	fakes.stats.histograms = map[string][]repository.RollupBucketSum{
+		"LCP": {
+			{Bucket: vitalHistogramBucket(lcp, 2000), Count: 1},
+			{Bucket: vitalHistogramBucket(lcp, 3000), Count: 3},
+		},
+	}
+	fakes.stats.sums = map[string][]repository.RollupCountSum{
+		rollupMetricVitalRating + "/LCP": {{Value: VitalRatingGood, Count: 1}},
+	}
+
+	resp, err := fakes.eventService().GetWebVitals("1", StatsQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := make(map[string]WebVitalMetric)
+	for _, metric := range resp.Metrics {
+		metrics[metric.Name] = metric
+	}
+	item := metrics["LCP"]
+	// 分位数按直方图估算，相对误差在桶宽以内
+	if item.Samples != 4 || item.P75 < 3000/rollupHistogramGrowth || item.P75 > 3000*rollupHistogramGrowth {
+		t.Fatalf("LCP %+v", item)
+	}
+	if item.Good != 1 || item.NeedsImprovement != 3 || item.Poor != 0 || item.Rating != VitalRatingNeedsImprovement {
+		t.Fatalf("LCP 评级 %+v", item)
+	}
+	// 没有样本的指标不评级
+	if fcp := metrics["FCP"]; fcp.Samples != 0 || fcp.Rating != "" {
+		t.Fatalf("FCP %+v", fcp)
+	}
+}
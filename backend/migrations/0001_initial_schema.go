@@ -0,0 +1,543 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// 初始表结构，与原先启动时 AutoMigrate 创建的表一致；已有数据库执行时只补齐缺少的表和字段。
+// 建表使用下方冻结的模型快照，不随 model 包的修改而变化，之后的表结构变更需要新增迁移
+func init() {
+	register(Migration{
+		Version: "0001",
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			// 先创建基础表
+			if err := tx.AutoMigrate(initialBaseModels...); err != nil {
+				return err
+			}
+			// 再创建依赖事件主表的各类事件表
+			if err := tx.AutoMigrate(&initialEventMain{}); err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(initialEventModels...); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(initialErrorModels...)
+		},
+		Down: func(tx *gorm.DB) error {
+			// 按依赖关系倒序删除
+			if err := tx.Migrator().DropTable(initialErrorModels...); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropTable(initialEventModels...); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropTable(&initialEventMain{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(initialBaseModels...)
+		},
+	})
+}
+
+var initialBaseModels = []interface{}{
+	&initialUser{},
+	&initialProject{},
+	&initialBaseInfo{},
+	&initialJobCursor{},
+	&initialPerformanceBudget{},
+	&initialRetentionPolicy{},
+	&initialRollupCount{},
+	&initialRollupSketch{},
+	&initialRollupHistogram{},
+}
+
+var initialEventModels = []interface{}{
+	&initialPerformancePageDetail{},
+	&initialPerformanceResourceDetail{},
+	&initialPVDetail{},
+	&initialClickDetail{},
+	&initialDwellDetail{},
+	&initialIntersectionDetail{},
+	&initialCustomDetail{},
+	&initialFrustrationDetail{},
+	&initialLongTaskDetail{},
+	&initialInteractionDetail{},
+	&initialLogDetail{},
+	&initialRouteDetail{},
+	&initialRequestDetail{},
+}
+
+var initialErrorModels = []interface{}{
+	&initialErrorDetail{},
+	&initialHttpErrorDetail{},
+	&initialResourceErrorDetail{},
+	&initialVueErrorDetail{},
+	&initialReactErrorDetail{},
+	&initialErrorGroup{},
+}
+
+// 以下为初始表结构对应的模型快照，只保留建表需要的字段和标签，不要修改
+
+type initialModel struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type initialUser struct {
+	Model    initialModel `gorm:"embedded"`
+	Username string       `gorm:"size:50;not null;unique"`
+	Password string       `gorm:"size:255;not null"`
+	Email    string       `gorm:"size:100;not null;unique"`
+}
+
+func (initialUser) TableName() string { return model.TableName("user") }
+
+type initialProject struct {
+	Model       initialModel `gorm:"embedded"`
+	Name        string       `gorm:"size:100;not null"`
+	AppKey      string       `gorm:"size:50;not null;unique"`
+	Description string       `gorm:"type:text"`
+	UserID      uint
+	User        initialUser `gorm:"foreignKey:UserID"`
+}
+
+func (initialProject) TableName() string { return model.TableName("project") }
+
+type initialBaseInfo struct {
+	Model          initialModel `gorm:"embedded"`
+	ProjectID      uint         `gorm:"not null"`
+	AppKey         string       `gorm:"size:50;not null"`
+	UserID         string       `gorm:"size:100"`
+	UserUUID       string       `gorm:"size:100"`
+	SessionID      string       `gorm:"size:100"`
+	PageURL        string       `gorm:"type:text"`
+	Referrer       string       `gorm:"type:text"`
+	UserAgent      string       `gorm:"type:text"`
+	IP             string       `gorm:"size:50"`
+	Browser        string       `gorm:"size:50"`
+	BrowserVersion string       `gorm:"size:50"`
+	OS             string       `gorm:"size:50"`
+	OSVersion      string       `gorm:"size:50"`
+	Device         string       `gorm:"size:50"`
+	DeviceType     string       `gorm:"size:50"`
+	Vendor         string       `gorm:"size:50"`
+	// 扩展字段
+	SDKVersion   string `gorm:"size:50"`
+	SDKUserUUID  string `gorm:"size:100"`
+	AppName      string `gorm:"size:100"`
+	AppCode      string `gorm:"size:50"`
+	Platform     string `gorm:"size:50"`
+	ScreenWidth  int
+	ScreenHeight int
+	ClientWidth  int
+	ClientHeight int
+	ColorDepth   int
+	PixelDepth   int
+	DeviceID     string `gorm:"size:100"`
+	PageID       string `gorm:"size:100"`
+	Region       string `gorm:"size:100"`
+	Release      string `gorm:"column:release_version;size:100;index"` // release 是 MySQL 保留字
+	Environment  string `gorm:"size:50"`
+	SendTime     int64
+	Ext          string `gorm:"type:text"`
+}
+
+func (initialBaseInfo) TableName() string { return model.TableName("base_info") }
+
+type initialEventMain struct {
+	Model          initialModel     `gorm:"embedded"`
+	EventID        string           `gorm:"size:100;not null;unique"`
+	EventType      string           `gorm:"size:50;not null"`
+	ProjectID      uint             `gorm:"not null"`
+	BaseInfoID     uint             `gorm:"not null"`
+	BaseInfo       *initialBaseInfo `gorm:"foreignKey:BaseInfoID"`
+	TriggerTime    int64            `gorm:"not null"`
+	SendTime       int64            `gorm:"not null"`
+	TriggerPageURL string           `gorm:"type:text"`
+	Title          string           `gorm:"size:255"`
+	Referer        string           `gorm:"type:text"`
+	// W3C Trace Context，用于关联后端链路
+	TraceID string `gorm:"size:32;index"`
+	SpanID  string `gorm:"size:16"`
+}
+
+func (initialEventMain) TableName() string { return model.TableName("event_main") }
+
+type initialPerformancePageDetail struct {
+	Model           initialModel      `gorm:"embedded"`
+	EventID         uint              `gorm:"not null"`
+	Event           *initialEventMain `gorm:"foreignKey:EventID"`
+	FP              int64
+	FCP             int64
+	LCP             int64
+	FID             int64
+	INP             int64
+	CLS             float64
+	TTFB            int64
+	DomReady        int64
+	Load            int64
+	FirstByte       int64
+	DNS             int64
+	TCP             int64
+	SSL             int64
+	TTFB2           int64
+	Trans           int64
+	DomParse        int64
+	ResourceLoad    int64
+	DomContentLoad  int64
+	FirstScreenTime int64
+}
+
+func (initialPerformancePageDetail) TableName() string {
+	return model.TableName("performance_page_detail")
+}
+
+type initialPerformanceResourceDetail struct {
+	Model           initialModel      `gorm:"embedded"`
+	EventID         uint              `gorm:"not null"`
+	Event           *initialEventMain `gorm:"foreignKey:EventID"`
+	InitiatorType   string            `gorm:"size:50;not null"`
+	ResourceType    string            `gorm:"size:50;not null"`
+	ResourceURL     string            `gorm:"type:text;not null"`
+	ResponseStatus  string            `gorm:"size:50"`
+	StartTime       int64             `gorm:"not null"`
+	Duration        int64             `gorm:"not null"`
+	TransferSize    int64
+	EncodedBodySize int64
+	DecodedBodySize int64
+	DNSTime         int64
+	TCPTime         int64
+	SSLTime         int64
+	TTFB            int64
+	DownloadTime    int64
+	FromCache       bool
+}
+
+func (initialPerformanceResourceDetail) TableName() string {
+	return model.TableName("performance_resource_detail")
+}
+
+type initialPVDetail struct {
+	Model        initialModel      `gorm:"embedded"`
+	EventID      uint              `gorm:"not null"`
+	Event        *initialEventMain `gorm:"foreignKey:EventID"`
+	PageURL      string            `gorm:"type:text;not null"`
+	Title        string            `gorm:"size:255"`
+	Referrer     string            `gorm:"type:text"`
+	StayTime     int64
+	IsNewVisit   bool
+	IsNewSession bool
+}
+
+func (initialPVDetail) TableName() string { return model.TableName("pv_detail") }
+
+type initialClickDetail struct {
+	Model       initialModel      `gorm:"embedded"`
+	EventID     uint              `gorm:"not null"`
+	Event       *initialEventMain `gorm:"foreignKey:EventID"`
+	ElementPath string            `gorm:"type:text"`
+	ElementType string            `gorm:"size:50"`
+	InnerText   string            `gorm:"type:text"`
+	// 点击坐标（相对页面左上角）及视口尺寸
+	X              int
+	Y              int
+	ViewportWidth  int
+	ViewportHeight int
+}
+
+func (initialClickDetail) TableName() string { return model.TableName("click_detail") }
+
+type initialDwellDetail struct {
+	Model    initialModel      `gorm:"embedded"`
+	EventID  uint              `gorm:"not null"`
+	Event    *initialEventMain `gorm:"foreignKey:EventID"`
+	PageURL  string            `gorm:"type:text;not null"`
+	Title    string            `gorm:"size:255"`
+	StayTime int64             `gorm:"not null"`
+}
+
+func (initialDwellDetail) TableName() string { return model.TableName("dwell_detail") }
+
+type initialIntersectionDetail struct {
+	Model       initialModel      `gorm:"embedded"`
+	EventID     uint              `gorm:"not null"`
+	Event       *initialEventMain `gorm:"foreignKey:EventID"`
+	ElementPath string            `gorm:"type:text"`
+	ElementType string            `gorm:"size:50"`
+	InnerText   string            `gorm:"type:text"`
+}
+
+func (initialIntersectionDetail) TableName() string { return model.TableName("intersection_detail") }
+
+type initialCustomDetail struct {
+	Model       initialModel      `gorm:"embedded"`
+	EventID     uint              `gorm:"not null"`
+	Event       *initialEventMain `gorm:"foreignKey:EventID"`
+	EventName   string            `gorm:"size:100;not null"`
+	EventParams string            `gorm:"type:text"`
+	Data        string            `gorm:"type:text"`
+}
+
+func (initialCustomDetail) TableName() string { return model.TableName("custom_detail") }
+
+type initialLogDetail struct {
+	Model     initialModel      `gorm:"embedded"`
+	EventID   uint              `gorm:"not null"`
+	Event     *initialEventMain `gorm:"foreignKey:EventID"`
+	Level     string            `gorm:"size:20;index"`
+	Message   string            `gorm:"type:text"`
+	Arguments string            `gorm:"type:text"` // 参数列表的 JSON
+	Stack     string            `gorm:"type:text"`
+}
+
+func (initialLogDetail) TableName() string { return model.TableName("log_detail") }
+
+type initialLongTaskDetail struct {
+	Model            initialModel      `gorm:"embedded"`
+	EventID          uint              `gorm:"not null"`
+	Event            *initialEventMain `gorm:"foreignKey:EventID"`
+	Kind             string            `gorm:"size:50"`
+	StartTime        int64
+	Duration         int64
+	BlockingDuration int64
+	ScriptURL        string `gorm:"type:text"`
+	ScriptFunction   string `gorm:"size:200"`
+	ScriptInvoker    string `gorm:"size:200"`
+	ScriptDuration   int64
+}
+
+func (initialLongTaskDetail) TableName() string { return model.TableName("long_task_detail") }
+
+type initialInteractionDetail struct {
+	Model             initialModel      `gorm:"embedded"`
+	EventID           uint              `gorm:"not null"`
+	Event             *initialEventMain `gorm:"foreignKey:EventID"`
+	InteractionType   string            `gorm:"size:50"`
+	ElementPath       string            `gorm:"type:text"`
+	ElementType       string            `gorm:"size:50"`
+	StartTime         int64
+	Duration          int64
+	InputDelay        int64
+	ProcessingTime    int64
+	PresentationDelay int64
+}
+
+func (initialInteractionDetail) TableName() string { return model.TableName("interaction_detail") }
+
+type initialRouteDetail struct {
+	Model    initialModel      `gorm:"embedded"`
+	EventID  uint              `gorm:"not null"`
+	Event    *initialEventMain `gorm:"foreignKey:EventID"`
+	FromURL  string            `gorm:"type:text"`
+	ToURL    string            `gorm:"type:text"`
+	Mode     string            `gorm:"size:20"` // history、hash
+	Action   string            `gorm:"size:20"` // push、replace、pop
+	Duration int64             // 路由切换耗时（毫秒）
+	StayTime int64             // 在来源路由的停留时间（毫秒）
+}
+
+func (initialRouteDetail) TableName() string { return model.TableName("route_detail") }
+
+type initialRequestDetail struct {
+	Model       initialModel      `gorm:"embedded"`
+	EventID     uint              `gorm:"not null"`
+	Event       *initialEventMain `gorm:"foreignKey:EventID"`
+	URL         string            `gorm:"type:text"`
+	URLTemplate string            `gorm:"size:255;index"` // 路径参数替换为 :id 后的接口地址
+	Method      string            `gorm:"size:20"`
+	Params      string            `gorm:"type:text"`
+	Status      int
+	Duration    int64
+	Success     bool
+	SampleRate  float64 // 入库时的采样率，用于估算实际调用量
+}
+
+func (initialRequestDetail) TableName() string { return model.TableName("request_detail") }
+
+type initialFrustrationDetail struct {
+	Model        initialModel      `gorm:"embedded"`
+	EventID      uint              `gorm:"not null"`
+	Event        *initialEventMain `gorm:"foreignKey:EventID"`
+	SessionID    string            `gorm:"size:100"`
+	PageURL      string            `gorm:"type:text"`
+	ElementPath  string            `gorm:"type:text"`
+	ElementType  string            `gorm:"size:50"`
+	InnerText    string            `gorm:"type:text"`
+	ClickCount   int
+	FirstClickID uint
+	StartTime    int64
+	EndTime      int64
+}
+
+func (initialFrustrationDetail) TableName() string { return model.TableName("frustration_detail") }
+
+type initialErrorDetail struct {
+	Model         initialModel      `gorm:"embedded"`
+	EventID       uint              `gorm:"not null"`
+	Event         *initialEventMain `gorm:"foreignKey:EventID"`
+	ErrorType     string            `gorm:"size:50;not null"`
+	ErrorMessage  string            `gorm:"type:text;not null"`
+	ErrorStack    string            `gorm:"type:text"`
+	FilePath      string            `gorm:"type:text"`
+	LineNumber    int
+	ColumnNumber  int
+	ComponentName string `gorm:"size:100"`
+	RecordScreen  string `gorm:"type:text"`
+	// 扩展字段
+	Severity    string `gorm:"size:20"`
+	Fingerprint string
+	SubType     string `gorm:"size:50"`
+	Context     string `gorm:"type:text"`
+}
+
+func (initialErrorDetail) TableName() string { return model.TableName("error_detail") }
+
+type initialHttpErrorDetail struct {
+	Model        initialModel      `gorm:"embedded"`
+	EventID      uint              `gorm:"not null"`
+	Event        *initialEventMain `gorm:"foreignKey:EventID"`
+	URL          string            `gorm:"type:text;not null"`
+	Method       string            `gorm:"size:20;not null"`
+	Status       int               `gorm:"not null"`
+	StatusText   string            `gorm:"size:100"`
+	RequestData  string            `gorm:"type:text"`
+	ResponseData string            `gorm:"type:text"`
+	Duration     int64
+	ErrorType    string `gorm:"size:50"`
+	ErrorMessage string `gorm:"type:text"`
+	TraceID      string `gorm:"size:32;index"`
+}
+
+func (initialHttpErrorDetail) TableName() string { return model.TableName("http_error_detail") }
+
+type initialResourceErrorDetail struct {
+	Model        initialModel      `gorm:"embedded"`
+	EventID      uint              `gorm:"not null"`
+	Event        *initialEventMain `gorm:"foreignKey:EventID"`
+	ResourceURL  string            `gorm:"type:text;not null"`
+	ResourceType string            `gorm:"size:50;not null"`
+	ErrorType    string            `gorm:"size:50"`
+	ErrorMessage string            `gorm:"type:text"`
+	ElementType  string            `gorm:"size:50"`
+}
+
+func (initialResourceErrorDetail) TableName() string { return model.TableName("resource_error_detail") }
+
+type initialVueErrorDetail struct {
+	Model         initialModel      `gorm:"embedded"`
+	EventID       uint              `gorm:"not null"`
+	Event         *initialEventMain `gorm:"foreignKey:EventID"`
+	ComponentName string            `gorm:"size:100"`
+	PropsData     string            `gorm:"type:text"`
+	ErrorType     string            `gorm:"size:50;not null"`
+	ErrorMessage  string            `gorm:"type:text;not null"`
+	ErrorStack    string            `gorm:"type:text"`
+	Info          string            `gorm:"type:text"`
+}
+
+func (initialVueErrorDetail) TableName() string { return model.TableName("vue_error_detail") }
+
+type initialReactErrorDetail struct {
+	Model          initialModel      `gorm:"embedded"`
+	EventID        uint              `gorm:"not null"`
+	Event          *initialEventMain `gorm:"foreignKey:EventID"`
+	ComponentName  string            `gorm:"size:100"`
+	ComponentStack string            `gorm:"type:text"`
+	ErrorType      string            `gorm:"size:50;not null"`
+	ErrorMessage   string            `gorm:"type:text;not null"`
+	ErrorStack     string            `gorm:"type:text"`
+}
+
+func (initialReactErrorDetail) TableName() string { return model.TableName("react_error_detail") }
+
+type initialErrorGroup struct {
+	Model         initialModel   `gorm:"embedded"`
+	Fingerprint   string         `gorm:"size:100;not null;unique"`
+	ErrorType     string         `gorm:"size:50;not null"`
+	ErrorMessage  string         `gorm:"type:text;not null"`
+	Count         int            `gorm:"not null;default:1"`
+	FirstSeen     int64          `gorm:"not null"`
+	LastSeen      int64          `gorm:"not null"`
+	ProjectID     uint           `gorm:"not null"`
+	Project       initialProject `gorm:"foreignKey:ProjectID"`
+	SampleEventID uint
+	Status        string `gorm:"size:20;default:'active'"`
+	Severity      string `gorm:"size:20"`
+	SubType       string `gorm:"size:50"`
+}
+
+func (initialErrorGroup) TableName() string { return model.TableName("error_group") }
+
+type initialPerformanceBudget struct {
+	Model        initialModel    `gorm:"embedded"`
+	ProjectID    uint            `gorm:"not null;index"`
+	Project      *initialProject `gorm:"foreignKey:ProjectID"`
+	Name         string          `gorm:"size:100;not null"`
+	Metric       string          `gorm:"size:50;not null"`
+	PageURL      string          `gorm:"size:500"` // 归一化后的页面，为空表示所有页面
+	ResourceType string          `gorm:"size:50"`  // 资源类指标的资源类型，为空表示所有类型
+	Threshold    float64         `gorm:"not null"`
+	Enabled      bool
+}
+
+func (initialPerformanceBudget) TableName() string { return model.TableName("performance_budget") }
+
+type initialRetentionPolicy struct {
+	Model     initialModel    `gorm:"embedded"`
+	ProjectID uint            `gorm:"not null;uniqueIndex:idx_retention_project_category"`
+	Project   *initialProject `gorm:"foreignKey:ProjectID"`
+	Category  string          `gorm:"size:50;not null;uniqueIndex:idx_retention_project_category"`
+	Days      int             `gorm:"not null"`
+}
+
+func (initialRetentionPolicy) TableName() string { return model.TableName("retention_policy") }
+
+type initialRollupCount struct {
+	Model       initialModel `gorm:"embedded"`
+	ProjectID   uint         `gorm:"not null;uniqueIndex:idx_rollup_count"`
+	Granularity string       `gorm:"size:10;not null;uniqueIndex:idx_rollup_count"`
+	BucketStart int64        `gorm:"not null;uniqueIndex:idx_rollup_count"`
+	Metric      string       `gorm:"size:50;not null;uniqueIndex:idx_rollup_count"`
+	Dimension   string       `gorm:"size:20;not null;uniqueIndex:idx_rollup_count"`
+	Value       string       `gorm:"size:255;not null;uniqueIndex:idx_rollup_count"`
+	Count       int64        `gorm:"not null"`
+	Sum         float64      `gorm:"not null"`
+}
+
+func (initialRollupCount) TableName() string { return model.TableName("rollup_count") }
+
+type initialRollupSketch struct {
+	Model       initialModel `gorm:"embedded"`
+	ProjectID   uint         `gorm:"not null;uniqueIndex:idx_rollup_sketch"`
+	Granularity string       `gorm:"size:10;not null;uniqueIndex:idx_rollup_sketch"`
+	BucketStart int64        `gorm:"not null;uniqueIndex:idx_rollup_sketch"`
+	Metric      string       `gorm:"size:50;not null;uniqueIndex:idx_rollup_sketch"`
+	Sketch      []byte       `gorm:"not null"`
+}
+
+func (initialRollupSketch) TableName() string { return model.TableName("rollup_sketch") }
+
+type initialRollupHistogram struct {
+	Model       initialModel `gorm:"embedded"`
+	ProjectID   uint         `gorm:"not null;uniqueIndex:idx_rollup_histogram"`
+	Granularity string       `gorm:"size:10;not null;uniqueIndex:idx_rollup_histogram"`
+	BucketStart int64        `gorm:"not null;uniqueIndex:idx_rollup_histogram"`
+	Metric      string       `gorm:"size:50;not null;uniqueIndex:idx_rollup_histogram"`
+	Bucket      int          `gorm:"not null;uniqueIndex:idx_rollup_histogram"`
+	Count       int64        `gorm:"not null"`
+}
+
+func (initialRollupHistogram) TableName() string { return model.TableName("rollup_histogram") }
+
+type initialJobCursor struct {
+	Model  initialModel `gorm:"embedded"`
+	Name   string       `gorm:"size:100;not null;unique"`
+	LastID uint         `gorm:"not null;default:0"`
+}
+
+func (initialJobCursor) TableName() string { return model.TableName("job_cursor") }
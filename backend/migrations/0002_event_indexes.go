@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// 事件查询常用的索引：按项目、事件类型和时间范围筛选事件，按项目筛选基础信息，
+// 以及各详情表关联事件主表的 event_id
+func init() {
+	register(Migration{
+		Version: "0002",
+		Name:    "event_indexes",
+		Up: func(tx *gorm.DB) error {
+			for _, index := range eventIndexes() {
+				if err := createIndex(tx, index.table, index.name, index.columns); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range eventIndexes() {
+				if err := dropIndex(tx, index.table, index.name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+type tableIndex struct {
+	table   string
+	name    string
+	columns string
+}
+
+// 表名和索引名包含配置的表前缀，在执行时计算
+func eventIndexes() []tableIndex {
+	indexes := []tableIndex{
+		{table: "event_main", name: "project_type_time", columns: "project_id, event_type, trigger_time"},
+		{table: "base_info", name: "project", columns: "project_id"},
+	}
+	for _, table := range []string{
+		"performance_page_detail",
+		"performance_resource_detail",
+		"pv_detail",
+		"click_detail",
+		"dwell_detail",
+		"intersection_detail",
+		"custom_detail",
+		"frustration_detail",
+		"long_task_detail",
+		"interaction_detail",
+		"log_detail",
+		"route_detail",
+		"request_detail",
+		"error_detail",
+		"http_error_detail",
+		"resource_error_detail",
+		"vue_error_detail",
+		"react_error_detail",
+	} {
+		indexes = append(indexes, tableIndex{table: table, name: "event_id", columns: "event_id"})
+	}
+
+	for i := range indexes {
+		indexes[i].table = model.TableName(indexes[i].table)
+		indexes[i].name = "idx_" + indexes[i].table + "_" + indexes[i].name
+	}
+	return indexes
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// 早期版本按上报原样保存事件时间，服务端生成的发送时间和错误分组时间也是秒级，统一转换为毫秒，与入库时的处理保持一致。
+// 转换后无法区分原始单位，回滚时保留毫秒
+func init() {
+	register(Migration{
+		Version: "0003",
+		Name:    "event_time_millis",
+		Up: func(tx *gorm.DB) error {
+			columns := []struct{ table, column string }{
+				{"event_main", "trigger_time"},
+				{"event_main", "send_time"},
+				{"base_info", "send_time"},
+				{"error_group", "first_seen"},
+				{"error_group", "last_seen"},
+			}
+			for _, c := range columns {
+				sql := fmt.Sprintf("UPDATE %s SET %s = %s * 1000 WHERE %s > 0 AND %s < ?",
+					model.TableName(c.table), c.column, c.column, c.column, c.column)
+				if err := tx.Exec(sql, int64(1e12)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
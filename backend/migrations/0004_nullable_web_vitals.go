@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// 页面性能指标的列本身可为空，此前未上报的指标保存为 0，与值为 0 的指标（如 CLS）无法区分。
+// 已有数据中除 CLS 外的 0 按未上报处理，改为 NULL；CLS 为 0 表示没有布局偏移，是常见的有效值，保持不变。
+// 回滚时全部恢复为 0
+var webVitalColumns = []string{"fp", "fcp", "lcp", "f_id", "inp", "ttfb", "dom_ready", "load"}
+
+func init() {
+	register(Migration{
+		Version: "0004",
+		Name:    "nullable_web_vitals",
+		Up: func(tx *gorm.DB) error {
+			return updateWebVitalColumns(tx, webVitalColumns, "= 0", "NULL")
+		},
+		Down: func(tx *gorm.DB) error {
+			return updateWebVitalColumns(tx, append(webVitalColumns, "cls"), "IS NULL", "0")
+		},
+	})
+}
+
+func updateWebVitalColumns(tx *gorm.DB, columns []string, condition, value string) error {
+	table := model.TableName("performance_page_detail")
+	for _, column := range columns {
+		sql := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s %s", table, tx.Statement.Quote(column), value, tx.Statement.Quote(column), condition)
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// 页面访问预聚合改为按归一化页面分组，跳出和平均停留时间只统计停留时间已知的访问。
+// 清空页面访问相关的预聚合并重置游标，由预聚合任务从头重新聚合；回滚时无需处理
+func init() {
+	register(Migration{
+		Version: "0005",
+		Name:    "rebuild_pv_rollups",
+		Up: func(tx *gorm.DB) error {
+			metrics := []string{"pv", "pv_stay", "pv_bounce", "uv"}
+			for _, table := range []string{"rollup_count", "rollup_sketch", "rollup_histogram"} {
+				sql := fmt.Sprintf("DELETE FROM %s WHERE metric IN ?", model.TableName(table))
+				if err := tx.Exec(sql, metrics).Error; err != nil {
+					return err
+				}
+			}
+			sql := fmt.Sprintf("DELETE FROM %s WHERE name = ?", model.TableName("job_cursor"))
+			return tx.Exec(sql, "rollup_pv").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
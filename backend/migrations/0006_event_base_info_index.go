@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// 清理事件时按 base_info_id 查找仍被引用的基础信息，为事件主表的 base_info_id 建立索引，
+// 避免每批清理都扫描整个事件主表
+func init() {
+	register(Migration{
+		Version: "0006",
+		Name:    "event_base_info_index",
+		Up: func(tx *gorm.DB) error {
+			table := model.TableName("event_main")
+			return createIndex(tx, table, "idx_"+table+"_base_info_id", "base_info_id")
+		},
+		Down: func(tx *gorm.DB) error {
+			table := model.TableName("event_main")
+			return dropIndex(tx, table, "idx_"+table+"_base_info_id")
+		},
+	})
+}
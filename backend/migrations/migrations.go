@@ -0,0 +1,199 @@
+// Package migrations 数据库版本迁移，按版本号顺序执行并记录在迁移表中
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akinoccc/web-tracing-admin/internal/model"
+	"gorm.io/gorm"
+)
+
+// Migration 一次数据库迁移，Up 和 Down 在同一事务中执行并更新迁移记录。
+// MySQL 的 DDL 会隐式提交事务，迁移需要能在中途失败后重新执行
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	model.Model
+	Version string `json:"version" gorm:"size:50;not null;unique"`
+	Name    string `json:"name" gorm:"size:100;not null"`
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Unknown   bool // 数据库中有记录但当前版本没有对应的迁移
+}
+
+// 全部迁移，在各迁移文件的 init 中注册
+var migrations []Migration
+
+// 注册迁移
+func register(migration Migration) {
+	migrations = append(migrations, migration)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+}
+
+// 创建迁移记录表并读取已执行的迁移
+func appliedMigrations(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[string]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Up 按版本顺序执行全部未执行的迁移，返回本次执行的迁移
+func Up(db *gorm.DB) ([]Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %s_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("回滚数量必须大于0: %d", steps)
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var done []Migration
+	for _, version := range versions {
+		migration, ok := findMigration(version)
+		if !ok {
+			return done, fmt.Errorf("迁移 %s 不存在，无法回滚", version)
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("迁移 %s_%s 不支持回滚", migration.Version, migration.Name)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", migration.Version).Delete(&SchemaMigration{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移 %s_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status 获取全部迁移的执行状态，按版本排序
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.CreatedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: record.CreatedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Pending 未执行的迁移数量
+func Pending(db *gorm.DB) (int, error) {
+	statuses, err := Status(db)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func findMigration(version string) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// 创建索引，已存在时跳过
+func createIndex(tx *gorm.DB, table, name string, columns string) error {
+	if tx.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s)", name, table, columns)).Error
+}
+
+// 删除索引，不存在时跳过
+func dropIndex(tx *gorm.DB, table, name string) error {
+	if !tx.Migrator().HasIndex(table, name) {
+		return nil
+	}
+	return tx.Migrator().DropIndex(table, name)
+}